@@ -0,0 +1,555 @@
+package taponark
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/commitment"
+	"github.com/lightninglabs/taproot-assets/proof"
+	"github.com/lightningnetwork/lnd/keychain"
+)
+
+// Human readable parts of Ark addresses, keyed by chain name.
+var arkAddressHrps = map[string]string{
+	chaincfg.MainNetParams.Name:       "ark",
+	chaincfg.TestNet3Params.Name:      "tark",
+	chaincfg.SigNetParams.Name:        "sark",
+	chaincfg.RegressionNetParams.Name: "rark",
+}
+
+// Payload tags of the asset an address receives.
+const (
+	arkAddressAssetId  byte = 0
+	arkAddressGroupKey byte = 1
+)
+
+// ArkAddress receives asset VTXOs of a single asset, or of any asset of a
+// group. The VTXO key is a fresh key of the receiver, the VTXOs paid to it
+// are spent cooperatively by the receiver and the server or by the receiver
+// alone once ExitDelay blocks deep.
+type ArkAddress struct {
+	Network   string
+	ServerKey *btcec.PublicKey
+	VtxoKey   *btcec.PublicKey
+	AssetId   []byte
+	GroupKey  *btcec.PublicKey
+	// Amount is the asset amount to pay, zero accepts any amount
+	Amount    uint64
+	ExitDelay uint32
+	// VtxoKeyLocator locates VtxoKey in the receiver's wallet. It is only set
+	// on the receiver's own addresses and never encoded.
+	VtxoKeyLocator *keychain.KeyLocator
+}
+
+// NewArkAddress returns an address of the receiver at the server with the Ark
+// key serverKey. Exactly one of assetId and groupKey is set.
+func NewArkAddress(receiver *TapClient, serverKey *btcec.PublicKey, assetId []byte, groupKey *btcec.PublicKey, amount uint64, exitDelay uint32) (ArkAddress, error) {
+	if (len(assetId) == 0) == (groupKey == nil) {
+		return ArkAddress{}, fmt.Errorf("ark address needs either an asset id or a group key")
+	}
+	if len(assetId) != 0 && len(assetId) != len(asset.ID{}) {
+		return ArkAddress{}, fmt.Errorf("invalid asset id length %d", len(assetId))
+	}
+	if exitDelay == 0 {
+		return ArkAddress{}, fmt.Errorf("ark address needs an exit delay")
+	}
+
+	vtxoKey, err := receiver.NewArkVtxoKey()
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("cannot derive vtxo key %v", err)
+	}
+
+	return ArkAddress{receiver.chainParams.Name, serverKey, vtxoKey.PubKey, assetId, groupKey, amount, exitDelay, &vtxoKey.KeyLocator}, nil
+}
+
+// Encode returns the bech32m encoding of the address.
+func (a ArkAddress) Encode() (string, error) {
+	hrp, ok := arkAddressHrps[a.Network]
+	if !ok {
+		return "", fmt.Errorf("no ark addresses on network %s", a.Network)
+	}
+
+	return encodeBech32m(hrp, a.payload())
+}
+
+// payload serializes the address without its network.
+func (a ArkAddress) payload() []byte {
+	var payload bytes.Buffer
+	payload.WriteByte(ARK_ADDRESS_VERSION)
+	payload.Write(a.ServerKey.SerializeCompressed())
+	payload.Write(a.VtxoKey.SerializeCompressed())
+	binary.Write(&payload, binary.BigEndian, a.ExitDelay)
+	binary.Write(&payload, binary.BigEndian, a.Amount)
+	if a.GroupKey != nil {
+		payload.WriteByte(arkAddressGroupKey)
+		payload.Write(a.GroupKey.SerializeCompressed())
+	} else {
+		payload.WriteByte(arkAddressAssetId)
+		payload.Write(a.AssetId)
+	}
+
+	return payload.Bytes()
+}
+
+func encodeBech32m(hrp string, payload []byte) (string, error) {
+	data, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", fmt.Errorf("cannot convert payload %v", err)
+	}
+
+	encoded, err := bech32.EncodeM(hrp, data)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode payload %v", err)
+	}
+
+	return encoded, nil
+}
+
+// decodeBech32m returns the human readable part and the payload of a bech32m
+// string.
+func decodeBech32m(encoded string) (string, []byte, error) {
+	// Payloads are longer than the 90 characters bech32 allows on-chain
+	hrp, data, err := bech32.DecodeNoLimit(encoded)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// DecodeNoLimit accepts either checksum, only bech32m re-encodes the same
+	reencoded, err := bech32.EncodeM(hrp, data)
+	if err != nil || reencoded != strings.ToLower(encoded) {
+		return "", nil, fmt.Errorf("not bech32m encoded")
+	}
+
+	payload, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot convert payload %v", err)
+	}
+
+	return hrp, payload, nil
+}
+
+// hrpNetwork returns the chain name of a human readable part of the given
+// kind, e.g. the request prefix of addresses.
+func hrpNetwork(hrp, suffix string) (string, error) {
+	for name, networkHrp := range arkAddressHrps {
+		if networkHrp+suffix == hrp {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown prefix %s", hrp)
+}
+
+// DecodeArkAddress parses a bech32m encoded Ark address.
+func DecodeArkAddress(encoded string) (ArkAddress, error) {
+	hrp, payload, err := decodeBech32m(encoded)
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("cannot decode ark address %v", err)
+	}
+
+	network, err := hrpNetwork(hrp, "")
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("cannot decode ark address %v", err)
+	}
+
+	return parseArkAddress(network, payload)
+}
+
+func parseArkAddress(network string, raw []byte) (ArkAddress, error) {
+	payload := bytes.NewReader(raw)
+	version, err := payload.ReadByte()
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("empty ark address")
+	}
+	if version != ARK_ADDRESS_VERSION {
+		return ArkAddress{}, fmt.Errorf("unknown ark address version %d", version)
+	}
+
+	addr := ArkAddress{Network: network}
+	addr.ServerKey, err = readAddressKey(payload)
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("invalid ark address server key %v", err)
+	}
+	addr.VtxoKey, err = readAddressKey(payload)
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("invalid ark address vtxo key %v", err)
+	}
+	err = binary.Read(payload, binary.BigEndian, &addr.ExitDelay)
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("invalid ark address exit delay %v", err)
+	}
+	err = binary.Read(payload, binary.BigEndian, &addr.Amount)
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("invalid ark address amount %v", err)
+	}
+
+	assetTag, err := payload.ReadByte()
+	if err != nil {
+		return ArkAddress{}, fmt.Errorf("ark address names no asset")
+	}
+	switch assetTag {
+	case arkAddressAssetId:
+		addr.AssetId = make([]byte, len(asset.ID{}))
+		n, _ := payload.Read(addr.AssetId)
+		if n != len(addr.AssetId) || payload.Len() != 0 {
+			return ArkAddress{}, fmt.Errorf("invalid ark address asset id")
+		}
+	case arkAddressGroupKey:
+		addr.GroupKey, err = readAddressKey(payload)
+		if err != nil || payload.Len() != 0 {
+			return ArkAddress{}, fmt.Errorf("invalid ark address group key")
+		}
+	default:
+		return ArkAddress{}, fmt.Errorf("unknown ark address asset tag %d", assetTag)
+	}
+	if addr.ExitDelay == 0 {
+		return ArkAddress{}, fmt.Errorf("ark address has no exit delay")
+	}
+
+	return addr, nil
+}
+
+func readAddressKey(payload *bytes.Reader) (*btcec.PublicKey, error) {
+	rawKey := make([]byte, btcec.PubKeyBytesLenCompressed)
+	n, _ := payload.Read(rawKey)
+	if n != len(rawKey) {
+		return nil, fmt.Errorf("key too short")
+	}
+
+	return btcec.ParsePubKey(rawKey)
+}
+
+// CheckPayment verifies a payment of amount of the asset through the server
+// with the Ark key serverKey on network fits the address.
+func (a ArkAddress) CheckPayment(network string, serverKey *btcec.PublicKey, assetId []byte, groupKey *btcec.PublicKey, amount uint64) error {
+	if a.Network != network {
+		return fmt.Errorf("ark address is for %s, not %s", a.Network, network)
+	}
+	if !a.ServerKey.IsEqual(serverKey) {
+		return fmt.Errorf("ark address is for server %x", a.ServerKey.SerializeCompressed())
+	}
+	if a.GroupKey != nil {
+		if groupKey == nil || !a.GroupKey.IsEqual(groupKey) {
+			return fmt.Errorf("asset %x is not in the group of the ark address", assetId)
+		}
+	} else if !bytes.Equal(a.AssetId, assetId) {
+		return fmt.Errorf("ark address receives asset %x, not %x", a.AssetId, assetId)
+	}
+	if a.Amount != 0 && a.Amount != amount {
+		return fmt.Errorf("ark address requests %d, not %d", a.Amount, amount)
+	}
+
+	return nil
+}
+
+// vtxoPolicy is the policy of VTXOs paid to the address, a MuSig2 leaf of the
+// receiver and the server and an exit leaf of the receiver after ExitDelay.
+func (a ArkAddress) vtxoPolicy() (ArkScriptPolicy, error) {
+	collaborativeKey, err := muSig2LeafKey([]*btcec.PublicKey{a.VtxoKey, a.ServerKey})
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+	collaborativeLeaf, err := CheckSigLeaf(ArkLeafCollaborative, collaborativeKey)
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+
+	exitLeaf, err := RelativeTimelockLeaf(ArkLeafExit, a.VtxoKey, a.ExitDelay)
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+
+	return NewArkScriptPolicy(collaborativeLeaf, exitLeaf)
+}
+
+// vtxoScripts returns the asset and BTC scripts of VTXOs paid to the address,
+// anchored with an unspendable internal key.
+func (a ArkAddress) vtxoScripts() (ArkAssetScript, ArkBtcScript, error) {
+	policy, err := a.vtxoPolicy()
+	if err != nil {
+		return ArkAssetScript{}, ArkBtcScript{}, fmt.Errorf("cannot create ark address policy %v", err)
+	}
+
+	arkBtcScript, err := NewArkBtcScript(policy, ArkLeafCollaborative, asset.NUMSPubKey, BtcSpendPathScript)
+	if err != nil {
+		return ArkAssetScript{}, ArkBtcScript{}, err
+	}
+
+	return NewArkAssetScript(policy), arkBtcScript, nil
+}
+
+// ArkAddressVtxo is an asset VTXO paid to an Ark address, out of round or in
+// the leaf of a round.
+type ArkAddressVtxo struct {
+	Address    ArkAddress
+	serverKeys Cosigner
+
+	policyOutput
+}
+
+// Outpoint is the anchor output of the VTXO.
+func (v ArkAddressVtxo) Outpoint() string {
+	return v.transfer.outpoint.String()
+}
+
+// arkCosigner signs with the Ark key of the client on the asset and the BTC
+// side.
+func arkCosigner(client *TapClient) (Cosigner, error) {
+	arkKey, err := client.GetArkKey()
+	if err != nil {
+		return Cosigner{}, fmt.Errorf("cannot derive ark key %v", err)
+	}
+
+	return keyCosigner(client, arkKey), nil
+}
+
+// keyCosigner signs with key of the client on the asset and the BTC side.
+func keyCosigner(client *TapClient, key keychain.KeyDescriptor) Cosigner {
+	return Cosigner{client.signer, asset.ScriptKey{PubKey: key.PubKey, TweakedScriptKey: &asset.TweakedScriptKey{RawKey: key}}, key}
+}
+
+// SendToArkAddress pays the boarded asset and BTC of the boarding user to the
+// address out of round, co-signed by the server, and broadcasts the transfer.
+func SendToArkAddress(assetId []byte, onboardTransfer ArkBoardingTransfer, addr ArkAddress, server *TapClient, bitcoinClient *BitcoinClient) (ArkAddressVtxo, error) {
+	serverKeys, err := arkCosigner(server)
+	if err != nil {
+		return ArkAddressVtxo{}, err
+	}
+
+	groupKey, err := proofGroupKey(onboardTransfer.AssetTransferDetails.RawProofFile)
+	if err != nil {
+		return ArkAddressVtxo{}, err
+	}
+	err = addr.CheckPayment(server.chainParams.Name, serverKeys.internalKey.PubKey, assetId, groupKey, onboardTransfer.AssetTransferDetails.assetBoardingAmount)
+	if err != nil {
+		return ArkAddressVtxo{}, err
+	}
+
+	arkAssetScript, arkBtcScript, err := addr.vtxoScripts()
+	if err != nil {
+		return ArkAddressVtxo{}, err
+	}
+
+	output, err := lockBoardingTransfer(assetId, onboardTransfer, arkAssetScript, arkBtcScript, server, bitcoinClient)
+	if err != nil {
+		return ArkAddressVtxo{}, err
+	}
+
+	return ArkAddressVtxo{addr, serverKeys, output}, nil
+}
+
+// ConstructAndBroadcastAddressRound pays the boarded asset and BTC of the
+// boarding user to the address in a round. The tree is a single leaf locked to
+// the address policy, so the VTXO keeps the exit delay of the address, and the
+// boarding user co-signs the round output in place of a leaf owner.
+func ConstructAndBroadcastAddressRound(assetId []byte, onboardTransfer ArkBoardingTransfer, serverBtcAmount uint64, addr ArkAddress, server *TapClient, bitcoinClient BitcoinClient, exits ...CollaborativeExit) (Round, error) {
+	if onboardTransfer.user == nil {
+		return Round{}, fmt.Errorf("boarding has no user")
+	}
+	err := checkRoundPayment(addr, assetId, onboardTransfer, exits, server)
+	if err != nil {
+		return Round{}, err
+	}
+
+	buildTree := func(roundTransfer ColoredTransfer, roundSpendingDetails ArkSpendingDetails) (RoundTree, error) {
+		var rootNode *RoundTreeNode
+		err := constructAddressLeaf(assetId, true, roundSpendingDetails, roundTransfer, addr, server, &rootNode)
+		if err != nil {
+			return RoundTree{}, fmt.Errorf("failed to construct address leaf: %w", err)
+		}
+
+		return RoundTree{rootNode}, nil
+	}
+
+	btcTransfers := []BtcTransferDetails{onboardTransfer.btcTransferDetails}
+	roundOwners := []*TapClient{onboardTransfer.user}
+
+	return constructAndBroadcastRound(assetId, onboardTransfer, btcTransfers, serverBtcAmount, exits, roundOwners, buildTree, server, bitcoinClient)
+}
+
+// checkRoundPayment verifies a round paying what remains of the boarding after
+// the exits fits the address.
+func checkRoundPayment(addr ArkAddress, assetId []byte, onboardTransfer ArkBoardingTransfer, exits []CollaborativeExit, server *TapClient) error {
+	serverKey, err := server.GetArkKey()
+	if err != nil {
+		return fmt.Errorf("cannot derive ark key %v", err)
+	}
+	groupKey, err := proofGroupKey(onboardTransfer.AssetTransferDetails.RawProofFile)
+	if err != nil {
+		return err
+	}
+	exitAssetAmount, _, err := collaborativeExitAmounts(assetId, exits)
+	if err != nil {
+		return err
+	}
+
+	return addr.CheckPayment(server.chainParams.Name, serverKey.PubKey, assetId, groupKey, onboardTransfer.AssetTransferDetails.assetBoardingAmount-exitAssetAmount)
+}
+
+// ExitAddressVtxos broadcasts the branches of the tree leading to the leaves
+// paid to addr and returns their VTXOs, to be claimed like out of round
+// payments.
+func ExitAddressVtxos(round Round, addr ArkAddress, server *TapClient, bitcoinClient *BitcoinClient) ([]ArkAddressVtxo, error) {
+	serverKeys, err := arkCosigner(server)
+	if err != nil {
+		return nil, err
+	}
+	arkAssetScript, arkBtcScript, err := addr.vtxoScripts()
+	if err != nil {
+		return nil, err
+	}
+
+	paysAddress := func(output NodeOutput) bool {
+		return output.Address != nil && output.Address.VtxoKey.IsEqual(addr.VtxoKey)
+	}
+	var leaves []*RoundTreeNode
+	forEachLeaf(round.RoundTree.Root, func(leaf *RoundTreeNode) {
+		if paysAddress(leaf.LeftOutput) {
+			leaves = append(leaves, leaf)
+		}
+	})
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("round pays nothing to the ark address")
+	}
+
+	exitsBranch := func(node *RoundTreeNode) bool {
+		contains := false
+		forEachLeaf(node, func(leaf *RoundTreeNode) {
+			contains = contains || slices.Contains(leaves, leaf)
+		})
+		return contains
+	}
+	proofFiles, err := exitRoundBranches(round, exitsBranch, paysAddress, bitcoinClient)
+	if err != nil {
+		return nil, err
+	}
+
+	vtxos := make([]ArkAddressVtxo, len(leaves))
+	for i, leaf := range leaves {
+		transfer, err := addressLeafTransfer(leaf, arkBtcScript)
+		if err != nil {
+			return nil, err
+		}
+
+		assetId := transfer.transferProof.Asset.ID()
+		leafBtcScript := arkBtcScript
+		leafBtcScript.controlBlock, err = extractControlBlock(arkBtcScript, transfer.taprootAssetRoot)
+		if err != nil {
+			return nil, err
+		}
+
+		vtxos[i] = ArkAddressVtxo{addr, serverKeys, policyOutput{
+			server:         server,
+			arkBtcScript:   leafBtcScript,
+			arkAssetScript: arkAssetScript,
+			assetId:        assetId[:],
+			transfer:       transfer,
+			ProofFile:      proofFiles[i],
+			GenesisPoint:   round.GenesisPoint,
+		}}
+	}
+
+	return vtxos, nil
+}
+
+// addressLeafTransfer rebuilds the transfer of the address VTXO of a leaf from
+// the leaf transaction and the asset proof, failing if the anchor output is
+// not locked to the address policy.
+func addressLeafTransfer(leaf *RoundTreeNode, arkBtcScript ArkBtcScript) (ColoredTransfer, error) {
+	assetProof := leaf.LeftOutput.AssetProof
+	if assetProof == nil || assetProof.InclusionProof.CommitmentProof == nil {
+		return ColoredTransfer{}, fmt.Errorf("address leaf has no asset proof")
+	}
+
+	outpoint := assetProof.OutPoint()
+	if outpoint.Hash != leaf.Transaction.TxHash() || int(outpoint.Index) >= len(leaf.Transaction.TxOut) {
+		return ColoredTransfer{}, fmt.Errorf("asset proof is not anchored in the address leaf")
+	}
+	anchorOut := leaf.Transaction.TxOut[outpoint.Index]
+
+	sibling, err := arkBtcScript.tapscriptSibling()
+	if err != nil {
+		return ColoredTransfer{}, fmt.Errorf("cannot create ark address tapscript sibling %v", err)
+	}
+	encodedSibling, siblingHash, err := commitment.MaybeEncodeTapscriptPreimage(sibling)
+	if err != nil {
+		return ColoredTransfer{}, fmt.Errorf("cannot encode tapscript sibling %v", err)
+	}
+
+	// The proof derives the commitment of every version, the anchor output
+	// commits to one of them below the address leaves
+	commitments, err := assetProof.InclusionProof.DeriveByAssetInclusion(&assetProof.Asset, nil)
+	if err != nil {
+		return ColoredTransfer{}, fmt.Errorf("cannot derive anchor commitment %v", err)
+	}
+	for _, tapCommitment := range commitments {
+		merkleRoot := tapCommitment.TapscriptRoot(siblingHash)
+		outputKey := txscript.ComputeTaprootOutputKey(arkBtcScript.internalKey, merkleRoot[:])
+		pkScript, err := txscript.PayToTaprootScript(outputKey)
+		if err != nil {
+			return ColoredTransfer{}, fmt.Errorf("cannot create anchor script %v", err)
+		}
+		if !bytes.Equal(pkScript, anchorOut.PkScript) {
+			continue
+		}
+
+		taprootAssetRoot := tapCommitment.TapscriptRoot(nil)
+		return ColoredTransfer{
+			finalTx:          leaf.Transaction,
+			outpoint:         &outpoint,
+			transferProof:    assetProof,
+			merkleRoot:       merkleRoot[:],
+			taprootSibling:   encodedSibling,
+			internalKey:      arkBtcScript.internalKey,
+			scriptKey:        assetProof.Asset.ScriptKey,
+			anchorValue:      anchorOut.Value,
+			taprootAssetRoot: taprootAssetRoot[:],
+			assetAmount:      assetProof.Asset.Amount,
+		}, nil
+	}
+
+	return ColoredTransfer{}, fmt.Errorf("address leaf %s is not locked to the ark address", outpoint)
+}
+
+// Claim pays the VTXO to a fresh key of the receiver, co-signed by the server,
+// and returns the receiver's proof file. addr is the receiver's own copy of
+// the address the VTXO was paid to.
+func (v ArkAddressVtxo) Claim(receiver *TapClient, addr ArkAddress, bitcoinClient *BitcoinClient) ([]byte, error) {
+	if !addr.VtxoKey.IsEqual(v.Address.VtxoKey) {
+		return nil, fmt.Errorf("vtxo is not paid to the ark address")
+	}
+	vtxoKey, err := receiver.ArkVtxoKey(addr)
+	if err != nil {
+		return nil, fmt.Errorf("ark address does not belong to the receiver %v", err)
+	}
+
+	return v.spend(ArkLeafCollaborative, []Cosigner{keyCosigner(receiver, vtxoKey), v.serverKeys}, receiver, 0, 0, bitcoinClient)
+}
+
+// proofGroupKey returns the group key of the asset of the proof file, nil for
+// ungrouped assets.
+func proofGroupKey(rawProofFile []byte) (*btcec.PublicKey, error) {
+	proofFile, err := proof.DecodeFile(rawProofFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode proof file %v", err)
+	}
+
+	lastProof, err := proofFile.LastProof()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get last proof %v", err)
+	}
+
+	if lastProof.Asset.GroupKey == nil {
+		return nil, nil
+	}
+
+	return &lastProof.Asset.GroupKey.GroupPubKey, nil
+}
@@ -0,0 +1,185 @@
+package taponark_test
+
+import (
+	"bytes"
+	"taponark"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/proof"
+	"github.com/stretchr/testify/require"
+)
+
+// newExitUserAddress returns an Ark address of the exit user and checks it
+// survives its encoding.
+func (n *testNetwork) newExitUserAddress(t *testing.T, exitDelay uint32) taponark.ArkAddress {
+	t.Helper()
+
+	serverKey, err := n.server.GetArkKey()
+	require.NoError(t, err)
+
+	addr, err := taponark.NewArkAddress(&n.exit, serverKey.PubKey, n.assetId, nil, 0, exitDelay)
+	require.NoError(t, err)
+
+	encoded, err := addr.Encode()
+	require.NoError(t, err)
+	decoded, err := taponark.DecodeArkAddress(encoded)
+	require.NoError(t, err)
+	require.Equal(t, addr.ExitDelay, decoded.ExitDelay)
+	require.True(t, addr.VtxoKey.IsEqual(decoded.VtxoKey))
+	require.Nil(t, decoded.VtxoKeyLocator)
+
+	return addr
+}
+
+// claim claims the VTXO paid to addr for the exit user, checks the witnesses
+// of the claim transaction and imports the proof.
+func (n *testNetwork) claim(t *testing.T, vtxo taponark.ArkAddressVtxo, addr taponark.ArkAddress) {
+	t.Helper()
+
+	proofFile, err := vtxo.Claim(&n.exit, addr, &n.bitcoin)
+	require.NoError(t, err)
+
+	file, err := proof.DecodeFile(proofFile)
+	require.NoError(t, err)
+	claimProof, err := file.LastProof()
+	require.NoError(t, err)
+	claimTx := n.requireConfirmed(t, claimProof.AnchorTx.TxHash())
+	require.Equal(t, vtxo.Outpoint(), claimTx.TxIn[0].PreviousOutPoint.String())
+	n.requireValidWitnesses(t, claimTx)
+
+	require.NoError(t, taponark.SubmitProof(vtxo.GenesisPoint, proofFile, &n.exit))
+}
+
+func TestSendToArkAddress(t *testing.T) {
+	n := newTestNetwork(t)
+	boarding := n.board(t, taponark.BtcSpendPathScript)
+	addr := n.newExitUserAddress(t, 288)
+
+	vtxo, err := taponark.SendToArkAddress(n.assetId, boarding, addr, &n.server, &n.bitcoin)
+	require.NoError(t, err)
+	outpoint, err := wire.NewOutPointFromString(vtxo.Outpoint())
+	require.NoError(t, err)
+	n.requireValidWitnesses(t, n.requireConfirmed(t, outpoint.Hash))
+
+	// The boarding input is spent, it cannot pay a second time
+	_, err = taponark.SendToArkAddress(n.assetId, boarding, addr, &n.server, &n.bitcoin)
+	require.Error(t, err)
+
+	n.claim(t, vtxo, addr)
+
+	assetBalance, _ := n.balance(t, &n.exit)
+	require.EqualValues(t, testAssetAmount, assetBalance)
+}
+
+func TestArkAddressKeys(t *testing.T) {
+	n := newTestNetwork(t)
+	arkKey, err := n.exit.GetArkKey()
+	require.NoError(t, err)
+
+	// Every address gets a fresh key, unlinked to the Ark key
+	first := n.newExitUserAddress(t, 144)
+	second := n.newExitUserAddress(t, 144)
+	require.False(t, first.VtxoKey.IsEqual(arkKey.PubKey))
+	require.False(t, first.VtxoKey.IsEqual(second.VtxoKey))
+
+	vtxoKey, err := n.exit.ArkVtxoKey(second)
+	require.NoError(t, err)
+	require.True(t, vtxoKey.PubKey.IsEqual(second.VtxoKey))
+	require.EqualValues(t, taponark.ARK_VTXO_KEY_FAMILY, vtxoKey.Family)
+	require.Equal(t, *second.VtxoKeyLocator, vtxoKey.KeyLocator)
+
+	// A decoded address carries no locator, nor does a mismatched one derive
+	// its key
+	encoded, err := second.Encode()
+	require.NoError(t, err)
+	decoded, err := taponark.DecodeArkAddress(encoded)
+	require.NoError(t, err)
+	_, err = n.exit.ArkVtxoKey(decoded)
+	require.Error(t, err)
+	second.VtxoKeyLocator = first.VtxoKeyLocator
+	_, err = n.exit.ArkVtxoKey(second)
+	require.Error(t, err)
+}
+
+func TestRoundPaysArkAddress(t *testing.T) {
+	n := newTestNetwork(t)
+	boarding := n.board(t, taponark.BtcSpendPathScript)
+	addr := n.newExitUserAddress(t, 288)
+
+	round := n.runRound(t, taponark.RoundIntent{AssetId: n.assetId, Boarding: boarding, Address: &addr})
+
+	// A single leaf carries the asset and the BTC to the address
+	leaf := round.RoundTree.Root
+	require.Equal(t, taponark.NodeTypeLeaf, leaf.NodeType)
+	require.Len(t, leaf.Transaction.TxOut, 1)
+	require.NotNil(t, leaf.LeftOutput.Address)
+	require.Nil(t, leaf.LeftOutput.Owner)
+	require.EqualValues(t, testAssetAmount, leaf.LeftOutput.AssetAmount)
+	require.Equal(t, leaf.Transaction.TxOut[0].Value, leaf.LeftOutput.BTCAmount)
+
+	// The leaf is locked to the exit delay of the address, not the round's
+	otherDelay := addr
+	otherDelay.ExitDelay++
+	_, err := taponark.ExitAddressVtxos(round, otherDelay, &n.server, &n.bitcoin)
+	require.ErrorContains(t, err, "not locked to the ark address")
+
+	vtxos, err := taponark.ExitAddressVtxos(round, addr, &n.server, &n.bitcoin)
+	require.NoError(t, err)
+	require.Len(t, vtxos, 1)
+	n.requireValidWitnesses(t, n.requireConfirmed(t, leaf.Transaction.TxHash()))
+
+	n.claim(t, vtxos[0], addr)
+
+	assetBalance, _ := n.balance(t, &n.exit)
+	require.EqualValues(t, testAssetAmount, assetBalance)
+}
+
+func TestPayRequest(t *testing.T) {
+	n := newTestNetwork(t)
+	boarding := n.board(t, taponark.BtcSpendPathScript)
+	addr := n.newExitUserAddress(t, 144)
+
+	request, err := taponark.NewPaymentRequest(&n.exit, addr, n.assetId, testAssetAmount, time.Hour, "coffee")
+	require.NoError(t, err)
+	encoded, err := request.Encode()
+	require.NoError(t, err)
+	decoded, err := taponark.DecodePaymentRequest(encoded)
+	require.NoError(t, err)
+
+	// A tampered request, or one for another amount than boarded, is refused
+	// before paying
+	tampered := decoded
+	tampered.Amount++
+	_, _, err = taponark.PayRequest(tampered, boarding, &n.server, &n.bitcoin)
+	require.ErrorContains(t, err, "not signed by the receiver")
+	smaller, err := taponark.NewPaymentRequest(&n.exit, addr, n.assetId, testAssetAmount-1, time.Hour, "coffee")
+	require.NoError(t, err)
+	require.ErrorContains(t, smaller.CheckPayable(testAssetAmount), "carry no change")
+	_, _, err = taponark.PayRequest(smaller, boarding, &n.server, &n.bitcoin)
+	require.ErrorContains(t, err, "carry no change")
+
+	vtxo, receipt, err := taponark.PayRequest(decoded, boarding, &n.server, &n.bitcoin)
+	require.NoError(t, err)
+	require.Equal(t, vtxo.Outpoint(), receipt.Outpoint)
+	require.Equal(t, "coffee", receipt.Request.Memo)
+	require.NoError(t, receipt.Verify(&n.bitcoin))
+
+	// A receipt claiming another outpoint does not verify
+	forged := receipt
+	forged.Outpoint = boarding.AssetOutpoint()
+	require.Error(t, forged.Verify(&n.bitcoin))
+	forged = receipt
+	forged.ProofFile = bytes.Clone(boarding.AssetTransferDetails.RawProofFile)
+	require.Error(t, forged.Verify(&n.bitcoin))
+
+	// Nor does a receipt whose blocks are not in the chain
+	other := newTestNetwork(t)
+	require.ErrorContains(t, receipt.Verify(&other.bitcoin), "is not in the chain")
+
+	n.claim(t, vtxo, addr)
+
+	assetBalance, _ := n.balance(t, &n.exit)
+	require.EqualValues(t, testAssetAmount, assetBalance)
+}
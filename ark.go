@@ -1,49 +1,92 @@
 package taponark
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
 	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
-	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/commitment"
 	"github.com/lightninglabs/taproot-assets/proof"
 	"github.com/lightninglabs/taproot-assets/tappsbt"
 	"github.com/lightninglabs/taproot-assets/taprpc"
 	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
 )
 
 const LOCK_BLOCK_HEIGHT = 4320
 
+// BtcSpendPath selects how the cooperative path of a BTC anchor is spent.
+type BtcSpendPath int
+
+const (
+	// BtcSpendPathScript anchors under a NUMS internal key and spends
+	// cooperatively through an n-of-n OP_CHECKSIGADD tapscript leaf.
+	BtcSpendPathScript BtcSpendPath = iota
+	// BtcSpendPathMuSig2 anchors under a MuSig2 aggregate of all cosigners
+	// and spends cooperatively with a single key-path signature.
+	// Only the unilateral leaf sits next to the Taproot Asset commitment.
+	BtcSpendPathMuSig2
+)
+
+// ParseBtcSpendPath maps the btc_spend_path config value to a BtcSpendPath,
+// an empty value keeps the script path.
+func ParseBtcSpendPath(spendPath string) (BtcSpendPath, error) {
+	switch spendPath {
+	case "", "script":
+		return BtcSpendPathScript, nil
+	case "musig2":
+		return BtcSpendPathMuSig2, nil
+	default:
+		return BtcSpendPathScript, fmt.Errorf("unknown btc spend path %s", spendPath)
+	}
+}
+
+// String returns the btc_spend_path config value of the spend path.
+func (p BtcSpendPath) String() string {
+	if p == BtcSpendPathMuSig2 {
+		return "musig2"
+	}
+
+	return "script"
+}
+
+// ArkBtcScript is the BTC side of an Ark output, its policy is committed next
+// to the Taproot Asset commitment of the anchor. The control block proves the
+// spend leaf and is set once the Taproot Asset root is known.
 type ArkBtcScript struct {
-	cooperativeSpend txscript.TapLeaf
-	unilateralSpend  txscript.TapLeaf
-	Branch           txscript.TapBranch
-	controlBlock     *txscript.ControlBlock
+	policy       ArkScriptPolicy
+	spendLeaf    string
+	controlBlock *txscript.ControlBlock
+	spendPath    BtcSpendPath
+	internalKey  *btcec.PublicKey
 }
 
+// ArkAssetScript is the asset side of an Ark output, a script key committing
+// to the policy.
 type ArkAssetScript struct {
-	userNonce    *musig2.Nonces
-	serverNonce  *musig2.Nonces
 	tapScriptKey asset.ScriptKey
+	policy       ArkScriptPolicy
+}
 
-	cooperativeSpend txscript.TapLeaf
-	unilateralSpend  txscript.TapLeaf
-
-	tree         *txscript.IndexedTapScriptTree
-	controlBlock *txscript.ControlBlock
+// Cosigner is a participant of the cooperative path of an Ark output. The
+// script key signs the asset virtual transaction, the internal key the BTC
+// anchor.
+type Cosigner struct {
+	signer      Signer
+	scriptKey   asset.ScriptKey
+	internalKey keychain.KeyDescriptor
 }
 
+// ArkSpendingDetails describes how an Ark output is spent. The cosigners are
+// the owners of every leaf below the output followed by the server, which
+// always comes last and coordinates the signing sessions.
 type ArkSpendingDetails struct {
-	userScriptKey     asset.ScriptKey
-	userInternalKey   keychain.KeyDescriptor
-	serverScriptKey   asset.ScriptKey
-	serverInternalKey keychain.KeyDescriptor
+	cosigners []Cosigner
 
 	arkBtcScript   ArkBtcScript
 	arkAssetScript ArkAssetScript
@@ -68,12 +111,53 @@ type ColoredTransfer struct {
 	assetAmount      uint64
 }
 
+// TransferOutputAnchor describes the anchor output of the transfer the way
+// tapd reports it.
+func (t ColoredTransfer) TransferOutputAnchor() *taprpc.TransferOutputAnchor {
+	return &taprpc.TransferOutputAnchor{
+		Outpoint:         t.outpoint.String(),
+		Value:            t.anchorValue,
+		InternalKey:      t.internalKey.SerializeCompressed(),
+		TaprootAssetRoot: t.taprootAssetRoot,
+		MerkleRoot:       t.merkleRoot,
+		TapscriptSibling: t.taprootSibling,
+	}
+}
+
 type ArkBoardingTransfer struct {
 	AssetTransferDetails AssetTransferDetails
 	btcTransferDetails   BtcTransferDetails
 	user                 *TapClient
 }
 
+// AssetAmount is the boarded asset amount.
+func (t ArkBoardingTransfer) AssetAmount() uint64 {
+	return t.AssetTransferDetails.assetBoardingAmount
+}
+
+// BtcAmount is the boarded BTC amount, without the anchor of the asset.
+func (t ArkBoardingTransfer) BtcAmount() uint64 {
+	return t.btcTransferDetails.btcBoardingAmount
+}
+
+// AssetOutpoint is the boarding output of the asset, empty without asset.
+func (t ArkBoardingTransfer) AssetOutpoint() string {
+	if t.AssetTransferDetails.AssetTransferOutput == nil {
+		return ""
+	}
+
+	return t.AssetTransferDetails.AssetTransferOutput.Anchor.Outpoint
+}
+
+// BtcOutpoint is the boarding output of the BTC, empty without BTC.
+func (t ArkBoardingTransfer) BtcOutpoint() string {
+	if t.btcTransferDetails.outpoint == nil {
+		return ""
+	}
+
+	return t.btcTransferDetails.outpoint.String()
+}
+
 type AssetTransferDetails struct {
 	AssetTransferOutput *taprpc.TransferOutput
 	ArkSpendingDetails  ArkSpendingDetails
@@ -88,61 +172,94 @@ type BtcTransferDetails struct {
 	arkSpendingDetails ArkSpendingDetails
 }
 
-func CreateRoundSpendingDetails(user, server *TapClient) (ArkSpendingDetails, error) {
-	userScriptKey, userInternalKey, err := user.GetNextKeys()
+func newCosigner(client *TapClient) (Cosigner, error) {
+	scriptKey, internalKey, err := client.GetNextKeys()
 	if err != nil {
-		return ArkSpendingDetails{}, fmt.Errorf("failed to fetch user  keys %v", err)
+		return Cosigner{}, err
 	}
 
-	serverScriptKey, serverInternalKey, err := server.GetNextKeys()
+	return Cosigner{client.signer, scriptKey, internalKey}, nil
+}
+
+// createCosigners derives fresh keys for every user followed by the server.
+func createCosigners(users []*TapClient, server *TapClient) ([]Cosigner, error) {
+	cosigners := make([]Cosigner, 0, len(users)+1)
+	for i, user := range users {
+		cosigner, err := newCosigner(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch user %d keys %v", i, err)
+		}
+		cosigners = append(cosigners, cosigner)
+	}
+
+	serverCosigner, err := newCosigner(server)
 	if err != nil {
-		return ArkSpendingDetails{}, fmt.Errorf("failed to fetch server keys %v", err)
+		return nil, fmt.Errorf("failed to fetch server keys %v", err)
+	}
+
+	return append(cosigners, serverCosigner), nil
+}
+
+func cosignerScriptKeys(cosigners []Cosigner) []*btcec.PublicKey {
+	keys := make([]*btcec.PublicKey, len(cosigners))
+	for i, cosigner := range cosigners {
+		keys[i] = cosigner.scriptKey.RawKey.PubKey
 	}
 
-	arkBtcScript, err := CreateRoundArkBtcScript(userInternalKey.PubKey, serverInternalKey.PubKey)
+	return keys
+}
+
+func cosignerInternalKeys(cosigners []Cosigner) []*btcec.PublicKey {
+	keys := make([]*btcec.PublicKey, len(cosigners))
+	for i, cosigner := range cosigners {
+		keys[i] = cosigner.internalKey.PubKey
+	}
+
+	return keys
+}
+
+// CreateRoundSpendingDetails creates the spending details of a round or tree
+// output co-signed by every user owning a leaf below it and the server.
+func CreateRoundSpendingDetails(users []*TapClient, server *TapClient, spendPath BtcSpendPath) (ArkSpendingDetails, error) {
+	cosigners, err := createCosigners(users, server)
+	if err != nil {
+		return ArkSpendingDetails{}, err
+	}
+
+	arkBtcScript, err := CreateRoundArkBtcScript(cosignerInternalKeys(cosigners), spendPath)
 	if err != nil {
 		return ArkSpendingDetails{}, fmt.Errorf("failed to fetch round ark btc script %v", err)
 	}
-	arkAssetScript, err := CreateRoundArkAssetScript(userScriptKey.RawKey.PubKey, serverScriptKey.RawKey.PubKey)
+	arkAssetScript, err := CreateRoundArkAssetScript(cosignerScriptKeys(cosigners))
 	if err != nil {
 		return ArkSpendingDetails{}, fmt.Errorf("failed to fetch round ark asset script %v", err)
 	}
 
 	return ArkSpendingDetails{
-		userScriptKey,
-		userInternalKey,
-		serverScriptKey,
-		serverInternalKey,
+		cosigners,
 		arkBtcScript,
 		arkAssetScript,
 	}, nil
 
 }
 
-func CreateOnboardSpendingDetails(user, server *TapClient) (ArkSpendingDetails, error) {
-	userScriptKey, userInternalKey, err := user.GetNextKeys()
-	if err != nil {
-		return ArkSpendingDetails{}, fmt.Errorf("failed to fetch user  keys %v", err)
-	}
-
-	serverScriptKey, serverInternalKey, err := server.GetNextKeys()
+func CreateOnboardSpendingDetails(user, server *TapClient, spendPath BtcSpendPath) (ArkSpendingDetails, error) {
+	cosigners, err := createCosigners([]*TapClient{user}, server)
 	if err != nil {
-		return ArkSpendingDetails{}, fmt.Errorf("failed to fetch server keys %v", err)
+		return ArkSpendingDetails{}, err
 	}
+	userKeys, serverKeys := cosigners[0], cosigners[1]
 
-	arkBtcScript, err := CreateBoardingArkBtcScript(userInternalKey.PubKey, serverInternalKey.PubKey)
+	arkBtcScript, err := CreateBoardingArkBtcScript(userKeys.internalKey.PubKey, serverKeys.internalKey.PubKey, spendPath)
 	if err != nil {
 		return ArkSpendingDetails{}, fmt.Errorf("failed to create round ark btc script %v", err)
 	}
-	arkAssetScript, err := CreateBoardingArkAssetScript(userScriptKey.RawKey.PubKey, serverScriptKey.RawKey.PubKey)
+	arkAssetScript, err := CreateBoardingArkAssetScript(userKeys.scriptKey.RawKey.PubKey, serverKeys.scriptKey.RawKey.PubKey)
 	if err != nil {
 		return ArkSpendingDetails{}, fmt.Errorf("failed to create round ark asset script %v", err)
 	}
 	return ArkSpendingDetails{
-		userScriptKey,
-		userInternalKey,
-		serverScriptKey,
-		serverInternalKey,
+		cosigners,
 		arkBtcScript,
 		arkAssetScript,
 	}, nil
@@ -160,7 +277,7 @@ func CreateBtcKeys(amount uint64, user, server *TapClient) (ArkBtcKeys, error) {
 		return ArkBtcKeys{}, err
 	}
 
-	arkScript, err := CreateBoardingArkBtcScript(userInternalKey.PubKey, serverInternalKey.PubKey)
+	arkScript, err := CreateBoardingArkBtcScript(userInternalKey.PubKey, serverInternalKey.PubKey, BtcSpendPathScript)
 	if err != nil {
 		return ArkBtcKeys{}, err
 	}
@@ -169,255 +286,242 @@ func CreateBtcKeys(amount uint64, user, server *TapClient) (ArkBtcKeys, error) {
 
 }
 
-func CreateBoardingArkBtcScript(user, server *btcec.PublicKey) (ArkBtcScript, error) {
-	cooperativeScript, err := txscript.NewScriptBuilder().
-		AddData(schnorr.SerializePubKey(user)).
-		AddOp(txscript.OP_CHECKSIG).
-		AddData(schnorr.SerializePubKey(server)).
-		AddOp(txscript.OP_CHECKSIGADD).
-		AddInt64(2).
-		AddOp(txscript.OP_EQUAL).
-		Script()
-
-	if err != nil {
-		return ArkBtcScript{}, fmt.Errorf("failed to create cooperative script: %w", err)
-
-	}
-
-	cooperativeLeaf := txscript.NewTapLeaf(txscript.BaseLeafVersion, cooperativeScript)
-
-	unilateralScript, err := txscript.NewScriptBuilder().
-		AddInt64(int64(LOCK_BLOCK_HEIGHT)).
-		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
-		AddData(schnorr.SerializePubKey(user)).
-		AddOp(txscript.OP_CHECKSIG).
-		Script()
-
-	if err != nil {
-		return ArkBtcScript{}, fmt.Errorf("failed to decode left script: %w", err)
+// NewArkBtcScript anchors the policy under internalKey. The spend leaf is the
+// one proven by the control block of the output.
+func NewArkBtcScript(policy ArkScriptPolicy, spendLeaf string, internalKey *btcec.PublicKey, spendPath BtcSpendPath) (ArkBtcScript, error) {
+	if !policy.HasLeaf(spendLeaf) {
+		return ArkBtcScript{}, fmt.Errorf("script policy has no %s leaf", spendLeaf)
 	}
 
-	unilateralLeaf := txscript.NewTapLeaf(txscript.BaseLeafVersion, unilateralScript)
-
-	branch := txscript.NewTapBranch(cooperativeLeaf, unilateralLeaf)
-
-	return ArkBtcScript{cooperativeSpend: cooperativeLeaf, unilateralSpend: unilateralLeaf, Branch: branch}, nil
+	return ArkBtcScript{policy: policy, spendLeaf: spendLeaf, spendPath: spendPath, internalKey: internalKey}, nil
 }
 
-func CreateBoardingArkAssetScript(user, server *btcec.PublicKey) (ArkAssetScript, error) {
-	userBoardingNonceOpt := musig2.WithPublicKey(
-		user,
-	)
-	serverBoardingNonceOpt := musig2.WithPublicKey(
-		server,
-	)
-	userNonces, _ := musig2.GenNonces(userBoardingNonceOpt)
-	serverNonces, _ := musig2.GenNonces(serverBoardingNonceOpt)
-
-	musigUserServer, err := input.MuSig2CombineKeys(
-		input.MuSig2Version100RC2, []*btcec.PublicKey{
-			user,
-			server,
-		}, true, &input.MuSig2Tweaks{TaprootBIP0086Tweak: true},
-	)
-
+// createArkBtcScript builds the BTC anchor script of the cosigner keys, the
+// unilateral key can spend alone once LOCK_BLOCK_HEIGHT is reached. MuSig2
+// anchors spend cooperatively with the key path, so only the exit leaf is
+// left in the tree.
+func createArkBtcScript(cosignerKeys []*btcec.PublicKey, unilateralKey *btcec.PublicKey, spendPath BtcSpendPath) (ArkBtcScript, error) {
+	exitLeaf, err := AbsoluteTimelockLeaf(ArkLeafExit, unilateralKey, LOCK_BLOCK_HEIGHT)
 	if err != nil {
-		return ArkAssetScript{}, fmt.Errorf("failed to combine musig keys: %v", err)
+		return ArkBtcScript{}, err
 	}
 
-	cooperativeScript, err := txscript.NewScriptBuilder().
-		AddData(schnorr.SerializePubKey(musigUserServer.FinalKey)).
-		AddOp(txscript.OP_CHECKSIG).
-		Script()
+	leaves := []ArkLeaf{exitLeaf}
+	spendLeaf := ArkLeafExit
+	if spendPath != BtcSpendPathMuSig2 {
+		cooperativeLeaf, err := MultisigLeaf(ArkLeafCollaborative, cosignerKeys)
+		if err != nil {
+			return ArkBtcScript{}, err
+		}
+		leaves = []ArkLeaf{cooperativeLeaf, exitLeaf}
+		spendLeaf = ArkLeafCollaborative
+	}
 
+	policy, err := NewArkScriptPolicy(leaves...)
 	if err != nil {
-		return ArkAssetScript{}, fmt.Errorf("cannot create tapscript %v", err)
+		return ArkBtcScript{}, err
 	}
 
-	cooperativeSpend := txscript.TapLeaf{
-		LeafVersion: txscript.BaseLeafVersion,
-		Script:      cooperativeScript,
+	internalKey, err := btcAnchorInternalKey(cosignerKeys, spendPath)
+	if err != nil {
+		return ArkBtcScript{}, err
 	}
 
-	sweep, err := txscript.NewScriptBuilder().
-		AddInt64(int64(LOCK_BLOCK_HEIGHT)).
-		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
-		AddData(schnorr.SerializePubKey(user)).
-		AddOp(txscript.OP_CHECKSIG).
-		Script()
+	return NewArkBtcScript(policy, spendLeaf, internalKey, spendPath)
+}
 
-	if err != nil {
-		return ArkAssetScript{}, fmt.Errorf("cannot create sweep script %v", err)
-	}
+func CreateBoardingArkBtcScript(user, server *btcec.PublicKey, spendPath BtcSpendPath) (ArkBtcScript, error) {
+	return createArkBtcScript([]*btcec.PublicKey{user, server}, user, spendPath)
+}
 
-	unilateralExit := txscript.TapLeaf{
-		LeafVersion: txscript.BaseLeafVersion,
-		Script:      sweep,
-	}
+// CreateRoundArkBtcScript builds the BTC script of a round output, the last
+// cosigner key is the server's which can sweep the output after the lock.
+func CreateRoundArkBtcScript(cosignerKeys []*btcec.PublicKey, spendPath BtcSpendPath) (ArkBtcScript, error) {
+	return createArkBtcScript(cosignerKeys, cosignerKeys[len(cosignerKeys)-1], spendPath)
+}
 
-	tree := txscript.AssembleTaprootScriptTree(cooperativeSpend, unilateralExit)
-	internalKey := asset.NUMSPubKey
-	controlBlock := &txscript.ControlBlock{
-		LeafVersion: txscript.BaseLeafVersion,
-		InternalKey: internalKey,
+// btcAnchorInternalKey returns the taproot internal key of a BTC anchor for the
+// given spend path.
+func btcAnchorInternalKey(cosignerKeys []*btcec.PublicKey, spendPath BtcSpendPath) (*btcec.PublicKey, error) {
+	if spendPath != BtcSpendPathMuSig2 {
+		return asset.NUMSPubKey, nil
 	}
-	merkleRootHash := tree.RootNode.TapHash()
 
-	tapKey := txscript.ComputeTaprootOutputKey(
-		internalKey, merkleRootHash[:],
+	// The merkle root is only known once the Taproot Asset commitment is,
+	// so the untweaked aggregate is used as the internal key.
+	aggregateKey, err := input.MuSig2CombineKeys(
+		input.MuSig2Version100RC2, cosignerKeys, true,
+		&input.MuSig2Tweaks{},
 	)
-	tapScriptKey := asset.ScriptKey{
-		PubKey: tapKey,
-		TweakedScriptKey: &asset.TweakedScriptKey{
-			RawKey: keychain.KeyDescriptor{
-				PubKey: internalKey,
-			},
-			Tweak: merkleRootHash[:],
-		},
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine musig keys: %v", err)
 	}
 
-	if tapKey.SerializeCompressed()[0] ==
-		secp256k1.PubKeyFormatCompressedOdd {
+	return aggregateKey.PreTweakedKey, nil
+}
 
-		controlBlock.OutputKeyYIsOdd = true
-	}
+// tapscriptSibling returns the preimage that is committed next to the
+// Taproot Asset commitment of the anchor output.
+func (s ArkBtcScript) tapscriptSibling() (*commitment.TapscriptPreimage, error) {
+	return s.policy.TapscriptSibling()
+}
+
+// spendLeafScript returns the leaf proven by the control block.
+func (s ArkBtcScript) spendLeafScript() txscript.TapLeaf {
+	leaf, _ := s.policy.Leaf(s.spendLeaf)
+	return leaf
+}
+
+// ControlBlock proves any leaf of the anchor output holding the Taproot Asset
+// root.
+func (s ArkBtcScript) ControlBlock(name string, taprootAssetRoot []byte) (*txscript.ControlBlock, error) {
+	return s.policy.ControlBlock(name, s.internalKey, taprootAssetRoot)
+}
 
-	return ArkAssetScript{userNonces, serverNonces, tapScriptKey, cooperativeSpend, unilateralExit, tree, controlBlock}, nil
+// merkleRoot returns the tapscript root of the anchor output, covering both
+// the Ark leaves and the Taproot Asset commitment.
+func (s ArkBtcScript) merkleRoot() []byte {
+	return s.controlBlock.RootHash(s.spendLeafScript().Script)
 }
 
-func CreateRoundArkBtcScript(user, server *btcec.PublicKey) (ArkBtcScript, error) {
-	cooperativeScript, err := txscript.NewScriptBuilder().
-		AddData(schnorr.SerializePubKey(user)).
-		AddOp(txscript.OP_CHECKSIG).
-		AddData(schnorr.SerializePubKey(server)).
-		AddOp(txscript.OP_CHECKSIGADD).
-		AddInt64(2).
-		AddOp(txscript.OP_EQUAL).
-		Script()
+func NewArkAssetScript(policy ArkScriptPolicy) ArkAssetScript {
+	return ArkAssetScript{policy.AssetScriptKey(), policy}
+}
 
+// createArkAssetScript builds the asset script key of the cosigner keys. The
+// cooperative leaf checks a MuSig2 signature of all cosigners, the unilateral
+// key can spend alone once LOCK_BLOCK_HEIGHT is reached.
+func createArkAssetScript(cosignerKeys []*btcec.PublicKey, unilateralKey *btcec.PublicKey) (ArkAssetScript, error) {
+	musigKey, err := muSig2LeafKey(cosignerKeys)
 	if err != nil {
-		return ArkBtcScript{}, fmt.Errorf("failed to decode left script: %w", err)
+		return ArkAssetScript{}, err
 	}
 
-	cooperativeLeaf := txscript.NewTapLeaf(txscript.BaseLeafVersion, cooperativeScript)
+	cooperativeLeaf, err := CheckSigLeaf(ArkLeafCollaborative, musigKey)
+	if err != nil {
+		return ArkAssetScript{}, err
+	}
 
-	unilateralScript, err := txscript.NewScriptBuilder().
-		AddInt64(int64(LOCK_BLOCK_HEIGHT)).
-		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
-		AddData(schnorr.SerializePubKey(server)).
-		AddOp(txscript.OP_CHECKSIG).
-		Script()
+	exitLeaf, err := AbsoluteTimelockLeaf(ArkLeafExit, unilateralKey, LOCK_BLOCK_HEIGHT)
+	if err != nil {
+		return ArkAssetScript{}, err
+	}
 
+	policy, err := NewArkScriptPolicy(cooperativeLeaf, exitLeaf)
 	if err != nil {
-		return ArkBtcScript{}, fmt.Errorf("failed to decode left script: %w", err)
+		return ArkAssetScript{}, err
 	}
 
-	unilateralLeaf := txscript.NewTapLeaf(txscript.BaseLeafVersion, unilateralScript)
+	return NewArkAssetScript(policy), nil
+}
 
-	branch := txscript.NewTapBranch(cooperativeLeaf, unilateralLeaf)
+// muSig2LeafKey returns the key a MuSig2 session of the signers tweaked with
+// KeySpendOnly signs for, to be used inside a tapscript leaf.
+func muSig2LeafKey(keys []*btcec.PublicKey) (*btcec.PublicKey, error) {
+	musigKey, err := input.MuSig2CombineKeys(
+		input.MuSig2Version100RC2, keys, true,
+		&input.MuSig2Tweaks{TaprootBIP0086Tweak: true},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine musig keys: %v", err)
+	}
 
-	return ArkBtcScript{cooperativeSpend: cooperativeLeaf, unilateralSpend: unilateralLeaf, Branch: branch}, nil
+	return musigKey.FinalKey, nil
 }
 
-func CreateRoundArkAssetScript(
-	user, server *btcec.PublicKey) (ArkAssetScript, error) {
-
-	userBoardingNonceOpt := musig2.WithPublicKey(
-		user,
-	)
-	serverBoardingNonceOpt := musig2.WithPublicKey(
-		server,
-	)
-	userNonces, _ := musig2.GenNonces(userBoardingNonceOpt)
-	serverNonces, _ := musig2.GenNonces(serverBoardingNonceOpt)
+func CreateBoardingArkAssetScript(user, server *btcec.PublicKey) (ArkAssetScript, error) {
+	return createArkAssetScript([]*btcec.PublicKey{user, server}, user)
+}
 
-	musigKey, err := input.MuSig2CombineKeys(
-		input.MuSig2Version100RC2, []*btcec.PublicKey{
-			user,
-			server,
-		}, true, &input.MuSig2Tweaks{TaprootBIP0086Tweak: true},
-	)
+// CreateRoundArkAssetScript builds the asset script of a round output, the
+// last cosigner key is the server's which can sweep the asset after the lock.
+func CreateRoundArkAssetScript(cosignerKeys []*btcec.PublicKey) (ArkAssetScript, error) {
+	return createArkAssetScript(cosignerKeys, cosignerKeys[len(cosignerKeys)-1])
+}
 
+// Witness returns the script path witness of the named leaf, the stack
+// elements are pushed before the leaf script and its control block.
+func (s ArkAssetScript) Witness(name string, stack ...[]byte) (wire.TxWitness, error) {
+	leaf, err := s.policy.Leaf(name)
 	if err != nil {
-		return ArkAssetScript{}, fmt.Errorf("failed to combine sigs for Musig: %v", err)
+		return wire.TxWitness{}, err
 	}
 
-	cooperativeScript, err := txscript.NewScriptBuilder().
-		AddData(schnorr.SerializePubKey(musigKey.FinalKey)).
-		AddOp(txscript.OP_CHECKSIG).
-		Script()
-
+	controlBlock, err := s.policy.ControlBlock(name, asset.NUMSPubKey)
 	if err != nil {
-		return ArkAssetScript{}, fmt.Errorf("failed to create cooperative Script %v", err)
+		return wire.TxWitness{}, err
 	}
 
-	cooperativeSpend := txscript.TapLeaf{
-		LeafVersion: txscript.BaseLeafVersion,
-		Script:      cooperativeScript,
+	controlBlockBytes, err := controlBlock.ToBytes()
+	if err != nil {
+		return wire.TxWitness{}, fmt.Errorf("cannot Get control byte %v", err)
 	}
 
-	unilateralScript, err := txscript.NewScriptBuilder().
-		AddInt64(int64(LOCK_BLOCK_HEIGHT)).
-		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
-		AddData(schnorr.SerializePubKey(server)).
-		AddOp(txscript.OP_CHECKSIG).
-		Script()
+	return append(append(wire.TxWitness{}, stack...), leaf.Script, controlBlockBytes), nil
+}
 
+// InsertAssetTransferWitness signs the virtual transaction with a MuSig2
+// session of every cosigner of the spent output.
+func InsertAssetTransferWitness(arkSpendingDetails ArkSpendingDetails, fundedPkt *tappsbt.VPacket) error {
+	return insertAssetLeafWitness(arkSpendingDetails.arkAssetScript, ArkLeafCollaborative, arkSpendingDetails.cosigners, fundedPkt)
+}
+
+// insertAssetLeafWitness signs the virtual transaction through the named leaf
+// of the asset script, whose key is the MuSig2 aggregate of the cosigners or
+// the script key of a single cosigner. The stack elements are pushed after
+// the signature.
+func insertAssetLeafWitness(arkAssetScript ArkAssetScript, leafName string, cosigners []Cosigner, fundedPkt *tappsbt.VPacket, stack ...[]byte) error {
+	leaf, err := arkAssetScript.policy.Leaf(leafName)
 	if err != nil {
-		return ArkAssetScript{}, fmt.Errorf("failed to  create sweep Tapscript %v", err)
+		return err
 	}
 
-	unilateralLeaf := txscript.TapLeaf{
-		LeafVersion: txscript.BaseLeafVersion,
-		Script:      unilateralScript,
-	}
+	coordinator := cosigners[len(cosigners)-1]
+	var virtualSigner virtualTxSigner
+	if len(cosigners) == 1 {
+		controlBlock, err := arkAssetScript.policy.ControlBlock(leafName, asset.NUMSPubKey)
+		if err != nil {
+			return err
+		}
+		controlBlockBytes, err := controlBlock.ToBytes()
+		if err != nil {
+			return fmt.Errorf("cannot convert control block to bytes %v", err)
+		}
 
-	tree := txscript.AssembleTaprootScriptTree(cooperativeSpend, unilateralLeaf)
-	internalKey := asset.NUMSPubKey
-	controlBlock := &txscript.ControlBlock{
-		LeafVersion: txscript.BaseLeafVersion,
-		InternalKey: internalKey,
-	}
-	merkleRootHash := tree.RootNode.TapHash()
+		virtualSigner = &leafKeySigner{coordinator.signer, coordinator.scriptKey.RawKey, leaf, controlBlockBytes}
+	} else {
+		signers := make([]MuSig2Signer, len(cosigners))
+		for i, cosigner := range cosigners {
+			signers[i] = MuSig2Signer{cosigner.signer, cosigner.scriptKey.RawKey}
+		}
 
-	tapKey := txscript.ComputeTaprootOutputKey(
-		internalKey, merkleRootHash[:],
-	)
-	tapScriptKey := asset.ScriptKey{
-		PubKey: tapKey,
-		TweakedScriptKey: &asset.TweakedScriptKey{
-			RawKey: keychain.KeyDescriptor{
-				PubKey: internalKey,
-			},
-			Tweak: merkleRootHash[:],
-		},
-	}
+		session, err := NewMuSig2Session(signers, &signrpc.TaprootTweakDesc{KeySpendOnly: true})
+		if err != nil {
+			return fmt.Errorf("failed to create asset signing session: %w", err)
+		}
+		defer session.Close()
 
-	if tapKey.SerializeCompressed()[0] ==
-		secp256k1.PubKeyFormatCompressedOdd {
+		if err := session.ExchangeNonces(); err != nil {
+			return fmt.Errorf("failed to exchange asset nonces: %w", err)
+		}
 
-		controlBlock.OutputKeyYIsOdd = true
+		virtualSigner = &muSig2SessionSigner{session: session, leafToSign: leaf}
 	}
 
-	return ArkAssetScript{userNonces, serverNonces, tapScriptKey, cooperativeSpend, unilateralLeaf, tree, controlBlock}, nil
-}
-
-func InsertAssetTransferWitness(arkSpendingDetails ArkSpendingDetails, fundedPkt *tappsbt.VPacket, user, server *TapClient) error {
-	_, serverSessionId, err := server.partialSignAssetTransfer(fundedPkt,
-		&arkSpendingDetails.arkAssetScript.cooperativeSpend, arkSpendingDetails.serverScriptKey.RawKey, arkSpendingDetails.arkAssetScript.serverNonce, arkSpendingDetails.userScriptKey.RawKey.PubKey, arkSpendingDetails.arkAssetScript.userNonce.PubNonce)
+	err = signAssetTransfer(fundedPkt, virtualSigner, coordinator.scriptKey.RawKey)
 	if err != nil {
-		return fmt.Errorf("failed to create server asset partial sig: %w", err)
+		return fmt.Errorf("failed to sign asset transfer: %w", err)
 	}
 
-	userPartialSig, _, err := user.partialSignAssetTransfer(fundedPkt,
-		&arkSpendingDetails.arkAssetScript.cooperativeSpend, arkSpendingDetails.userScriptKey.RawKey, arkSpendingDetails.arkAssetScript.userNonce, arkSpendingDetails.serverScriptKey.RawKey.PubKey, arkSpendingDetails.arkAssetScript.serverNonce.PubNonce)
+	signedAsset, err := signedTransferAsset(fundedPkt)
 	if err != nil {
-		return fmt.Errorf("failed to create user asset partial sig: %w", err)
+		return err
 	}
 
-	transferAssetWitness, err := server.combineSigs(serverSessionId, userPartialSig, arkSpendingDetails.arkAssetScript.cooperativeSpend, arkSpendingDetails.arkAssetScript.tree, arkSpendingDetails.arkAssetScript.controlBlock)
+	witnessStack := append([][]byte{signedAsset.PrevWitnesses[0].TxWitness[0]}, stack...)
+	transferAssetWitness, err := arkAssetScript.Witness(leafName, witnessStack...)
 	if err != nil {
-		return fmt.Errorf("failed to combine sigs: %v", err)
+		return fmt.Errorf("failed to create asset witness: %v", err)
 	}
 
 	for idx := range fundedPkt.Outputs {
@@ -430,70 +534,124 @@ func InsertAssetTransferWitness(arkSpendingDetails ArkSpendingDetails, fundedPkt
 		firstPrevWitness.TxWitness = transferAssetWitness
 	}
 
-	changeOutput := fundedPkt.Outputs[CHANGE_OUTPUT_INDEX]
-	changeOutput.AnchorOutputInternalKey = asset.NUMSPubKey
-
 	return nil
 }
 
-// CreateBtcWitness creates BTC witness for multiple inputs.
-func CreateBtcWitness(arkSpendingDetails []ArkSpendingDetails, btcPacket *psbt.Packet, inputLength int, user, server *TapClient) ([]wire.TxWitness, error) {
-	serverbtcControlBytesList := make([][]byte, inputLength)
-	serverkeys := make([]keychain.KeyDescriptor, inputLength)
-	serverTapLeaves := make([]txscript.TapLeaf, inputLength)
+// signedTransferAsset returns the asset holding the virtual transaction
+// signature. For splits, it is the one with the split root set.
+func signedTransferAsset(assetTransferPacket *tappsbt.VPacket) (*asset.Asset, error) {
+	isSplit, err := assetTransferPacket.HasSplitCommitment()
+	if err != nil {
+		return nil, fmt.Errorf("cannot check for split commitment %v", err)
+	}
+
+	if !isSplit {
+		return assetTransferPacket.Outputs[0].Asset, nil
+	}
+
+	splitOut, err := assetTransferPacket.SplitRootOutput()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get split root output %v", err)
+	}
+
+	return splitOut.Asset, nil
+}
+
+// CreateBtcWitness creates BTC witness for multiple inputs. Script path
+// inputs are signed through the cooperative leaf, MuSig2 inputs with a single
+// aggregated key spend signature.
+func CreateBtcWitness(arkSpendingDetails []ArkSpendingDetails, btcPacket *psbt.Packet, inputLength int) ([]wire.TxWitness, error) {
+	txwitnessList := make([]wire.TxWitness, inputLength)
 
 	for i := 0; i < inputLength; i++ {
-		controlBlockBytes, err := arkSpendingDetails[i].arkBtcScript.controlBlock.ToBytes()
+		var (
+			txWitness wire.TxWitness
+			err       error
+		)
+		if arkSpendingDetails[i].arkBtcScript.spendPath == BtcSpendPathMuSig2 {
+			txWitness, err = createBtcKeySpendWitness(arkSpendingDetails[i], btcPacket, i)
+		} else {
+			txWitness, err = createBtcScriptWitness(arkSpendingDetails[i], btcPacket, i)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("cannot convert control block to bytes %v", err)
+			return nil, err
 		}
-		serverbtcControlBytesList[i] = controlBlockBytes
-		serverkeys[i] = arkSpendingDetails[i].serverInternalKey
-		serverTapLeaves[i] = arkSpendingDetails[i].arkBtcScript.cooperativeSpend
 
+		txwitnessList[i] = txWitness
 	}
 
-	serverBtcPartialSigs, err := server.partialSignBtcTransfer(
-		btcPacket, inputLength,
-		serverkeys, serverbtcControlBytesList, serverTapLeaves,
-	)
+	return txwitnessList, nil
+}
+
+// createBtcScriptWitness signs the input through the OP_CHECKSIGADD
+// cooperative leaf, one signature per cosigner.
+func createBtcScriptWitness(arkSpendingDetails ArkSpendingDetails, btcPacket *psbt.Packet, inputIndex int) (wire.TxWitness, error) {
+	arkBtcScript := arkSpendingDetails.arkBtcScript
+	cooperativeLeaf, err := arkBtcScript.policy.Leaf(ArkLeafCollaborative)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create server btc partial sigs %v", err)
+		return nil, err
 	}
 
-	userbtcControlBytesList := make([][]byte, inputLength)
-	userkeys := make([]keychain.KeyDescriptor, inputLength)
-	userTapLeaves := make([]txscript.TapLeaf, inputLength)
-
-	for i := 0; i < inputLength; i++ {
-		controlBlockBytes, err := arkSpendingDetails[i].arkBtcScript.controlBlock.ToBytes()
+	controlBlockBytes, err := arkBtcScript.controlBlock.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert control block to bytes %v", err)
+	}
+
+	cosigners := arkSpendingDetails.cosigners
+	txWitness := make(wire.TxWitness, len(cosigners), len(cosigners)+2)
+	for i, cosigner := range cosigners {
+		partialSigs, err := callSigner(func(ctx context.Context) ([][]byte, error) {
+			return cosigner.signer.SignBtcTapscript(
+				ctx, btcPacket, []int{inputIndex},
+				[]keychain.KeyDescriptor{cosigner.internalKey},
+				[][]byte{controlBlockBytes},
+				[]txscript.TapLeaf{cooperativeLeaf},
+			)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert user control block to bytes: %v", err)
+			return nil, fmt.Errorf("failed to create btc partial sig: %w",
+				&SignerError{i, cosigner.internalKey.PubKey, cosigner.signer, err})
 		}
-		userbtcControlBytesList[i] = controlBlockBytes
-		userkeys[i] = arkSpendingDetails[i].userInternalKey
-		userTapLeaves[i] = arkSpendingDetails[i].arkBtcScript.cooperativeSpend
 
+		// The first key of the script consumes the top of the stack, so
+		// signatures are pushed in reverse cosigner order.
+		txWitness[len(cosigners)-1-i] = partialSigs[0]
 	}
 
-	userBtcPartialSigs, err := user.partialSignBtcTransfer(
-		btcPacket, inputLength,
-		userkeys, userbtcControlBytesList, userTapLeaves,
-	)
+	return append(txWitness, cooperativeLeaf.Script, controlBlockBytes), nil
+}
+
+// createBtcKeySpendWitness signs a MuSig2 anchored input with a key spend.
+// Every cosigner opens an lnd session tweaked with the anchor merkle root, the
+// server combines all partial signatures.
+func createBtcKeySpendWitness(arkSpendingDetails ArkSpendingDetails, btcPacket *psbt.Packet, inputIndex int) (wire.TxWitness, error) {
+	cosigners := arkSpendingDetails.cosigners
+	signers := make([]MuSig2Signer, len(cosigners))
+	for i, cosigner := range cosigners {
+		signers[i] = MuSig2Signer{cosigner.signer, cosigner.internalKey}
+	}
+
+	session, err := NewMuSig2Session(signers, &signrpc.TaprootTweakDesc{
+		ScriptRoot: arkSpendingDetails.arkBtcScript.merkleRoot(),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user btc partial sigs: %v", err)
+		return nil, fmt.Errorf("failed to create btc signing session: %w", err)
 	}
+	defer session.Close()
 
-	// created btc partial sig for user
-	txwitnessList := make([]wire.TxWitness, inputLength)
-	for i := 0; i < inputLength; i++ {
-		txwitnessList[i] = wire.TxWitness{
-			serverBtcPartialSigs[i],
-			userBtcPartialSigs[i],
-			arkSpendingDetails[i].arkBtcScript.cooperativeSpend.Script,
-			serverbtcControlBytesList[i],
-		}
+	if err := session.ExchangeNonces(); err != nil {
+		return nil, fmt.Errorf("failed to exchange btc nonces: %w", err)
 	}
 
-	return txwitnessList, nil
+	sigHash, err := keySpendSigHash(btcPacket, inputIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	finalSig, err := session.Sign(sigHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign btc key spend: %w", err)
+	}
+
+	return wire.TxWitness{finalSig}, nil
 }
@@ -0,0 +1,3316 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v4.25.1
+// source: ark.proto
+
+package arkrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type KeyType int32
+
+const (
+	KeyType_KEY_TYPE_SCRIPT   KeyType = 0
+	KeyType_KEY_TYPE_INTERNAL KeyType = 1
+)
+
+// Enum value maps for KeyType.
+var (
+	KeyType_name = map[int32]string{
+		0: "KEY_TYPE_SCRIPT",
+		1: "KEY_TYPE_INTERNAL",
+	}
+	KeyType_value = map[string]int32{
+		"KEY_TYPE_SCRIPT":   0,
+		"KEY_TYPE_INTERNAL": 1,
+	}
+)
+
+func (x KeyType) Enum() *KeyType {
+	p := new(KeyType)
+	*p = x
+	return p
+}
+
+func (x KeyType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KeyType) Descriptor() protoreflect.EnumDescriptor {
+	return file_ark_proto_enumTypes[0].Descriptor()
+}
+
+func (KeyType) Type() protoreflect.EnumType {
+	return &file_ark_proto_enumTypes[0]
+}
+
+func (x KeyType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KeyType.Descriptor instead.
+func (KeyType) EnumDescriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{0}
+}
+
+type OutputType int32
+
+const (
+	OutputType_OUTPUT_TYPE_ASSET   OutputType = 0
+	OutputType_OUTPUT_TYPE_BTC     OutputType = 1
+	OutputType_OUTPUT_TYPE_COLORED OutputType = 2
+)
+
+// Enum value maps for OutputType.
+var (
+	OutputType_name = map[int32]string{
+		0: "OUTPUT_TYPE_ASSET",
+		1: "OUTPUT_TYPE_BTC",
+		2: "OUTPUT_TYPE_COLORED",
+	}
+	OutputType_value = map[string]int32{
+		"OUTPUT_TYPE_ASSET":   0,
+		"OUTPUT_TYPE_BTC":     1,
+		"OUTPUT_TYPE_COLORED": 2,
+	}
+)
+
+func (x OutputType) Enum() *OutputType {
+	p := new(OutputType)
+	*p = x
+	return p
+}
+
+func (x OutputType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OutputType) Descriptor() protoreflect.EnumDescriptor {
+	return file_ark_proto_enumTypes[1].Descriptor()
+}
+
+func (OutputType) Type() protoreflect.EnumType {
+	return &file_ark_proto_enumTypes[1]
+}
+
+func (x OutputType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OutputType.Descriptor instead.
+func (OutputType) EnumDescriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{1}
+}
+
+type GetInfoRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetInfoRequest) Reset() {
+	*x = GetInfoRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRequest) ProtoMessage() {}
+
+func (x *GetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{0}
+}
+
+type GetInfoResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Network string `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	// The compressed public key of the server.
+	Pubkey []byte `protobuf:"bytes,2,opt,name=pubkey,proto3" json:"pubkey,omitempty"`
+	// The cooperative spend path of BTC anchors, "script" or "musig2".
+	BtcSpendPath string `protobuf:"bytes,3,opt,name=btc_spend_path,json=btcSpendPath,proto3" json:"btc_spend_path,omitempty"`
+}
+
+func (x *GetInfoResponse) Reset() {
+	*x = GetInfoResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoResponse) ProtoMessage() {}
+
+func (x *GetInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetInfoResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetInfoResponse) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *GetInfoResponse) GetPubkey() []byte {
+	if x != nil {
+		return x.Pubkey
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetBtcSpendPath() string {
+	if x != nil {
+		return x.BtcSpendPath
+	}
+	return ""
+}
+
+type KeyDescriptor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RawKeyBytes []byte `protobuf:"bytes,1,opt,name=raw_key_bytes,json=rawKeyBytes,proto3" json:"raw_key_bytes,omitempty"`
+	KeyFamily   int32  `protobuf:"varint,2,opt,name=key_family,json=keyFamily,proto3" json:"key_family,omitempty"`
+	KeyIndex    int32  `protobuf:"varint,3,opt,name=key_index,json=keyIndex,proto3" json:"key_index,omitempty"`
+}
+
+func (x *KeyDescriptor) Reset() {
+	*x = KeyDescriptor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyDescriptor) ProtoMessage() {}
+
+func (x *KeyDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyDescriptor.ProtoReflect.Descriptor instead.
+func (*KeyDescriptor) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *KeyDescriptor) GetRawKeyBytes() []byte {
+	if x != nil {
+		return x.RawKeyBytes
+	}
+	return nil
+}
+
+func (x *KeyDescriptor) GetKeyFamily() int32 {
+	if x != nil {
+		return x.KeyFamily
+	}
+	return 0
+}
+
+func (x *KeyDescriptor) GetKeyIndex() int32 {
+	if x != nil {
+		return x.KeyIndex
+	}
+	return 0
+}
+
+type ScriptKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PubKey   []byte         `protobuf:"bytes,1,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	KeyDesc  *KeyDescriptor `protobuf:"bytes,2,opt,name=key_desc,json=keyDesc,proto3" json:"key_desc,omitempty"`
+	TapTweak []byte         `protobuf:"bytes,3,opt,name=tap_tweak,json=tapTweak,proto3" json:"tap_tweak,omitempty"`
+}
+
+func (x *ScriptKey) Reset() {
+	*x = ScriptKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScriptKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScriptKey) ProtoMessage() {}
+
+func (x *ScriptKey) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScriptKey.ProtoReflect.Descriptor instead.
+func (*ScriptKey) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScriptKey) GetPubKey() []byte {
+	if x != nil {
+		return x.PubKey
+	}
+	return nil
+}
+
+func (x *ScriptKey) GetKeyDesc() *KeyDescriptor {
+	if x != nil {
+		return x.KeyDesc
+	}
+	return nil
+}
+
+func (x *ScriptKey) GetTapTweak() []byte {
+	if x != nil {
+		return x.TapTweak
+	}
+	return nil
+}
+
+type SubscribeEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The compressed public key identifying the participant.
+	ParticipantKey []byte `protobuf:"bytes,1,opt,name=participant_key,json=participantKey,proto3" json:"participant_key,omitempty"`
+}
+
+func (x *SubscribeEventsRequest) Reset() {
+	*x = SubscribeEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeEventsRequest) ProtoMessage() {}
+
+func (x *SubscribeEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeEventsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeEventsRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SubscribeEventsRequest) GetParticipantKey() []byte {
+	if x != nil {
+		return x.ParticipantKey
+	}
+	return nil
+}
+
+type RoundEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*RoundEvent_Phase
+	//	*RoundEvent_KeyRequest
+	//	*RoundEvent_NonceRequest
+	//	*RoundEvent_PartialSigRequest
+	//	*RoundEvent_TapscriptSigRequest
+	//	*RoundEvent_CleanupRequest
+	//	*RoundEvent_Proof
+	//	*RoundEvent_WatchKey
+	Event isRoundEvent_Event `protobuf_oneof:"event"`
+}
+
+func (x *RoundEvent) Reset() {
+	*x = RoundEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundEvent) ProtoMessage() {}
+
+func (x *RoundEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundEvent.ProtoReflect.Descriptor instead.
+func (*RoundEvent) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{5}
+}
+
+func (m *RoundEvent) GetEvent() isRoundEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetPhase() *RoundPhaseEvent {
+	if x, ok := x.GetEvent().(*RoundEvent_Phase); ok {
+		return x.Phase
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetKeyRequest() *KeyRequest {
+	if x, ok := x.GetEvent().(*RoundEvent_KeyRequest); ok {
+		return x.KeyRequest
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetNonceRequest() *NonceRequest {
+	if x, ok := x.GetEvent().(*RoundEvent_NonceRequest); ok {
+		return x.NonceRequest
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetPartialSigRequest() *PartialSigRequest {
+	if x, ok := x.GetEvent().(*RoundEvent_PartialSigRequest); ok {
+		return x.PartialSigRequest
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetTapscriptSigRequest() *TapscriptSigRequest {
+	if x, ok := x.GetEvent().(*RoundEvent_TapscriptSigRequest); ok {
+		return x.TapscriptSigRequest
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetCleanupRequest() *CleanupRequest {
+	if x, ok := x.GetEvent().(*RoundEvent_CleanupRequest); ok {
+		return x.CleanupRequest
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetProof() *ProofEvent {
+	if x, ok := x.GetEvent().(*RoundEvent_Proof); ok {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *RoundEvent) GetWatchKey() *WatchKeyEvent {
+	if x, ok := x.GetEvent().(*RoundEvent_WatchKey); ok {
+		return x.WatchKey
+	}
+	return nil
+}
+
+type isRoundEvent_Event interface {
+	isRoundEvent_Event()
+}
+
+type RoundEvent_Phase struct {
+	Phase *RoundPhaseEvent `protobuf:"bytes,1,opt,name=phase,proto3,oneof"`
+}
+
+type RoundEvent_KeyRequest struct {
+	KeyRequest *KeyRequest `protobuf:"bytes,2,opt,name=key_request,json=keyRequest,proto3,oneof"`
+}
+
+type RoundEvent_NonceRequest struct {
+	NonceRequest *NonceRequest `protobuf:"bytes,3,opt,name=nonce_request,json=nonceRequest,proto3,oneof"`
+}
+
+type RoundEvent_PartialSigRequest struct {
+	PartialSigRequest *PartialSigRequest `protobuf:"bytes,4,opt,name=partial_sig_request,json=partialSigRequest,proto3,oneof"`
+}
+
+type RoundEvent_TapscriptSigRequest struct {
+	TapscriptSigRequest *TapscriptSigRequest `protobuf:"bytes,5,opt,name=tapscript_sig_request,json=tapscriptSigRequest,proto3,oneof"`
+}
+
+type RoundEvent_CleanupRequest struct {
+	CleanupRequest *CleanupRequest `protobuf:"bytes,6,opt,name=cleanup_request,json=cleanupRequest,proto3,oneof"`
+}
+
+type RoundEvent_Proof struct {
+	Proof *ProofEvent `protobuf:"bytes,7,opt,name=proof,proto3,oneof"`
+}
+
+type RoundEvent_WatchKey struct {
+	WatchKey *WatchKeyEvent `protobuf:"bytes,8,opt,name=watch_key,json=watchKey,proto3,oneof"`
+}
+
+func (*RoundEvent_Phase) isRoundEvent_Event() {}
+
+func (*RoundEvent_KeyRequest) isRoundEvent_Event() {}
+
+func (*RoundEvent_NonceRequest) isRoundEvent_Event() {}
+
+func (*RoundEvent_PartialSigRequest) isRoundEvent_Event() {}
+
+func (*RoundEvent_TapscriptSigRequest) isRoundEvent_Event() {}
+
+func (*RoundEvent_CleanupRequest) isRoundEvent_Event() {}
+
+func (*RoundEvent_Proof) isRoundEvent_Event() {}
+
+func (*RoundEvent_WatchKey) isRoundEvent_Event() {}
+
+type RoundPhaseEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// One of "registration", "signing", "finalized" or "failed".
+	Phase string `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	// The round transaction, set once the round is finalized.
+	RoundTxid string `protobuf:"bytes,2,opt,name=round_txid,json=roundTxid,proto3" json:"round_txid,omitempty"`
+}
+
+func (x *RoundPhaseEvent) Reset() {
+	*x = RoundPhaseEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundPhaseEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundPhaseEvent) ProtoMessage() {}
+
+func (x *RoundPhaseEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundPhaseEvent.ProtoReflect.Descriptor instead.
+func (*RoundPhaseEvent) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RoundPhaseEvent) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *RoundPhaseEvent) GetRoundTxid() string {
+	if x != nil {
+		return x.RoundTxid
+	}
+	return ""
+}
+
+type KeyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string  `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Type      KeyType `protobuf:"varint,2,opt,name=type,proto3,enum=arkrpc.KeyType" json:"type,omitempty"`
+}
+
+func (x *KeyRequest) Reset() {
+	*x = KeyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyRequest) ProtoMessage() {}
+
+func (x *KeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyRequest.ProtoReflect.Descriptor instead.
+func (*KeyRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *KeyRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *KeyRequest) GetType() KeyType {
+	if x != nil {
+		return x.Type
+	}
+	return KeyType_KEY_TYPE_SCRIPT
+}
+
+type TaprootTweak struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ScriptRoot   []byte `protobuf:"bytes,1,opt,name=script_root,json=scriptRoot,proto3" json:"script_root,omitempty"`
+	KeySpendOnly bool   `protobuf:"varint,2,opt,name=key_spend_only,json=keySpendOnly,proto3" json:"key_spend_only,omitempty"`
+}
+
+func (x *TaprootTweak) Reset() {
+	*x = TaprootTweak{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaprootTweak) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaprootTweak) ProtoMessage() {}
+
+func (x *TaprootTweak) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaprootTweak.ProtoReflect.Descriptor instead.
+func (*TaprootTweak) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TaprootTweak) GetScriptRoot() []byte {
+	if x != nil {
+		return x.ScriptRoot
+	}
+	return nil
+}
+
+func (x *TaprootTweak) GetKeySpendOnly() bool {
+	if x != nil {
+		return x.KeySpendOnly
+	}
+	return false
+}
+
+type NonceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId     string         `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	LocalKey      *KeyDescriptor `protobuf:"bytes,2,opt,name=local_key,json=localKey,proto3" json:"local_key,omitempty"`
+	AllSignerKeys [][]byte       `protobuf:"bytes,3,rep,name=all_signer_keys,json=allSignerKeys,proto3" json:"all_signer_keys,omitempty"`
+	Tweak         *TaprootTweak  `protobuf:"bytes,4,opt,name=tweak,proto3" json:"tweak,omitempty"`
+}
+
+func (x *NonceRequest) Reset() {
+	*x = NonceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NonceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NonceRequest) ProtoMessage() {}
+
+func (x *NonceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NonceRequest.ProtoReflect.Descriptor instead.
+func (*NonceRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NonceRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *NonceRequest) GetLocalKey() *KeyDescriptor {
+	if x != nil {
+		return x.LocalKey
+	}
+	return nil
+}
+
+func (x *NonceRequest) GetAllSignerKeys() [][]byte {
+	if x != nil {
+		return x.AllSignerKeys
+	}
+	return nil
+}
+
+func (x *NonceRequest) GetTweak() *TaprootTweak {
+	if x != nil {
+		return x.Tweak
+	}
+	return nil
+}
+
+type PartialSigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId   string   `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	SessionId   []byte   `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	OtherNonces [][]byte `protobuf:"bytes,3,rep,name=other_nonces,json=otherNonces,proto3" json:"other_nonces,omitempty"`
+	SigHash     []byte   `protobuf:"bytes,4,opt,name=sig_hash,json=sigHash,proto3" json:"sig_hash,omitempty"`
+	Cleanup     bool     `protobuf:"varint,5,opt,name=cleanup,proto3" json:"cleanup,omitempty"`
+}
+
+func (x *PartialSigRequest) Reset() {
+	*x = PartialSigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartialSigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialSigRequest) ProtoMessage() {}
+
+func (x *PartialSigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialSigRequest.ProtoReflect.Descriptor instead.
+func (*PartialSigRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PartialSigRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *PartialSigRequest) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *PartialSigRequest) GetOtherNonces() [][]byte {
+	if x != nil {
+		return x.OtherNonces
+	}
+	return nil
+}
+
+func (x *PartialSigRequest) GetSigHash() []byte {
+	if x != nil {
+		return x.SigHash
+	}
+	return nil
+}
+
+func (x *PartialSigRequest) GetCleanup() bool {
+	if x != nil {
+		return x.Cleanup
+	}
+	return false
+}
+
+type TapLeaf struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Script      []byte `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	LeafVersion uint32 `protobuf:"varint,2,opt,name=leaf_version,json=leafVersion,proto3" json:"leaf_version,omitempty"`
+}
+
+func (x *TapLeaf) Reset() {
+	*x = TapLeaf{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TapLeaf) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapLeaf) ProtoMessage() {}
+
+func (x *TapLeaf) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapLeaf.ProtoReflect.Descriptor instead.
+func (*TapLeaf) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TapLeaf) GetScript() []byte {
+	if x != nil {
+		return x.Script
+	}
+	return nil
+}
+
+func (x *TapLeaf) GetLeafVersion() uint32 {
+	if x != nil {
+		return x.LeafVersion
+	}
+	return 0
+}
+
+type TapscriptSigRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId     string           `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Psbt          []byte           `protobuf:"bytes,2,opt,name=psbt,proto3" json:"psbt,omitempty"`
+	InputIndexes  []uint32         `protobuf:"varint,3,rep,packed,name=input_indexes,json=inputIndexes,proto3" json:"input_indexes,omitempty"`
+	Keys          []*KeyDescriptor `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	ControlBlocks [][]byte         `protobuf:"bytes,5,rep,name=control_blocks,json=controlBlocks,proto3" json:"control_blocks,omitempty"`
+	TapLeaves     []*TapLeaf       `protobuf:"bytes,6,rep,name=tap_leaves,json=tapLeaves,proto3" json:"tap_leaves,omitempty"`
+}
+
+func (x *TapscriptSigRequest) Reset() {
+	*x = TapscriptSigRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TapscriptSigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapscriptSigRequest) ProtoMessage() {}
+
+func (x *TapscriptSigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapscriptSigRequest.ProtoReflect.Descriptor instead.
+func (*TapscriptSigRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TapscriptSigRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *TapscriptSigRequest) GetPsbt() []byte {
+	if x != nil {
+		return x.Psbt
+	}
+	return nil
+}
+
+func (x *TapscriptSigRequest) GetInputIndexes() []uint32 {
+	if x != nil {
+		return x.InputIndexes
+	}
+	return nil
+}
+
+func (x *TapscriptSigRequest) GetKeys() []*KeyDescriptor {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *TapscriptSigRequest) GetControlBlocks() [][]byte {
+	if x != nil {
+		return x.ControlBlocks
+	}
+	return nil
+}
+
+func (x *TapscriptSigRequest) GetTapLeaves() []*TapLeaf {
+	if x != nil {
+		return x.TapLeaves
+	}
+	return nil
+}
+
+type CleanupRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SessionId []byte `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+}
+
+func (x *CleanupRequest) Reset() {
+	*x = CleanupRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CleanupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CleanupRequest) ProtoMessage() {}
+
+func (x *CleanupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CleanupRequest.ProtoReflect.Descriptor instead.
+func (*CleanupRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CleanupRequest) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+// ProofEvent delivers the proof file of an asset paid to the participant by
+// a collaborative exit.
+type ProofEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GenesisPoint string `protobuf:"bytes,1,opt,name=genesis_point,json=genesisPoint,proto3" json:"genesis_point,omitempty"`
+	ProofFile    []byte `protobuf:"bytes,2,opt,name=proof_file,json=proofFile,proto3" json:"proof_file,omitempty"`
+}
+
+func (x *ProofEvent) Reset() {
+	*x = ProofEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProofEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProofEvent) ProtoMessage() {}
+
+func (x *ProofEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProofEvent.ProtoReflect.Descriptor instead.
+func (*ProofEvent) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ProofEvent) GetGenesisPoint() string {
+	if x != nil {
+		return x.GenesisPoint
+	}
+	return ""
+}
+
+func (x *ProofEvent) GetProofFile() []byte {
+	if x != nil {
+		return x.ProofFile
+	}
+	return nil
+}
+
+// WatchKeyEvent names a BIP-0086 key of the participant that receives a BTC
+// VTXO, the wallet should watch it.
+type WatchKeyEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pubkey []byte `protobuf:"bytes,1,opt,name=pubkey,proto3" json:"pubkey,omitempty"`
+}
+
+func (x *WatchKeyEvent) Reset() {
+	*x = WatchKeyEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchKeyEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchKeyEvent) ProtoMessage() {}
+
+func (x *WatchKeyEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchKeyEvent.ProtoReflect.Descriptor instead.
+func (*WatchKeyEvent) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WatchKeyEvent) GetPubkey() []byte {
+	if x != nil {
+		return x.Pubkey
+	}
+	return nil
+}
+
+type NewBoardingAddressRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ParticipantKey []byte `protobuf:"bytes,1,opt,name=participant_key,json=participantKey,proto3" json:"participant_key,omitempty"`
+	AssetId        []byte `protobuf:"bytes,2,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	AssetAmount    uint64 `protobuf:"varint,3,opt,name=asset_amount,json=assetAmount,proto3" json:"asset_amount,omitempty"`
+	BtcAmount      uint64 `protobuf:"varint,4,opt,name=btc_amount,json=btcAmount,proto3" json:"btc_amount,omitempty"`
+}
+
+func (x *NewBoardingAddressRequest) Reset() {
+	*x = NewBoardingAddressRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewBoardingAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewBoardingAddressRequest) ProtoMessage() {}
+
+func (x *NewBoardingAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewBoardingAddressRequest.ProtoReflect.Descriptor instead.
+func (*NewBoardingAddressRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *NewBoardingAddressRequest) GetParticipantKey() []byte {
+	if x != nil {
+		return x.ParticipantKey
+	}
+	return nil
+}
+
+func (x *NewBoardingAddressRequest) GetAssetId() []byte {
+	if x != nil {
+		return x.AssetId
+	}
+	return nil
+}
+
+func (x *NewBoardingAddressRequest) GetAssetAmount() uint64 {
+	if x != nil {
+		return x.AssetAmount
+	}
+	return 0
+}
+
+func (x *NewBoardingAddressRequest) GetBtcAmount() uint64 {
+	if x != nil {
+		return x.BtcAmount
+	}
+	return 0
+}
+
+type NewBoardingAddressResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BoardingId string `protobuf:"bytes,1,opt,name=boarding_id,json=boardingId,proto3" json:"boarding_id,omitempty"`
+	// The Taproot Asset address receiving the boarding asset.
+	AssetAddress string `protobuf:"bytes,2,opt,name=asset_address,json=assetAddress,proto3" json:"asset_address,omitempty"`
+	// The BTC boarding address, only set for boardings without asset.
+	BtcAddress string `protobuf:"bytes,3,opt,name=btc_address,json=btcAddress,proto3" json:"btc_address,omitempty"`
+}
+
+func (x *NewBoardingAddressResponse) Reset() {
+	*x = NewBoardingAddressResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewBoardingAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewBoardingAddressResponse) ProtoMessage() {}
+
+func (x *NewBoardingAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewBoardingAddressResponse.ProtoReflect.Descriptor instead.
+func (*NewBoardingAddressResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NewBoardingAddressResponse) GetBoardingId() string {
+	if x != nil {
+		return x.BoardingId
+	}
+	return ""
+}
+
+func (x *NewBoardingAddressResponse) GetAssetAddress() string {
+	if x != nil {
+		return x.AssetAddress
+	}
+	return ""
+}
+
+func (x *NewBoardingAddressResponse) GetBtcAddress() string {
+	if x != nil {
+		return x.BtcAddress
+	}
+	return ""
+}
+
+type SubmitBoardingAssetRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BoardingId string `protobuf:"bytes,1,opt,name=boarding_id,json=boardingId,proto3" json:"boarding_id,omitempty"`
+	// The serialized taprpc.TransferOutput paying the asset address.
+	TransferOutput []byte `protobuf:"bytes,2,opt,name=transfer_output,json=transferOutput,proto3" json:"transfer_output,omitempty"`
+}
+
+func (x *SubmitBoardingAssetRequest) Reset() {
+	*x = SubmitBoardingAssetRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitBoardingAssetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBoardingAssetRequest) ProtoMessage() {}
+
+func (x *SubmitBoardingAssetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBoardingAssetRequest.ProtoReflect.Descriptor instead.
+func (*SubmitBoardingAssetRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SubmitBoardingAssetRequest) GetBoardingId() string {
+	if x != nil {
+		return x.BoardingId
+	}
+	return ""
+}
+
+func (x *SubmitBoardingAssetRequest) GetTransferOutput() []byte {
+	if x != nil {
+		return x.TransferOutput
+	}
+	return nil
+}
+
+type SubmitBoardingAssetResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BtcAddress string `protobuf:"bytes,1,opt,name=btc_address,json=btcAddress,proto3" json:"btc_address,omitempty"`
+}
+
+func (x *SubmitBoardingAssetResponse) Reset() {
+	*x = SubmitBoardingAssetResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitBoardingAssetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBoardingAssetResponse) ProtoMessage() {}
+
+func (x *SubmitBoardingAssetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBoardingAssetResponse.ProtoReflect.Descriptor instead.
+func (*SubmitBoardingAssetResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SubmitBoardingAssetResponse) GetBtcAddress() string {
+	if x != nil {
+		return x.BtcAddress
+	}
+	return ""
+}
+
+type SubmitBoardingBtcRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BoardingId string `protobuf:"bytes,1,opt,name=boarding_id,json=boardingId,proto3" json:"boarding_id,omitempty"`
+	// The serialized transaction paying the BTC boarding address.
+	RawTx []byte `protobuf:"bytes,2,opt,name=raw_tx,json=rawTx,proto3" json:"raw_tx,omitempty"`
+}
+
+func (x *SubmitBoardingBtcRequest) Reset() {
+	*x = SubmitBoardingBtcRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitBoardingBtcRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBoardingBtcRequest) ProtoMessage() {}
+
+func (x *SubmitBoardingBtcRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBoardingBtcRequest.ProtoReflect.Descriptor instead.
+func (*SubmitBoardingBtcRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SubmitBoardingBtcRequest) GetBoardingId() string {
+	if x != nil {
+		return x.BoardingId
+	}
+	return ""
+}
+
+func (x *SubmitBoardingBtcRequest) GetRawTx() []byte {
+	if x != nil {
+		return x.RawTx
+	}
+	return nil
+}
+
+type SubmitBoardingBtcResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SubmitBoardingBtcResponse) Reset() {
+	*x = SubmitBoardingBtcResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitBoardingBtcResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitBoardingBtcResponse) ProtoMessage() {}
+
+func (x *SubmitBoardingBtcResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitBoardingBtcResponse.ProtoReflect.Descriptor instead.
+func (*SubmitBoardingBtcResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{21}
+}
+
+type CollaborativeExit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A Taproot Asset address of the participant receiving part of the asset.
+	AssetAddress string `protobuf:"bytes,1,opt,name=asset_address,json=assetAddress,proto3" json:"asset_address,omitempty"`
+	// A BIP-0086 internal key receiving btc_amount sats.
+	BtcInternalKey []byte `protobuf:"bytes,2,opt,name=btc_internal_key,json=btcInternalKey,proto3" json:"btc_internal_key,omitempty"`
+	BtcAmount      uint64 `protobuf:"varint,3,opt,name=btc_amount,json=btcAmount,proto3" json:"btc_amount,omitempty"`
+}
+
+func (x *CollaborativeExit) Reset() {
+	*x = CollaborativeExit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CollaborativeExit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollaborativeExit) ProtoMessage() {}
+
+func (x *CollaborativeExit) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollaborativeExit.ProtoReflect.Descriptor instead.
+func (*CollaborativeExit) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CollaborativeExit) GetAssetAddress() string {
+	if x != nil {
+		return x.AssetAddress
+	}
+	return ""
+}
+
+func (x *CollaborativeExit) GetBtcInternalKey() []byte {
+	if x != nil {
+		return x.BtcInternalKey
+	}
+	return nil
+}
+
+func (x *CollaborativeExit) GetBtcAmount() uint64 {
+	if x != nil {
+		return x.BtcAmount
+	}
+	return 0
+}
+
+type RegisterIntentRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BoardingId string `protobuf:"bytes,1,opt,name=boarding_id,json=boardingId,proto3" json:"boarding_id,omitempty"`
+	// The participants receiving the leaves of the tree, a single one
+	// receives every leaf and the boarding participant all if empty.
+	LeafOwners [][]byte `protobuf:"bytes,2,rep,name=leaf_owners,json=leafOwners,proto3" json:"leaf_owners,omitempty"`
+	// Exits paying part of the boarding on-chain, their asset proofs are
+	// delivered to the boarding participant.
+	Exits []*CollaborativeExit `protobuf:"bytes,3,rep,name=exits,proto3" json:"exits,omitempty"`
+	// An encoded Ark address paid by the intent instead of leaf owners. The
+	// round leaf is locked to the address policy and the boarding
+	// participant co-signs the tree.
+	Address string `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *RegisterIntentRequest) Reset() {
+	*x = RegisterIntentRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterIntentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterIntentRequest) ProtoMessage() {}
+
+func (x *RegisterIntentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterIntentRequest.ProtoReflect.Descriptor instead.
+func (*RegisterIntentRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RegisterIntentRequest) GetBoardingId() string {
+	if x != nil {
+		return x.BoardingId
+	}
+	return ""
+}
+
+func (x *RegisterIntentRequest) GetLeafOwners() [][]byte {
+	if x != nil {
+		return x.LeafOwners
+	}
+	return nil
+}
+
+func (x *RegisterIntentRequest) GetExits() []*CollaborativeExit {
+	if x != nil {
+		return x.Exits
+	}
+	return nil
+}
+
+func (x *RegisterIntentRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type RegisterIntentResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IntentId string `protobuf:"bytes,1,opt,name=intent_id,json=intentId,proto3" json:"intent_id,omitempty"`
+}
+
+func (x *RegisterIntentResponse) Reset() {
+	*x = RegisterIntentResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterIntentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterIntentResponse) ProtoMessage() {}
+
+func (x *RegisterIntentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterIntentResponse.ProtoReflect.Descriptor instead.
+func (*RegisterIntentResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RegisterIntentResponse) GetIntentId() string {
+	if x != nil {
+		return x.IntentId
+	}
+	return ""
+}
+
+type TreeOutput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        OutputType `protobuf:"varint,1,opt,name=type,proto3,enum=arkrpc.OutputType" json:"type,omitempty"`
+	AssetAmount uint64     `protobuf:"varint,2,opt,name=asset_amount,json=assetAmount,proto3" json:"asset_amount,omitempty"`
+	BtcAmount   int64      `protobuf:"varint,3,opt,name=btc_amount,json=btcAmount,proto3" json:"btc_amount,omitempty"`
+	// The encoded transition proof of the asset in the output.
+	AssetProof []byte `protobuf:"bytes,4,opt,name=asset_proof,json=assetProof,proto3" json:"asset_proof,omitempty"`
+	// The node spending the output, unset for leaf outputs.
+	Node *TreeNode `protobuf:"bytes,5,opt,name=node,proto3" json:"node,omitempty"`
+	// The participant owning a leaf output.
+	Owner []byte `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`
+	// The encoded Ark address a leaf output is paid to, instead of an owner.
+	Address string `protobuf:"bytes,7,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *TreeOutput) Reset() {
+	*x = TreeOutput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeOutput) ProtoMessage() {}
+
+func (x *TreeOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeOutput.ProtoReflect.Descriptor instead.
+func (*TreeOutput) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TreeOutput) GetType() OutputType {
+	if x != nil {
+		return x.Type
+	}
+	return OutputType_OUTPUT_TYPE_ASSET
+}
+
+func (x *TreeOutput) GetAssetAmount() uint64 {
+	if x != nil {
+		return x.AssetAmount
+	}
+	return 0
+}
+
+func (x *TreeOutput) GetBtcAmount() int64 {
+	if x != nil {
+		return x.BtcAmount
+	}
+	return 0
+}
+
+func (x *TreeOutput) GetAssetProof() []byte {
+	if x != nil {
+		return x.AssetProof
+	}
+	return nil
+}
+
+func (x *TreeOutput) GetNode() *TreeNode {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *TreeOutput) GetOwner() []byte {
+	if x != nil {
+		return x.Owner
+	}
+	return nil
+}
+
+func (x *TreeOutput) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type TreeNode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Leaf  bool        `protobuf:"varint,1,opt,name=leaf,proto3" json:"leaf,omitempty"`
+	RawTx []byte      `protobuf:"bytes,2,opt,name=raw_tx,json=rawTx,proto3" json:"raw_tx,omitempty"`
+	Left  *TreeOutput `protobuf:"bytes,3,opt,name=left,proto3" json:"left,omitempty"`
+	Right *TreeOutput `protobuf:"bytes,4,opt,name=right,proto3" json:"right,omitempty"`
+}
+
+func (x *TreeNode) Reset() {
+	*x = TreeNode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TreeNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeNode) ProtoMessage() {}
+
+func (x *TreeNode) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeNode.ProtoReflect.Descriptor instead.
+func (*TreeNode) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TreeNode) GetLeaf() bool {
+	if x != nil {
+		return x.Leaf
+	}
+	return false
+}
+
+func (x *TreeNode) GetRawTx() []byte {
+	if x != nil {
+		return x.RawTx
+	}
+	return nil
+}
+
+func (x *TreeNode) GetLeft() *TreeOutput {
+	if x != nil {
+		return x.Left
+	}
+	return nil
+}
+
+func (x *TreeNode) GetRight() *TreeOutput {
+	if x != nil {
+		return x.Right
+	}
+	return nil
+}
+
+type GetRoundTreeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoundTxid string `protobuf:"bytes,1,opt,name=round_txid,json=roundTxid,proto3" json:"round_txid,omitempty"`
+}
+
+func (x *GetRoundTreeRequest) Reset() {
+	*x = GetRoundTreeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRoundTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoundTreeRequest) ProtoMessage() {}
+
+func (x *GetRoundTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoundTreeRequest.ProtoReflect.Descriptor instead.
+func (*GetRoundTreeRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetRoundTreeRequest) GetRoundTxid() string {
+	if x != nil {
+		return x.RoundTxid
+	}
+	return ""
+}
+
+type GetRoundTreeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoundTxid    string `protobuf:"bytes,1,opt,name=round_txid,json=roundTxid,proto3" json:"round_txid,omitempty"`
+	GenesisPoint string `protobuf:"bytes,2,opt,name=genesis_point,json=genesisPoint,proto3" json:"genesis_point,omitempty"`
+	// The proof file of the asset in the round output.
+	RoundProofFile []byte    `protobuf:"bytes,3,opt,name=round_proof_file,json=roundProofFile,proto3" json:"round_proof_file,omitempty"`
+	Root           *TreeNode `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"`
+}
+
+func (x *GetRoundTreeResponse) Reset() {
+	*x = GetRoundTreeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRoundTreeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoundTreeResponse) ProtoMessage() {}
+
+func (x *GetRoundTreeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoundTreeResponse.ProtoReflect.Descriptor instead.
+func (*GetRoundTreeResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetRoundTreeResponse) GetRoundTxid() string {
+	if x != nil {
+		return x.RoundTxid
+	}
+	return ""
+}
+
+func (x *GetRoundTreeResponse) GetGenesisPoint() string {
+	if x != nil {
+		return x.GenesisPoint
+	}
+	return ""
+}
+
+func (x *GetRoundTreeResponse) GetRoundProofFile() []byte {
+	if x != nil {
+		return x.RoundProofFile
+	}
+	return nil
+}
+
+func (x *GetRoundTreeResponse) GetRoot() *TreeNode {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+type Vtxo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoundTxid   string     `protobuf:"bytes,1,opt,name=round_txid,json=roundTxid,proto3" json:"round_txid,omitempty"`
+	Outpoint    string     `protobuf:"bytes,2,opt,name=outpoint,proto3" json:"outpoint,omitempty"`
+	Type        OutputType `protobuf:"varint,3,opt,name=type,proto3,enum=arkrpc.OutputType" json:"type,omitempty"`
+	AssetAmount uint64     `protobuf:"varint,4,opt,name=asset_amount,json=assetAmount,proto3" json:"asset_amount,omitempty"`
+	BtcAmount   int64      `protobuf:"varint,5,opt,name=btc_amount,json=btcAmount,proto3" json:"btc_amount,omitempty"`
+}
+
+func (x *Vtxo) Reset() {
+	*x = Vtxo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Vtxo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vtxo) ProtoMessage() {}
+
+func (x *Vtxo) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vtxo.ProtoReflect.Descriptor instead.
+func (*Vtxo) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Vtxo) GetRoundTxid() string {
+	if x != nil {
+		return x.RoundTxid
+	}
+	return ""
+}
+
+func (x *Vtxo) GetOutpoint() string {
+	if x != nil {
+		return x.Outpoint
+	}
+	return ""
+}
+
+func (x *Vtxo) GetType() OutputType {
+	if x != nil {
+		return x.Type
+	}
+	return OutputType_OUTPUT_TYPE_ASSET
+}
+
+func (x *Vtxo) GetAssetAmount() uint64 {
+	if x != nil {
+		return x.AssetAmount
+	}
+	return 0
+}
+
+func (x *Vtxo) GetBtcAmount() int64 {
+	if x != nil {
+		return x.BtcAmount
+	}
+	return 0
+}
+
+type ListVtxosRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ParticipantKey []byte `protobuf:"bytes,1,opt,name=participant_key,json=participantKey,proto3" json:"participant_key,omitempty"`
+}
+
+func (x *ListVtxosRequest) Reset() {
+	*x = ListVtxosRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListVtxosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVtxosRequest) ProtoMessage() {}
+
+func (x *ListVtxosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVtxosRequest.ProtoReflect.Descriptor instead.
+func (*ListVtxosRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListVtxosRequest) GetParticipantKey() []byte {
+	if x != nil {
+		return x.ParticipantKey
+	}
+	return nil
+}
+
+type ListVtxosResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vtxos []*Vtxo `protobuf:"bytes,1,rep,name=vtxos,proto3" json:"vtxos,omitempty"`
+}
+
+func (x *ListVtxosResponse) Reset() {
+	*x = ListVtxosResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListVtxosResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVtxosResponse) ProtoMessage() {}
+
+func (x *ListVtxosResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVtxosResponse.ProtoReflect.Descriptor instead.
+func (*ListVtxosResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListVtxosResponse) GetVtxos() []*Vtxo {
+	if x != nil {
+		return x.Vtxos
+	}
+	return nil
+}
+
+type SubmitKeysRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Either key, as asked for by the request.
+	ScriptKey   *ScriptKey     `protobuf:"bytes,2,opt,name=script_key,json=scriptKey,proto3" json:"script_key,omitempty"`
+	InternalKey *KeyDescriptor `protobuf:"bytes,3,opt,name=internal_key,json=internalKey,proto3" json:"internal_key,omitempty"`
+	// Set instead of the key if the participant failed the request.
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *SubmitKeysRequest) Reset() {
+	*x = SubmitKeysRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitKeysRequest) ProtoMessage() {}
+
+func (x *SubmitKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitKeysRequest.ProtoReflect.Descriptor instead.
+func (*SubmitKeysRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SubmitKeysRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SubmitKeysRequest) GetScriptKey() *ScriptKey {
+	if x != nil {
+		return x.ScriptKey
+	}
+	return nil
+}
+
+func (x *SubmitKeysRequest) GetInternalKey() *KeyDescriptor {
+	if x != nil {
+		return x.InternalKey
+	}
+	return nil
+}
+
+func (x *SubmitKeysRequest) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SubmitNonceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId   string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	SessionId   []byte `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PublicNonce []byte `protobuf:"bytes,3,opt,name=public_nonce,json=publicNonce,proto3" json:"public_nonce,omitempty"`
+	Error       string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *SubmitNonceRequest) Reset() {
+	*x = SubmitNonceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitNonceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitNonceRequest) ProtoMessage() {}
+
+func (x *SubmitNonceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitNonceRequest.ProtoReflect.Descriptor instead.
+func (*SubmitNonceRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SubmitNonceRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SubmitNonceRequest) GetSessionId() []byte {
+	if x != nil {
+		return x.SessionId
+	}
+	return nil
+}
+
+func (x *SubmitNonceRequest) GetPublicNonce() []byte {
+	if x != nil {
+		return x.PublicNonce
+	}
+	return nil
+}
+
+func (x *SubmitNonceRequest) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SubmitSignaturesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId  string   `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Signatures [][]byte `protobuf:"bytes,2,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	Error      string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *SubmitSignaturesRequest) Reset() {
+	*x = SubmitSignaturesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitSignaturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitSignaturesRequest) ProtoMessage() {}
+
+func (x *SubmitSignaturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitSignaturesRequest.ProtoReflect.Descriptor instead.
+func (*SubmitSignaturesRequest) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SubmitSignaturesRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SubmitSignaturesRequest) GetSignatures() [][]byte {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+func (x *SubmitSignaturesRequest) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SubmitResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SubmitResponse) Reset() {
+	*x = SubmitResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ark_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitResponse) ProtoMessage() {}
+
+func (x *SubmitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ark_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitResponse.ProtoReflect.Descriptor instead.
+func (*SubmitResponse) Descriptor() ([]byte, []int) {
+	return file_ark_proto_rawDescGZIP(), []int{35}
+}
+
+var File_ark_proto protoreflect.FileDescriptor
+
+var file_ark_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x61, 0x72, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x61, 0x72, 0x6b,
+	0x72, 0x70, 0x63, 0x22, 0x10, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x69, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77,
+	0x6f, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f,
+	0x72, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x12, 0x24, 0x0a, 0x0e, 0x62, 0x74,
+	0x63, 0x5f, 0x73, 0x70, 0x65, 0x6e, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x62, 0x74, 0x63, 0x53, 0x70, 0x65, 0x6e, 0x64, 0x50, 0x61, 0x74, 0x68,
+	0x22, 0x6f, 0x0a, 0x0d, 0x4b, 0x65, 0x79, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f,
+	0x72, 0x12, 0x22, 0x0a, 0x0d, 0x72, 0x61, 0x77, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x61, 0x77, 0x4b, 0x65, 0x79,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x66, 0x61, 0x6d,
+	0x69, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x46, 0x61,
+	0x6d, 0x69, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x5f, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6b, 0x65, 0x79, 0x49, 0x6e, 0x64, 0x65,
+	0x78, 0x22, 0x73, 0x0a, 0x09, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x17,
+	0x0a, 0x07, 0x70, 0x75, 0x62, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x06, 0x70, 0x75, 0x62, 0x4b, 0x65, 0x79, 0x12, 0x30, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x64,
+	0x65, 0x73, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x72, 0x6b, 0x72,
+	0x70, 0x63, 0x2e, 0x4b, 0x65, 0x79, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72,
+	0x52, 0x07, 0x6b, 0x65, 0x79, 0x44, 0x65, 0x73, 0x63, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x61, 0x70,
+	0x5f, 0x74, 0x77, 0x65, 0x61, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x74, 0x61,
+	0x70, 0x54, 0x77, 0x65, 0x61, 0x6b, 0x22, 0x41, 0x0a, 0x16, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x27, 0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x4b, 0x65, 0x79, 0x22, 0xff, 0x03, 0x0a, 0x0a, 0x52, 0x6f,
+	0x75, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2f, 0x0a, 0x05, 0x70, 0x68, 0x61, 0x73,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x50, 0x68, 0x61, 0x73, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x48, 0x00, 0x52, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0b, 0x6b, 0x65, 0x79,
+	0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x6b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x3b, 0x0a, 0x0d, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52,
+	0x0c, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x4b, 0x0a,
+	0x13, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x5f, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x72, 0x6b,
+	0x72, 0x70, 0x63, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69, 0x67, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x11, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c,
+	0x53, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x51, 0x0a, 0x15, 0x74, 0x61,
+	0x70, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x5f, 0x73, 0x69, 0x67, 0x5f, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x61, 0x72, 0x6b, 0x72,
+	0x70, 0x63, 0x2e, 0x54, 0x61, 0x70, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x53, 0x69, 0x67, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x13, 0x74, 0x61, 0x70, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x53, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x41, 0x0a,
+	0x0f, 0x63, 0x6c, 0x65, 0x61, 0x6e, 0x75, 0x70, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e,
+	0x43, 0x6c, 0x65, 0x61, 0x6e, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00,
+	0x52, 0x0e, 0x63, 0x6c, 0x65, 0x61, 0x6e, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x2a, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x34, 0x0a, 0x09,
+	0x77, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4b, 0x65,
+	0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x08, 0x77, 0x61, 0x74, 0x63, 0x68, 0x4b,
+	0x65, 0x79, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x46, 0x0a, 0x0f, 0x52,
+	0x6f, 0x75, 0x6e, 0x64, 0x50, 0x68, 0x61, 0x73, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70,
+	0x68, 0x61, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x78,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54,
+	0x78, 0x69, 0x64, 0x22, 0x50, 0x0a, 0x0a, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x12, 0x23, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f,
+	0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x4b, 0x65, 0x79, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x55, 0x0a, 0x0c, 0x54, 0x61, 0x70, 0x72, 0x6f, 0x6f, 0x74,
+	0x54, 0x77, 0x65, 0x61, 0x6b, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x5f,
+	0x72, 0x6f, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x70,
+	0x65, 0x6e, 0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x6b, 0x65, 0x79, 0x53, 0x70, 0x65, 0x6e, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x22, 0xb5, 0x01, 0x0a,
+	0x0c, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x32, 0x0a, 0x09,
+	0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x4b, 0x65, 0x79, 0x44, 0x65, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x4b, 0x65, 0x79,
+	0x12, 0x26, 0x0a, 0x0f, 0x61, 0x6c, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x5f, 0x6b,
+	0x65, 0x79, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x53, 0x69,
+	0x67, 0x6e, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x73, 0x12, 0x2a, 0x0a, 0x05, 0x74, 0x77, 0x65, 0x61,
+	0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x54, 0x61, 0x70, 0x72, 0x6f, 0x6f, 0x74, 0x54, 0x77, 0x65, 0x61, 0x6b, 0x52, 0x05, 0x74,
+	0x77, 0x65, 0x61, 0x6b, 0x22, 0xa9, 0x01, 0x0a, 0x11, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c,
+	0x53, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x74, 0x68, 0x65,
+	0x72, 0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0b,
+	0x6f, 0x74, 0x68, 0x65, 0x72, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x73,
+	0x69, 0x67, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x73,
+	0x69, 0x67, 0x48, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x65, 0x61, 0x6e, 0x75,
+	0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x6c, 0x65, 0x61, 0x6e, 0x75, 0x70,
+	0x22, 0x44, 0x0a, 0x07, 0x54, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x6c, 0x65, 0x61, 0x66, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xef, 0x01, 0x0a, 0x13, 0x54, 0x61, 0x70, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x53, 0x69, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x70, 0x73, 0x62, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x70, 0x73, 0x62,
+	0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0c, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x4b, 0x65,
+	0x79, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x04, 0x6b, 0x65, 0x79,
+	0x73, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x5f, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x6f, 0x6c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x2e, 0x0a, 0x0a, 0x74, 0x61, 0x70, 0x5f,
+	0x6c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61,
+	0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x61, 0x70, 0x4c, 0x65, 0x61, 0x66, 0x52, 0x09, 0x74,
+	0x61, 0x70, 0x4c, 0x65, 0x61, 0x76, 0x65, 0x73, 0x22, 0x2f, 0x0a, 0x0e, 0x43, 0x6c, 0x65, 0x61,
+	0x6e, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x50, 0x0a, 0x0a, 0x50, 0x72, 0x6f,
+	0x6f, 0x66, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x67, 0x65, 0x6e, 0x65, 0x73,
+	0x69, 0x73, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x67, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x46, 0x69, 0x6c, 0x65, 0x22, 0x27, 0x0a, 0x0d, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x4b, 0x65, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x75,
+	0x62, 0x6b, 0x65, 0x79, 0x22, 0xa1, 0x01, 0x0a, 0x19, 0x4e, 0x65, 0x77, 0x42, 0x6f, 0x61, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e,
+	0x74, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x61,
+	0x73, 0x73, 0x65, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61,
+	0x73, 0x73, 0x65, 0x74, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x74, 0x63,
+	0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x62,
+	0x74, 0x63, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x83, 0x01, 0x0a, 0x1a, 0x4e, 0x65, 0x77,
+	0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x6f, 0x61, 0x72, 0x64,
+	0x69, 0x6e, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x6f,
+	0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x73, 0x73, 0x65,
+	0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x61, 0x73, 0x73, 0x65, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x62, 0x74, 0x63, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x62, 0x74, 0x63, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x66,
+	0x0a, 0x1a, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67,
+	0x41, 0x73, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x62, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x12, 0x27, 0x0a,
+	0x0f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0x3e, 0x0a, 0x1b, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x73, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x74, 0x63, 0x5f, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x74, 0x63, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x52, 0x0a, 0x18, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x74, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e,
+	0x67, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x61, 0x77, 0x5f, 0x74, 0x78, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x05, 0x72, 0x61, 0x77, 0x54, 0x78, 0x22, 0x1b, 0x0a, 0x19, 0x53, 0x75,
+	0x62, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x74, 0x63, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x81, 0x01, 0x0a, 0x11, 0x43, 0x6f, 0x6c, 0x6c,
+	0x61, 0x62, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65, 0x45, 0x78, 0x69, 0x74, 0x12, 0x23, 0x0a,
+	0x0d, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x73, 0x73, 0x65, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x62, 0x74, 0x63, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e,
+	0x61, 0x6c, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x62, 0x74,
+	0x63, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x4b, 0x65, 0x79, 0x12, 0x1d, 0x0a, 0x0a,
+	0x62, 0x74, 0x63, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x62, 0x74, 0x63, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xa4, 0x01, 0x0a, 0x15,
+	0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e,
+	0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x6f, 0x61, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0a, 0x6c, 0x65, 0x61,
+	0x66, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x2f, 0x0a, 0x05, 0x65, 0x78, 0x69, 0x74, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e,
+	0x43, 0x6f, 0x6c, 0x6c, 0x61, 0x62, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x76, 0x65, 0x45, 0x78, 0x69,
+	0x74, 0x52, 0x05, 0x65, 0x78, 0x69, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x22, 0x35, 0x0a, 0x16, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x49, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x69, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22, 0xed, 0x01, 0x0a, 0x0a, 0x54, 0x72,
+	0x65, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x26, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x21, 0x0a, 0x0c, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x61, 0x73, 0x73, 0x65, 0x74, 0x41, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x74, 0x63, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x62, 0x74, 0x63, 0x41, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x70, 0x72, 0x6f, 0x6f,
+	0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x74, 0x50, 0x72,
+	0x6f, 0x6f, 0x66, 0x12, 0x24, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4e,
+	0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12,
+	0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x08, 0x54, 0x72,
+	0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x65, 0x61, 0x66, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x65, 0x61, 0x66, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x61,
+	0x77, 0x5f, 0x74, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x72, 0x61, 0x77, 0x54,
+	0x78, 0x12, 0x26, 0x0a, 0x04, 0x6c, 0x65, 0x66, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x52, 0x04, 0x6c, 0x65, 0x66, 0x74, 0x12, 0x28, 0x0a, 0x05, 0x72, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70,
+	0x63, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x05, 0x72, 0x69,
+	0x67, 0x68, 0x74, 0x22, 0x34, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x54,
+	0x72, 0x65, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x5f, 0x74, 0x78, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x78, 0x69, 0x64, 0x22, 0xaa, 0x01, 0x0a, 0x14, 0x47, 0x65,
+	0x74, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x78, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x78, 0x69,
+	0x64, 0x12, 0x23, 0x0a, 0x0d, 0x67, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x5f, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x65, 0x6e, 0x65, 0x73, 0x69,
+	0x73, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x28, 0x0a, 0x10, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0e, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x46, 0x69, 0x6c, 0x65,
+	0x12, 0x24, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x22, 0xab, 0x01, 0x0a, 0x04, 0x56, 0x74, 0x78, 0x6f, 0x12,
+	0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x74, 0x78, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x78, 0x69, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x6f, 0x75, 0x74, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6f, 0x75, 0x74, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x26, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70,
+	0x63, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x61, 0x73, 0x73, 0x65, 0x74, 0x41,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x74, 0x63, 0x5f, 0x61, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x62, 0x74, 0x63, 0x41, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x22, 0x3b, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x74, 0x78, 0x6f,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x4b, 0x65,
+	0x79, 0x22, 0x37, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x74, 0x78, 0x6f, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x05, 0x76, 0x74, 0x78, 0x6f, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x56,
+	0x74, 0x78, 0x6f, 0x52, 0x05, 0x76, 0x74, 0x78, 0x6f, 0x73, 0x22, 0xb4, 0x01, 0x0a, 0x11, 0x53,
+	0x75, 0x62, 0x6d, 0x69, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12,
+	0x30, 0x0a, 0x0a, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x4b, 0x65, 0x79, 0x52, 0x09, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x4b, 0x65,
+	0x79, 0x12, 0x38, 0x0a, 0x0c, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x6b, 0x65,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x4b, 0x65, 0x79, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x0b,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x8b, 0x01, 0x0a, 0x12, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4e, 0x6f, 0x6e, 0x63,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x5f, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22,
+	0x6e, 0x0a, 0x17, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0a, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22,
+	0x10, 0x0a, 0x0e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2a, 0x35, 0x0a, 0x07, 0x4b, 0x65, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x13, 0x0a, 0x0f,
+	0x4b, 0x45, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x43, 0x52, 0x49, 0x50, 0x54, 0x10,
+	0x00, 0x12, 0x15, 0x0a, 0x11, 0x4b, 0x45, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e,
+	0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x01, 0x2a, 0x51, 0x0a, 0x0a, 0x4f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41, 0x53, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x13, 0x0a,
+	0x0f, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x42, 0x54, 0x43,
+	0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x43, 0x4f, 0x4c, 0x4f, 0x52, 0x45, 0x44, 0x10, 0x02, 0x32, 0xd7, 0x06, 0x0a, 0x0a,
+	0x41, 0x72, 0x6b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x47, 0x65,
+	0x74, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x47,
+	0x65, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e,
+	0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0f, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1e, 0x2e, 0x61, 0x72, 0x6b, 0x72,
+	0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x61, 0x72, 0x6b, 0x72,
+	0x70, 0x63, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12,
+	0x5b, 0x0a, 0x12, 0x4e, 0x65, 0x77, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x21, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x4e,
+	0x65, 0x77, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70,
+	0x63, 0x2e, 0x4e, 0x65, 0x77, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x13,
+	0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x73,
+	0x73, 0x65, 0x74, 0x12, 0x22, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62,
+	0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x73, 0x73, 0x65, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x41,
+	0x73, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x11,
+	0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x74,
+	0x63, 0x12, 0x20, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x74, 0x63, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62,
+	0x6d, 0x69, 0x74, 0x42, 0x6f, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x74, 0x63, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x0e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x65, 0x72, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70,
+	0x63, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x52, 0x6f,
+	0x75, 0x6e, 0x64, 0x54, 0x72, 0x65, 0x65, 0x12, 0x1b, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63,
+	0x2e, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x72, 0x65, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x40, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x74, 0x78, 0x6f, 0x73, 0x12,
+	0x18, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x74, 0x78,
+	0x6f, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x72, 0x6b, 0x72,
+	0x70, 0x63, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x74, 0x78, 0x6f, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4b, 0x65,
+	0x79, 0x73, 0x12, 0x19, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x6d,
+	0x69, 0x74, 0x4b, 0x65, 0x79, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0b, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4e,
+	0x6f, 0x6e, 0x63, 0x65, 0x12, 0x1a, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75,
+	0x62, 0x6d, 0x69, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x6d,
+	0x69, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x1f, 0x2e, 0x61,
+	0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x11, 0x5a, 0x0f, 0x74, 0x61, 0x70, 0x6f, 0x6e, 0x61, 0x72,
+	0x6b, 0x2f, 0x61, 0x72, 0x6b, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ark_proto_rawDescOnce sync.Once
+	file_ark_proto_rawDescData = file_ark_proto_rawDesc
+)
+
+func file_ark_proto_rawDescGZIP() []byte {
+	file_ark_proto_rawDescOnce.Do(func() {
+		file_ark_proto_rawDescData = protoimpl.X.CompressGZIP(file_ark_proto_rawDescData)
+	})
+	return file_ark_proto_rawDescData
+}
+
+var file_ark_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_ark_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_ark_proto_goTypes = []interface{}{
+	(KeyType)(0),                        // 0: arkrpc.KeyType
+	(OutputType)(0),                     // 1: arkrpc.OutputType
+	(*GetInfoRequest)(nil),              // 2: arkrpc.GetInfoRequest
+	(*GetInfoResponse)(nil),             // 3: arkrpc.GetInfoResponse
+	(*KeyDescriptor)(nil),               // 4: arkrpc.KeyDescriptor
+	(*ScriptKey)(nil),                   // 5: arkrpc.ScriptKey
+	(*SubscribeEventsRequest)(nil),      // 6: arkrpc.SubscribeEventsRequest
+	(*RoundEvent)(nil),                  // 7: arkrpc.RoundEvent
+	(*RoundPhaseEvent)(nil),             // 8: arkrpc.RoundPhaseEvent
+	(*KeyRequest)(nil),                  // 9: arkrpc.KeyRequest
+	(*TaprootTweak)(nil),                // 10: arkrpc.TaprootTweak
+	(*NonceRequest)(nil),                // 11: arkrpc.NonceRequest
+	(*PartialSigRequest)(nil),           // 12: arkrpc.PartialSigRequest
+	(*TapLeaf)(nil),                     // 13: arkrpc.TapLeaf
+	(*TapscriptSigRequest)(nil),         // 14: arkrpc.TapscriptSigRequest
+	(*CleanupRequest)(nil),              // 15: arkrpc.CleanupRequest
+	(*ProofEvent)(nil),                  // 16: arkrpc.ProofEvent
+	(*WatchKeyEvent)(nil),               // 17: arkrpc.WatchKeyEvent
+	(*NewBoardingAddressRequest)(nil),   // 18: arkrpc.NewBoardingAddressRequest
+	(*NewBoardingAddressResponse)(nil),  // 19: arkrpc.NewBoardingAddressResponse
+	(*SubmitBoardingAssetRequest)(nil),  // 20: arkrpc.SubmitBoardingAssetRequest
+	(*SubmitBoardingAssetResponse)(nil), // 21: arkrpc.SubmitBoardingAssetResponse
+	(*SubmitBoardingBtcRequest)(nil),    // 22: arkrpc.SubmitBoardingBtcRequest
+	(*SubmitBoardingBtcResponse)(nil),   // 23: arkrpc.SubmitBoardingBtcResponse
+	(*CollaborativeExit)(nil),           // 24: arkrpc.CollaborativeExit
+	(*RegisterIntentRequest)(nil),       // 25: arkrpc.RegisterIntentRequest
+	(*RegisterIntentResponse)(nil),      // 26: arkrpc.RegisterIntentResponse
+	(*TreeOutput)(nil),                  // 27: arkrpc.TreeOutput
+	(*TreeNode)(nil),                    // 28: arkrpc.TreeNode
+	(*GetRoundTreeRequest)(nil),         // 29: arkrpc.GetRoundTreeRequest
+	(*GetRoundTreeResponse)(nil),        // 30: arkrpc.GetRoundTreeResponse
+	(*Vtxo)(nil),                        // 31: arkrpc.Vtxo
+	(*ListVtxosRequest)(nil),            // 32: arkrpc.ListVtxosRequest
+	(*ListVtxosResponse)(nil),           // 33: arkrpc.ListVtxosResponse
+	(*SubmitKeysRequest)(nil),           // 34: arkrpc.SubmitKeysRequest
+	(*SubmitNonceRequest)(nil),          // 35: arkrpc.SubmitNonceRequest
+	(*SubmitSignaturesRequest)(nil),     // 36: arkrpc.SubmitSignaturesRequest
+	(*SubmitResponse)(nil),              // 37: arkrpc.SubmitResponse
+}
+var file_ark_proto_depIdxs = []int32{
+	4,  // 0: arkrpc.ScriptKey.key_desc:type_name -> arkrpc.KeyDescriptor
+	8,  // 1: arkrpc.RoundEvent.phase:type_name -> arkrpc.RoundPhaseEvent
+	9,  // 2: arkrpc.RoundEvent.key_request:type_name -> arkrpc.KeyRequest
+	11, // 3: arkrpc.RoundEvent.nonce_request:type_name -> arkrpc.NonceRequest
+	12, // 4: arkrpc.RoundEvent.partial_sig_request:type_name -> arkrpc.PartialSigRequest
+	14, // 5: arkrpc.RoundEvent.tapscript_sig_request:type_name -> arkrpc.TapscriptSigRequest
+	15, // 6: arkrpc.RoundEvent.cleanup_request:type_name -> arkrpc.CleanupRequest
+	16, // 7: arkrpc.RoundEvent.proof:type_name -> arkrpc.ProofEvent
+	17, // 8: arkrpc.RoundEvent.watch_key:type_name -> arkrpc.WatchKeyEvent
+	0,  // 9: arkrpc.KeyRequest.type:type_name -> arkrpc.KeyType
+	4,  // 10: arkrpc.NonceRequest.local_key:type_name -> arkrpc.KeyDescriptor
+	10, // 11: arkrpc.NonceRequest.tweak:type_name -> arkrpc.TaprootTweak
+	4,  // 12: arkrpc.TapscriptSigRequest.keys:type_name -> arkrpc.KeyDescriptor
+	13, // 13: arkrpc.TapscriptSigRequest.tap_leaves:type_name -> arkrpc.TapLeaf
+	24, // 14: arkrpc.RegisterIntentRequest.exits:type_name -> arkrpc.CollaborativeExit
+	1,  // 15: arkrpc.TreeOutput.type:type_name -> arkrpc.OutputType
+	28, // 16: arkrpc.TreeOutput.node:type_name -> arkrpc.TreeNode
+	27, // 17: arkrpc.TreeNode.left:type_name -> arkrpc.TreeOutput
+	27, // 18: arkrpc.TreeNode.right:type_name -> arkrpc.TreeOutput
+	28, // 19: arkrpc.GetRoundTreeResponse.root:type_name -> arkrpc.TreeNode
+	1,  // 20: arkrpc.Vtxo.type:type_name -> arkrpc.OutputType
+	31, // 21: arkrpc.ListVtxosResponse.vtxos:type_name -> arkrpc.Vtxo
+	5,  // 22: arkrpc.SubmitKeysRequest.script_key:type_name -> arkrpc.ScriptKey
+	4,  // 23: arkrpc.SubmitKeysRequest.internal_key:type_name -> arkrpc.KeyDescriptor
+	2,  // 24: arkrpc.ArkService.GetInfo:input_type -> arkrpc.GetInfoRequest
+	6,  // 25: arkrpc.ArkService.SubscribeEvents:input_type -> arkrpc.SubscribeEventsRequest
+	18, // 26: arkrpc.ArkService.NewBoardingAddress:input_type -> arkrpc.NewBoardingAddressRequest
+	20, // 27: arkrpc.ArkService.SubmitBoardingAsset:input_type -> arkrpc.SubmitBoardingAssetRequest
+	22, // 28: arkrpc.ArkService.SubmitBoardingBtc:input_type -> arkrpc.SubmitBoardingBtcRequest
+	25, // 29: arkrpc.ArkService.RegisterIntent:input_type -> arkrpc.RegisterIntentRequest
+	29, // 30: arkrpc.ArkService.GetRoundTree:input_type -> arkrpc.GetRoundTreeRequest
+	32, // 31: arkrpc.ArkService.ListVtxos:input_type -> arkrpc.ListVtxosRequest
+	34, // 32: arkrpc.ArkService.SubmitKeys:input_type -> arkrpc.SubmitKeysRequest
+	35, // 33: arkrpc.ArkService.SubmitNonce:input_type -> arkrpc.SubmitNonceRequest
+	36, // 34: arkrpc.ArkService.SubmitSignatures:input_type -> arkrpc.SubmitSignaturesRequest
+	3,  // 35: arkrpc.ArkService.GetInfo:output_type -> arkrpc.GetInfoResponse
+	7,  // 36: arkrpc.ArkService.SubscribeEvents:output_type -> arkrpc.RoundEvent
+	19, // 37: arkrpc.ArkService.NewBoardingAddress:output_type -> arkrpc.NewBoardingAddressResponse
+	21, // 38: arkrpc.ArkService.SubmitBoardingAsset:output_type -> arkrpc.SubmitBoardingAssetResponse
+	23, // 39: arkrpc.ArkService.SubmitBoardingBtc:output_type -> arkrpc.SubmitBoardingBtcResponse
+	26, // 40: arkrpc.ArkService.RegisterIntent:output_type -> arkrpc.RegisterIntentResponse
+	30, // 41: arkrpc.ArkService.GetRoundTree:output_type -> arkrpc.GetRoundTreeResponse
+	33, // 42: arkrpc.ArkService.ListVtxos:output_type -> arkrpc.ListVtxosResponse
+	37, // 43: arkrpc.ArkService.SubmitKeys:output_type -> arkrpc.SubmitResponse
+	37, // 44: arkrpc.ArkService.SubmitNonce:output_type -> arkrpc.SubmitResponse
+	37, // 45: arkrpc.ArkService.SubmitSignatures:output_type -> arkrpc.SubmitResponse
+	35, // [35:46] is the sub-list for method output_type
+	24, // [24:35] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_ark_proto_init() }
+func file_ark_proto_init() {
+	if File_ark_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_ark_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInfoRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInfoResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyDescriptor); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScriptKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoundEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoundPhaseEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaprootTweak); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NonceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PartialSigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TapLeaf); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TapscriptSigRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CleanupRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProofEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchKeyEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewBoardingAddressRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewBoardingAddressResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitBoardingAssetRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitBoardingAssetResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitBoardingBtcRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitBoardingBtcResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CollaborativeExit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterIntentRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterIntentResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TreeOutput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TreeNode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRoundTreeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRoundTreeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Vtxo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListVtxosRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListVtxosResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitKeysRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitNonceRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitSignaturesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ark_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_ark_proto_msgTypes[5].OneofWrappers = []interface{}{
+		(*RoundEvent_Phase)(nil),
+		(*RoundEvent_KeyRequest)(nil),
+		(*RoundEvent_NonceRequest)(nil),
+		(*RoundEvent_PartialSigRequest)(nil),
+		(*RoundEvent_TapscriptSigRequest)(nil),
+		(*RoundEvent_CleanupRequest)(nil),
+		(*RoundEvent_Proof)(nil),
+		(*RoundEvent_WatchKey)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ark_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   36,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ark_proto_goTypes,
+		DependencyIndexes: file_ark_proto_depIdxs,
+		EnumInfos:         file_ark_proto_enumTypes,
+		MessageInfos:      file_ark_proto_msgTypes,
+	}.Build()
+	File_ark_proto = out.File
+	file_ark_proto_rawDesc = nil
+	file_ark_proto_goTypes = nil
+	file_ark_proto_depIdxs = nil
+}
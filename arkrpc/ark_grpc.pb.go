@@ -0,0 +1,577 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: ark.proto
+
+package arkrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ArkService_GetInfo_FullMethodName             = "/arkrpc.ArkService/GetInfo"
+	ArkService_SubscribeEvents_FullMethodName     = "/arkrpc.ArkService/SubscribeEvents"
+	ArkService_NewBoardingAddress_FullMethodName  = "/arkrpc.ArkService/NewBoardingAddress"
+	ArkService_SubmitBoardingAsset_FullMethodName = "/arkrpc.ArkService/SubmitBoardingAsset"
+	ArkService_SubmitBoardingBtc_FullMethodName   = "/arkrpc.ArkService/SubmitBoardingBtc"
+	ArkService_RegisterIntent_FullMethodName      = "/arkrpc.ArkService/RegisterIntent"
+	ArkService_GetRoundTree_FullMethodName        = "/arkrpc.ArkService/GetRoundTree"
+	ArkService_ListVtxos_FullMethodName           = "/arkrpc.ArkService/ListVtxos"
+	ArkService_SubmitKeys_FullMethodName          = "/arkrpc.ArkService/SubmitKeys"
+	ArkService_SubmitNonce_FullMethodName         = "/arkrpc.ArkService/SubmitNonce"
+	ArkService_SubmitSignatures_FullMethodName    = "/arkrpc.ArkService/SubmitSignatures"
+)
+
+// ArkServiceClient is the client API for ArkService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArkServiceClient interface {
+	//
+	//GetInfo returns the network and the public key of the server.
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	//
+	//SubscribeEvents opens the event stream of a participant. Round phases and
+	//every key, nonce and signature request for the participant are sent on
+	//it. A participant must stay subscribed while it boards or takes part in
+	//a round.
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (ArkService_SubscribeEventsClient, error)
+	//
+	//NewBoardingAddress starts boarding an asset and BTC amount. The asset is
+	//sent to the returned Taproot Asset address, the BTC boarding address is
+	//returned by SubmitBoardingAsset once the asset transfer is known. A
+	//boarding without asset returns the BTC boarding address right away.
+	NewBoardingAddress(ctx context.Context, in *NewBoardingAddressRequest, opts ...grpc.CallOption) (*NewBoardingAddressResponse, error)
+	//
+	//SubmitBoardingAsset hands the server the transfer output of the boarding
+	//asset and returns the BTC boarding address.
+	SubmitBoardingAsset(ctx context.Context, in *SubmitBoardingAssetRequest, opts ...grpc.CallOption) (*SubmitBoardingAssetResponse, error)
+	//
+	//SubmitBoardingBtc hands the server the transaction paying the BTC
+	//boarding address. It returns once the transaction is accepted, the
+	//server then waits for both boarding transfers to confirm.
+	SubmitBoardingBtc(ctx context.Context, in *SubmitBoardingBtcRequest, opts ...grpc.CallOption) (*SubmitBoardingBtcResponse, error)
+	//
+	//RegisterIntent moves a completed boarding into the next round. It fails
+	//with UNAVAILABLE while the boarding transfers are not confirmed yet.
+	RegisterIntent(ctx context.Context, in *RegisterIntentRequest, opts ...grpc.CallOption) (*RegisterIntentResponse, error)
+	//
+	//GetRoundTree returns the tree of a finalized round with everything needed
+	//to exit it unilaterally.
+	GetRoundTree(ctx context.Context, in *GetRoundTreeRequest, opts ...grpc.CallOption) (*GetRoundTreeResponse, error)
+	//
+	//ListVtxos returns the VTXOs a participant owns in the leaves of finalized
+	//rounds.
+	ListVtxos(ctx context.Context, in *ListVtxosRequest, opts ...grpc.CallOption) (*ListVtxosResponse, error)
+	//
+	//SubmitKeys answers a KeyRequest with a fresh script or internal key.
+	SubmitKeys(ctx context.Context, in *SubmitKeysRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+	//
+	//SubmitNonce answers a NonceRequest with the MuSig2 session and its public
+	//nonce.
+	SubmitNonce(ctx context.Context, in *SubmitNonceRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+	//
+	//SubmitSignatures answers a PartialSigRequest or TapscriptSigRequest.
+	SubmitSignatures(ctx context.Context, in *SubmitSignaturesRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+}
+
+type arkServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArkServiceClient(cc grpc.ClientConnInterface) ArkServiceClient {
+	return &arkServiceClient{cc}
+}
+
+func (c *arkServiceClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	out := new(GetInfoResponse)
+	err := c.cc.Invoke(ctx, ArkService_GetInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (ArkService_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ArkService_ServiceDesc.Streams[0], ArkService_SubscribeEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &arkServiceSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ArkService_SubscribeEventsClient interface {
+	Recv() (*RoundEvent, error)
+	grpc.ClientStream
+}
+
+type arkServiceSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *arkServiceSubscribeEventsClient) Recv() (*RoundEvent, error) {
+	m := new(RoundEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *arkServiceClient) NewBoardingAddress(ctx context.Context, in *NewBoardingAddressRequest, opts ...grpc.CallOption) (*NewBoardingAddressResponse, error) {
+	out := new(NewBoardingAddressResponse)
+	err := c.cc.Invoke(ctx, ArkService_NewBoardingAddress_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) SubmitBoardingAsset(ctx context.Context, in *SubmitBoardingAssetRequest, opts ...grpc.CallOption) (*SubmitBoardingAssetResponse, error) {
+	out := new(SubmitBoardingAssetResponse)
+	err := c.cc.Invoke(ctx, ArkService_SubmitBoardingAsset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) SubmitBoardingBtc(ctx context.Context, in *SubmitBoardingBtcRequest, opts ...grpc.CallOption) (*SubmitBoardingBtcResponse, error) {
+	out := new(SubmitBoardingBtcResponse)
+	err := c.cc.Invoke(ctx, ArkService_SubmitBoardingBtc_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) RegisterIntent(ctx context.Context, in *RegisterIntentRequest, opts ...grpc.CallOption) (*RegisterIntentResponse, error) {
+	out := new(RegisterIntentResponse)
+	err := c.cc.Invoke(ctx, ArkService_RegisterIntent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) GetRoundTree(ctx context.Context, in *GetRoundTreeRequest, opts ...grpc.CallOption) (*GetRoundTreeResponse, error) {
+	out := new(GetRoundTreeResponse)
+	err := c.cc.Invoke(ctx, ArkService_GetRoundTree_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) ListVtxos(ctx context.Context, in *ListVtxosRequest, opts ...grpc.CallOption) (*ListVtxosResponse, error) {
+	out := new(ListVtxosResponse)
+	err := c.cc.Invoke(ctx, ArkService_ListVtxos_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) SubmitKeys(ctx context.Context, in *SubmitKeysRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	out := new(SubmitResponse)
+	err := c.cc.Invoke(ctx, ArkService_SubmitKeys_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) SubmitNonce(ctx context.Context, in *SubmitNonceRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	out := new(SubmitResponse)
+	err := c.cc.Invoke(ctx, ArkService_SubmitNonce_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arkServiceClient) SubmitSignatures(ctx context.Context, in *SubmitSignaturesRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	out := new(SubmitResponse)
+	err := c.cc.Invoke(ctx, ArkService_SubmitSignatures_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ArkServiceServer is the server API for ArkService service.
+// All implementations must embed UnimplementedArkServiceServer
+// for forward compatibility
+type ArkServiceServer interface {
+	//
+	//GetInfo returns the network and the public key of the server.
+	GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error)
+	//
+	//SubscribeEvents opens the event stream of a participant. Round phases and
+	//every key, nonce and signature request for the participant are sent on
+	//it. A participant must stay subscribed while it boards or takes part in
+	//a round.
+	SubscribeEvents(*SubscribeEventsRequest, ArkService_SubscribeEventsServer) error
+	//
+	//NewBoardingAddress starts boarding an asset and BTC amount. The asset is
+	//sent to the returned Taproot Asset address, the BTC boarding address is
+	//returned by SubmitBoardingAsset once the asset transfer is known. A
+	//boarding without asset returns the BTC boarding address right away.
+	NewBoardingAddress(context.Context, *NewBoardingAddressRequest) (*NewBoardingAddressResponse, error)
+	//
+	//SubmitBoardingAsset hands the server the transfer output of the boarding
+	//asset and returns the BTC boarding address.
+	SubmitBoardingAsset(context.Context, *SubmitBoardingAssetRequest) (*SubmitBoardingAssetResponse, error)
+	//
+	//SubmitBoardingBtc hands the server the transaction paying the BTC
+	//boarding address. It returns once the transaction is accepted, the
+	//server then waits for both boarding transfers to confirm.
+	SubmitBoardingBtc(context.Context, *SubmitBoardingBtcRequest) (*SubmitBoardingBtcResponse, error)
+	//
+	//RegisterIntent moves a completed boarding into the next round. It fails
+	//with UNAVAILABLE while the boarding transfers are not confirmed yet.
+	RegisterIntent(context.Context, *RegisterIntentRequest) (*RegisterIntentResponse, error)
+	//
+	//GetRoundTree returns the tree of a finalized round with everything needed
+	//to exit it unilaterally.
+	GetRoundTree(context.Context, *GetRoundTreeRequest) (*GetRoundTreeResponse, error)
+	//
+	//ListVtxos returns the VTXOs a participant owns in the leaves of finalized
+	//rounds.
+	ListVtxos(context.Context, *ListVtxosRequest) (*ListVtxosResponse, error)
+	//
+	//SubmitKeys answers a KeyRequest with a fresh script or internal key.
+	SubmitKeys(context.Context, *SubmitKeysRequest) (*SubmitResponse, error)
+	//
+	//SubmitNonce answers a NonceRequest with the MuSig2 session and its public
+	//nonce.
+	SubmitNonce(context.Context, *SubmitNonceRequest) (*SubmitResponse, error)
+	//
+	//SubmitSignatures answers a PartialSigRequest or TapscriptSigRequest.
+	SubmitSignatures(context.Context, *SubmitSignaturesRequest) (*SubmitResponse, error)
+	mustEmbedUnimplementedArkServiceServer()
+}
+
+// UnimplementedArkServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedArkServiceServer struct {
+}
+
+func (UnimplementedArkServiceServer) GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedArkServiceServer) SubscribeEvents(*SubscribeEventsRequest, ArkService_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedArkServiceServer) NewBoardingAddress(context.Context, *NewBoardingAddressRequest) (*NewBoardingAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewBoardingAddress not implemented")
+}
+func (UnimplementedArkServiceServer) SubmitBoardingAsset(context.Context, *SubmitBoardingAssetRequest) (*SubmitBoardingAssetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitBoardingAsset not implemented")
+}
+func (UnimplementedArkServiceServer) SubmitBoardingBtc(context.Context, *SubmitBoardingBtcRequest) (*SubmitBoardingBtcResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitBoardingBtc not implemented")
+}
+func (UnimplementedArkServiceServer) RegisterIntent(context.Context, *RegisterIntentRequest) (*RegisterIntentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterIntent not implemented")
+}
+func (UnimplementedArkServiceServer) GetRoundTree(context.Context, *GetRoundTreeRequest) (*GetRoundTreeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoundTree not implemented")
+}
+func (UnimplementedArkServiceServer) ListVtxos(context.Context, *ListVtxosRequest) (*ListVtxosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVtxos not implemented")
+}
+func (UnimplementedArkServiceServer) SubmitKeys(context.Context, *SubmitKeysRequest) (*SubmitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitKeys not implemented")
+}
+func (UnimplementedArkServiceServer) SubmitNonce(context.Context, *SubmitNonceRequest) (*SubmitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitNonce not implemented")
+}
+func (UnimplementedArkServiceServer) SubmitSignatures(context.Context, *SubmitSignaturesRequest) (*SubmitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitSignatures not implemented")
+}
+func (UnimplementedArkServiceServer) mustEmbedUnimplementedArkServiceServer() {}
+
+// UnsafeArkServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArkServiceServer will
+// result in compilation errors.
+type UnsafeArkServiceServer interface {
+	mustEmbedUnimplementedArkServiceServer()
+}
+
+func RegisterArkServiceServer(s grpc.ServiceRegistrar, srv ArkServiceServer) {
+	s.RegisterService(&ArkService_ServiceDesc, srv)
+}
+
+func _ArkService_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_GetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArkServiceServer).SubscribeEvents(m, &arkServiceSubscribeEventsServer{stream})
+}
+
+type ArkService_SubscribeEventsServer interface {
+	Send(*RoundEvent) error
+	grpc.ServerStream
+}
+
+type arkServiceSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *arkServiceSubscribeEventsServer) Send(m *RoundEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ArkService_NewBoardingAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewBoardingAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).NewBoardingAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_NewBoardingAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).NewBoardingAddress(ctx, req.(*NewBoardingAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_SubmitBoardingAsset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitBoardingAssetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).SubmitBoardingAsset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_SubmitBoardingAsset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).SubmitBoardingAsset(ctx, req.(*SubmitBoardingAssetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_SubmitBoardingBtc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitBoardingBtcRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).SubmitBoardingBtc(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_SubmitBoardingBtc_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).SubmitBoardingBtc(ctx, req.(*SubmitBoardingBtcRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_RegisterIntent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterIntentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).RegisterIntent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_RegisterIntent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).RegisterIntent(ctx, req.(*RegisterIntentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_GetRoundTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRoundTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).GetRoundTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_GetRoundTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).GetRoundTree(ctx, req.(*GetRoundTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_ListVtxos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVtxosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).ListVtxos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_ListVtxos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).ListVtxos(ctx, req.(*ListVtxosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_SubmitKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).SubmitKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_SubmitKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).SubmitKeys(ctx, req.(*SubmitKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_SubmitNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitNonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).SubmitNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_SubmitNonce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).SubmitNonce(ctx, req.(*SubmitNonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArkService_SubmitSignatures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitSignaturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArkServiceServer).SubmitSignatures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArkService_SubmitSignatures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArkServiceServer).SubmitSignatures(ctx, req.(*SubmitSignaturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ArkService_ServiceDesc is the grpc.ServiceDesc for ArkService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ArkService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "arkrpc.ArkService",
+	HandlerType: (*ArkServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInfo",
+			Handler:    _ArkService_GetInfo_Handler,
+		},
+		{
+			MethodName: "NewBoardingAddress",
+			Handler:    _ArkService_NewBoardingAddress_Handler,
+		},
+		{
+			MethodName: "SubmitBoardingAsset",
+			Handler:    _ArkService_SubmitBoardingAsset_Handler,
+		},
+		{
+			MethodName: "SubmitBoardingBtc",
+			Handler:    _ArkService_SubmitBoardingBtc_Handler,
+		},
+		{
+			MethodName: "RegisterIntent",
+			Handler:    _ArkService_RegisterIntent_Handler,
+		},
+		{
+			MethodName: "GetRoundTree",
+			Handler:    _ArkService_GetRoundTree_Handler,
+		},
+		{
+			MethodName: "ListVtxos",
+			Handler:    _ArkService_ListVtxos_Handler,
+		},
+		{
+			MethodName: "SubmitKeys",
+			Handler:    _ArkService_SubmitKeys_Handler,
+		},
+		{
+			MethodName: "SubmitNonce",
+			Handler:    _ArkService_SubmitNonce_Handler,
+		},
+		{
+			MethodName: "SubmitSignatures",
+			Handler:    _ArkService_SubmitSignatures_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _ArkService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ark.proto",
+}
@@ -0,0 +1,12 @@
+#!/bin/bash
+
+# Regenerates the Go code of the Ark service, needs protoc with the
+# protoc-gen-go v1.33.0 and protoc-gen-go-grpc v1.3.0 plugins on the PATH.
+set -e
+
+cd "$(dirname "$0")"
+
+protoc -I. \
+    --go_out . --go_opt paths=source_relative \
+    --go-grpc_out . --go-grpc_opt paths=source_relative \
+    ark.proto
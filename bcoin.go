@@ -1,16 +1,20 @@
 package taponark
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/lntest/wait"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 type BitcoinSendTxResult struct {
@@ -19,43 +23,82 @@ type BitcoinSendTxResult struct {
 	txindex     int
 }
 
+// NewBitcoinSendTxResult records the inclusion of the transaction at txIndex
+// of block.
+func NewBitcoinSendTxResult(block *wire.MsgBlock, blockHeight int64, txIndex int) BitcoinSendTxResult {
+	return BitcoinSendTxResult{block, blockHeight, txIndex}
+}
+
 type BitcoinClient struct {
-	client      *rpcclient.Client
+	backend     ChainBackend
 	chainParams chaincfg.Params
 	timeout     time.Duration
 }
 
 func GetBitcoinClient(config BitcoinClientConfig, chainParams chaincfg.Params, timeout time.Duration) BitcoinClient {
-	hostPort := config.Host + ":" + config.Port
+	if config.ReplayFile != "" {
+		tape, err := NewChainReplayer(config.ReplayFile)
+		if err != nil {
+			log.Fatalf("cannot replay chain %v", err)
+		}
 
-	connCfg := &rpcclient.ConnConfig{
-		Host:         hostPort,        // btcd's RPC host:port
-		User:         config.User,     // RPC username
-		Pass:         config.Password, // RPC password
-		HTTPPostMode: true,            // btcd only supports HTTP POST mode
-		DisableTLS:   true,            // Use TLS if configured
+		return NewBitcoinClient(tape, chainParams, timeout)
 	}
 
-	// Create a new RPC client instance.
-	client, err := rpcclient.New(connCfg, nil)
-	if err != nil {
-		log.Fatalf("Error creating new RPC client: %v", err)
+	var backend ChainBackend
+
+	switch config.Backend {
+	case ChainBackendEsplora:
+		backend = NewEsploraBackend(config.EsploraUrl, timeout)
+
+	case "", ChainBackendBitcoind:
+		bitcoindBackend, err := NewBitcoindBackend(config)
+		if err != nil {
+			log.Fatalf("Error creating new RPC client: %v", err)
+		}
+		backend = bitcoindBackend
+
+	default:
+		log.Fatalf("unknown chain backend %s", config.Backend)
+	}
+
+	if config.RecordFile != "" {
+		tape, err := NewChainRecorder(backend, config.RecordFile)
+		if err != nil {
+			log.Fatalf("cannot record chain %v", err)
+		}
+		backend = tape
 	}
 
-	return BitcoinClient{client, chainParams, timeout}
+	return NewBitcoinClient(backend, chainParams, timeout)
+}
+
+// NewBitcoinClient creates a BitcoinClient on top of an already initialised
+// chain backend.
+func NewBitcoinClient(backend ChainBackend, chainParams chaincfg.Params, timeout time.Duration) BitcoinClient {
+	return BitcoinClient{backend, chainParams, timeout}
+}
+
+// Close ends the recording of a taped chain backend, a replayed tape must
+// have been served to its end.
+func (b BitcoinClient) Close() error {
+	if tape, ok := b.backend.(*ChainTape); ok {
+		return tape.Close()
+	}
 
+	return nil
 }
 
 func (b BitcoinClient) WaitForConfirmation(txhash chainhash.Hash) error {
 	log.Println("awaiting block to be mined")
 	err := wait.NoError(func() error {
-		txInfo, err := b.client.GetRawTransactionVerbose(&txhash)
+		status, err := b.backend.GetTransactionStatus(&txhash)
 		if err == nil {
-			if txInfo.Confirmations == 0 {
+			if !status.Confirmed {
 				return fmt.Errorf("transaction not confirmed with hash %s", txhash.String())
 			}
 		} else {
-			if !strings.Contains(err.Error(), "-5: No such mempool transaction") {
+			if !errors.Is(err, ErrTxNotFound) {
 				return fmt.Errorf("failed to get transaction: %w", err)
 			}
 		}
@@ -66,52 +109,210 @@ func (b BitcoinClient) WaitForConfirmation(txhash chainhash.Hash) error {
 }
 
 func (b BitcoinClient) SendTransaction(transaction *wire.MsgTx) (BitcoinSendTxResult, error) {
-	txhash, err := b.client.SendRawTransaction(transaction, true)
+	txhash, err := b.backend.BroadcastTransaction(transaction)
 	if err != nil {
 		return BitcoinSendTxResult{}, fmt.Errorf("cannot send raw transaction %v", err)
 	}
 
+	return b.WaitForInclusion(*txhash)
+}
+
+// SendTransactionOnce is SendTransaction for transactions that may already be
+// mined, such as tree branches shared by several exits.
+func (b BitcoinClient) SendTransactionOnce(transaction *wire.MsgTx) (BitcoinSendTxResult, error) {
+	txhash := transaction.TxHash()
+	status, err := b.backend.GetTransactionStatus(&txhash)
+	if err == nil && status.Confirmed {
+		return b.WaitForInclusion(txhash)
+	}
+
+	return b.SendTransaction(transaction)
+}
+
+// BroadcastTransaction publishes the transaction without waiting for it to
+// confirm.
+func (b BitcoinClient) BroadcastTransaction(transaction *wire.MsgTx) (chainhash.Hash, error) {
+	txhash, err := b.backend.BroadcastTransaction(transaction)
+	if err != nil {
+		return chainhash.Hash{}, fmt.Errorf("cannot send raw transaction %v", err)
+	}
+
+	return *txhash, nil
+}
+
+// WaitForInclusion waits until the transaction is mined and returns the block
+// it was included in, or an error once the client timeout expires.
+func (b BitcoinClient) WaitForInclusion(txhash chainhash.Hash) (BitcoinSendTxResult, error) {
 	log.Printf("awaiting confirmation for tx: %s", txhash.String())
 
 	var bitcoinSendResult BitcoinSendTxResult
 
-	err = wait.NoError(func() error {
-		txInfo, err := b.client.GetRawTransactionVerbose(txhash)
-		if err == nil {
-			if txInfo.Confirmations == 0 {
-				return fmt.Errorf("transaction not confirmed with hash %s", txhash.String())
-			}
-		} else {
-			if !strings.Contains(err.Error(), "-5: No such mempool transaction") {
-				return fmt.Errorf("failed to get transaction: %w", err)
-			}
+	err := wait.NoError(func() error {
+		status, err := b.backend.GetTransactionStatus(&txhash)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if !status.Confirmed {
+			return fmt.Errorf("transaction not confirmed with hash %s", txhash.String())
 		}
-		for {
-			blockheight, err := b.client.GetBlockCount()
-			if err != nil {
-				return fmt.Errorf("cannot get blocokheight %v", err)
-			}
-
-			blockhash, err := b.client.GetBlockHash(blockheight)
-			if err != nil {
-				return fmt.Errorf("cannot get block  hash%v", err)
-			}
 
-			block, err := b.client.GetBlock(blockhash)
-			if err != nil {
-				return fmt.Errorf("cannot get block  %v", err)
-			}
+		block, err := b.backend.GetBlock(status.BlockHash)
+		if err != nil {
+			return fmt.Errorf("cannot get block  %v", err)
+		}
 
-			for index, txn := range block.Transactions {
-				if txn.TxHash().String() == txhash.String() {
-					bitcoinSendResult = BitcoinSendTxResult{block, blockheight, index}
-					return nil
-				}
+		for index, txn := range block.Transactions {
+			if txn.TxHash() == txhash {
+				bitcoinSendResult = BitcoinSendTxResult{block, status.BlockHeight, index}
+				return nil
 			}
-
 		}
 
+		return fmt.Errorf("transaction %s not found in block %s", txhash, status.BlockHash)
 	}, b.timeout)
 
 	return bitcoinSendResult, err
 }
+
+// CheckMempoolAccept runs a mempool acceptance test on the transactions and
+// returns an error naming the first transaction that would be rejected.
+func (b BitcoinClient) CheckMempoolAccept(txs ...*wire.MsgTx) error {
+	results, err := b.backend.TestMempoolAccept(txs)
+	if err != nil {
+		return err
+	}
+
+	if len(results) != len(txs) {
+		return fmt.Errorf("expected %d mempool accept results, got %d", len(txs), len(results))
+	}
+
+	for _, result := range results {
+		if !result.Allowed {
+			return fmt.Errorf("transaction %s rejected by mempool: %s", result.Txid, result.RejectReason)
+		}
+	}
+
+	return nil
+}
+
+// EstimateFee returns the backend fee estimate for the given confirmation
+// target.
+func (b BitcoinClient) EstimateFee(confTarget uint32) (chainfee.SatPerKWeight, error) {
+	return b.backend.EstimateFee(confTarget)
+}
+
+// BitcoindBackend implements ChainBackend using bitcoind's JSON-RPC interface.
+type BitcoindBackend struct {
+	client *rpcclient.Client
+}
+
+func NewBitcoindBackend(config BitcoinClientConfig) (*BitcoindBackend, error) {
+	hostPort := config.Host + ":" + config.Port
+
+	connCfg := &rpcclient.ConnConfig{
+		Host:         hostPort,        // btcd's RPC host:port
+		User:         config.User,     // RPC username
+		Pass:         config.Password, // RPC password
+		HTTPPostMode: true,            // btcd only supports HTTP POST mode
+		DisableTLS:   true,            // Use TLS if configured
+	}
+
+	// Create a new RPC client instance.
+	client, err := rpcclient.New(connCfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BitcoindBackend{client}, nil
+}
+
+func (b *BitcoindBackend) BroadcastTransaction(tx *wire.MsgTx) (*chainhash.Hash, error) {
+	return b.client.SendRawTransaction(tx, true)
+}
+
+func (b *BitcoindBackend) GetTransaction(txid *chainhash.Hash) (*wire.MsgTx, error) {
+	tx, err := b.client.GetRawTransaction(txid)
+	if err != nil {
+		return nil, mapBitcoindError(err)
+	}
+
+	return tx.MsgTx(), nil
+}
+
+func (b *BitcoindBackend) GetTransactionStatus(txid *chainhash.Hash) (TxStatus, error) {
+	txInfo, err := b.client.GetRawTransactionVerbose(txid)
+	if err != nil {
+		return TxStatus{}, mapBitcoindError(err)
+	}
+
+	if txInfo.Confirmations == 0 {
+		return TxStatus{}, nil
+	}
+
+	blockHash, err := chainhash.NewHashFromStr(txInfo.BlockHash)
+	if err != nil {
+		return TxStatus{}, fmt.Errorf("cannot decode block hash %v", err)
+	}
+
+	header, err := b.client.GetBlockHeaderVerbose(blockHash)
+	if err != nil {
+		return TxStatus{}, fmt.Errorf("cannot get block header %v", err)
+	}
+
+	return TxStatus{true, blockHash, int64(header.Height)}, nil
+}
+
+func (b *BitcoindBackend) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return b.client.GetBlock(blockHash)
+}
+
+func (b *BitcoindBackend) GetBlockCount() (int64, error) {
+	return b.client.GetBlockCount()
+}
+
+func (b *BitcoindBackend) TestMempoolAccept(txs []*wire.MsgTx) ([]MempoolAcceptResult, error) {
+	// A zero max fee rate disables bitcoind's absurd fee check.
+	results, err := b.client.TestMempoolAccept(txs, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot test mempool accept %v", err)
+	}
+
+	acceptResults := make([]MempoolAcceptResult, len(results))
+	for i, result := range results {
+		rejectReason := result.RejectReason
+		if rejectReason == "" {
+			rejectReason = result.PackageError
+		}
+		acceptResults[i] = MempoolAcceptResult{result.Txid, result.Allowed, rejectReason}
+	}
+
+	return acceptResults, nil
+}
+
+func (b *BitcoindBackend) EstimateFee(confTarget uint32) (chainfee.SatPerKWeight, error) {
+	result, err := b.client.EstimateSmartFee(int64(confTarget), &btcjson.EstimateModeConservative)
+	if err != nil {
+		return 0, fmt.Errorf("cannot estimate fee %v", err)
+	}
+
+	if result.FeeRate == nil {
+		return 0, fmt.Errorf("no fee estimate available: %v", result.Errors)
+	}
+
+	feeRate, err := btcutil.NewAmount(*result.FeeRate)
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode fee rate %v", err)
+	}
+
+	return chainfee.SatPerKVByte(feeRate).FeePerKWeight(), nil
+}
+
+// mapBitcoindError translates bitcoind's "unknown transaction" RPC error into
+// ErrTxNotFound.
+func mapBitcoindError(err error) error {
+	if strings.Contains(err.Error(), "-5: No such mempool") {
+		return fmt.Errorf("%w: %v", ErrTxNotFound, err)
+	}
+
+	return err
+}
@@ -6,65 +6,195 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
-	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/taprpc"
 )
 
 // / Logic To Onboard User ( BTC + ASSET)
-func OnboardUser(assetId []byte, boardingAssetAmount uint64, boardingBtcAmount uint64, boardingClient, serverTapClient *TapClient, bitcoinClient *BitcoinClient) (ArkBoardingTransfer, error) {
+func OnboardUser(assetId []byte, boardingAssetAmount uint64, boardingBtcAmount uint64, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient, bitcoinClient *BitcoinClient) (ArkBoardingTransfer, error) {
 	/// 1. Send Asset From Boarding User To Boarding Address
-	// Create Server Boarding Details
-	assetSpendingDetails, err := CreateOnboardSpendingDetails(boardingClient, serverTapClient)
+	boardingAddrResp, assetTransferOutput, assetSpendingDetails, _, err := sendBoardingAsset(assetId, boardingAssetAmount, spendPath, boardingClient, serverTapClient)
 	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("cannot create Boarding Spending Details %v", err)
+		return ArkBoardingTransfer{}, err
 	}
+	taprootAssetRoot := assetTransferOutput.Anchor.TaprootAssetRoot
 
-	// Deriver Server Boarding Address
-	boardingAddrResp, err := serverTapClient.GetNewAddress(assetSpendingDetails.arkBtcScript.Branch, assetSpendingDetails.arkAssetScript.tapScriptKey, assetId, boardingAssetAmount)
+	/// 2. Send BTC From Boarding User To Boarding Address
+	zeroHash := taprootAssetRoot
+	boardingBtcTransferDetails, onboardBtcTxHash, err := sendBoardingBtc(boardingBtcAmount, zeroHash, spendPath, boardingClient, serverTapClient)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	// Ensure Btc and Asset Transfer Sucess
+	err = waitForTransfers(bitcoinClient, serverTapClient, onboardBtcTxHash, boardingAddrResp)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	assetTransferDetails, err := exportBoardingAsset(assetId, assetTransferOutput, assetSpendingDetails, boardingAssetAmount, serverTapClient)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	return ArkBoardingTransfer{assetTransferDetails, boardingBtcTransferDetails, boardingClient}, nil
+}
+
+// OnboardBtcUser boards BTC only, the returned transfer carries no asset.
+func OnboardBtcUser(boardingBtcAmount uint64, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient, bitcoinClient *BitcoinClient) (ArkBoardingTransfer, error) {
+	boardingBtcTransferDetails, onboardBtcTxHash, err := sendBoardingBtc(boardingBtcAmount, nil, spendPath, boardingClient, serverTapClient)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	err = bitcoinClient.WaitForConfirmation(onboardBtcTxHash)
+	if err != nil {
+		return ArkBoardingTransfer{}, fmt.Errorf("BTC confirmation failed: %w", err)
+	}
+
+	return ArkBoardingTransfer{AssetTransferDetails{}, boardingBtcTransferDetails, boardingClient}, nil
+}
+
+// OnboardAssetUser boards the asset only, the returned transfer carries no
+// BTC besides the anchor of the asset.
+func OnboardAssetUser(assetId []byte, boardingAssetAmount uint64, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient, bitcoinClient *BitcoinClient) (ArkBoardingTransfer, error) {
+	boardingAddrResp, assetTransferOutput, assetSpendingDetails, anchorTxHash, err := sendBoardingAsset(assetId, boardingAssetAmount, spendPath, boardingClient, serverTapClient)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	err = waitForTransfers(bitcoinClient, serverTapClient, anchorTxHash, boardingAddrResp)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	assetTransferDetails, err := exportBoardingAsset(assetId, assetTransferOutput, assetSpendingDetails, boardingAssetAmount, serverTapClient)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	return ArkBoardingTransfer{assetTransferDetails, BtcTransferDetails{}, boardingClient}, nil
+}
+
+// sendBoardingAsset sends the boarding asset to a new boarding address of the
+// server and returns the boarding output with the control block of its spend
+// leaf.
+func sendBoardingAsset(assetId []byte, boardingAssetAmount uint64, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient) (*taprpc.Addr, *taprpc.TransferOutput, ArkSpendingDetails, chainhash.Hash, error) {
+	boardingAddrResp, assetSpendingDetails, err := newBoardingAssetAddress(assetId, boardingAssetAmount, spendPath, boardingClient, serverTapClient)
 	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("cannot get Boarding address from Server %v", err)
+		return nil, nil, ArkSpendingDetails{}, chainhash.Hash{}, err
 	}
 
 	// Send Asset to onboarding address
 	sendBoardingAssetResp, err := boardingClient.SendAsset(boardingAddrResp)
 	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("failed to send boarding asset [%s] to boarding address %v", hex.EncodeToString(assetId), err)
+		return nil, nil, ArkSpendingDetails{}, chainhash.Hash{}, fmt.Errorf("failed to send boarding asset [%s] to boarding address %v", hex.EncodeToString(assetId), err)
 	}
 
 	log.Printf("Boarding Asset TxId %s", hex.EncodeToString(sendBoardingAssetResp.Transfer.AnchorTxHash))
 
+	anchorTxHash, err := chainhash.NewHash(sendBoardingAssetResp.Transfer.AnchorTxHash)
+	if err != nil {
+		return nil, nil, ArkSpendingDetails{}, chainhash.Hash{}, fmt.Errorf("cannot parse boarding asset txid %v", err)
+	}
+
 	// Insert Boarding AssetSpendingDetails Control Block
 	assetTransferOutput := sendBoardingAssetResp.Transfer.Outputs[BOARDING_ASSET_TRANSFER_OUTPUT_INDEX]
-	taprootAssetRoot := assetTransferOutput.Anchor.TaprootAssetRoot
-	assetControlBlock := extractControlBlock(assetSpendingDetails.arkBtcScript, taprootAssetRoot)
+	assetControlBlock, err := extractControlBlock(assetSpendingDetails.arkBtcScript, assetTransferOutput.Anchor.TaprootAssetRoot)
+	if err != nil {
+		return nil, nil, ArkSpendingDetails{}, chainhash.Hash{}, err
+	}
 	assetSpendingDetails.arkBtcScript.controlBlock = assetControlBlock
 
-	/// 2. Send BTC From Boarding User To Boarding Address
-	zeroHash := taprootAssetRoot
-	btcSpendingDetails, err := CreateOnboardSpendingDetails(boardingClient, serverTapClient)
+	return boardingAddrResp, assetTransferOutput, assetSpendingDetails, *anchorTxHash, nil
+}
+
+// newBoardingAssetAddress derives the server address the boarding asset is
+// sent to, spent by the user and server together or by the user alone once
+// the timelock expires.
+func newBoardingAssetAddress(assetId []byte, boardingAssetAmount uint64, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient) (*taprpc.Addr, ArkSpendingDetails, error) {
+	// Create Server Boarding Details
+	assetSpendingDetails, err := CreateOnboardSpendingDetails(boardingClient, serverTapClient, spendPath)
 	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("cannot create boarding spending details %v", err)
+		return nil, ArkSpendingDetails{}, fmt.Errorf("cannot create Boarding Spending Details %v", err)
 	}
 
-	// Create Boarding BTC OutputScript
-	btcControlBlock := extractControlBlock(btcSpendingDetails.arkBtcScript, zeroHash)
-	btcSpendingDetails.arkBtcScript.controlBlock = btcControlBlock
-	rootHash := btcControlBlock.RootHash(btcSpendingDetails.arkBtcScript.cooperativeSpend.Script)
-	outputKey := txscript.ComputeTaprootOutputKey(asset.NUMSPubKey, rootHash)
-	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	// Deriver Server Boarding Address
+	boardingAddrResp, err := serverTapClient.GetNewAddress(assetSpendingDetails.arkBtcScript, assetSpendingDetails.arkAssetScript.tapScriptKey, assetId, boardingAssetAmount)
+	if err != nil {
+		return nil, ArkSpendingDetails{}, fmt.Errorf("cannot get Boarding address from Server %v", err)
+	}
+
+	return boardingAddrResp, assetSpendingDetails, nil
+}
+
+// exportBoardingAsset fetches the proof of the boarding asset received by the
+// server.
+func exportBoardingAsset(assetId []byte, assetTransferOutput *taprpc.TransferOutput, assetSpendingDetails ArkSpendingDetails, boardingAssetAmount uint64, serverTapClient *TapClient) (AssetTransferDetails, error) {
+	// Fetch Onboard Asset Transfer Proof
+	assetTransferProof, err := serverTapClient.ExportProof(assetId,
+		assetTransferOutput.ScriptKey,
+	)
+	if err != nil {
+		return AssetTransferDetails{}, fmt.Errorf("cannot fetch Boarding Transfer Proof %v", err)
+	}
+
+	return AssetTransferDetails{assetTransferOutput, assetSpendingDetails, boardingAssetAmount, assetTransferProof}, nil
+}
+
+// sendBoardingBtc sends BTC from the boarding user to a boarding output whose
+// spend leaf is proven under taprootAssetRoot.
+func sendBoardingBtc(boardingBtcAmount uint64, taprootAssetRoot []byte, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient) (BtcTransferDetails, chainhash.Hash, error) {
+	btcSpendingDetails, pkScript, err := newBoardingBtcOutput(taprootAssetRoot, spendPath, boardingClient, serverTapClient)
 	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("cannot create Btc Boarding Output Script %v", err)
+		return BtcTransferDetails{}, chainhash.Hash{}, err
 	}
 
 	// Send BTC to Onboarding Output
 	onboardBtcTransaction, err := boardingClient.lndClient.SendOutput(int64(boardingBtcAmount), pkScript)
 	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("cannot send btc to output %v", err)
+		return BtcTransferDetails{}, chainhash.Hash{}, fmt.Errorf("cannot send btc to output %v", err)
 	}
 
 	log.Printf("Boarding BTC TxId %s", onboardBtcTransaction.TxHash().String())
 
+	btcTransferDetails, err := findBoardingBtcOutput(&onboardBtcTransaction, pkScript, btcSpendingDetails)
+	if err != nil {
+		return BtcTransferDetails{}, chainhash.Hash{}, err
+	}
+
+	return btcTransferDetails, onboardBtcTransaction.TxHash(), nil
+}
+
+// newBoardingBtcOutput derives the script of a BTC boarding output whose
+// spend leaf is proven under taprootAssetRoot.
+func newBoardingBtcOutput(taprootAssetRoot []byte, spendPath BtcSpendPath, boardingClient, serverTapClient *TapClient) (ArkSpendingDetails, []byte, error) {
+	btcSpendingDetails, err := CreateOnboardSpendingDetails(boardingClient, serverTapClient, spendPath)
+	if err != nil {
+		return ArkSpendingDetails{}, nil, fmt.Errorf("cannot create boarding spending details %v", err)
+	}
+
+	// Create Boarding BTC OutputScript
+	btcControlBlock, err := extractControlBlock(btcSpendingDetails.arkBtcScript, taprootAssetRoot)
+	if err != nil {
+		return ArkSpendingDetails{}, nil, err
+	}
+	btcSpendingDetails.arkBtcScript.controlBlock = btcControlBlock
+	rootHash := btcSpendingDetails.arkBtcScript.merkleRoot()
+	outputKey := txscript.ComputeTaprootOutputKey(btcSpendingDetails.arkBtcScript.internalKey, rootHash)
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	if err != nil {
+		return ArkSpendingDetails{}, nil, fmt.Errorf("cannot create Btc Boarding Output Script %v", err)
+	}
+
+	return btcSpendingDetails, pkScript, nil
+}
+
+// findBoardingBtcOutput locates the boarding output paying pkScript in the
+// boarding transaction.
+func findBoardingBtcOutput(onboardBtcTransaction *wire.MsgTx, pkScript []byte, btcSpendingDetails ArkSpendingDetails) (BtcTransferDetails, error) {
 	var (
 		matchedTxOut    *wire.TxOut
 		matchedOutPoint *wire.OutPoint
@@ -83,24 +213,10 @@ func OnboardUser(assetId []byte, boardingAssetAmount uint64, boardingBtcAmount u
 	}
 
 	if matchedTxOut == nil || matchedOutPoint == nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("unable to locate matching TxOut and OutPoint")
+		return BtcTransferDetails{}, fmt.Errorf("unable to locate matching TxOut and OutPoint")
 	}
 
-	boardingBtcTransferDetails := BtcTransferDetails{
-		matchedTxOut, matchedOutPoint, boardingBtcAmount, btcSpendingDetails,
-	}
-
-	// Ensure Btc and Asset Transfer Sucess
-	waitForTransfers(bitcoinClient, serverTapClient, onboardBtcTransaction.TxHash(), boardingAddrResp)
-
-	// Fetch Onboard Asset Transfer Proof
-	assetTransferProof, err := serverTapClient.ExportProof(assetId,
-		assetTransferOutput.ScriptKey,
-	)
-	if err != nil {
-		return ArkBoardingTransfer{}, fmt.Errorf("cannot fetch Boarding Transfer Proof %v", err)
-	}
-	assetTransferDetails := AssetTransferDetails{assetTransferOutput, assetSpendingDetails, boardingAssetAmount, assetTransferProof}
-
-	return ArkBoardingTransfer{assetTransferDetails, boardingBtcTransferDetails, boardingClient}, nil
+	return BtcTransferDetails{
+		matchedTxOut, matchedOutPoint, uint64(matchedTxOut.Value), btcSpendingDetails,
+	}, nil
 }
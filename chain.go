@@ -0,0 +1,64 @@
+package taponark
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+const (
+	// ChainBackendBitcoind selects the bitcoind JSON-RPC chain backend.
+	ChainBackendBitcoind = "bitcoind"
+	// ChainBackendEsplora selects the Esplora HTTP chain backend.
+	ChainBackendEsplora = "esplora"
+)
+
+// ErrTxNotFound is returned by a ChainBackend when the requested
+// transaction is neither in the mempool nor in the chain.
+var ErrTxNotFound = errors.New("transaction not found")
+
+// TxStatus describes where a transaction currently sits in the chain.
+type TxStatus struct {
+	Confirmed   bool
+	BlockHash   *chainhash.Hash
+	BlockHeight int64
+}
+
+// MempoolAcceptResult is the outcome of a mempool acceptance test for a single
+// transaction.
+type MempoolAcceptResult struct {
+	Txid         string
+	Allowed      bool
+	RejectReason string
+}
+
+// ChainBackend is the set of chain operations needed to broadcast and track
+// boarding, round and exit transactions.
+type ChainBackend interface {
+	// BroadcastTransaction publishes the transaction to the network.
+	BroadcastTransaction(tx *wire.MsgTx) (*chainhash.Hash, error)
+
+	// GetTransaction returns a previously broadcasted transaction.
+	GetTransaction(txid *chainhash.Hash) (*wire.MsgTx, error)
+
+	// GetTransactionStatus returns the confirmation status of a
+	// transaction, or ErrTxNotFound if it is unknown.
+	GetTransactionStatus(txid *chainhash.Hash) (TxStatus, error)
+
+	// GetBlock returns the full block for the given block hash.
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+
+	// GetBlockCount returns the height of the current chain tip.
+	GetBlockCount() (int64, error)
+
+	// TestMempoolAccept checks whether the transactions would be accepted
+	// into the mempool without broadcasting them. Transactions may depend
+	// on each other and are tested as a package.
+	TestMempoolAccept(txs []*wire.MsgTx) ([]MempoolAcceptResult, error)
+
+	// EstimateFee returns the fee rate needed to confirm within the given
+	// number of blocks.
+	EstimateFee(confTarget uint32) (chainfee.SatPerKWeight, error)
+}
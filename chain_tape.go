@@ -0,0 +1,211 @@
+package taponark
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+// chainExchange is one recorded chain backend call, arguments and results
+// are kept as JSON.
+type chainExchange struct {
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	NotFound bool            `json:"not_found,omitempty"`
+}
+
+// ChainTape is a ChainBackend that records every call to a tape file, one
+// JSON object per line, or serves the calls of such a tape back without a
+// chain. Like the gRPC tapes, replayed calls are matched by method in
+// recorded order and must carry the recorded arguments.
+type ChainTape struct {
+	mu        sync.Mutex
+	path      string
+	backend   ChainBackend
+	file      *os.File
+	encoder   *json.Encoder
+	exchanges map[string][]chainExchange
+}
+
+// NewChainRecorder records the calls made to backend into a tape at path.
+func NewChainRecorder(backend ChainBackend, path string) (*ChainTape, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create chain tape %v", err)
+	}
+
+	return &ChainTape{path: path, backend: backend, file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// NewChainReplayer serves the calls of the tape at path.
+func NewChainReplayer(path string) (*ChainTape, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open chain tape %v", err)
+	}
+	defer file.Close()
+
+	tape := &ChainTape{path: path, exchanges: make(map[string][]chainExchange)}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, GRPC_TAPE_MAX_LINE)
+	for scanner.Scan() {
+		var exchange chainExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("cannot decode chain tape %v", err)
+		}
+		tape.exchanges[exchange.Method] = append(tape.exchanges[exchange.Method], exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read chain tape %v", err)
+	}
+
+	return tape, nil
+}
+
+// tapeChainCall records call when recording, otherwise it replays the next
+// recorded call of method.
+func tapeChainCall[T any](t *ChainTape, method string, request any, call func(backend ChainBackend) (T, error)) (T, error) {
+	var response T
+
+	encodedRequest, err := json.Marshal(request)
+	if err != nil {
+		return response, fmt.Errorf("cannot encode %s request %v", method, err)
+	}
+
+	if t.backend == nil {
+		return replayChainCall[T](t, method, encodedRequest)
+	}
+
+	response, callErr := call(t.backend)
+
+	exchange := chainExchange{Method: method, Request: encodedRequest}
+	if callErr != nil {
+		exchange.Error = callErr.Error()
+		exchange.NotFound = errors.Is(callErr, ErrTxNotFound)
+	} else {
+		exchange.Response, err = json.Marshal(response)
+		if err != nil {
+			return response, fmt.Errorf("cannot encode %s response %v", method, err)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.encoder.Encode(exchange); err != nil {
+		return response, fmt.Errorf("cannot record %s %v", method, err)
+	}
+
+	return response, callErr
+}
+
+func replayChainCall[T any](t *ChainTape, method string, request []byte) (T, error) {
+	var response T
+
+	t.mu.Lock()
+	queue := t.exchanges[method]
+	if len(queue) == 0 {
+		t.mu.Unlock()
+		return response, fmt.Errorf("chain tape has no more calls to %s", method)
+	}
+	exchange := queue[0]
+	t.exchanges[method] = queue[1:]
+	t.mu.Unlock()
+
+	if !bytes.Equal(request, exchange.Request) {
+		return response, fmt.Errorf("chain tape request of %s differs from the recording", method)
+	}
+	if exchange.NotFound {
+		return response, ErrTxNotFound
+	}
+	if exchange.Error != "" {
+		return response, errors.New(exchange.Error)
+	}
+	if err := json.Unmarshal(exchange.Response, &response); err != nil {
+		return response, fmt.Errorf("cannot replay %s %v", method, err)
+	}
+
+	return response, nil
+}
+
+func (t *ChainTape) BroadcastTransaction(tx *wire.MsgTx) (*chainhash.Hash, error) {
+	return tapeChainCall(t, "BroadcastTransaction", tx, func(backend ChainBackend) (*chainhash.Hash, error) {
+		return backend.BroadcastTransaction(tx)
+	})
+}
+
+func (t *ChainTape) GetTransaction(txid *chainhash.Hash) (*wire.MsgTx, error) {
+	return tapeChainCall(t, "GetTransaction", txid, func(backend ChainBackend) (*wire.MsgTx, error) {
+		return backend.GetTransaction(txid)
+	})
+}
+
+func (t *ChainTape) GetTransactionStatus(txid *chainhash.Hash) (TxStatus, error) {
+	return tapeChainCall(t, "GetTransactionStatus", txid, func(backend ChainBackend) (TxStatus, error) {
+		return backend.GetTransactionStatus(txid)
+	})
+}
+
+func (t *ChainTape) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return tapeChainCall(t, "GetBlock", blockHash, func(backend ChainBackend) (*wire.MsgBlock, error) {
+		return backend.GetBlock(blockHash)
+	})
+}
+
+func (t *ChainTape) GetBlockCount() (int64, error) {
+	return tapeChainCall(t, "GetBlockCount", nil, func(backend ChainBackend) (int64, error) {
+		return backend.GetBlockCount()
+	})
+}
+
+func (t *ChainTape) TestMempoolAccept(txs []*wire.MsgTx) ([]MempoolAcceptResult, error) {
+	return tapeChainCall(t, "TestMempoolAccept", txs, func(backend ChainBackend) ([]MempoolAcceptResult, error) {
+		return backend.TestMempoolAccept(txs)
+	})
+}
+
+func (t *ChainTape) EstimateFee(confTarget uint32) (chainfee.SatPerKWeight, error) {
+	return tapeChainCall(t, "EstimateFee", confTarget, func(backend ChainBackend) (chainfee.SatPerKWeight, error) {
+		return backend.EstimateFee(confTarget)
+	})
+}
+
+// Remaining returns the number of recorded calls not replayed yet.
+func (t *ChainTape) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	remaining := 0
+	for _, queue := range t.exchanges {
+		remaining += len(queue)
+	}
+
+	return remaining
+}
+
+// Close ends a recording, or fails if calls of a replayed tape were never
+// made.
+func (t *ChainTape) Close() error {
+	if t.file != nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		return t.file.Close()
+	}
+
+	if remaining := t.Remaining(); remaining != 0 {
+		return fmt.Errorf("chain tape %s has %d calls left, the session diverged from the recording", t.path, remaining)
+	}
+
+	return nil
+}
@@ -0,0 +1,562 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"taponark/arkrpc"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/taprpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/assetwalletrpc"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"github.com/lightningnetwork/lnd/lntest/wait"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ArkClient is a user of an Ark server daemon. It holds only the user's own
+// tapd and lnd, answers the key, nonce and signature requests of the server
+// with them and keeps the data to exit its VTXOs without the server.
+type ArkClient struct {
+	user          *TapClient
+	bitcoinClient *BitcoinClient
+	conn          *grpc.ClientConn
+	server        arkrpc.ArkServiceClient
+	store         *VtxoStore
+	arkKey        keychain.KeyDescriptor
+	serverKey     *btcec.PublicKey
+
+	mu sync.Mutex
+	// issued holds the keys handed to the server, only they are signed with
+	issued map[string]struct{}
+
+	// rounds receives the txid of every stored round
+	rounds chan string
+}
+
+// NewArkClient connects the user to the server at serverAddr, which must hold
+// serverKey.
+func NewArkClient(serverAddr string, serverKey *btcec.PublicKey, user *TapClient, bitcoinClient *BitcoinClient, store *VtxoStore) (*ArkClient, error) {
+	arkKey, err := user.GetArkKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive ark key %v", err)
+	}
+
+	conn, err := DialArkServer(user, serverAddr, serverKey)
+	if err != nil {
+		return nil, err
+	}
+
+	server := arkrpc.NewArkServiceClient(conn)
+	_, err = server.GetInfo(context.TODO(), &arkrpc.GetInfoRequest{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("cannot reach ark server %v", err)
+	}
+
+	return &ArkClient{
+		user:          user,
+		bitcoinClient: bitcoinClient,
+		conn:          conn,
+		server:        server,
+		store:         store,
+		arkKey:        arkKey,
+		serverKey:     serverKey,
+		issued:        make(map[string]struct{}),
+		rounds:        make(chan string, PARTICIPANT_EVENT_BUFFER),
+	}, nil
+}
+
+// Close disconnects from the server.
+func (c *ArkClient) Close() error {
+	return c.conn.Close()
+}
+
+// ArkKey is the key identifying the client to the server.
+func (c *ArkClient) ArkKey() []byte {
+	return c.arkKey.PubKey.SerializeCompressed()
+}
+
+// ServerKey is the identity key of the server.
+func (c *ArkClient) ServerKey() *btcec.PublicKey {
+	return c.serverKey
+}
+
+// Rounds receives the txid of every round the client got VTXOs in.
+func (c *ArkClient) Rounds() <-chan string {
+	return c.rounds
+}
+
+// Start subscribes to the server's events and answers them in the background
+// until ctx is cancelled or the stream breaks.
+func (c *ArkClient) Start(ctx context.Context) error {
+	stream, err := c.server.SubscribeEvents(ctx, &arkrpc.SubscribeEventsRequest{ParticipantKey: c.ArkKey()})
+	if err != nil {
+		return fmt.Errorf("cannot subscribe to ark server %v", err)
+	}
+
+	// The subscription is open once the stream headers arrived
+	_, err = stream.Header()
+	if err != nil {
+		return fmt.Errorf("cannot subscribe to ark server %v", err)
+	}
+
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Ark server event stream closed: %v", err)
+				}
+				return
+			}
+
+			// Requests are answered concurrently, the server signs in parallel
+			go c.handleEvent(event)
+		}
+	}()
+
+	return nil
+}
+
+func (c *ArkClient) handleEvent(event *arkrpc.RoundEvent) {
+	var err error
+	switch e := event.Event.(type) {
+	case *arkrpc.RoundEvent_Phase:
+		if e.Phase.RoundTxid == "" {
+			return
+		}
+		err = c.SyncRound(e.Phase.RoundTxid)
+
+	case *arkrpc.RoundEvent_KeyRequest:
+		err = c.answerKeyRequest(e.KeyRequest)
+
+	case *arkrpc.RoundEvent_NonceRequest:
+		err = c.answerNonceRequest(e.NonceRequest)
+
+	case *arkrpc.RoundEvent_PartialSigRequest:
+		err = c.answerPartialSigRequest(e.PartialSigRequest)
+
+	case *arkrpc.RoundEvent_TapscriptSigRequest:
+		err = c.answerTapscriptSigRequest(e.TapscriptSigRequest)
+
+	case *arkrpc.RoundEvent_CleanupRequest:
+		err = c.user.signer.MuSig2Cleanup(context.TODO(), e.CleanupRequest.SessionId)
+
+	case *arkrpc.RoundEvent_Proof:
+		err = SubmitProof(e.Proof.GenesisPoint, e.Proof.ProofFile, c.user)
+		if err == nil {
+			log.Printf("Imported collaborative exit proof of %s", e.Proof.GenesisPoint)
+		}
+
+	case *arkrpc.RoundEvent_WatchKey:
+		_, err = c.user.lndClient.wallet.ImportPublicKey(context.TODO(), &walletrpc.ImportPublicKeyRequest{
+			PublicKey:   e.WatchKey.Pubkey,
+			AddressType: walletrpc.AddressType_TAPROOT_PUBKEY,
+		})
+	}
+
+	if err != nil {
+		log.Printf("Cannot handle ark server event: %v", err)
+	}
+}
+
+func (c *ArkClient) issue(rawKeys ...[]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rawKey := range rawKeys {
+		c.issued[hex.EncodeToString(rawKey)] = struct{}{}
+	}
+}
+
+// checkIssued refuses to sign with keys the client never handed out.
+func (c *ArkClient) checkIssued(keyDesc keychain.KeyDescriptor) error {
+	if keyDesc.PubKey == nil {
+		return fmt.Errorf("signing request names no key")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.issued[hex.EncodeToString(keyDesc.PubKey.SerializeCompressed())]
+	if !ok {
+		return fmt.Errorf("signing request for foreign key %x", keyDesc.PubKey.SerializeCompressed())
+	}
+
+	return nil
+}
+
+func (c *ArkClient) answerKeyRequest(request *arkrpc.KeyRequest) error {
+	answer := &arkrpc.SubmitKeysRequest{RequestId: request.RequestId}
+
+	if request.Type == arkrpc.KeyType_KEY_TYPE_SCRIPT {
+		resp, err := c.user.wallet.NextScriptKey(context.TODO(), &assetwalletrpc.NextScriptKeyRequest{
+			KeyFamily: uint32(asset.TaprootAssetsKeyFamily),
+		})
+		if err != nil {
+			answer.Error = err.Error()
+		} else {
+			answer.ScriptKey = rpcTapScriptKey(resp.ScriptKey)
+			if resp.ScriptKey.KeyDesc != nil {
+				c.issue(resp.ScriptKey.KeyDesc.RawKeyBytes)
+			}
+		}
+	} else {
+		resp, err := c.user.wallet.NextInternalKey(context.TODO(), &assetwalletrpc.NextInternalKeyRequest{
+			KeyFamily: uint32(asset.TaprootAssetsKeyFamily),
+		})
+		if err != nil {
+			answer.Error = err.Error()
+		} else {
+			answer.InternalKey = rpcTapKeyDescriptor(resp.InternalKey)
+			c.issue(resp.InternalKey.RawKeyBytes)
+		}
+	}
+
+	_, err := c.server.SubmitKeys(context.TODO(), answer)
+	return err
+}
+
+func (c *ArkClient) answerNonceRequest(request *arkrpc.NonceRequest) error {
+	answer := &arkrpc.SubmitNonceRequest{RequestId: request.RequestId}
+
+	var tweak *signrpc.TaprootTweakDesc
+	if request.Tweak != nil {
+		tweak = &signrpc.TaprootTweakDesc{ScriptRoot: request.Tweak.ScriptRoot, KeySpendOnly: request.Tweak.KeySpendOnly}
+	}
+
+	localKey, err := parseRpcKeyDescriptor(request.LocalKey)
+	if err == nil {
+		err = c.checkIssued(localKey)
+	}
+	if err == nil {
+		answer.SessionId, answer.PublicNonce, err = c.user.signer.MuSig2CreateSession(context.TODO(), localKey, request.AllSignerKeys, tweak)
+	}
+	if err != nil {
+		answer.Error = err.Error()
+	}
+
+	_, err = c.server.SubmitNonce(context.TODO(), answer)
+	return err
+}
+
+func (c *ArkClient) answerPartialSigRequest(request *arkrpc.PartialSigRequest) error {
+	answer := &arkrpc.SubmitSignaturesRequest{RequestId: request.RequestId}
+
+	// Only sessions of issued keys were created, so the session id suffices
+	err := c.user.signer.MuSig2RegisterNonces(context.TODO(), request.SessionId, request.OtherNonces)
+	if err == nil {
+		var partialSig []byte
+		partialSig, err = c.user.signer.MuSig2Sign(context.TODO(), request.SessionId, request.SigHash, request.Cleanup)
+		answer.Signatures = [][]byte{partialSig}
+	}
+	if err != nil {
+		answer.Error = err.Error()
+	}
+
+	_, err = c.server.SubmitSignatures(context.TODO(), answer)
+	return err
+}
+
+func (c *ArkClient) answerTapscriptSigRequest(request *arkrpc.TapscriptSigRequest) error {
+	answer := &arkrpc.SubmitSignaturesRequest{RequestId: request.RequestId}
+
+	err := func() error {
+		pkt, err := psbt.NewFromRawBytes(bytes.NewReader(request.Psbt), false)
+		if err != nil {
+			return fmt.Errorf("cannot decode packet %v", err)
+		}
+		if len(request.Keys) != len(request.InputIndexes) || len(request.TapLeaves) != len(request.InputIndexes) {
+			return fmt.Errorf("malformed tapscript signing request")
+		}
+
+		inputIndexes := make([]int, len(request.InputIndexes))
+		keys := make([]keychain.KeyDescriptor, len(request.InputIndexes))
+		tapLeaves := make([]txscript.TapLeaf, len(request.InputIndexes))
+		for i, inputIndex := range request.InputIndexes {
+			inputIndexes[i] = int(inputIndex)
+
+			keys[i], err = parseRpcKeyDescriptor(request.Keys[i])
+			if err != nil {
+				return err
+			}
+			err = c.checkIssued(keys[i])
+			if err != nil {
+				return err
+			}
+
+			tapLeaves[i] = txscript.NewTapLeaf(txscript.TapscriptLeafVersion(request.TapLeaves[i].LeafVersion), request.TapLeaves[i].Script)
+		}
+
+		answer.Signatures, err = c.user.signer.SignBtcTapscript(context.TODO(), pkt, inputIndexes, keys, request.ControlBlocks, tapLeaves)
+		return err
+	}()
+	if err != nil {
+		answer.Error = err.Error()
+	}
+
+	_, err = c.server.SubmitSignatures(context.TODO(), answer)
+	return err
+}
+
+// Board sends the asset and BTC amount to the server's boarding outputs and
+// returns once the server accepted them, they join rounds once confirmed. A
+// zero asset amount boards BTC only.
+func (c *ArkClient) Board(assetId []byte, assetAmount, btcAmount uint64) (string, error) {
+	addrResp, err := c.server.NewBoardingAddress(context.TODO(), &arkrpc.NewBoardingAddressRequest{
+		ParticipantKey: c.ArkKey(),
+		AssetId:        assetId,
+		AssetAmount:    assetAmount,
+		BtcAmount:      btcAmount,
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot get boarding address %v", err)
+	}
+
+	btcAddress := addrResp.BtcAddress
+	if assetAmount > 0 {
+		sendAssetResp, err := c.user.SendAsset(&taprpc.Addr{Encoded: addrResp.AssetAddress})
+		if err != nil {
+			return "", fmt.Errorf("failed to send boarding asset [%s] to boarding address %v", hex.EncodeToString(assetId), err)
+		}
+
+		log.Printf("Boarding Asset TxId %s", hex.EncodeToString(sendAssetResp.Transfer.AnchorTxHash))
+
+		transferOutput, err := proto.Marshal(sendAssetResp.Transfer.Outputs[BOARDING_ASSET_TRANSFER_OUTPUT_INDEX])
+		if err != nil {
+			return "", fmt.Errorf("cannot encode boarding transfer output %v", err)
+		}
+
+		assetResp, err := c.server.SubmitBoardingAsset(context.TODO(), &arkrpc.SubmitBoardingAssetRequest{
+			BoardingId:     addrResp.BoardingId,
+			TransferOutput: transferOutput,
+		})
+		if err != nil {
+			return "", fmt.Errorf("cannot submit boarding asset %v", err)
+		}
+		btcAddress = assetResp.BtcAddress
+	}
+
+	addr, err := btcutil.DecodeAddress(btcAddress, &c.user.chainParams)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode boarding address %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", fmt.Errorf("cannot create boarding output script %v", err)
+	}
+
+	boardingTx, err := c.user.lndClient.SendOutput(int64(btcAmount), pkScript)
+	if err != nil {
+		return "", fmt.Errorf("cannot send btc to output %v", err)
+	}
+
+	log.Printf("Boarding BTC TxId %s", boardingTx.TxHash().String())
+
+	var buf bytes.Buffer
+	err = boardingTx.Serialize(&buf)
+	if err != nil {
+		return "", fmt.Errorf("cannot serialize boarding transaction %v", err)
+	}
+
+	_, err = c.server.SubmitBoardingBtc(context.TODO(), &arkrpc.SubmitBoardingBtcRequest{
+		BoardingId: addrResp.BoardingId,
+		RawTx:      buf.Bytes(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot submit boarding btc %v", err)
+	}
+
+	return addrResp.BoardingId, nil
+}
+
+// RegisterIntent moves a boarding into the next round. Without leaf owners
+// the client receives every leaf.
+func (c *ArkClient) RegisterIntent(boardingId string, leafOwners [][]byte, exits []*arkrpc.CollaborativeExit) (string, error) {
+	resp, err := c.registerIntent(&arkrpc.RegisterIntentRequest{
+		BoardingId: boardingId,
+		LeafOwners: leafOwners,
+		Exits:      exits,
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot register intent %v", err)
+	}
+
+	return resp.IntentId, nil
+}
+
+// registerIntent registers the intent, retried while the server still waits
+// for the boarding to confirm.
+func (c *ArkClient) registerIntent(request *arkrpc.RegisterIntentRequest) (*arkrpc.RegisterIntentResponse, error) {
+	var (
+		resp *arkrpc.RegisterIntentResponse
+		err  error
+	)
+	_ = wait.Predicate(func() bool {
+		resp, err = c.server.RegisterIntent(context.TODO(), request)
+		return status.Code(err) != codes.Unavailable
+	}, c.bitcoinClient.timeout)
+
+	return resp, err
+}
+
+// NewAddress returns an Ark address of the client at its server.
+func (c *ArkClient) NewAddress(assetId []byte, groupKey *btcec.PublicKey, amount uint64, exitDelay uint32) (ArkAddress, error) {
+	return NewArkAddress(c.user, c.serverKey, assetId, groupKey, amount, exitDelay)
+}
+
+// PayAddress boards the asset and BTC and registers an intent paying them to
+// the address in the next round. The round leaf is locked to the address
+// policy, the receiver does not have to be connected to the server.
+func (c *ArkClient) PayAddress(addr ArkAddress, assetId []byte, assetAmount, btcAmount uint64) (string, error) {
+	groupKey, err := c.user.AssetGroupKey(assetId)
+	if err != nil {
+		return "", err
+	}
+	err = addr.CheckPayment(c.user.chainParams.Name, c.serverKey, assetId, groupKey, assetAmount)
+	if err != nil {
+		return "", err
+	}
+
+	boardingId, err := c.Board(assetId, assetAmount, btcAmount)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := addr.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.registerIntent(&arkrpc.RegisterIntentRequest{
+		BoardingId: boardingId,
+		Address:    encoded,
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot register intent %v", err)
+	}
+
+	return resp.IntentId, nil
+}
+
+// SyncRound fetches the tree of a finalized round and stores it if the client
+// owns VTXOs in it.
+func (c *ArkClient) SyncRound(txid string) error {
+	if _, ok := c.store.Round(txid); ok {
+		return nil
+	}
+
+	treeResp, err := c.server.GetRoundTree(context.TODO(), &arkrpc.GetRoundTreeRequest{RoundTxid: txid})
+	if err != nil {
+		return fmt.Errorf("cannot fetch round tree %v", err)
+	}
+
+	root, err := parseRpcTreeNode(treeResp.Root, c.owner)
+	if err != nil {
+		return err
+	}
+
+	var vtxos []StoredVtxo
+	forEachLeaf(root, func(leaf *RoundTreeNode) {
+		for _, output := range []NodeOutput{leaf.LeftOutput, leaf.RightOutput} {
+			if output.Owner != c.user {
+				continue
+			}
+
+			vtxos = append(vtxos, StoredVtxo{
+				RoundTxid:   txid,
+				Outpoint:    leafOutpoint(leaf, output).String(),
+				Type:        output.OutputType,
+				AssetAmount: output.AssetAmount,
+				BtcAmount:   output.BTCAmount,
+			})
+		}
+	})
+	if len(vtxos) == 0 {
+		return nil
+	}
+
+	tree, err := proto.Marshal(treeResp)
+	if err != nil {
+		return fmt.Errorf("cannot encode round tree %v", err)
+	}
+
+	err = c.store.AddRound(StoredRound{Txid: txid, GenesisPoint: treeResp.GenesisPoint, Tree: tree}, vtxos)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Stored %d VTXOs of round %s", len(vtxos), txid)
+	select {
+	case c.rounds <- txid:
+	default:
+	}
+
+	return nil
+}
+
+// ListVtxos returns the VTXOs of the client that were not exited.
+func (c *ArkClient) ListVtxos() []StoredVtxo {
+	return c.store.ListVtxos()
+}
+
+// UnilateralExit broadcasts the branches of a stored round leading to the
+// client's VTXOs and imports the proofs of its asset VTXOs into tapd.
+func (c *ArkClient) UnilateralExit(txid string) error {
+	storedRound, ok := c.store.Round(txid)
+	if !ok {
+		return fmt.Errorf("no VTXOs in round %s", txid)
+	}
+
+	treeResp := &arkrpc.GetRoundTreeResponse{}
+	err := proto.Unmarshal(storedRound.Tree, treeResp)
+	if err != nil {
+		return fmt.Errorf("cannot decode stored round tree %v", err)
+	}
+
+	root, err := parseRpcTreeNode(treeResp.Root, c.owner)
+	if err != nil {
+		return err
+	}
+
+	round := Round{
+		RoundTree:              RoundTree{root},
+		assetTransferProofFile: treeResp.RoundProofFile,
+		GenesisPoint:           treeResp.GenesisPoint,
+	}
+	exitProofs, err := ExitRoundVtxos(round, c.user, c.bitcoinClient)
+	if err != nil {
+		return err
+	}
+
+	for _, exitProof := range exitProofs {
+		err = SubmitProof(round.GenesisPoint, exitProof, c.user)
+		if err != nil {
+			return fmt.Errorf("cannot import exit proof %v", err)
+		}
+	}
+
+	return c.store.SetExited(txid, exitProofs)
+}
+
+// owner maps the owner key of a tree output to the client's TapClient.
+func (c *ArkClient) owner(key []byte) *TapClient {
+	if bytes.Equal(key, c.ArkKey()) {
+		return c.user
+	}
+
+	return nil
+}
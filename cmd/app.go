@@ -1,171 +1,142 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
-	"os"
-	"path/filepath"
+	"slices"
+	"strconv"
 	"taponark"
 	"time"
 
-	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/lightninglabs/taproot-assets/address"
-	"gopkg.in/yaml.v2"
+	"github.com/btcsuite/btcd/btcec/v2"
 )
 
-type App struct {
-	serverTapClient         taponark.TapClient
-	boardingUserTapClient   taponark.TapClient
-	exitUserTapClient       taponark.TapClient
-	bitcoinClient           taponark.BitcoinClient
-	boardingTransferDetails *taponark.ArkBoardingTransfer
-	round                   taponark.Round
-	roundRootProofFile      []byte
-	assetId                 []byte
-	assetVtxoProofList      [][]byte
-}
+// ROUND_REGISTRATION_WINDOW is how long the coordinator accepts intents
+const ROUND_REGISTRATION_WINDOW = 10 * time.Second
 
-func DeriveLndTlsAndMacaroonHex(container string, network string) (string, string) {
-	tlsPath := filepath.Join("data", network, "volumes", "lnd", container, "tls.cert")
-	tlsBytes, err := os.ReadFile(tlsPath)
-	if err != nil {
-		log.Fatalf("failed to read TLS certificate: %v", err)
-	}
-	tlsHex := hex.EncodeToString(tlsBytes)
+// ROUND_SIGNING_ATTEMPTS bounds the round restarts after dropping a signer
+const ROUND_SIGNING_ATTEMPTS = 3
 
-	// Read macaroon file
-	macaroonFileDir := network
-	if network == "mutinynet" {
-		macaroonFileDir = "signet"
-	}
-	macaroonPath := filepath.Join("data", network, "volumes", "lnd", container, "data", "chain", "bitcoin", macaroonFileDir, "admin.macaroon")
-	macaroonBytes, err := os.ReadFile(macaroonPath)
-	if err != nil {
-		log.Fatalf("failed to read macaroon file: %v", err)
-	}
-	macaroonHex := hex.EncodeToString(macaroonBytes)
-	return tlsHex, macaroonHex
-}
+// PAYMENT_REQUEST_EXPIRY is how long payment requests of the exit user are valid
+const PAYMENT_REQUEST_EXPIRY = time.Hour
 
-func DeriveTapTlsAndMacaroonHex(container string, network string) (string, string) {
-	tlsPath := filepath.Join("data", network, "volumes", "tapd", container, "tls.cert")
-	tlsBytes, err := os.ReadFile(tlsPath)
-	if err != nil {
-		log.Fatalf("failed to read TLS certificate: %v", err)
-	}
-	tlsHex := hex.EncodeToString(tlsBytes)
+// PAYMENT_REQUEST_MEMO is the default memo of payment requests
+const PAYMENT_REQUEST_MEMO = "Boarded tokens"
 
-	// Read macaroon file
-	macaroonFileDir := network
-	if network == "mutinynet" {
-		macaroonFileDir = "signet"
-	}
-	macaroonPath := filepath.Join("data", network, "volumes", "tapd", container, "data", macaroonFileDir, "admin.macaroon")
-	macaroonBytes, err := os.ReadFile(macaroonPath)
-	if err != nil {
-		log.Fatalf("failed to read macaroon file: %v", err)
-	}
-	macaroonHex := hex.EncodeToString(macaroonBytes)
-	return tlsHex, macaroonHex
-}
+// DEFAULT_EXIT_ASSET_AMOUNT is paid out on-chain by coexit unless given
+const DEFAULT_EXIT_ASSET_AMOUNT = 10
 
-func InitConfig(network string) taponark.Config {
-	// Read the YAML configuration file
-	configFile := "config-regtest.yaml"
+// DEFAULT_EXIT_BTC_AMOUNT in sats is paid out on-chain by coexit unless given
+const DEFAULT_EXIT_BTC_AMOUNT = 20_000
 
-	if network == "signet" {
-		configFile = "config-signet.yaml"
-	} else if network == "mutinynet" {
-		configFile = "config-mutinynet.yaml"
-	}
+// DEFAULT_SWAP_BTC_AMOUNT in sats is boarded by the exit user to swap
+const DEFAULT_SWAP_BTC_AMOUNT = 100_000
 
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		log.Fatalf("error: %v", err)
-	}
+// DEFAULT_BOARDING_ASSET_AMOUNT is boarded by board without arguments
+const DEFAULT_BOARDING_ASSET_AMOUNT = 40
 
-	// Create an instance of the Config struct
-	var config taponark.Config
+// DEFAULT_BOARDING_BTC_AMOUNT in sats is boarded with each asset unless given
+const DEFAULT_BOARDING_BTC_AMOUNT = 100_000
 
-	// Unmarshal the YAML data into the struct
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		log.Fatalf("error: %v", err)
-	}
+type App struct {
+	serverTapClient       taponark.TapClient
+	boardingUserTapClient taponark.TapClient
+	exitUserTapClient     taponark.TapClient
+	bitcoinClient         taponark.BitcoinClient
+	// boardings are the boardings not settled yet, keyed by hex asset id
+	boardings map[string]taponark.ArkBoardingTransfer
+	// rounds are the last rounds of each asset, keyed by hex asset id
+	rounds               map[string]*assetRound
+	assetId              []byte
+	serverRoundLiquidity uint64
+	btcSpendPath         taponark.BtcSpendPath
+	roundCoordinator     *taponark.RoundCoordinator
+	roundSchedulerConfig taponark.RoundSchedulerConfig
+	roundScheduler       *taponark.RoundScheduler
+}
 
-	return config
+// assetRound is the last round of an asset and the proofs of its exited VTXOs
+// not uploaded yet.
+type assetRound struct {
+	round      taponark.Round
+	vtxoProofs [][]byte
 }
 
 func Init(network string) App {
 	// Read the YAML configuration file
-	config := InitConfig(network)
-
-	chainParams := chaincfg.RegressionNetParams
-	tapParams := address.RegressionNetTap
-	if network == "signet" {
-		chainParams = chaincfg.SigNetParams
-		tapParams = address.SigNetTap
-	} else if network == "mutinynet" {
-		if config.SignetChallenge == nil {
-			log.Panicln("Signet Challenge Must be Present in Signet")
-		}
+	config := taponark.LoadConfig(network)
 
-		signetChallenge, err := hex.DecodeString(*config.SignetChallenge)
-		if err != nil {
-			log.Panicf("Signet Challenge cannot be decoded %v", err)
-		}
-
-		chainParams = chaincfg.CustomSignetParams(signetChallenge, []chaincfg.DNSSeed{})
-		tapParams = address.SigNetTap
+	if network == "offline" {
+		return InitOffline(config)
 	}
 
+	chainParams, _ := taponark.NetworkParams(network, config)
 	timeout := time.Duration(config.Timeout) * time.Minute
 	syncHostname := config.OnboardingUserTapClient.Hostname
 
 	// Init BoardingUser
-	boardingUserTapTlsHex, boardingUserTapMacaroonHex := DeriveTapTlsAndMacaroonHex(config.OnboardingUserTapClient.Container, network)
-	boardingUserLndTlsHex, boardingUserLndMacaroonHex := DeriveLndTlsAndMacaroonHex(config.OnboardingUserLndClient.Container, network)
-	boardingUserLndClient := taponark.InitLndClient(config.OnboardingUserLndClient, boardingUserLndTlsHex, boardingUserLndMacaroonHex)
-	boardingUserTapClient := taponark.InitTapClient(syncHostname, config.OnboardingUserTapClient, boardingUserLndClient, boardingUserTapTlsHex, boardingUserTapMacaroonHex, chainParams, tapParams, timeout)
+	boardingUserTapClient := taponark.InitNode(network, syncHostname, config.OnboardingUserTapClient, config.OnboardingUserLndClient, config)
 
 	// Init ExitUser
-	exitUserTapTlsHex, exitUserTapMacaroonHex := DeriveTapTlsAndMacaroonHex(config.ExitUserTapClient.Container, network)
-	exitUserLndTlsHex, exitUserLndMacaroonHex := DeriveLndTlsAndMacaroonHex(config.ExitUserLndClient.Container, network)
-	exitUserLndClient := taponark.InitLndClient(config.ExitUserLndClient, exitUserLndTlsHex, exitUserLndMacaroonHex)
-	exitUserTapClient := taponark.InitTapClient(syncHostname, config.ExitUserTapClient, exitUserLndClient, exitUserTapTlsHex, exitUserTapMacaroonHex, chainParams, tapParams, timeout)
+	exitUserTapClient := taponark.InitNode(network, syncHostname, config.ExitUserTapClient, config.ExitUserLndClient, config)
 
 	// Init Server
-	serverTapTlsHex, serverTapMacaroonHex := DeriveTapTlsAndMacaroonHex(config.ServerTapClient.Container, network)
-	serverLndTlsHex, serverLndMacaroonHex := DeriveLndTlsAndMacaroonHex(config.ServerLndClient.Container, network)
-	serverLndClient := taponark.InitLndClient(config.ServerLndClient, serverLndTlsHex, serverLndMacaroonHex)
-	serverTapClient := taponark.InitTapClient(syncHostname, config.ServerTapClient, serverLndClient, serverTapTlsHex, serverTapMacaroonHex, chainParams, tapParams, timeout)
+	serverTapClient := taponark.InitNode(network, syncHostname, config.ServerTapClient, config.ServerLndClient, config)
 
 	bitcoinClient := taponark.GetBitcoinClient(config.BitcoinClient, chainParams, timeout)
 
+	btcSpendPath, err := taponark.ParseBtcSpendPath(config.BtcSpendPath)
+	if err != nil {
+		log.Panicf("Invalid btc spend path %v", err)
+	}
+
 	log.Println("All clients Initilised")
-	return App{serverTapClient, boardingUserTapClient, exitUserTapClient, bitcoinClient, nil, taponark.Round{}, nil, nil, nil}
+	return newApp(serverTapClient, boardingUserTapClient, exitUserTapClient, bitcoinClient, btcSpendPath, config)
+}
+
+func newApp(server, boardingUser, exitUser taponark.TapClient, bitcoinClient taponark.BitcoinClient, btcSpendPath taponark.BtcSpendPath, config taponark.Config) App {
+	return App{
+		serverTapClient:       server,
+		boardingUserTapClient: boardingUser,
+		exitUserTapClient:     exitUser,
+		bitcoinClient:         bitcoinClient,
+		boardings:             make(map[string]taponark.ArkBoardingTransfer),
+		rounds:                make(map[string]*assetRound),
+		serverRoundLiquidity:  config.ServerRoundLiquidity,
+		btcSpendPath:          btcSpendPath,
+		roundSchedulerConfig:  roundSchedulerConfig(config),
+	}
+}
+
+// Close closes the clients of the nodes and the chain, it fails if a replayed
+// tape was not served to its end.
+func (ap *App) Close() error {
+	return errors.Join(
+		ap.serverTapClient.Close(),
+		ap.boardingUserTapClient.Close(),
+		ap.exitUserTapClient.Close(),
+		ap.bitcoinClient.Close(),
+	)
 }
 
 // Onboarder Mint
-func (ap *App) Mint() {
+func (ap *App) Mint() (MintResult, error) {
 	assetId, err := ap.boardingUserTapClient.CreateAsset()
 	if err != nil {
-		log.Printf("Error creating asset: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return MintResult{}, fmt.Errorf("cannot create asset %v", err)
 	}
 	err = ap.serverTapClient.Sync()
 	if err != nil {
-		log.Printf("Error Sycing Server: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return MintResult{}, fmt.Errorf("cannot sync server %v", err)
 	}
 
 	err = ap.exitUserTapClient.Sync()
 	if err != nil {
-		log.Printf("Error Sycing Exit User: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return MintResult{}, fmt.Errorf("cannot sync exit user %v", err)
 	}
 
 	ap.assetId = assetId
@@ -173,79 +144,598 @@ func (ap *App) Mint() {
 	log.Printf("\nAsset ID: %s", hexEncodedString)
 	log.Println("Minting Complete")
 	log.Println("-------------------------------------")
+	return MintResult{hexEncodedString}, nil
 }
 
-func (ap *App) FundOnboarding() {
+func (ap *App) FundOnboarding() (DepositResult, error) {
 	// Fund the onboarding user
 	boardingUserAddr, err := ap.boardingUserTapClient.GetBtcAddress()
 	if err != nil {
-		log.Printf("Error getting deposit address: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return DepositResult{}, fmt.Errorf("cannot get deposit address %v", err)
 	}
 
 	log.Printf("\nDeposit Address For Onboarding User : %s", boardingUserAddr)
 	log.Println("-------------------------------------")
+	return DepositResult{boardingUserAddr}, nil
 }
 
-func (ap *App) Board() {
-	boardingAssetAmnt := 40
-	boardingBtcAmnt := 100_000
+// boardingAsset is an asset to board with its amount
+type boardingAsset struct {
+	assetId []byte
+	amount  uint64
+}
 
-	// Onboard Asset and Btc
-	boardingTransferDetails, err := taponark.OnboardUser(ap.assetId, uint64(boardingAssetAmnt), uint64(boardingBtcAmnt), &ap.boardingUserTapClient, &ap.serverTapClient, &ap.bitcoinClient)
+// Board boards the boarding user with
+//
+//	board [<asset_id|group_key> <asset_amount>]... [<btc_amount>]
+//
+// The BTC amount is boarded along with every asset, 0 boards the assets only
+// and the server funds their rounds. Without arguments the last minted asset
+// is boarded with the default amounts. Each asset is boarded once until a
+// round or payment settles it, later commands pick the boarding by asset.
+func (ap *App) Board(args []string) (BoardResult, error) {
+	assets, btcAmount, err := ap.parseBoardArgs(args)
 	if err != nil {
-		log.Printf("Error onboarding user: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return BoardResult{}, err
+	}
+	for _, boarding := range assets {
+		if _, ok := ap.boardings[hex.EncodeToString(boarding.assetId)]; ok {
+			return BoardResult{}, fmt.Errorf("asset %x is already boarded, settle it first", boarding.assetId)
+		}
+	}
+	err = ap.checkBoardingBalances(assets, btcAmount)
+	if err != nil {
+		return BoardResult{}, err
+	}
+
+	// Refuse boardings no round could settle before sending anything
+	if btcAmount == 0 && ap.serverRoundLiquidity == 0 {
+		return BoardResult{}, errors.New("assets boarded without btc need server_round_liquidity to join a round")
+	}
+
+	result := BoardResult{}
+
+	// Onboard Asset and Btc
+	for _, boarding := range assets {
+		var boardingTransferDetails taponark.ArkBoardingTransfer
+		if btcAmount == 0 {
+			boardingTransferDetails, err = taponark.OnboardAssetUser(boarding.assetId, boarding.amount, ap.btcSpendPath, &ap.boardingUserTapClient, &ap.serverTapClient, &ap.bitcoinClient)
+		} else {
+			boardingTransferDetails, err = taponark.OnboardUser(boarding.assetId, boarding.amount, btcAmount, ap.btcSpendPath, &ap.boardingUserTapClient, &ap.serverTapClient, &ap.bitcoinClient)
+		}
+		// The assets boarded before stay boarded
+		if err != nil {
+			return BoardResult{}, fmt.Errorf("cannot onboard asset %x after boarding %d assets %v", boarding.assetId, len(result.Boardings), err)
+		}
+		ap.assetId = boarding.assetId
+		ap.boardings[hex.EncodeToString(boarding.assetId)] = boardingTransferDetails
+		result.Boardings = append(result.Boardings, boardingResult(boarding.assetId, boardingTransferDetails))
+		log.Printf("Boarded %d of asset %x with %d sats", boarding.amount, boarding.assetId, btcAmount)
 	}
-	ap.boardingTransferDetails = &boardingTransferDetails
+
 	log.Println("Boarding User Complete")
 	log.Println("------------------------------------------------")
+	return result, nil
+}
+
+func boardingResult(assetId []byte, transfer taponark.ArkBoardingTransfer) BoardingResult {
+	return BoardingResult{
+		AssetId:       hex.EncodeToString(assetId),
+		AssetAmount:   transfer.AssetAmount(),
+		AssetOutpoint: transfer.AssetOutpoint(),
+		BtcAmount:     transfer.BtcAmount(),
+		BtcOutpoint:   transfer.BtcOutpoint(),
+	}
+}
+
+// parseBoardArgs returns the assets and BTC amount of the board command, group
+// keys resolve to the owned asset of the group with the largest amount.
+func (ap *App) parseBoardArgs(args []string) ([]boardingAsset, uint64, error) {
+	if len(args) == 0 {
+		if ap.assetId == nil {
+			return nil, 0, errors.New("no asset minted, pass an asset id or group key")
+		}
+		return []boardingAsset{{ap.assetId, DEFAULT_BOARDING_ASSET_AMOUNT}}, DEFAULT_BOARDING_BTC_AMOUNT, nil
+	}
+
+	btcAmount := uint64(DEFAULT_BOARDING_BTC_AMOUNT)
+	if len(args)%2 == 1 {
+		amount, err := strconv.ParseUint(args[len(args)-1], 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid btc amount %v", err)
+		}
+		btcAmount = amount
+		args = args[:len(args)-1]
+	}
+
+	assets := make([]boardingAsset, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		assetId, err := ap.parseAsset(args[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		if slices.ContainsFunc(assets, func(boarding boardingAsset) bool { return bytes.Equal(boarding.assetId, assetId) }) {
+			return nil, 0, fmt.Errorf("asset %x is boarded twice", assetId)
+		}
+		amount, err := strconv.ParseUint(args[i+1], 10, 64)
+		if err != nil || amount == 0 {
+			return nil, 0, fmt.Errorf("invalid asset amount %s", args[i+1])
+		}
+		assets = append(assets, boardingAsset{assetId, amount})
+	}
+
+	// BTC boarded alone only settles as the BTC side of a swap
+	if len(assets) == 0 {
+		return nil, 0, errors.New("btc is only boarded along with an asset, swap boards the btc side itself")
+	}
+
+	return assets, btcAmount, nil
+}
+
+// parseAsset returns the asset id of a hex asset id or group key.
+func (ap *App) parseAsset(arg string) ([]byte, error) {
+	raw, err := hex.DecodeString(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset id or group key %v", err)
+	}
+
+	switch len(raw) {
+	case 32:
+		return raw, nil
+	case 33:
+		groupKey, err := btcec.ParsePubKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group key %v", err)
+		}
+		return ap.boardingUserTapClient.GroupAssetId(groupKey)
+	default:
+		return nil, fmt.Errorf("%s is neither an asset id nor a group key", arg)
+	}
+}
+
+// checkBoardingBalances ensures the boarding user owns every asset amount and
+// the BTC boarded along with each asset.
+func (ap *App) checkBoardingBalances(assets []boardingAsset, btcAmount uint64) error {
+	assetAmounts := make(map[string]uint64)
+	for _, boarding := range assets {
+		assetAmounts[string(boarding.assetId)] += boarding.amount
+	}
+
+	for assetId, amount := range assetAmounts {
+		assetBalance, _, err := ap.boardingUserTapClient.GetBalance([]byte(assetId))
+		if err != nil {
+			return err
+		}
+		if assetBalance < amount {
+			return fmt.Errorf("asset %x balance %d below %d", []byte(assetId), assetBalance, amount)
+		}
+	}
+
+	boardings := uint64(max(len(assets), 1))
+	_, btcBalance, err := ap.boardingUserTapClient.GetBalance(nil)
+	if err != nil {
+		return err
+	}
+	if uint64(btcBalance) < btcAmount*boardings {
+		return fmt.Errorf("btc balance %d below %d", btcBalance, btcAmount*boardings)
+	}
 
+	return nil
 }
 
-func (ap *App) ConstructRound() {
-	round, err := taponark.ConstructAndBroadcastRound(ap.assetId, *ap.boardingTransferDetails, &ap.exitUserTapClient, &ap.serverTapClient, ap.bitcoinClient)
+// errNoSessionState is wrapped by the errors of commands needing a boarding
+// or round the process has not made, nothing of a session is stored.
+var errNoSessionState = errors.New("boardings and rounds only live in the process that made them")
+
+// selectAsset returns the hex asset id and entry of the asset assetArg names,
+// an asset id or group key, or of the only entry if assetArg is empty.
+func selectAsset[T any](ap *App, entries map[string]T, assetArg, what string) (string, T, error) {
+	var entry T
+	if assetArg == "" {
+		switch len(entries) {
+		case 0:
+			return "", entry, fmt.Errorf("no %s yet, %w", what, errNoSessionState)
+		case 1:
+			for key, entry := range entries {
+				return key, entry, nil
+			}
+		}
+		return "", entry, fmt.Errorf("%d assets have a %s, pick one with -asset", len(entries), what)
+	}
+
+	assetId, err := ap.parseAsset(assetArg)
+	if err != nil {
+		return "", entry, err
+	}
+	key := hex.EncodeToString(assetId)
+	entry, ok := entries[key]
+	if !ok {
+		return "", entry, fmt.Errorf("no %s of asset %s, %w", what, key, errNoSessionState)
+	}
+
+	return key, entry, nil
+}
+
+// boarding returns the asset id and pending boarding of the asset assetArg
+// names, see selectAsset.
+func (ap *App) boarding(assetArg string) ([]byte, taponark.ArkBoardingTransfer, error) {
+	key, boarding, err := selectAsset(ap, ap.boardings, assetArg, "boarding")
+	if err != nil {
+		return nil, taponark.ArkBoardingTransfer{}, err
+	}
+	assetId, _ := hex.DecodeString(key)
+
+	return assetId, boarding, nil
+}
+
+// settleRound forgets the boarding the round spent, even a failed round may
+// have been broadcast, and keeps the round for exits.
+func (ap *App) settleRound(assetId []byte, round *taponark.Round, err error) {
+	var broadcastErr *taponark.RoundBroadcastError
+	if err == nil || errors.As(err, &broadcastErr) {
+		delete(ap.boardings, hex.EncodeToString(assetId))
+	}
+	if round != nil {
+		ap.rounds[hex.EncodeToString(round.AssetId())] = &assetRound{round: *round}
+	}
+}
+
+// ConstructRound runs a round for the boarded asset assetArg names.
+func (ap *App) ConstructRound(assetArg string) (RoundResult, error) {
+	assetId, boarding, err := ap.boarding(assetArg)
+	if err != nil {
+		return RoundResult{}, err
+	}
+
+	// The exit user owns every leaf of the tree
+	leafOwners := []*taponark.TapClient{&ap.exitUserTapClient, &ap.exitUserTapClient}
+	round, err := ap.runRound(taponark.RoundIntent{AssetId: assetId, Boarding: boarding, LeafOwners: leafOwners})
+	ap.settleRound(assetId, round, err)
 	if err != nil {
-		log.Printf("Error creating round transfer: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return RoundResult{}, fmt.Errorf("cannot create round transfer %v", err)
+	}
+	if round == nil {
+		log.Println("Round Intent Registered, waiting for the scheduled round")
+		log.Println("------------------------------------------------")
+		return RoundResult{Registered: true}, nil
 	}
 
-	ap.round = round
 	log.Println("Round Construction Complete")
 	log.Println("------------------------------------------------")
+	return roundResult(*round), nil
 }
 
-func (ap *App) ShowRoundTree() {
+func roundSchedulerConfig(config taponark.Config) taponark.RoundSchedulerConfig {
+	return taponark.RoundSchedulerConfig{
+		Interval:   time.Duration(config.RoundInterval) * time.Second,
+		MinIntents: config.RoundMinIntents,
+	}
+}
+
+func (ap *App) coordinator() *taponark.RoundCoordinator {
+	if ap.roundCoordinator == nil {
+		cfg := taponark.RoundCoordinatorConfig{
+			RegistrationWindow: ROUND_REGISTRATION_WINDOW,
+			MaxSigningAttempts: ROUND_SIGNING_ATTEMPTS,
+			ServerBtcAmount:    ap.serverRoundLiquidity,
+		}
+		ap.roundCoordinator = taponark.NewRoundCoordinator(cfg, &ap.serverTapClient, ap.bitcoinClient)
+	}
 
-	taponark.PrintTree(ap.round.RoundTree.Root, "", true)
+	return ap.roundCoordinator
+}
+
+// runRound registers the intent with the server's round coordinator and runs
+// the round right away instead of waiting for the registration window. Once
+// the scheduler runs, the intent is only registered and nil is returned.
+func (ap *App) runRound(intent taponark.RoundIntent) (*taponark.Round, error) {
+	coordinator := ap.coordinator()
+	if ap.roundScheduler != nil {
+		_, err := coordinator.RegisterIntent(intent)
+		return nil, err
+	}
 
-	log.Println("Print Of Round Complete")
+	err := coordinator.OpenRegistration()
+	if err != nil {
+		return nil, err
+	}
+
+	// An intent left pending by a failed round is retried as is
+	_, err = coordinator.RegisterIntent(intent)
+	if err != nil && !errors.Is(err, taponark.ErrIntentRegistered) {
+		return nil, err
+	}
+
+	round, err := coordinator.RunRound()
+	if err != nil {
+		return nil, err
+	}
+
+	return &round, nil
+}
+
+// StartScheduler lets the server run rounds on the configured interval or
+// intent threshold, round and coexit then only register their intent.
+func (ap *App) StartScheduler() error {
+	if ap.roundScheduler != nil {
+		return errors.New("round scheduler is already running")
+	}
+
+	scheduler, err := taponark.NewRoundScheduler(ap.roundSchedulerConfig, ap.coordinator(), func(round taponark.Round, err error) {
+		if err != nil {
+			log.Printf("Error running scheduled round: %v", err)
+			log.Println("-------------------------------------")
+			return
+		}
+
+		ap.rounds[hex.EncodeToString(round.AssetId())] = &assetRound{round: round}
+		log.Println("Scheduled Round Complete")
+		log.Println("------------------------------------------------")
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create round scheduler %v", err)
+	}
+
+	err = scheduler.Start(context.Background())
+	if err != nil {
+		return fmt.Errorf("cannot start round scheduler %v", err)
+	}
+
+	ap.roundScheduler = scheduler
+	log.Println("Round Scheduler Started")
 	log.Println("------------------------------------------------")
+	return nil
 }
 
-func (ap *App) ExitRound() {
-	assetVtxoProofList, err := taponark.ExitRoundAndAppendProof(ap.round, &ap.bitcoinClient)
+// CollaborativeExit runs a round that pays part of the boarded funds straight
+// to the exit user's on-chain addresses, the tree holds the rest.
+func (ap *App) CollaborativeExit(assetArg string, exitAssetAmnt, exitBtcAmnt uint64) (RoundResult, error) {
+	assetId, boarding, err := ap.boarding(assetArg)
 	if err != nil {
-		log.Printf("Error Exitng round or appending round: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return RoundResult{}, err
 	}
-	ap.assetVtxoProofList = assetVtxoProofList
-	log.Println("Exit Transactions Broadcasted and Token Transfer Proof Appended")
+
+	assetAddr, err := ap.exitUserTapClient.GetAssetAddress(assetId, exitAssetAmnt)
+	if err != nil {
+		return RoundResult{}, fmt.Errorf("cannot get exit asset address %v", err)
+	}
+
+	btcInternalKey, err := ap.exitUserTapClient.GetBtcInternalKey()
+	if err != nil {
+		return RoundResult{}, fmt.Errorf("cannot get exit btc key %v", err)
+	}
+
+	exit := taponark.CollaborativeExit{
+		AssetAddr:      assetAddr,
+		Recipient:      &ap.exitUserTapClient,
+		BtcInternalKey: btcInternalKey,
+		BtcAmount:      exitBtcAmnt,
+	}
+
+	leafOwners := []*taponark.TapClient{&ap.exitUserTapClient, &ap.exitUserTapClient}
+	round, err := ap.runRound(taponark.RoundIntent{AssetId: assetId, Boarding: boarding, LeafOwners: leafOwners, Exits: []taponark.CollaborativeExit{exit}})
+	ap.settleRound(assetId, round, err)
+	if err != nil {
+		return RoundResult{}, fmt.Errorf("cannot create collaborative exit round %v", err)
+	}
+	if round == nil {
+		log.Println("Collaborative Exit Intent Registered, waiting for the scheduled round")
+		log.Println("------------------------------------------------")
+		return RoundResult{Registered: true}, nil
+	}
+
+	log.Println("Collaborative Exit Round Complete")
+	log.Println("------------------------------------------------")
+	return roundResult(*round), nil
+}
+
+// Swap trades the boarded asset of the boarding user for BTC boarded by the
+// exit user, both settle in one round.
+func (ap *App) Swap(assetArg string, swapBtcAmnt uint64) (RoundResult, error) {
+	assetId, boarding, err := ap.boarding(assetArg)
+	if err != nil {
+		return RoundResult{}, err
+	}
+
+	btcTransferDetails, err := taponark.OnboardBtcUser(swapBtcAmnt, ap.btcSpendPath, &ap.exitUserTapClient, &ap.serverTapClient, &ap.bitcoinClient)
+	if err != nil {
+		return RoundResult{}, fmt.Errorf("cannot onboard swap btc %v", err)
+	}
+
+	swap := taponark.SwapIntent{AssetTransfer: boarding, BtcTransfer: btcTransferDetails}
+	round, err := taponark.ConstructAndBroadcastSwapRound(assetId, swap, &ap.serverTapClient, ap.bitcoinClient)
+	if err != nil {
+		ap.settleRound(assetId, nil, err)
+		return RoundResult{}, fmt.Errorf("cannot create swap round %v", err)
+	}
+
+	ap.settleRound(assetId, &round, nil)
+	log.Println("Swap Round Construction Complete")
 	log.Println("------------------------------------------------")
+	return roundResult(round), nil
+}
+
+// exitUserAddress returns an Ark address of the exit user for the asset.
+func (ap *App) exitUserAddress(assetId []byte, exitDelay uint32) (taponark.ArkAddress, error) {
+	serverKey, err := ap.serverTapClient.GetArkKey()
+	if err != nil {
+		return taponark.ArkAddress{}, fmt.Errorf("cannot derive server key %v", err)
+	}
+	addr, err := taponark.NewArkAddress(&ap.exitUserTapClient, serverKey.PubKey, assetId, nil, 0, exitDelay)
+	if err != nil {
+		return taponark.ArkAddress{}, fmt.Errorf("cannot create ark address %v", err)
+	}
 
+	return addr, nil
 }
 
-func (ap *App) ShowBalance() {
+// claim claims an out of round VTXO paid to addr of the exit user and uploads
+// its proof.
+func (ap *App) claim(vtxo taponark.ArkAddressVtxo, addr taponark.ArkAddress) error {
+	proofFile, err := vtxo.Claim(&ap.exitUserTapClient, addr, &ap.bitcoinClient)
+	if err != nil {
+		return fmt.Errorf("cannot claim ark address vtxo %v", err)
+	}
+	err = taponark.SubmitProof(vtxo.GenesisPoint, proofFile, &ap.exitUserTapClient)
+	if err != nil {
+		return fmt.Errorf("cannot upload proof %v", err)
+	}
 
-	boardingUserAssetBalance, boardingUserBtcBalance, err := ap.boardingUserTapClient.GetBalance(ap.assetId)
+	return nil
+}
+
+// SendToAddress pays the boarded asset of the boarding user to an Ark address
+// of the exit user out of round, which the exit user then claims with the
+// server.
+func (ap *App) SendToAddress(assetArg string, exitDelay uint32) (AddressPaymentResult, error) {
+	assetId, boarding, err := ap.boarding(assetArg)
 	if err != nil {
-		log.Printf("Error getting balance: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return AddressPaymentResult{}, err
+	}
+
+	addr, err := ap.exitUserAddress(assetId, exitDelay)
+	if err != nil {
+		return AddressPaymentResult{}, err
+	}
+	encoded, err := addr.Encode()
+	if err != nil {
+		return AddressPaymentResult{}, fmt.Errorf("cannot encode ark address %v", err)
+	}
+	log.Printf("Ark Address Of Exit User: %s", encoded)
+
+	decoded, err := taponark.DecodeArkAddress(encoded)
+	if err != nil {
+		return AddressPaymentResult{}, fmt.Errorf("cannot decode ark address %v", err)
+	}
+	vtxo, err := taponark.SendToArkAddress(assetId, boarding, decoded, &ap.serverTapClient, &ap.bitcoinClient)
+	if err != nil {
+		return AddressPaymentResult{}, fmt.Errorf("cannot send to ark address %v", err)
+	}
+	delete(ap.boardings, hex.EncodeToString(assetId))
+
+	err = ap.claim(vtxo, addr)
+	if err != nil {
+		return AddressPaymentResult{}, err
+	}
+
+	log.Println("Ark Address Payment Claimed")
+	log.Println("------------------------------------------------")
+	return AddressPaymentResult{encoded, vtxo.Outpoint()}, nil
+}
+
+// PayRequest pays a signed payment request of the exit user with the boarded
+// asset of the boarding user out of round and returns the receipt, verified
+// against the chain. A receipt that does not verify is only logged, the
+// request is paid.
+func (ap *App) PayRequest(assetArg string, memo string, expiry time.Duration, exitDelay uint32) (PaymentRequestResult, error) {
+	assetId, boarding, err := ap.boarding(assetArg)
+	if err != nil {
+		return PaymentRequestResult{}, err
+	}
+
+	addr, err := ap.exitUserAddress(assetId, exitDelay)
+	if err != nil {
+		return PaymentRequestResult{}, err
+	}
+	amount := boarding.AssetAmount()
+	request, err := taponark.NewPaymentRequest(&ap.exitUserTapClient, addr, assetId, amount, expiry, memo)
+	if err != nil {
+		return PaymentRequestResult{}, fmt.Errorf("cannot create payment request %v", err)
+	}
+	encoded, err := request.Encode()
+	if err != nil {
+		return PaymentRequestResult{}, fmt.Errorf("cannot encode payment request %v", err)
+	}
+	log.Printf("Payment Request Of Exit User: %s", encoded)
+
+	decoded, err := taponark.DecodePaymentRequest(encoded)
+	if err != nil {
+		return PaymentRequestResult{}, fmt.Errorf("cannot decode payment request %v", err)
+	}
+	vtxo, receipt, err := taponark.PayRequest(decoded, boarding, &ap.serverTapClient, &ap.bitcoinClient)
+	var receiptErr *taponark.ReceiptVerificationError
+	if errors.As(err, &receiptErr) {
+		// Paid all the same, the VTXO is still claimed
+		log.Printf("Warning: %v", receiptErr)
+	} else if err != nil {
+		return PaymentRequestResult{}, fmt.Errorf("cannot pay request %v", err)
+	}
+	delete(ap.boardings, hex.EncodeToString(assetId))
+	log.Printf("Paid %d tokens for %q to vtxo %s", receipt.Request.Amount, receipt.Request.Memo, receipt.Outpoint)
+
+	err = ap.claim(vtxo, addr)
+	if err != nil {
+		return PaymentRequestResult{}, err
+	}
+
+	log.Println("Payment Request Paid and Claimed")
+	log.Println("------------------------------------------------")
+	return PaymentRequestResult{
+		Request: encoded,
+		Amount:  receipt.Request.Amount,
+		Memo:    receipt.Request.Memo,
+		Receipt: PaymentReceiptResult{
+			Outpoint:     receipt.Outpoint,
+			GenesisPoint: receipt.GenesisPoint,
+			ProofFile:    hex.EncodeToString(receipt.ProofFile),
+			Verified:     receiptErr == nil,
+		},
+	}, nil
+}
+
+// ShowRoundTree returns the tree of the last round of the asset assetArg
+// names.
+func (ap *App) ShowRoundTree(assetArg string) (*taponark.RoundTreeNode, *TreeNodeResult, error) {
+	_, last, err := selectAsset(ap, ap.rounds, assetArg, "round")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := last.round.RoundTree.Root
+	return root, treeResult(root), nil
+}
+
+// ExitRound broadcasts the tree of the last round of the asset assetArg names,
+// or only the branch of the leaf at index if it is not negative.
+func (ap *App) ExitRound(assetArg string, leaf int) (ExitResult, error) {
+	_, last, err := selectAsset(ap, ap.rounds, assetArg, "round")
+	if err != nil {
+		return ExitResult{}, err
+	}
+	root := last.round.RoundTree.Root
+
+	var (
+		assetVtxoProofList [][]byte
+		exitedLeaf         *taponark.RoundTreeNode
+	)
+	if leaf < 0 {
+		assetVtxoProofList, err = taponark.ExitRoundAndAppendProof(last.round, &ap.bitcoinClient)
+	} else {
+		assetVtxoProofList, err = taponark.ExitRoundLeaf(last.round, leaf, &ap.bitcoinClient)
+		if err == nil {
+			exitedLeaf = taponark.RoundLeaves(root)[leaf]
+		}
+	}
+	if err != nil {
+		return ExitResult{}, fmt.Errorf("cannot exit round or append proof %v", err)
+	}
+
+	last.vtxoProofs = append(last.vtxoProofs, assetVtxoProofList...)
+	log.Println("Exit Transactions Broadcasted and Token Transfer Proof Appended")
+	log.Println("------------------------------------------------")
+	return ExitResult{exitTxids(root, exitedLeaf), len(assetVtxoProofList)}, nil
+}
+
+// ShowBalance shows the balances of the asset, the last minted or boarded
+// asset if nil.
+func (ap *App) ShowBalance(assetId []byte) (BalanceResult, error) {
+	if assetId == nil {
+		assetId = ap.assetId
+	}
+
+	boardingUserAssetBalance, boardingUserBtcBalance, err := ap.boardingUserTapClient.GetBalance(assetId)
+	if err != nil {
+		return BalanceResult{}, err
 	}
 
 	log.Println("------Boarding User-----")
@@ -253,36 +743,42 @@ func (ap *App) ShowBalance() {
 	log.Printf("Btc Balance = %d", boardingUserBtcBalance)
 	log.Println("-------------------------------------")
 
-	exitUserAssetBalance, exitUserBtcBalance, err := ap.exitUserTapClient.GetBalance(ap.assetId)
+	exitUserAssetBalance, exitUserBtcBalance, err := ap.exitUserTapClient.GetBalance(assetId)
 	if err != nil {
-		log.Printf("Error getting balance: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return BalanceResult{}, err
 	}
 
 	log.Println("-----Exit User------")
 	log.Printf("Asset Balance = %d", exitUserAssetBalance)
 	log.Printf("Btc Balance = %d", exitUserBtcBalance)
 	log.Println("-------------------------------------")
+
+	return BalanceResult{
+		AssetId:      hex.EncodeToString(assetId),
+		BoardingUser: UserBalance{boardingUserAssetBalance, boardingUserBtcBalance},
+		ExitUser:     UserBalance{exitUserAssetBalance, exitUserBtcBalance},
+	}, nil
 }
 
-func (ap *App) UploadTokenVtxoProof() {
-	length := len(ap.assetVtxoProofList)
-	if length == 0 {
-		log.Println("No Vtxo Proofs to Upload")
-		log.Println("-------------------------------------")
-		return
+// UploadTokenVtxoProof uploads the next exited VTXO proof of the last round of
+// the asset assetArg names to the exit user.
+func (ap *App) UploadTokenVtxoProof(assetArg string) (UploadResult, error) {
+	_, last, err := selectAsset(ap, ap.rounds, assetArg, "round")
+	if err != nil {
+		return UploadResult{}, err
+	}
+	if len(last.vtxoProofs) == 0 {
+		return UploadResult{}, errors.New("no vtxo proofs to upload")
 	}
 
-	proofFile := ap.assetVtxoProofList[0]
-	err := taponark.SubmitProof(ap.round.GenesisPoint, proofFile, &ap.exitUserTapClient)
+	proofFile := last.vtxoProofs[0]
+	err = taponark.SubmitProof(last.round.GenesisPoint, proofFile, &ap.exitUserTapClient)
 	if err != nil {
-		log.Printf("Error uploading proof: %v", err)
-		log.Println("-------------------------------------")
-		return
+		return UploadResult{}, fmt.Errorf("cannot upload proof %v", err)
 	}
 
-	ap.assetVtxoProofList = ap.assetVtxoProofList[1:]
+	last.vtxoProofs = last.vtxoProofs[1:]
 	log.Println("Proof Uploaded")
 	log.Println("------------------------------------------------")
+	return UploadResult{last.round.GenesisPoint, len(last.vtxoProofs)}, nil
 }
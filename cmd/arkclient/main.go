@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"taponark"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+func main() {
+	network := flag.String("network", "regtest", "Specify the network of the user")
+	server := flag.String("server", "localhost:10030", "Address of the Ark server daemon")
+	serverKeyHex := flag.String("server_key", "", "Ark key of the server, as logged by arkd")
+	user := flag.String("user", "onboard", "Node of the config the client runs on, onboard or exit")
+	storePath := flag.String("store", "", "VTXO store file, vtxos-{user}.json by default")
+	flag.Parse()
+
+	// The offline nodes and their chain only live in the REPL process
+	if *network == "offline" {
+		log.Fatalln("the offline network runs in process only, use the REPL")
+	}
+
+	// The server is pinned, the handshake fails against any other key
+	serverKeyBytes, err := hex.DecodeString(*serverKeyHex)
+	if err != nil {
+		log.Fatalf("invalid server key %v", err)
+	}
+	serverKey, err := btcec.ParsePubKey(serverKeyBytes)
+	if err != nil {
+		log.Fatalf("invalid server key %v", err)
+	}
+
+	log.Println("Ark Client")
+	log.Println("------------------------------------------------")
+	log.Println("Network: ", *network)
+
+	// Run from the cmd directory, next to the configs and docker volumes
+	config := taponark.LoadConfig(*network)
+	chainParams, _ := taponark.NetworkParams(*network, config)
+	timeout := time.Duration(config.Timeout) * time.Minute
+
+	tapConfig, lndConfig := config.OnboardingUserTapClient, config.OnboardingUserLndClient
+	if *user == "exit" {
+		tapConfig, lndConfig = config.ExitUserTapClient, config.ExitUserLndClient
+	} else if *user != "onboard" {
+		log.Fatalf("unknown user %s", *user)
+	}
+	if *storePath == "" {
+		*storePath = "vtxos-" + *user + ".json"
+	}
+
+	userTapClient := taponark.InitNode(*network, config.OnboardingUserTapClient.Hostname, tapConfig, lndConfig, config)
+	bitcoinClient := taponark.GetBitcoinClient(config.BitcoinClient, chainParams, timeout)
+
+	defer func() {
+		if err := errors.Join(userTapClient.Close(), bitcoinClient.Close()); err != nil {
+			log.Printf("Error: %v", err)
+		}
+	}()
+
+	store, err := taponark.OpenVtxoStore(*storePath)
+	if err != nil {
+		log.Fatalf("cannot open vtxo store %v", err)
+	}
+
+	client, err := taponark.NewArkClient(*server, serverKey, &userTapClient, &bitcoinClient, store)
+	if err != nil {
+		log.Fatalf("cannot create ark client %v", err)
+	}
+	defer client.Close()
+
+	err = client.Start(context.Background())
+	if err != nil {
+		log.Fatalf("cannot start ark client %v", err)
+	}
+
+	log.Printf("Ark Key: %x", client.ArkKey())
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(">> ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println("Error reading input:", err)
+			return
+		}
+
+		args := strings.Fields(input)
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			fmt.Println("Goodbye!")
+			return
+		}
+
+		processInput(args, client, &userTapClient)
+	}
+}
+
+func processInput(args []string, client *taponark.ArkClient, user *taponark.TapClient) {
+	var err error
+	switch args[0] {
+	case "mint":
+		err = mint(user)
+	case "board":
+		err = board(args[1:], client)
+	case "address":
+		err = address(args[1:], client)
+	case "send":
+		err = send(args[1:], client)
+	case "vtxos":
+		listVtxos(client)
+	case "unilateral":
+		err = unilateral(args[1:], client)
+	case "balance":
+		err = balance(args[1:], user)
+	default:
+		log.Println("unknown command")
+	}
+
+	if err != nil {
+		log.Printf("Error: %v", err)
+	}
+	log.Println("------------------------------------------------")
+}
+
+func mint(user *taponark.TapClient) error {
+	assetId, err := user.CreateAsset()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Asset ID: %x", assetId)
+	return nil
+}
+
+// board <asset_id> <asset_amount> <btc_amount> [leaf_owner_key...]
+func board(args []string, client *taponark.ArkClient) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: board <asset_id> <asset_amount> <btc_amount> [leaf_owner_key...]")
+	}
+
+	assetId, err := hex.DecodeString(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid asset id %v", err)
+	}
+	assetAmount, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid asset amount %v", err)
+	}
+	btcAmount, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid btc amount %v", err)
+	}
+
+	var leafOwners [][]byte
+	for _, ownerHex := range args[3:] {
+		owner, err := hex.DecodeString(ownerHex)
+		if err != nil {
+			return fmt.Errorf("invalid leaf owner key %v", err)
+		}
+		leafOwners = append(leafOwners, owner)
+	}
+
+	boardingId, err := client.Board(assetId, assetAmount, btcAmount)
+	if err != nil {
+		return err
+	}
+
+	intentId, err := client.RegisterIntent(boardingId, leafOwners, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Boarding %s registered as intent %s, waiting for the next round", boardingId, intentId)
+	return nil
+}
+
+// address <asset_id> [asset_amount]
+func address(args []string, client *taponark.ArkClient) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: address <asset_id> [asset_amount]")
+	}
+
+	assetId, err := hex.DecodeString(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid asset id %v", err)
+	}
+	var amount uint64
+	if len(args) == 2 {
+		amount, err = strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid asset amount %v", err)
+		}
+	}
+
+	addr, err := client.NewAddress(assetId, nil, amount, taponark.DEFAULT_ARK_EXIT_DELAY)
+	if err != nil {
+		return err
+	}
+	encoded, err := addr.Encode()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Ark Address: %s", encoded)
+	return nil
+}
+
+// send <ark_address> <asset_amount> <btc_amount> [asset_id]
+func send(args []string, client *taponark.ArkClient) error {
+	if len(args) < 3 || len(args) > 4 {
+		return fmt.Errorf("usage: send <ark_address> <asset_amount> <btc_amount> [asset_id]")
+	}
+
+	addr, err := taponark.DecodeArkAddress(args[0])
+	if err != nil {
+		return err
+	}
+	assetAmount, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid asset amount %v", err)
+	}
+	btcAmount, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid btc amount %v", err)
+	}
+
+	// Group addresses leave the asset to the sender
+	assetId := addr.AssetId
+	if len(args) == 4 {
+		assetId, err = hex.DecodeString(args[3])
+		if err != nil {
+			return fmt.Errorf("invalid asset id %v", err)
+		}
+	}
+
+	intentId, err := client.PayAddress(addr, assetId, assetAmount, btcAmount)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Payment registered as intent %s, waiting for the next round", intentId)
+	return nil
+}
+
+func listVtxos(client *taponark.ArkClient) {
+	for _, vtxo := range client.ListVtxos() {
+		if vtxo.Type == taponark.OutputTypeBTC {
+			log.Printf("%s Round %s BTC %d", vtxo.Outpoint, vtxo.RoundTxid, vtxo.BtcAmount)
+		} else {
+			log.Printf("%s Round %s Token %d", vtxo.Outpoint, vtxo.RoundTxid, vtxo.AssetAmount)
+		}
+	}
+}
+
+// unilateral <round_txid>
+func unilateral(args []string, client *taponark.ArkClient) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: unilateral <round_txid>")
+	}
+
+	err := client.UnilateralExit(args[0])
+	if err != nil {
+		return err
+	}
+
+	log.Println("Exit Transactions Broadcasted and Token Transfer Proofs Imported")
+	return nil
+}
+
+// balance <asset_id>
+func balance(args []string, user *taponark.TapClient) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: balance <asset_id>")
+	}
+
+	assetId, err := hex.DecodeString(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid asset id %v", err)
+	}
+
+	assetBalance, btcBalance, err := user.GetBalance(assetId)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Asset Balance = %d", assetBalance)
+	log.Printf("Btc Balance = %d", btcBalance)
+	return nil
+}
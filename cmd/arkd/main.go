@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"taponark"
+	"taponark/arkrpc"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ROUND_REGISTRATION_WINDOW is how long the coordinator accepts intents
+const ROUND_REGISTRATION_WINDOW = 10 * time.Second
+
+// ROUND_SIGNING_ATTEMPTS bounds the round restarts after dropping a signer
+const ROUND_SIGNING_ATTEMPTS = 3
+
+// DEFAULT_ROUND_INTERVAL schedules rounds when the config leaves it to the
+// round command of the REPL
+const DEFAULT_ROUND_INTERVAL = 30 * time.Second
+
+func main() {
+	network := flag.String("network", "regtest", "Specify the network of the server")
+	listen := flag.String("listen", "localhost:10030", "Address the Ark service listens on")
+	flag.Parse()
+
+	// The offline nodes and their chain only live in the REPL process
+	if *network == "offline" {
+		log.Fatalln("the offline network runs in process only, use the REPL")
+	}
+
+	log.Println("Ark Server Daemon")
+	log.Println("------------------------------------------------")
+	log.Println("Network: ", *network)
+
+	// Run from the cmd directory, next to the configs and docker volumes
+	config := taponark.LoadConfig(*network)
+	chainParams, _ := taponark.NetworkParams(*network, config)
+	timeout := time.Duration(config.Timeout) * time.Minute
+
+	serverTapClient := taponark.InitNode(*network, config.OnboardingUserTapClient.Hostname, config.ServerTapClient, config.ServerLndClient, config)
+	bitcoinClient := taponark.GetBitcoinClient(config.BitcoinClient, chainParams, timeout)
+
+	btcSpendPath, err := taponark.ParseBtcSpendPath(config.BtcSpendPath)
+	if err != nil {
+		log.Fatalf("Invalid btc spend path %v", err)
+	}
+
+	schedulerConfig := taponark.RoundSchedulerConfig{
+		Interval:   time.Duration(config.RoundInterval) * time.Second,
+		MinIntents: config.RoundMinIntents,
+	}
+	if schedulerConfig.Interval == 0 && schedulerConfig.MinIntents == 0 {
+		schedulerConfig.Interval = DEFAULT_ROUND_INTERVAL
+	}
+
+	arkServer, err := taponark.NewArkServer(taponark.ArkServerConfig{
+		Network:   *network,
+		SpendPath: btcSpendPath,
+		Coordinator: taponark.RoundCoordinatorConfig{
+			RegistrationWindow: ROUND_REGISTRATION_WINDOW,
+			MaxSigningAttempts: ROUND_SIGNING_ATTEMPTS,
+			ServerBtcAmount:    config.ServerRoundLiquidity,
+		},
+		Scheduler: schedulerConfig,
+	}, &serverTapClient, bitcoinClient)
+	if err != nil {
+		log.Fatalf("cannot create ark server %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	err = arkServer.Start(ctx)
+	if err != nil {
+		log.Fatalf("cannot start round scheduler %v", err)
+	}
+
+	// Users dial the server's Ark key over brontide
+	listener, err := taponark.ListenArk(&serverTapClient, *listen)
+	if err != nil {
+		log.Fatalf("cannot listen %v", err)
+	}
+
+	grpcServer := grpc.NewServer(taponark.ArkServerOptions()...)
+	arkrpc.RegisterArkServiceServer(grpcServer, arkServer)
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
+
+	log.Printf("Ark server %x listening on %s", arkServer.ArkKey().SerializeCompressed(), *listen)
+	err = grpcServer.Serve(listener)
+	if err != nil {
+		log.Fatalf("ark server stopped %v", err)
+	}
+
+	if err := errors.Join(serverTapClient.Close(), bitcoinClient.Close()); err != nil {
+		log.Fatalf("cannot close clients %v", err)
+	}
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"taponark"
+)
+
+// errQuit is returned by the exit command to end the REPL
+var errQuit = errors.New("quit")
+
+// handler runs a command with the arguments left after its flags and returns
+// its result, printed as JSON with -json.
+type handler func(app *App, args []string) (any, error)
+
+// command is a REPL command. setup registers the flags of the command and
+// returns its handler. Commands with replOnly are not run non-interactively,
+// cliAliases only name the command there.
+type command struct {
+	name       string
+	aliases    []string
+	cliAliases []string
+	replOnly   bool
+	args       string
+	summary    string
+	setup      func(fs *flag.FlagSet) handler
+}
+
+// assetFlag adds the -asset flag picking one of several boarded or settled
+// assets.
+func assetFlag(fs *flag.FlagSet, state string) *string {
+	return fs.String("asset", "", "asset id or group key of the "+state+" asset, needed once several are")
+}
+
+// noArgs wraps a handler of a command without arguments.
+func noArgs[T any](run func(app *App) (T, error)) handler {
+	return func(app *App, args []string) (any, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("unexpected arguments %s", strings.Join(args, " "))
+		}
+		return run(app)
+	}
+}
+
+// commandSet holds the commands and the lines entered so far. Commands run
+// interactively in the REPL, or from the command line otherwise.
+type commandSet struct {
+	commands    []command
+	history     []string
+	interactive bool
+	json        bool
+	// jsonOutput is set if the last command prints its result as JSON
+	jsonOutput bool
+}
+
+func newCommandSet(interactive, json bool) *commandSet {
+	cs := &commandSet{interactive: interactive, json: json}
+	cs.commands = []command{
+		{name: "mint", summary: "Mint a 100k token asset for the boarding user",
+			setup: func(fs *flag.FlagSet) handler {
+				return noArgs((*App).Mint)
+			}},
+		{name: "deposit", summary: "Show a BTC deposit address of the boarding user",
+			setup: func(fs *flag.FlagSet) handler {
+				return noArgs((*App).FundOnboarding)
+			}},
+		{name: "board", args: "[<asset_id|group_key> <asset_amount>]... [<btc_amount>]", summary: "Board assets and BTC of the boarding user, the last minted asset without arguments",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := fs.String("asset", "", "asset id or group key boarded before the arguments")
+				amount := fs.Uint64("amount", DEFAULT_BOARDING_ASSET_AMOUNT, "asset amount of -asset")
+				btcAmount := fs.Uint64("btc", DEFAULT_BOARDING_BTC_AMOUNT, "sats boarded with every asset, replaces <btc_amount>")
+				return func(app *App, args []string) (any, error) {
+					if *assetArg != "" {
+						args = append([]string{*assetArg, strconv.FormatUint(*amount, 10)}, args...)
+					}
+					if isFlagSet(fs, "btc") {
+						// Like board without arguments, the last minted asset is boarded
+						if len(args) == 0 && app.assetId != nil {
+							args = []string{hex.EncodeToString(app.assetId), strconv.Itoa(DEFAULT_BOARDING_ASSET_AMOUNT)}
+						}
+						if len(args)%2 == 1 {
+							return nil, fmt.Errorf("btc amount given twice")
+						}
+						args = append(args, strconv.FormatUint(*btcAmount, 10))
+					}
+					return app.Board(args)
+				}
+			}},
+		{name: "round", summary: "Run a round paying every leaf to the exit user",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "boarded")
+				return noArgs(func(app *App) (RoundResult, error) {
+					return app.ConstructRound(*assetArg)
+				})
+			}},
+		{name: "coexit", summary: "Run a round paying part of the boarding to the exit user on-chain",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "boarded")
+				assetAmount := fs.Uint64("asset_amount", DEFAULT_EXIT_ASSET_AMOUNT, "asset amount paid out on-chain")
+				btcAmount := fs.Uint64("btc_amount", DEFAULT_EXIT_BTC_AMOUNT, "sats paid out on-chain")
+				return noArgs(func(app *App) (RoundResult, error) {
+					return app.CollaborativeExit(*assetArg, *assetAmount, *btcAmount)
+				})
+			}},
+		{name: "swap", summary: "Swap the boarded asset for BTC boarded by the exit user",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "boarded")
+				btcAmount := fs.Uint64("btc_amount", DEFAULT_SWAP_BTC_AMOUNT, "sats boarded by the exit user")
+				return noArgs(func(app *App) (RoundResult, error) {
+					return app.Swap(*assetArg, *btcAmount)
+				})
+			}},
+		{name: "sendaddr", summary: "Pay the boarding to an Ark address of the exit user out of round",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "boarded")
+				exitDelay := fs.Uint("exit_delay", taponark.DEFAULT_ARK_EXIT_DELAY, "exit delay of the address in blocks")
+				return noArgs(func(app *App) (AddressPaymentResult, error) {
+					return app.SendToAddress(*assetArg, uint32(*exitDelay))
+				})
+			}},
+		{name: "payreq", summary: "Pay a payment request of the exit user out of round",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "boarded")
+				memo := fs.String("memo", PAYMENT_REQUEST_MEMO, "memo of the request")
+				expiry := fs.Duration("expiry", PAYMENT_REQUEST_EXPIRY, "how long the request is valid")
+				exitDelay := fs.Uint("exit_delay", taponark.DEFAULT_ARK_EXIT_DELAY, "exit delay of the address in blocks")
+				return noArgs(func(app *App) (PaymentRequestResult, error) {
+					return app.PayRequest(*assetArg, *memo, *expiry, uint32(*exitDelay))
+				})
+			}},
+		{name: "schedule", summary: "Start the round scheduler, round and coexit then only register",
+			setup: func(fs *flag.FlagSet) handler {
+				return noArgs(func(app *App) (any, error) {
+					return nil, app.StartScheduler()
+				})
+			}},
+		{name: "unilateral", cliAliases: []string{"exit"}, summary: "Broadcast the exit transactions of the last round",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "settled")
+				leaf := fs.Int("leaf", -1, "only exit the branch of this leaf, numbered from the left")
+				return noArgs(func(app *App) (ExitResult, error) {
+					return app.ExitRound(*assetArg, *leaf)
+				})
+			}},
+		{name: "upload", summary: "Upload the next VTXO proof to the exit user",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "settled")
+				return noArgs(func(app *App) (UploadResult, error) {
+					return app.UploadTokenVtxoProof(*assetArg)
+				})
+			}},
+		{name: "tree", summary: "Print the tree of the last round",
+			setup: func(fs *flag.FlagSet) handler {
+				assetArg := assetFlag(fs, "settled")
+				return noArgs(func(app *App) (*TreeNodeResult, error) {
+					root, tree, err := app.ShowRoundTree(*assetArg)
+					if err == nil && !cs.json {
+						taponark.PrintTree(root, "", true)
+						log.Println("Print Of Round Complete")
+						log.Println("------------------------------------------------")
+					}
+					return tree, err
+				})
+			}},
+		{name: "balance", args: "[<asset_id>]", summary: "Show the balances of both users, of the last asset by default",
+			setup: func(fs *flag.FlagSet) handler {
+				return func(app *App, args []string) (any, error) {
+					if len(args) > 1 {
+						return nil, fmt.Errorf("unexpected arguments %s", strings.Join(args[1:], " "))
+					}
+					var assetId []byte
+					if len(args) == 1 {
+						var err error
+						assetId, err = hex.DecodeString(args[0])
+						if err != nil {
+							return nil, fmt.Errorf("invalid asset id %v", err)
+						}
+					}
+					return app.ShowBalance(assetId)
+				}
+			}},
+		{name: "history", replOnly: true, summary: "List the commands entered so far",
+			setup: func(fs *flag.FlagSet) handler {
+				return noArgs(func(*App) (any, error) {
+					for i, line := range cs.history {
+						log.Printf("%4d  %s", i+1, line)
+					}
+					return nil, nil
+				})
+			}},
+		{name: "help", args: "[<command>]", summary: "List the commands or show the usage of one",
+			setup: func(fs *flag.FlagSet) handler {
+				return func(app *App, args []string) (any, error) {
+					return nil, cs.help(log.Writer(), args)
+				}
+			}},
+		{name: "exit", aliases: []string{"quit"}, replOnly: true, summary: "Leave the REPL",
+			setup: func(fs *flag.FlagSet) handler {
+				return func(*App, []string) (any, error) {
+					return nil, errQuit
+				}
+			}},
+	}
+
+	return cs
+}
+
+// available returns the commands of the mode the set runs in.
+func (cs *commandSet) available() []command {
+	var commands []command
+	for _, cmd := range cs.commands {
+		if cmd.replOnly && !cs.interactive {
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+
+	return commands
+}
+
+// lookup returns the command named or aliased name.
+func (cs *commandSet) lookup(name string) (command, bool) {
+	for _, cmd := range cs.available() {
+		if cmd.name == name || slices.Contains(cmd.aliases, name) {
+			return cmd, true
+		}
+		if !cs.interactive && slices.Contains(cmd.cliAliases, name) {
+			return cmd, true
+		}
+	}
+
+	return command{}, false
+}
+
+// isFlagSet reports whether the flag was given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// flagSet returns the flags of cmd and its handler.
+func (cmd command) flagSet() (*flag.FlagSet, handler) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	run := cmd.setup(fs)
+
+	return fs, run
+}
+
+// usage writes the usage line and flags of cmd.
+func (cs *commandSet) usage(w io.Writer, cmd command) {
+	fs, _ := cmd.flagSet()
+	line := cmd.name
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		line += " [flags]"
+	}
+	if cmd.args != "" {
+		line += " " + cmd.args
+	}
+
+	fmt.Fprintf(w, "%s\n  %s\n", line, cmd.summary)
+	aliases := cmd.aliases
+	if !cs.interactive {
+		aliases = append(aliases, cmd.cliAliases...)
+	}
+	if len(aliases) != 0 {
+		fmt.Fprintf(w, "  aliases: %s\n", strings.Join(aliases, ", "))
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, "  -%s %s (default %q)\n", f.Name, f.Usage, f.DefValue)
+	})
+}
+
+// help lists every command, or shows the usage of the command in args.
+func (cs *commandSet) help(w io.Writer, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments %s", strings.Join(args[1:], " "))
+	}
+	if len(args) == 1 {
+		cmd, ok := cs.lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown command %s", args[0])
+		}
+		cs.usage(w, cmd)
+		return nil
+	}
+
+	width := 0
+	for _, cmd := range cs.available() {
+		width = max(width, len(cmd.name))
+	}
+	for _, cmd := range cs.available() {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "Run help <command> for its arguments and flags, flags come before arguments")
+	if !cs.interactive {
+		fmt.Fprintf(w, "Every command takes -json, chain commands with %s\n", COMMAND_SEPARATOR)
+	}
+
+	return nil
+}
+
+// runLine parses and runs a line of the REPL, errQuit ends the REPL.
+func (cs *commandSet) runLine(line string, app *App) (any, error) {
+	fields, err := splitArgs(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	cs.history = append(cs.history, line)
+
+	return cs.run(fields, app)
+}
+
+// run runs the command and flags in fields.
+func (cs *commandSet) run(fields []string, app *App) (any, error) {
+	cmd, ok := cs.lookup(fields[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown command %s, run help to list the commands", fields[0])
+	}
+
+	fs, handler := cmd.flagSet()
+	jsonOutput := new(bool)
+	if !cs.interactive {
+		jsonOutput = fs.Bool("json", cs.json, "print the result as JSON")
+	}
+	err := fs.Parse(fields[1:])
+	cs.jsonOutput = *jsonOutput
+	if errors.Is(err, flag.ErrHelp) {
+		cs.usage(log.Writer(), cmd)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v, run help %s for its usage", cmd.name, err, cmd.name)
+	}
+
+	result, err := handler(app, fs.Args())
+	if err != nil && !errors.Is(err, errQuit) {
+		return nil, fmt.Errorf("%s: %w", cmd.name, err)
+	}
+
+	return result, err
+}
+
+// complete completes the command name or flag before pos in line.
+func (cs *commandSet) complete(line string, pos int) (string, int, bool) {
+	prefix := line[:pos]
+	start := strings.LastIndexAny(prefix, " \t") + 1
+	word := prefix[start:]
+
+	var candidates []string
+	if start == 0 {
+		for _, cmd := range cs.available() {
+			candidates = append(candidates, cmd.name)
+		}
+	} else if strings.HasPrefix(word, "-") {
+		cmd, ok := cs.lookup(strings.Fields(prefix)[0])
+		if !ok {
+			return "", 0, false
+		}
+		fs, _ := cmd.flagSet()
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, "-"+f.Name)
+		})
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(matches)
+
+	completion := commonPrefix(matches)
+	if len(matches) == 1 {
+		completion += " "
+	}
+	if completion == word {
+		return "", 0, false
+	}
+
+	return prefix[:start] + completion + line[pos:], start + len(completion), true
+}
+
+// commonPrefix returns the longest prefix of the sorted words.
+func commonPrefix(words []string) string {
+	first, last := words[0], words[len(words)-1]
+	i := 0
+	for i < len(first) && i < len(last) && first[i] == last[i] {
+		i++
+	}
+
+	return first[:i]
+}
+
+// splitArgs splits a line into arguments, single or double quotes keep
+// spaces in an argument.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inArg   bool
+	)
+
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
@@ -1,19 +1,27 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strings"
 )
 
+// COMMAND_SEPARATOR chains commands given on the command line
+const COMMAND_SEPARATOR = "then"
+
 func main() {
-	// Create a new reader from standard input
-	reader := bufio.NewReader(os.Stdin)
 	// Define a command-line flag called "network" to determine which network.
 	network := flag.String("network", "regtest", "Specify the network to run the application")
+	jsonOutput := flag.Bool("json", false, "Print the results of the commands given on the command line as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [<command> [flags] [args] [%s <command> ...]]\n", os.Args[0], COMMAND_SEPARATOR)
+		fmt.Fprintln(flag.CommandLine.Output(), "Without a command the REPL starts, run help for the commands")
+		flag.PrintDefaults()
+	}
 
 	// Parse the command-line flags.
 	flag.Parse()
@@ -26,53 +34,104 @@ func main() {
 	// Initialise the App
 	app := Init(*network)
 
+	if flag.NArg() > 0 {
+		code := runCommands(flag.Args(), &app, newCommandSet(false, *jsonOutput))
+		if err := app.Close(); err != nil {
+			log.Printf("Error: %v", err)
+			code = max(code, 1)
+		}
+		os.Exit(code)
+	}
+
+	commands := newCommandSet(true, false)
+	log.Println("Run help to list the commands")
+
+	reader := newLineReader(commands.complete)
+	defer reader.Close()
+
 	for {
-		// Display prompt
-		fmt.Print(">> ")
 		// Read input until newline
-		input, err := reader.ReadString('\n')
+		input, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			fmt.Println("Error reading input:", err)
+			log.Println("Error reading input:", err)
 			continue
 		}
 
-		// Trim newline and whitespace
-		input = strings.TrimSpace(input)
-
-		// Check for exit commands
-		if input == "exit" || input == "quit" {
-			fmt.Println("Goodbye!")
+		// Process the command
+		if !processInput(input, &app, commands) {
 			break
 		}
-
-		// Process the command
-		processInput(input, &app)
 	}
 
+	if err := app.Close(); err != nil {
+		log.Printf("Error: %v", err)
+	}
+	log.Println("Goodbye!")
 }
 
-func processInput(input string, app *App) {
-	switch input {
-	case "board":
-		app.Board()
-	case "round":
-		app.ConstructRound()
-	case "unilateral":
-		app.ExitRound()
-	case "tree":
-		app.ShowRoundTree()
-	case "balance":
-		app.ShowBalance()
-	case "mint":
-		app.Mint()
-	case "deposit":
-		app.FundOnboarding()
-	case "upload":
-		app.UploadTokenVtxoProof()
-
-	default:
-		log.Println("unknown command")
+// processInput runs a line of the REPL and returns false once it should end.
+func processInput(input string, app *App, commands *commandSet) bool {
+	_, err := commands.runLine(input, app)
+	if errors.Is(err, errQuit) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error: %v", err)
 		log.Println("------------------------------------------------")
 	}
 
+	return true
+}
+
+// runCommands runs the commands of the command line in one process, as the
+// boarding and round state only live in it, and returns the exit code. A
+// command needing state an earlier command of the chain did not make fails
+// with a hint to chain them. With -json every command prints its result or
+// error as a line of JSON.
+func runCommands(args []string, app *App, commands *commandSet) int {
+	var fields []string
+	for i, arg := range append(args, COMMAND_SEPARATOR) {
+		if arg != COMMAND_SEPARATOR {
+			fields = append(fields, arg)
+			continue
+		}
+		if len(fields) == 0 {
+			log.Printf("Error: no command before argument %d", i+1)
+			return 2
+		}
+
+		result, err := commands.run(fields, app)
+		if errors.Is(err, errNoSessionState) {
+			err = fmt.Errorf("%w, run %s after the command making them, chained with %q", err, fields[0], COMMAND_SEPARATOR)
+		}
+		if commands.jsonOutput {
+			printJSON(result, err)
+		}
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return 1
+		}
+		fields = nil
+	}
+
+	return 0
+}
+
+// printJSON prints the result of a command, or its error, as a line of JSON.
+func printJSON(result any, err error) {
+	if err != nil {
+		result = map[string]string{"error": err.Error()}
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		encoded, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	fmt.Println(string(encoded))
 }
@@ -0,0 +1,37 @@
+//go:build offline
+
+package main
+
+import (
+	"log"
+	"taponark"
+	"taponark/internal/taponarktest"
+	"time"
+)
+
+// OFFLINE_NODE_FUNDING is the wallet balance of every offline node in sats
+const OFFLINE_NODE_FUNDING = 10_000_000
+
+// InitOffline runs every node in process against a fake chain, no docker
+// needed. Each wallet starts out funded.
+func InitOffline(config taponark.Config) App {
+	timeout := time.Duration(config.Timeout) * time.Minute
+	fakeNetwork := taponarktest.NewFakeNetwork(timeout)
+
+	nodes := make([]taponark.TapClient, 3)
+	for i := range nodes {
+		node, err := fakeNetwork.NewNode(OFFLINE_NODE_FUNDING)
+		if err != nil {
+			log.Fatalf("cannot create offline node %v", err)
+		}
+		nodes[i] = node
+	}
+
+	btcSpendPath, err := taponark.ParseBtcSpendPath(config.BtcSpendPath)
+	if err != nil {
+		log.Panicf("Invalid btc spend path %v", err)
+	}
+
+	log.Println("All offline clients Initilised")
+	return newApp(nodes[0], nodes[1], nodes[2], fakeNetwork.BitcoinClient(), btcSpendPath, config)
+}
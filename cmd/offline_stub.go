@@ -0,0 +1,15 @@
+//go:build !offline
+
+package main
+
+import (
+	"log"
+	"taponark"
+)
+
+// InitOffline is left out of release builds, the fake nodes of the offline
+// network are only linked in with -tags offline.
+func InitOffline(config taponark.Config) App {
+	log.Fatalf("the offline network is not built in, run with -tags offline")
+	return App{}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// REPL_PROMPT is shown before every command
+const REPL_PROMPT = ">> "
+
+// lineReader reads the lines of the REPL.
+type lineReader interface {
+	ReadLine() (string, error)
+	Close()
+}
+
+// newLineReader edits lines with history and tab completion on a terminal,
+// piped input is read line by line.
+func newLineReader(complete func(line string, pos int) (string, int, bool)) lineReader {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return &pipeReader{bufio.NewReader(os.Stdin)}
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		log.Printf("cannot enable line editing %v", err)
+		return &pipeReader{bufio.NewReader(os.Stdin)}
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, REPL_PROMPT)
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return complete(line, pos)
+	}
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		terminal.SetSize(width, height)
+	}
+
+	// Raw mode needs the terminal to return the carriage on every new line
+	log.SetOutput(terminal)
+
+	return &terminalReader{terminal, fd, oldState}
+}
+
+// terminalReader reads lines of a terminal in raw mode.
+type terminalReader struct {
+	terminal *term.Terminal
+	fd       int
+	oldState *term.State
+}
+
+func (r *terminalReader) ReadLine() (string, error) {
+	return r.terminal.ReadLine()
+}
+
+func (r *terminalReader) Close() {
+	log.SetOutput(os.Stderr)
+	term.Restore(r.fd, r.oldState)
+}
+
+// pipeReader reads lines of piped input.
+type pipeReader struct {
+	reader *bufio.Reader
+}
+
+func (r *pipeReader) ReadLine() (string, error) {
+	fmt.Print(REPL_PROMPT)
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+
+	return strings.TrimSpace(line), err
+}
+
+func (r *pipeReader) Close() {}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"taponark"
+)
+
+// MintResult is the asset minted by mint
+type MintResult struct {
+	AssetId string `json:"asset_id"`
+}
+
+// DepositResult is the deposit address of the boarding user
+type DepositResult struct {
+	Address string `json:"address"`
+}
+
+// BoardingResult is a boarding output pair of board, either side may be empty
+type BoardingResult struct {
+	AssetId       string `json:"asset_id,omitempty"`
+	AssetAmount   uint64 `json:"asset_amount,omitempty"`
+	AssetOutpoint string `json:"asset_outpoint,omitempty"`
+	BtcAmount     uint64 `json:"btc_amount,omitempty"`
+	BtcOutpoint   string `json:"btc_outpoint,omitempty"`
+}
+
+// BoardResult lists the boardings of board, one per asset
+type BoardResult struct {
+	Boardings []BoardingResult `json:"boardings"`
+}
+
+// RoundResult is a round of round, coexit or swap. Intents registered with the
+// scheduler have no round yet.
+type RoundResult struct {
+	Registered   bool            `json:"registered,omitempty"`
+	Txid         string          `json:"txid,omitempty"`
+	GenesisPoint string          `json:"genesis_point,omitempty"`
+	Tree         *TreeNodeResult `json:"tree,omitempty"`
+}
+
+// TreeNodeResult is a transaction of the round tree, leaves are numbered from
+// the left as exit -leaf expects
+type TreeNodeResult struct {
+	Txid    string             `json:"txid"`
+	Leaf    *int               `json:"leaf,omitempty"`
+	Outputs []TreeOutputResult `json:"outputs"`
+}
+
+// TreeOutputResult is an output of a tree transaction, branch outputs carry the
+// node spending them
+type TreeOutputResult struct {
+	Type        string          `json:"type"`
+	AssetAmount uint64          `json:"asset_amount,omitempty"`
+	BtcAmount   int64           `json:"btc_amount,omitempty"`
+	Node        *TreeNodeResult `json:"node,omitempty"`
+}
+
+// ExitResult lists the tree transactions broadcast by an exit
+type ExitResult struct {
+	Txids       []string `json:"txids"`
+	AssetProofs int      `json:"asset_proofs"`
+}
+
+// UploadResult is the proof uploaded to the exit user
+type UploadResult struct {
+	GenesisPoint string `json:"genesis_point"`
+	Remaining    int    `json:"remaining"`
+}
+
+// AddressPaymentResult is an out of round payment claimed by the exit user
+type AddressPaymentResult struct {
+	Address  string `json:"address"`
+	Outpoint string `json:"outpoint"`
+}
+
+// PaymentRequestResult is a payment request paid and claimed by the exit user
+type PaymentRequestResult struct {
+	Request string               `json:"request"`
+	Amount  uint64               `json:"amount"`
+	Memo    string               `json:"memo"`
+	Receipt PaymentReceiptResult `json:"receipt"`
+}
+
+// PaymentReceiptResult is the receipt proving a paid payment request
+type PaymentReceiptResult struct {
+	Outpoint     string `json:"outpoint"`
+	GenesisPoint string `json:"genesis_point"`
+	ProofFile    string `json:"proof_file"`
+	// Verified is false if the paid receipt did not verify against the chain
+	Verified bool `json:"verified"`
+}
+
+// UserBalance is the balance of a user
+type UserBalance struct {
+	Asset uint64 `json:"asset"`
+	Btc   int64  `json:"btc"`
+}
+
+// BalanceResult is the balance of both users
+type BalanceResult struct {
+	AssetId      string      `json:"asset_id"`
+	BoardingUser UserBalance `json:"boarding_user"`
+	ExitUser     UserBalance `json:"exit_user"`
+}
+
+// roundResult describes a finalized round with its tree.
+func roundResult(round taponark.Round) RoundResult {
+	return RoundResult{
+		Txid:         round.Txid().String(),
+		GenesisPoint: round.GenesisPoint,
+		Tree:         treeResult(round.RoundTree.Root),
+	}
+}
+
+// treeResult describes the tree below root.
+func treeResult(root *taponark.RoundTreeNode) *TreeNodeResult {
+	leafIndex := 0
+
+	var describe func(node *taponark.RoundTreeNode) *TreeNodeResult
+	describe = func(node *taponark.RoundTreeNode) *TreeNodeResult {
+		result := &TreeNodeResult{Txid: node.Transaction.TxHash().String()}
+		if node.NodeType == taponark.NodeTypeLeaf {
+			index := leafIndex
+			result.Leaf = &index
+			leafIndex++
+		}
+
+		for _, output := range []taponark.NodeOutput{node.LeftOutput, node.RightOutput} {
+			outputResult := TreeOutputResult{
+				Type:        outputTypeName(output.OutputType),
+				AssetAmount: output.AssetAmount,
+				BtcAmount:   output.BTCAmount,
+			}
+			if output.Node != nil {
+				outputResult.Node = describe(output.Node)
+			}
+			result.Outputs = append(result.Outputs, outputResult)
+		}
+
+		return result
+	}
+
+	return describe(root)
+}
+
+func outputTypeName(outputType taponark.OutputType) string {
+	switch outputType {
+	case taponark.OutputTypeAsset:
+		return "asset"
+	case taponark.OutputTypeBTC:
+		return "btc"
+	default:
+		return "colored"
+	}
+}
+
+// exitTxids returns the tree transactions below root an exit of leaf
+// broadcasts, every transaction for a nil leaf.
+func exitTxids(root *taponark.RoundTreeNode, leaf *taponark.RoundTreeNode) []string {
+	var txids []string
+
+	var visit func(node *taponark.RoundTreeNode)
+	visit = func(node *taponark.RoundTreeNode) {
+		if node == nil {
+			return
+		}
+		if leaf != nil && !containsLeaf(node, leaf) {
+			return
+		}
+
+		txids = append(txids, node.Transaction.TxHash().String())
+		visit(node.LeftChild())
+		visit(node.RightChild())
+	}
+	visit(root)
+
+	return txids
+}
+
+func containsLeaf(node *taponark.RoundTreeNode, leaf *taponark.RoundTreeNode) bool {
+	for _, other := range taponark.RoundLeaves(node) {
+		if other == leaf {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,6 +5,12 @@ type TapClientConfig struct {
 	Port      string `yaml:"port"`
 	Container string `yaml:"container"`
 	Hostname  string `yaml:"hostname"`
+
+	// RecordFile records every gRPC call to the daemon into a tape file.
+	// ReplayFile serves the calls from such a tape instead of dialing the
+	// daemon.
+	RecordFile string `yaml:"record_file,omitempty"`
+	ReplayFile string `yaml:"replay_file,omitempty"`
 }
 
 type LndClientConfig struct {
@@ -12,13 +18,29 @@ type LndClientConfig struct {
 	Port      string `yaml:"port"`
 	Container string `yaml:"container"`
 	Hostname  string `yaml:"hostname"`
+
+	// RecordFile records every gRPC call to the daemon into a tape file.
+	// ReplayFile serves the calls from such a tape instead of dialing the
+	// daemon.
+	RecordFile string `yaml:"record_file,omitempty"`
+	ReplayFile string `yaml:"replay_file,omitempty"`
 }
 
 type BitcoinClientConfig struct {
-	Host     string `yaml:"host"`
-	Port     string `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
+	// Backend selects the chain backend, either "bitcoind" (default) or
+	// "esplora".
+	Backend    string `yaml:"backend"`
+	Host       string `yaml:"host"`
+	Port       string `yaml:"port"`
+	User       string `yaml:"user"`
+	Password   string `yaml:"password"`
+	EsploraUrl string `yaml:"esplora_url"`
+
+	// RecordFile records every chain backend call into a tape file.
+	// ReplayFile serves the calls from such a tape instead of reaching the
+	// chain.
+	RecordFile string `yaml:"record_file,omitempty"`
+	ReplayFile string `yaml:"replay_file,omitempty"`
 }
 
 type Config struct {
@@ -35,5 +57,19 @@ type Config struct {
 
 	Timeout int64 `yaml:"timeout"`
 
+	// ServerRoundLiquidity is the amount of BTC in sats the server adds to
+	// every round from its own wallet to fund users' BTC VTXOs.
+	ServerRoundLiquidity uint64 `yaml:"server_round_liquidity"`
+
+	// BtcSpendPath selects how the cooperative path of BTC anchors is
+	// spent, either "script" or "musig2".
+	BtcSpendPath string `yaml:"btc_spend_path"`
+
+	// RoundInterval runs a round every that many seconds, RoundMinIntents
+	// runs one as soon as that many intents are pending. Both zero leaves
+	// rounds to the round command.
+	RoundInterval   int64 `yaml:"round_interval"`
+	RoundMinIntents int   `yaml:"round_min_intents"`
+
 	SignetChallenge *string `yaml:"signet_challenge,omitempty"`
 }
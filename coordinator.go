@@ -0,0 +1,384 @@
+package taponark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// RoundPhase is the state of the round coordinator.
+type RoundPhase int
+
+const (
+	// RoundPhaseIdle accepts no intents until registration opens.
+	RoundPhaseIdle RoundPhase = iota
+	// RoundPhaseRegistration accepts intents for the next round.
+	RoundPhaseRegistration
+	// RoundPhaseSigning builds the round and collects the nonces and
+	// signatures of every participant.
+	RoundPhaseSigning
+	// RoundPhaseFinalized broadcast the round, it confirmed.
+	RoundPhaseFinalized
+	// RoundPhaseFailed gave up on the round, its intents stay pending.
+	RoundPhaseFailed
+)
+
+func (p RoundPhase) String() string {
+	switch p {
+	case RoundPhaseIdle:
+		return "idle"
+	case RoundPhaseRegistration:
+		return "registration"
+	case RoundPhaseSigning:
+		return "signing"
+	case RoundPhaseFinalized:
+		return "finalized"
+	case RoundPhaseFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+var (
+	// ErrNoIntents is returned when a round closes without any intent.
+	ErrNoIntents = errors.New("no intents registered for the round")
+
+	// ErrRegistrationClosed is returned for intents arriving outside the
+	// registration phase.
+	ErrRegistrationClosed = errors.New("round registration is closed")
+
+	// ErrIntentRegistered is returned for a boarding input that is already
+	// pending in another intent.
+	ErrIntentRegistered = errors.New("boarding input is already registered")
+
+	// ErrBoardingSettled is returned for a boarding input that an earlier
+	// round already spent.
+	ErrBoardingSettled = errors.New("boarding input is already settled")
+)
+
+// RoundIntent asks the coordinator to move a boarded transfer into a round.
+// The boarding user signs the round inputs, the leaf owners receive the tree
+// leaves and co-sign every node above them, exits are paid by the round
+// transaction itself. An intent with an Address has no leaf owners, its
+// single leaf pays to the address.
+type RoundIntent struct {
+	Id         string
+	AssetId    []byte
+	Boarding   ArkBoardingTransfer
+	LeafOwners []*TapClient
+	Address    *ArkAddress
+	Exits      []CollaborativeExit
+}
+
+// participants returns every client signing for the intent.
+func (i RoundIntent) participants() []*TapClient {
+	return append([]*TapClient{i.Boarding.user}, i.LeafOwners...)
+}
+
+func (i RoundIntent) boardingOutpoints() []wire.OutPoint {
+	var outpoints []wire.OutPoint
+	if i.Boarding.btcTransferDetails.outpoint != nil {
+		outpoints = append(outpoints, *i.Boarding.btcTransferDetails.outpoint)
+	}
+	if assetOutput := i.Boarding.AssetTransferDetails.AssetTransferOutput; assetOutput != nil {
+		outpoint, err := wire.NewOutPointFromString(assetOutput.Anchor.Outpoint)
+		if err == nil {
+			outpoints = append(outpoints, *outpoint)
+		}
+	}
+
+	return outpoints
+}
+
+type RoundCoordinatorConfig struct {
+	// RegistrationWindow is how long Run accepts intents before building
+	// the round.
+	RegistrationWindow time.Duration
+	// MaxSigningAttempts bounds how often a round is restarted after
+	// dropping participants that failed to sign.
+	MaxSigningAttempts int
+	// ServerBtcAmount is funded into every round from the server wallet.
+	ServerBtcAmount uint64
+	// OnPhase is called on every phase change, it must not block.
+	OnPhase func(phase RoundPhase)
+}
+
+// RoundCoordinator runs rounds on the server. It opens a registration window,
+// accepts intents, builds the round and its tree, and collects the nonces and
+// signatures of all participants through their Signer. A participant that
+// fails to answer within SIGNER_TIMEOUT, or answers with an invalid nonce or
+// signature, is dropped together with its intents and the round is rebuilt
+// without it.
+//
+// Each round settles the asset of a single boarding input, so the oldest
+// intent goes first and the others stay pending for the next round.
+type RoundCoordinator struct {
+	cfg           RoundCoordinatorConfig
+	server        *TapClient
+	bitcoinClient BitcoinClient
+
+	mu      sync.Mutex
+	phase   RoundPhase
+	pending []RoundIntent
+	settled map[wire.OutPoint]struct{}
+
+	// registered is signalled whenever an intent is registered
+	registered chan struct{}
+}
+
+func NewRoundCoordinator(cfg RoundCoordinatorConfig, server *TapClient, bitcoinClient BitcoinClient) *RoundCoordinator {
+	if cfg.MaxSigningAttempts <= 0 {
+		cfg.MaxSigningAttempts = 1
+	}
+
+	return &RoundCoordinator{
+		cfg:           cfg,
+		server:        server,
+		bitcoinClient: bitcoinClient,
+		settled:       make(map[wire.OutPoint]struct{}),
+		registered:    make(chan struct{}, 1),
+	}
+}
+
+func (c *RoundCoordinator) Phase() RoundPhase {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.phase
+}
+
+// Pending returns the number of intents waiting for a round.
+func (c *RoundCoordinator) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.pending)
+}
+
+// OpenRegistration starts accepting intents for the next round.
+func (c *RoundCoordinator) OpenRegistration() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.phase {
+	case RoundPhaseRegistration:
+		return nil
+	case RoundPhaseSigning:
+		return fmt.Errorf("cannot open registration while a round is %s", c.phase)
+	}
+
+	c.setPhase(RoundPhaseRegistration)
+	return nil
+}
+
+// RegisterIntent queues the intent for the next round and returns its id.
+func (c *RoundCoordinator) RegisterIntent(intent RoundIntent) (string, error) {
+	if intent.Boarding.user == nil {
+		return "", fmt.Errorf("intent has no boarding user")
+	}
+	if intent.Boarding.AssetTransferDetails.assetBoardingAmount == 0 {
+		return "", fmt.Errorf("intent carries no asset, btc boarded alone is settled in swap rounds")
+	}
+	if intent.Boarding.BtcAmount() == 0 && c.cfg.ServerBtcAmount == 0 {
+		return "", fmt.Errorf("intent carries no btc and the server funds no round liquidity")
+	}
+	if intent.Address != nil && len(intent.LeafOwners) != 0 {
+		return "", fmt.Errorf("intent paying an ark address takes no leaf owners")
+	}
+	if intent.Address == nil && len(intent.LeafOwners) != 1<<(ROUND_TREE_LEVEL-1) {
+		return "", fmt.Errorf("intent needs %d leaf owners, got %d", 1<<(ROUND_TREE_LEVEL-1), len(intent.LeafOwners))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.phase != RoundPhaseRegistration {
+		return "", ErrRegistrationClosed
+	}
+
+	// A boarding input can only be spent by one round
+	for _, outpoint := range intent.boardingOutpoints() {
+		if _, ok := c.settled[outpoint]; ok {
+			return "", fmt.Errorf("%w %s", ErrBoardingSettled, outpoint)
+		}
+	}
+	for _, pending := range c.pending {
+		for _, outpoint := range intent.boardingOutpoints() {
+			if slices.Contains(pending.boardingOutpoints(), outpoint) {
+				return "", fmt.Errorf("%w %s by intent %s", ErrIntentRegistered, outpoint, pending.Id)
+			}
+		}
+	}
+
+	id, err := RandomHexString(8)
+	if err != nil {
+		return "", fmt.Errorf("cannot create intent id %v", err)
+	}
+	intent.Id = id
+	c.pending = append(c.pending, intent)
+
+	select {
+	case c.registered <- struct{}{}:
+	default:
+	}
+
+	log.Printf("Registered round intent %s", id)
+	return id, nil
+}
+
+// Run opens registration, waits for the registration window or ctx and then
+// runs the round.
+func (c *RoundCoordinator) Run(ctx context.Context) (Round, error) {
+	if err := c.OpenRegistration(); err != nil {
+		return Round{}, err
+	}
+
+	select {
+	case <-time.After(c.cfg.RegistrationWindow):
+	case <-ctx.Done():
+	}
+
+	return c.RunRound()
+}
+
+// RunRound closes registration and settles the oldest pending intent. The
+// round is restarted without any participant that failed to sign. A round
+// failing after broadcast returns a RoundBroadcastError and still settles the
+// intent, as its transaction can confirm.
+func (c *RoundCoordinator) RunRound() (Round, error) {
+	c.mu.Lock()
+	if c.phase != RoundPhaseRegistration {
+		c.mu.Unlock()
+		return Round{}, fmt.Errorf("cannot run a round in %s phase", c.phase)
+	}
+	c.setPhase(RoundPhaseSigning)
+	c.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < c.cfg.MaxSigningAttempts; attempt++ {
+		intent, ok := c.nextIntent()
+		if !ok && lastErr != nil {
+			break
+		}
+		if !ok {
+			c.finish(RoundPhaseFailed)
+			return Round{}, ErrNoIntents
+		}
+
+		round, err := c.constructRound(intent)
+		if err == nil {
+			c.settleIntent(intent)
+			c.finish(RoundPhaseFinalized)
+			return round, nil
+		}
+
+		// A broadcast round can still confirm, its boarding inputs are
+		// not retried in another round
+		var broadcastErr *RoundBroadcastError
+		if errors.As(err, &broadcastErr) {
+			c.settleIntent(intent)
+			c.finish(RoundPhaseFailed)
+			return Round{}, fmt.Errorf("round failed %w", err)
+		}
+		lastErr = err
+
+		participant := c.blamedParticipant(intent, err)
+		if participant == nil {
+			break
+		}
+
+		dropped := c.dropParticipant(participant)
+		log.Printf("round attempt %d failed, dropped %d intents of an unresponsive participant: %v", attempt+1, dropped, err)
+	}
+
+	c.finish(RoundPhaseFailed)
+	return Round{}, fmt.Errorf("round failed %w", lastErr)
+}
+
+// constructRound builds and broadcasts the round of the intent.
+func (c *RoundCoordinator) constructRound(intent RoundIntent) (Round, error) {
+	if intent.Address != nil {
+		return ConstructAndBroadcastAddressRound(intent.AssetId, intent.Boarding, c.cfg.ServerBtcAmount, *intent.Address, c.server, c.bitcoinClient, intent.Exits...)
+	}
+
+	return ConstructAndBroadcastRound(intent.AssetId, intent.Boarding, c.cfg.ServerBtcAmount, intent.LeafOwners, c.server, c.bitcoinClient, intent.Exits...)
+}
+
+func (c *RoundCoordinator) setPhase(phase RoundPhase) {
+	log.Printf("Round coordinator %s -> %s", c.phase, phase)
+	c.phase = phase
+
+	if c.cfg.OnPhase != nil {
+		c.cfg.OnPhase(phase)
+	}
+}
+
+func (c *RoundCoordinator) finish(phase RoundPhase) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setPhase(phase)
+}
+
+func (c *RoundCoordinator) nextIntent() (RoundIntent, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.pending) == 0 {
+		return RoundIntent{}, false
+	}
+
+	return c.pending[0], true
+}
+
+// settleIntent removes the intent and remembers its spent boarding inputs.
+func (c *RoundCoordinator) settleIntent(settled RoundIntent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, outpoint := range settled.boardingOutpoints() {
+		c.settled[outpoint] = struct{}{}
+	}
+	c.pending = slices.DeleteFunc(c.pending, func(intent RoundIntent) bool {
+		return intent.Id == settled.Id
+	})
+}
+
+// blamedParticipant maps a signer error of the round onto the participant of
+// the intent owning that signer. The server is never blamed.
+func (c *RoundCoordinator) blamedParticipant(intent RoundIntent, err error) *TapClient {
+	var signerErr *SignerError
+	if !errors.As(err, &signerErr) {
+		return nil
+	}
+
+	for _, participant := range intent.participants() {
+		if participant != c.server && participant.signer == signerErr.Signer {
+			return participant
+		}
+	}
+
+	return nil
+}
+
+// dropParticipant removes every pending intent the participant signs for.
+func (c *RoundCoordinator) dropParticipant(participant *TapClient) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	before := len(c.pending)
+	c.pending = slices.DeleteFunc(c.pending, func(intent RoundIntent) bool {
+		return slices.ContainsFunc(intent.participants(), func(client *TapClient) bool {
+			return client.signer == participant.signer
+		})
+	})
+
+	return before - len(c.pending)
+}
@@ -0,0 +1,219 @@
+package taponark
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+// EsploraBackend implements ChainBackend against an Esplora HTTP API such as
+// the ones served by blockstream.info or mutinynet.com/api.
+type EsploraBackend struct {
+	baseUrl string
+	client  *http.Client
+}
+
+type esploraMempoolAcceptResult struct {
+	Txid         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	RejectReason string `json:"reject-reason"`
+}
+
+type esploraTxStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+}
+
+func NewEsploraBackend(baseUrl string, timeout time.Duration) *EsploraBackend {
+	return &EsploraBackend{
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *EsploraBackend) BroadcastTransaction(tx *wire.MsgTx) (*chainhash.Hash, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("cannot serialize transaction %v", err)
+	}
+
+	body, err := e.request(http.MethodPost, "/tx", strings.NewReader(hex.EncodeToString(buf.Bytes())))
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(strings.TrimSpace(string(body)))
+}
+
+func (e *EsploraBackend) GetTransaction(txid *chainhash.Hash) (*wire.MsgTx, error) {
+	body, err := e.request(http.MethodGet, "/tx/"+txid.String()+"/hex", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx, err := hex.DecodeString(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode transaction hex %v", err)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		return nil, fmt.Errorf("cannot deserialize transaction %v", err)
+	}
+
+	return tx, nil
+}
+
+func (e *EsploraBackend) GetTransactionStatus(txid *chainhash.Hash) (TxStatus, error) {
+	body, err := e.request(http.MethodGet, "/tx/"+txid.String()+"/status", nil)
+	if err != nil {
+		return TxStatus{}, err
+	}
+
+	var status esploraTxStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return TxStatus{}, fmt.Errorf("cannot decode transaction status %v", err)
+	}
+
+	if !status.Confirmed {
+		return TxStatus{}, nil
+	}
+
+	blockHash, err := chainhash.NewHashFromStr(status.BlockHash)
+	if err != nil {
+		return TxStatus{}, fmt.Errorf("cannot decode block hash %v", err)
+	}
+
+	return TxStatus{true, blockHash, status.BlockHeight}, nil
+}
+
+func (e *EsploraBackend) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	body, err := e.request(http.MethodGet, "/block/"+blockHash.String()+"/raw", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &wire.MsgBlock{}
+	if err := block.Deserialize(bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("cannot deserialize block %v", err)
+	}
+
+	return block, nil
+}
+
+func (e *EsploraBackend) GetBlockCount() (int64, error) {
+	body, err := e.request(http.MethodGet, "/blocks/tip/height", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+}
+
+// TestMempoolAccept uses the /txs/test endpoint, which is served by
+// mempool.space flavoured Esplora instances such as mutinynet.
+func (e *EsploraBackend) TestMempoolAccept(txs []*wire.MsgTx) ([]MempoolAcceptResult, error) {
+	rawTxs := make([]string, len(txs))
+	for i, tx := range txs {
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("cannot serialize transaction %v", err)
+		}
+		rawTxs[i] = hex.EncodeToString(buf.Bytes())
+	}
+
+	reqBody, err := json.Marshal(rawTxs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode transactions %v", err)
+	}
+
+	body, err := e.request(http.MethodPost, "/txs/test", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []esploraMempoolAcceptResult
+	if err := json.Unmarshal(body, &results); err != nil {
+		return nil, fmt.Errorf("cannot decode mempool accept results %v", err)
+	}
+
+	acceptResults := make([]MempoolAcceptResult, len(results))
+	for i, result := range results {
+		acceptResults[i] = MempoolAcceptResult(result)
+	}
+
+	return acceptResults, nil
+}
+
+// EstimateFee returns the estimate for the closest target Esplora provides
+// that is not above confTarget, Esplora reports rates in sat/vbyte.
+func (e *EsploraBackend) EstimateFee(confTarget uint32) (chainfee.SatPerKWeight, error) {
+	body, err := e.request(http.MethodGet, "/fee-estimates", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var estimates map[string]float64
+	if err := json.Unmarshal(body, &estimates); err != nil {
+		return 0, fmt.Errorf("cannot decode fee estimates %v", err)
+	}
+
+	targets := make([]int, 0, len(estimates))
+	for target := range estimates {
+		parsedTarget, err := strconv.Atoi(target)
+		if err != nil {
+			continue
+		}
+		targets = append(targets, parsedTarget)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(targets)))
+
+	for _, target := range targets {
+		if target <= int(confTarget) {
+			satPerVByte := estimates[strconv.Itoa(target)]
+			return chainfee.SatPerKVByte(satPerVByte * 1000).FeePerKWeight(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no fee estimate available for target %d", confTarget)
+}
+
+func (e *EsploraBackend) request(method, path string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, e.baseUrl+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create esplora request %v", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("esplora request %s failed %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read esplora response %v", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && strings.HasPrefix(path, "/tx/"):
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, strings.TrimSpace(string(respBody)))
+
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("esplora request %s failed with status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,140 @@
+package taponark
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/address"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/tappsbt"
+	"github.com/lightninglabs/taproot-assets/taprpc"
+	"github.com/lightningnetwork/lnd/keychain"
+)
+
+// CollaborativeExit pays part of the boarded funds to on-chain outputs of the
+// round transaction instead of the tree, so the user never has to broadcast a
+// tree path. Either side may be left empty.
+type CollaborativeExit struct {
+	// AssetAddr receives its amount of the asset, the transfer proof is
+	// imported into the tapd of Recipient once the round confirms.
+	AssetAddr *taprpc.Addr
+	Recipient *TapClient
+
+	// BtcInternalKey receives BtcAmount sats in a BIP-0086 output. The key
+	// is needed for the exclusion proofs of the asset outputs.
+	BtcInternalKey *btcec.PublicKey
+	BtcAmount      uint64
+}
+
+// collaborativeExitAmounts returns the asset and BTC leaving the round, the
+// BTC includes the anchor value of every asset exit.
+func collaborativeExitAmounts(assetId []byte, exits []CollaborativeExit) (uint64, uint64, error) {
+	var assetAmount, btcAmount uint64
+	for i, exit := range exits {
+		if exit.AssetAddr != nil {
+			if exit.Recipient == nil {
+				return 0, 0, fmt.Errorf("asset exit %d has no recipient", i)
+			}
+			if asset.ID(exit.AssetAddr.AssetId) != asset.ID(assetId) {
+				return 0, 0, fmt.Errorf("asset exit %d is for another asset", i)
+			}
+			if exit.AssetAddr.Amount == 0 {
+				return 0, 0, fmt.Errorf("asset exit %d has no amount", i)
+			}
+			assetAmount += exit.AssetAddr.Amount
+			btcAmount += DUMMY_ASSET_BTC_AMOUNT
+		}
+
+		if exit.BtcAmount > 0 {
+			if exit.BtcInternalKey == nil {
+				return 0, 0, fmt.Errorf("btc exit %d has no internal key", i)
+			}
+			btcAmount += exit.BtcAmount
+		}
+	}
+
+	return assetAmount, btcAmount, nil
+}
+
+// addExitAssetOutputs sends the asset exits from the round packet, each to its
+// own anchor output after the round output. It returns the output index of
+// every exit, which is both its virtual and anchor output index, or -1 for
+// exits without asset.
+func addExitAssetOutputs(vPkt *tappsbt.VPacket, exits []CollaborativeExit, tapParams *address.ChainParams) ([]int, error) {
+	outputIndexes := make([]int, len(exits))
+	for i, exit := range exits {
+		outputIndexes[i] = -1
+		if exit.AssetAddr == nil {
+			continue
+		}
+
+		tapAddr, err := address.DecodeAddress(exit.AssetAddr.Encoded, tapParams)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode exit address %d %v", i, err)
+		}
+
+		anchorOutputIndex := uint32(len(vPkt.Outputs))
+		tappsbt.AddOutput(vPkt, tapAddr.Amount, asset.NewScriptKey(&tapAddr.ScriptKey), anchorOutputIndex,
+			keychain.KeyDescriptor{
+				PubKey: &tapAddr.InternalKey,
+			}, tapAddr.AssetVersion)
+		vPkt.Outputs[anchorOutputIndex].AnchorOutputTapscriptSibling = tapAddr.TapscriptSibling
+
+		outputIndexes[i] = int(anchorOutputIndex)
+	}
+
+	if len(vPkt.Outputs) > 1 {
+		vPkt.Outputs[ROUND_ROOT_ASSET_OUTPUT_INDEX].Type = tappsbt.TypeSplitRoot
+	}
+
+	return outputIndexes, nil
+}
+
+// addExitBtcOutputs appends the BTC exits to the round transaction.
+func addExitBtcOutputs(pkt *psbt.Packet, exits []CollaborativeExit, assetOutputIndexes []int) error {
+	for i, exit := range exits {
+		// Asset exits keep the dummy amount as their anchor value
+		if assetOutputIndexes[i] >= 0 {
+			pkt.UnsignedTx.TxOut[assetOutputIndexes[i]].Value = DUMMY_ASSET_BTC_AMOUNT
+		}
+
+		if exit.BtcAmount == 0 {
+			continue
+		}
+
+		err := addBtcOutput(pkt, exit.BtcAmount, exit.BtcInternalKey)
+		if err != nil {
+			return fmt.Errorf("cannot add btc exit %d %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// deliverExitProofs appends the round transaction to the proofs of the asset
+// exits and imports them into the recipients' tapd.
+func deliverExitProofs(rawProofFile []byte, genesisPoint string, roundTx *wire.MsgTx, sendTxResult BitcoinSendTxResult, vPkt *tappsbt.VPacket, exits []CollaborativeExit, assetOutputIndexes []int) error {
+	for i, exit := range exits {
+		if assetOutputIndexes[i] < 0 {
+			continue
+		}
+
+		exitProof := vPkt.Outputs[assetOutputIndexes[i]].ProofSuffix
+		exitProofFile, err := AppendProof(rawProofFile, roundTx, exitProof, sendTxResult)
+		if err != nil {
+			return fmt.Errorf("failed to update exit %d proof %v", i, err)
+		}
+
+		err = SubmitProof(genesisPoint, exitProofFile, exit.Recipient)
+		if err != nil {
+			return fmt.Errorf("failed to import exit %d proof %v", i, err)
+		}
+
+		log.Printf("Collaborative exit of %d asset to %s", exit.AssetAddr.Amount, exit.AssetAddr.Encoded)
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package taponark
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcExchange is one recorded unary call. Messages are kept in the
+// deterministic proto wire format so they replay, and compare, byte for byte.
+type grpcExchange struct {
+	Method   string     `json:"method"`
+	Request  []byte     `json:"request"`
+	Response []byte     `json:"response,omitempty"`
+	Code     codes.Code `json:"code,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// GrpcRecorder writes every unary call made through its interceptor to a
+// tape file, one JSON object per line, so that a session against real
+// daemons can be replayed later.
+type GrpcRecorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func NewGrpcRecorder(path string) (*GrpcRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create grpc tape %v", err)
+	}
+
+	return &GrpcRecorder{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (r *GrpcRecorder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		callErr := invoker(ctx, method, req, reply, cc, opts...)
+
+		exchange := grpcExchange{Method: method}
+		var err error
+		exchange.Request, err = marshalGrpcMessage(req)
+		if err != nil {
+			return err
+		}
+		if callErr != nil {
+			callStatus := status.Convert(callErr)
+			exchange.Code = callStatus.Code()
+			exchange.Error = callStatus.Message()
+		} else {
+			exchange.Response, err = marshalGrpcMessage(reply)
+			if err != nil {
+				return err
+			}
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err := r.encoder.Encode(exchange); err != nil {
+			return fmt.Errorf("cannot record %s %v", method, err)
+		}
+
+		return callErr
+	}
+}
+
+func (r *GrpcRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+// GrpcReplayer serves the calls of a tape back in place of the daemon. Calls
+// are matched by method in recorded order and must carry the recorded
+// request, a session that diverged from the recording fails the call.
+type GrpcReplayer struct {
+	mu        sync.Mutex
+	path      string
+	exchanges map[string][]grpcExchange
+}
+
+func NewGrpcReplayer(path string) (*GrpcReplayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open grpc tape %v", err)
+	}
+	defer file.Close()
+
+	replayer := &GrpcReplayer{path: path, exchanges: make(map[string][]grpcExchange)}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, GRPC_TAPE_MAX_LINE)
+	for scanner.Scan() {
+		var exchange grpcExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("cannot decode grpc tape %v", err)
+		}
+		replayer.exchanges[exchange.Method] = append(replayer.exchanges[exchange.Method], exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read grpc tape %v", err)
+	}
+
+	return replayer, nil
+}
+
+func (r *GrpcReplayer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(_ context.Context, method string, req, reply interface{},
+		_ *grpc.ClientConn, _ grpc.UnaryInvoker, _ ...grpc.CallOption) error {
+
+		request, err := marshalGrpcMessage(req)
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		queue := r.exchanges[method]
+		if len(queue) == 0 {
+			r.mu.Unlock()
+			return status.Errorf(codes.Unavailable, "grpc tape has no more calls to %s", method)
+		}
+		exchange := queue[0]
+		r.exchanges[method] = queue[1:]
+		r.mu.Unlock()
+
+		if !bytes.Equal(request, exchange.Request) {
+			return status.Errorf(codes.FailedPrecondition, "grpc tape request of %s differs from the recording", method)
+		}
+
+		if exchange.Code != codes.OK {
+			return status.Error(exchange.Code, exchange.Error)
+		}
+
+		message, ok := reply.(proto.Message)
+		if !ok {
+			return fmt.Errorf("cannot replay %s into %T", method, reply)
+		}
+		if err := proto.Unmarshal(exchange.Response, message); err != nil {
+			return fmt.Errorf("cannot replay %s %v", method, err)
+		}
+
+		return nil
+	}
+}
+
+// Remaining returns the number of recorded calls not replayed yet, a replayed
+// session that diverged from the recording leaves calls behind.
+func (r *GrpcReplayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := 0
+	for _, queue := range r.exchanges {
+		remaining += len(queue)
+	}
+
+	return remaining
+}
+
+// Close fails if calls of the tape were never replayed.
+func (r *GrpcReplayer) Close() error {
+	if remaining := r.Remaining(); remaining != 0 {
+		return fmt.Errorf("grpc tape %s has %d calls left, the session diverged from the recording", r.path, remaining)
+	}
+
+	return nil
+}
+
+// NewReplayConn returns a connection answering every unary call from the
+// tape. It never reaches the network, so no TLS certificate or macaroon is
+// needed.
+func NewReplayConn(replayer *GrpcReplayer) (*grpc.ClientConn, error) {
+	conn, err := grpc.Dial(
+		"passthrough:///grpc-tape",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(replayer.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create replay connection %v", err)
+	}
+
+	return conn, nil
+}
+
+func marshalGrpcMessage(message interface{}) ([]byte, error) {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cannot record message of type %T", message)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(protoMessage)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal %T %v", message, err)
+	}
+
+	return data, nil
+}
+
+// tapeConn dials a daemon through dial, recording its calls if recordFile is
+// set. If replayFile is set the daemon is never dialed and the calls are
+// served from the tape instead, closing the connection then fails if the
+// tape was not replayed to its end.
+func tapeConn(recordFile, replayFile string,
+	dial func(opts ...grpc.DialOption) (*grpc.ClientConn, error)) (*grpc.ClientConn, func() error, error) {
+
+	if replayFile != "" {
+		replayer, err := NewGrpcReplayer(replayFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		conn, err := NewReplayConn(replayer)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cleanUp := func() error {
+			conn.Close()
+			return replayer.Close()
+		}
+
+		return conn, cleanUp, nil
+	}
+
+	if recordFile == "" {
+		conn, err := dial()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return conn, conn.Close, nil
+	}
+
+	recorder, err := NewGrpcRecorder(recordFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := dial(grpc.WithChainUnaryInterceptor(recorder.UnaryClientInterceptor()))
+	if err != nil {
+		recorder.Close()
+		return nil, nil, err
+	}
+
+	cleanUp := func() error {
+		conn.Close()
+		return recorder.Close()
+	}
+
+	return conn, cleanUp, nil
+}
@@ -0,0 +1,85 @@
+package taponark_test
+
+import (
+	"context"
+	"path/filepath"
+	"taponark"
+	"taponark/internal/taponarktest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestGrpcTapeReplaysRecordedRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tape.json")
+
+	recorder, err := taponark.NewGrpcRecorder(path)
+	require.NoError(t, err)
+	echo := func(_ context.Context, _ string, req, reply interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		proto.Merge(reply.(proto.Message), wrapperspb.String("echo "+req.(*wrapperspb.StringValue).Value))
+		return nil
+	}
+	record := recorder.UnaryClientInterceptor()
+	for _, value := range []string{"a", "b"} {
+		require.NoError(t, record(context.Background(), "/echo", wrapperspb.String(value), &wrapperspb.StringValue{}, nil, echo))
+	}
+	require.NoError(t, recorder.Close())
+
+	replayer, err := taponark.NewGrpcReplayer(path)
+	require.NoError(t, err)
+	replay := replayer.UnaryClientInterceptor()
+
+	reply := &wrapperspb.StringValue{}
+	require.NoError(t, replay(context.Background(), "/echo", wrapperspb.String("a"), reply, nil, nil))
+	require.Equal(t, "echo a", reply.Value)
+
+	// A session that diverged asks for something else, or stops early
+	require.Error(t, replay(context.Background(), "/echo", wrapperspb.String("c"), reply, nil, nil))
+	require.Equal(t, 0, replayer.Remaining())
+	require.NoError(t, replayer.Close())
+
+	replayer, err = taponark.NewGrpcReplayer(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, replayer.Remaining())
+	require.Error(t, replayer.Close())
+}
+
+func TestChainTapeReplaysRecordedCalls(t *testing.T) {
+	esplora := taponarktest.NewFakeEsploraServer()
+	t.Cleanup(esplora.Close)
+	path := filepath.Join(t.TempDir(), "chain.json")
+
+	recorder, err := taponark.NewChainRecorder(taponark.NewEsploraBackend(esplora.URL(), testTimeout), path)
+	require.NoError(t, err)
+	height, err := recorder.GetBlockCount()
+	require.NoError(t, err)
+	var unknown chainhash.Hash
+	_, err = recorder.GetTransactionStatus(&unknown)
+	require.ErrorIs(t, err, taponark.ErrTxNotFound)
+	require.NoError(t, recorder.Close())
+
+	// The chain is gone, the tape answers
+	esplora.Close()
+	replayer, err := taponark.NewChainReplayer(path)
+	require.NoError(t, err)
+	replayedHeight, err := replayer.GetBlockCount()
+	require.NoError(t, err)
+	require.Equal(t, height, replayedHeight)
+
+	unknown[0] = 1
+	_, err = replayer.GetTransactionStatus(&unknown)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, taponark.ErrTxNotFound)
+	require.NoError(t, replayer.Close())
+
+	replayer, err = taponark.NewChainReplayer(path)
+	require.NoError(t, err)
+	unknown[0] = 0
+	_, err = replayer.GetTransactionStatus(&unknown)
+	require.ErrorIs(t, err, taponark.ErrTxNotFound)
+	require.ErrorContains(t, replayer.Close(), "1 calls left")
+}
@@ -0,0 +1,400 @@
+package taponarktest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// FakeEsploraServer is an in-process Esplora HTTP server used to exercise the
+// EsploraBackend without network access. Broadcasted transactions are kept in
+// a mempool until MineBlock is called. Inputs are not looked up, but a
+// transaction spending an output another transaction already spends is
+// rejected.
+type FakeEsploraServer struct {
+	server *httptest.Server
+
+	mu           sync.Mutex
+	mempool      []*wire.MsgTx
+	txs          map[chainhash.Hash]*wire.MsgTx
+	txBlock      map[chainhash.Hash]int64
+	spentBy      map[wire.OutPoint]chainhash.Hash
+	blocks       []*wire.MsgBlock
+	feeEstimates map[string]float64
+	rejections   map[chainhash.Hash]string
+	autoMine     bool
+
+	// Subscribers are notified of every mined block, in order, from a
+	// dedicated goroutine so that they may call back into the server. Blocks
+	// mined after Close are not notified.
+	subscribers []func(block *wire.MsgBlock, height int64)
+	notify      chan minedBlock
+	quit        chan struct{}
+	closeOnce   sync.Once
+}
+
+// esploraMempoolAcceptResult and esploraTxStatus are the JSON answers of
+// Esplora the EsploraBackend decodes.
+type esploraMempoolAcceptResult struct {
+	Txid         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	RejectReason string `json:"reject-reason"`
+}
+
+type esploraTxStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+}
+
+type minedBlock struct {
+	block  *wire.MsgBlock
+	height int64
+}
+
+func NewFakeEsploraServer() *FakeEsploraServer {
+	genesis := &wire.MsgBlock{
+		Header: wire.BlockHeader{Timestamp: time.Unix(0, 0)},
+	}
+
+	f := &FakeEsploraServer{
+		txs:          make(map[chainhash.Hash]*wire.MsgTx),
+		txBlock:      make(map[chainhash.Hash]int64),
+		spentBy:      make(map[wire.OutPoint]chainhash.Hash),
+		blocks:       []*wire.MsgBlock{genesis},
+		feeEstimates: map[string]float64{"1": 2, "6": 1, "144": 1},
+		rejections:   make(map[chainhash.Hash]string),
+		notify:       make(chan minedBlock, 100),
+		quit:         make(chan struct{}),
+	}
+	go f.notifySubscribers()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tx", f.handleBroadcast)
+	mux.HandleFunc("POST /txs/test", f.handleTestMempoolAccept)
+	mux.HandleFunc("GET /tx/{txid}/hex", f.handleTxHex)
+	mux.HandleFunc("GET /tx/{txid}/status", f.handleTxStatus)
+	mux.HandleFunc("GET /block/{hash}/raw", f.handleBlockRaw)
+	mux.HandleFunc("GET /blocks/tip/height", f.handleTipHeight)
+	mux.HandleFunc("GET /fee-estimates", f.handleFeeEstimates)
+
+	f.server = httptest.NewServer(mux)
+
+	return f
+}
+
+// URL returns the base URL to pass to NewEsploraBackend.
+func (f *FakeEsploraServer) URL() string {
+	return f.server.URL
+}
+
+func (f *FakeEsploraServer) Close() {
+	f.closeOnce.Do(func() {
+		close(f.quit)
+		f.server.Close()
+	})
+}
+
+// SetAutoMine makes every accepted broadcast get mined in its own block right
+// away, like a regtest node with a miner attached.
+func (f *FakeEsploraServer) SetAutoMine(autoMine bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.autoMine = autoMine
+}
+
+// Subscribe registers a callback that is called with every block mined from
+// now on.
+func (f *FakeEsploraServer) Subscribe(onBlock func(block *wire.MsgBlock, height int64)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subscribers = append(f.subscribers, onBlock)
+}
+
+func (f *FakeEsploraServer) notifySubscribers() {
+	for {
+		var mined minedBlock
+		select {
+		case mined = <-f.notify:
+		case <-f.quit:
+			return
+		}
+
+		f.mu.Lock()
+		subscribers := append([]func(*wire.MsgBlock, int64){}, f.subscribers...)
+		f.mu.Unlock()
+
+		for _, onBlock := range subscribers {
+			onBlock(mined.block, mined.height)
+		}
+	}
+}
+
+// SetFeeEstimates replaces the sat/vbyte estimates served by /fee-estimates.
+func (f *FakeEsploraServer) SetFeeEstimates(estimates map[string]float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.feeEstimates = estimates
+}
+
+// RejectTransaction makes mempool acceptance tests and broadcasts of the
+// transaction fail with the given reason.
+func (f *FakeEsploraServer) RejectTransaction(txid chainhash.Hash, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rejections[txid] = reason
+}
+
+// MineBlock confirms every transaction currently in the mempool in a new
+// block and returns it.
+func (f *FakeEsploraServer) MineBlock() *wire.MsgBlock {
+	f.mu.Lock()
+	block, height := f.mineBlock()
+	f.mu.Unlock()
+
+	f.publish(minedBlock{block, height})
+
+	return block
+}
+
+// publish hands the mined block to the subscribers unless the server is
+// closed.
+func (f *FakeEsploraServer) publish(mined minedBlock) {
+	select {
+	case f.notify <- mined:
+	case <-f.quit:
+	}
+}
+
+// mineBlock must be called with the lock held.
+func (f *FakeEsploraServer) mineBlock() (*wire.MsgBlock, int64) {
+	tip := f.blocks[len(f.blocks)-1]
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			PrevBlock: tip.BlockHash(),
+			Timestamp: tip.Header.Timestamp.Add(10 * time.Minute),
+		},
+		Transactions: f.mempool,
+	}
+	if len(block.Transactions) > 0 {
+		merkles := blockchain.BuildMerkleTreeStore(
+			utilTxs(block.Transactions), false,
+		)
+		block.Header.MerkleRoot = *merkles[len(merkles)-1]
+	}
+
+	height := int64(len(f.blocks))
+	for _, tx := range f.mempool {
+		f.txBlock[tx.TxHash()] = height
+	}
+
+	f.blocks = append(f.blocks, block)
+	f.mempool = nil
+
+	return block, height
+}
+
+func utilTxs(txs []*wire.MsgTx) []*btcutil.Tx {
+	utilTxs := make([]*btcutil.Tx, len(txs))
+	for i, tx := range txs {
+		utilTxs[i] = btcutil.NewTx(tx)
+	}
+
+	return utilTxs
+}
+
+func (f *FakeEsploraServer) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rawTx, err := hex.DecodeString(strings.TrimSpace(string(body)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	txid := tx.TxHash()
+	if reason, rejected := f.rejection(tx); rejected {
+		f.mu.Unlock()
+		http.Error(w, reason, http.StatusBadRequest)
+		return
+	}
+	var mined *minedBlock
+	if _, ok := f.txs[txid]; !ok {
+		f.txs[txid] = tx
+		f.mempool = append(f.mempool, tx)
+		for _, txIn := range tx.TxIn {
+			f.spentBy[txIn.PreviousOutPoint] = txid
+		}
+
+		if f.autoMine {
+			block, height := f.mineBlock()
+			mined = &minedBlock{block, height}
+		}
+	}
+	f.mu.Unlock()
+
+	if mined != nil {
+		f.publish(*mined)
+	}
+
+	fmt.Fprint(w, txid.String())
+}
+
+// rejection returns why the transaction is not accepted, it must be called
+// with the lock held.
+func (f *FakeEsploraServer) rejection(tx *wire.MsgTx) (string, bool) {
+	txid := tx.TxHash()
+	if reason, rejected := f.rejections[txid]; rejected {
+		return reason, true
+	}
+
+	for _, txIn := range tx.TxIn {
+		spender, spent := f.spentBy[txIn.PreviousOutPoint]
+		if spent && spender != txid {
+			return fmt.Sprintf("bad-txns-inputs-missingorspent, %s is spent by %s", txIn.PreviousOutPoint, spender), true
+		}
+	}
+
+	return "", false
+}
+
+func (f *FakeEsploraServer) handleTestMempoolAccept(w http.ResponseWriter, r *http.Request) {
+	var rawTxs []string
+	if err := json.NewDecoder(r.Body).Decode(&rawTxs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	results := make([]esploraMempoolAcceptResult, len(rawTxs))
+	for i, rawTxHex := range rawTxs {
+		rawTx, err := hex.DecodeString(rawTxHex)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		reason, rejected := f.rejection(tx)
+		results[i] = esploraMempoolAcceptResult{
+			Txid:         tx.TxHash().String(),
+			Allowed:      !rejected,
+			RejectReason: reason,
+		}
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
+
+func (f *FakeEsploraServer) handleTxHex(w http.ResponseWriter, r *http.Request) {
+	tx, ok := f.lookupTx(r.PathValue("txid"))
+	if !ok {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprint(w, hex.EncodeToString(buf.Bytes()))
+}
+
+func (f *FakeEsploraServer) handleTxStatus(w http.ResponseWriter, r *http.Request) {
+	tx, ok := f.lookupTx(r.PathValue("txid"))
+	if !ok {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	status := esploraTxStatus{}
+	if height, confirmed := f.txBlock[tx.TxHash()]; confirmed {
+		status = esploraTxStatus{
+			Confirmed:   true,
+			BlockHeight: height,
+			BlockHash:   f.blocks[height].BlockHash().String(),
+		}
+	}
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(status)
+}
+
+func (f *FakeEsploraServer) handleBlockRaw(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, block := range f.blocks {
+		if block.BlockHash().String() == r.PathValue("hash") {
+			block.Serialize(w)
+			return
+		}
+	}
+
+	http.Error(w, "Block not found", http.StatusNotFound)
+}
+
+func (f *FakeEsploraServer) handleTipHeight(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprint(w, strconv.Itoa(len(f.blocks)-1))
+}
+
+func (f *FakeEsploraServer) handleFeeEstimates(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(f.feeEstimates)
+}
+
+func (f *FakeEsploraServer) lookupTx(txidStr string) (*wire.MsgTx, bool) {
+	txid, err := chainhash.NewHashFromStr(txidStr)
+	if err != nil {
+		return nil, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tx, ok := f.txs[*txid]
+	return tx, ok
+}
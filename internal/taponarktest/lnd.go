@@ -0,0 +1,761 @@
+package taponarktest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"taponark"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/tappsbt"
+	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"google.golang.org/grpc"
+)
+
+// fakeWalletKeyFamily stands in for lnd's BIP-0086 wallet account.
+const fakeWalletKeyFamily = keychain.KeyFamily(86)
+
+// fakeDustLimit is the smallest change output the fake wallet creates.
+const fakeDustLimit = 330
+
+// fakeLeaseID is the lease id of every output locked by FakeLnd.
+var fakeLeaseID = chainhash.HashB([]byte("fake lnd lease"))
+
+// FakeLnd is an in-process stand-in for the lnd signrpc and walletrpc
+// services. Keys live in a MemorySigner and the wallet is a set of P2TR
+// outputs kept up to date from the blocks of the fake chain. RPCs that are
+// not used by LndClient and LndSigner panic.
+type FakeLnd struct {
+	signrpc.SignerClient
+	walletrpc.WalletKitClient
+
+	signer      *taponark.MemorySigner
+	chain       taponark.ChainBackend
+	chainParams chaincfg.Params
+
+	mu        sync.Mutex
+	addresses []*fakeWalletAddress
+	utxos     map[wire.OutPoint]*fakeUtxo
+
+	// spent outpoints are never added back by a late block notification
+	spent map[wire.OutPoint]bool
+}
+
+type fakeWalletAddress struct {
+	address   string
+	pkScript  []byte
+	key       keychain.KeyDescriptor
+	internal  bool
+	watchOnly bool
+}
+
+type fakeUtxo struct {
+	txOut  *wire.TxOut
+	addr   *fakeWalletAddress
+	leased bool
+}
+
+func NewFakeLnd(signer *taponark.MemorySigner, chain taponark.ChainBackend, chainParams chaincfg.Params) *FakeLnd {
+	return &FakeLnd{
+		signer:      signer,
+		chain:       chain,
+		chainParams: chainParams,
+		utxos:       make(map[wire.OutPoint]*fakeUtxo),
+		spent:       make(map[wire.OutPoint]bool),
+	}
+}
+
+// Client returns an LndClient talking to the fake.
+func (f *FakeLnd) Client() taponark.LndClient {
+	return taponark.NewLndClient(f, f)
+}
+
+// OnBlock updates the wallet with the outputs created and spent by the block.
+func (f *FakeLnd) OnBlock(block *wire.MsgBlock, _ int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, tx := range block.Transactions {
+		f.applyTx(tx)
+	}
+}
+
+// Deposit pays amount to a fresh wallet address out of thin air, the fake
+// chain does not check where coins come from.
+func (f *FakeLnd) Deposit(amount int64) (*wire.MsgTx, error) {
+	f.mu.Lock()
+	addr, err := f.newAddress(false)
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var faucetPoint wire.OutPoint
+	if _, err := rand.Read(faucetPoint.Hash[:]); err != nil {
+		return nil, fmt.Errorf("cannot create faucet outpoint %v", err)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&faucetPoint, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(amount, addr.pkScript))
+
+	if err := f.publish(tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// newAddress must be called with the lock held.
+func (f *FakeLnd) newAddress(internal bool) (*fakeWalletAddress, error) {
+	keyDesc, err := f.signer.DeriveNextKey(fakeWalletKeyFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.addAddress(keyDesc, internal, false)
+}
+
+// addAddress must be called with the lock held.
+func (f *FakeLnd) addAddress(keyDesc keychain.KeyDescriptor, internal, watchOnly bool) (*fakeWalletAddress, error) {
+	outputKey := txscript.ComputeTaprootKeyNoScript(keyDesc.PubKey)
+	taprootAddr, err := btcutil.NewAddressTaproot(
+		schnorr.SerializePubKey(outputKey), &f.chainParams,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create taproot address %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(taprootAddr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create taproot script %v", err)
+	}
+
+	addr := &fakeWalletAddress{taprootAddr.EncodeAddress(), pkScript, keyDesc, internal, watchOnly}
+	f.addresses = append(f.addresses, addr)
+
+	return addr, nil
+}
+
+// addressByScript must be called with the lock held.
+func (f *FakeLnd) addressByScript(pkScript []byte) *fakeWalletAddress {
+	for _, addr := range f.addresses {
+		if bytes.Equal(addr.pkScript, pkScript) {
+			return addr
+		}
+	}
+
+	return nil
+}
+
+// applyTx must be called with the lock held.
+func (f *FakeLnd) applyTx(tx *wire.MsgTx) {
+	for _, txIn := range tx.TxIn {
+		delete(f.utxos, txIn.PreviousOutPoint)
+		f.spent[txIn.PreviousOutPoint] = true
+	}
+
+	txHash := tx.TxHash()
+	for i, txOut := range tx.TxOut {
+		outpoint := wire.OutPoint{Hash: txHash, Index: uint32(i)}
+		addr := f.addressByScript(txOut.PkScript)
+		if addr == nil || f.spent[outpoint] {
+			continue
+		}
+		if _, ok := f.utxos[outpoint]; !ok {
+			f.utxos[outpoint] = &fakeUtxo{txOut: txOut, addr: addr}
+		}
+	}
+}
+
+// publish broadcasts the transaction and applies it to the wallet right
+// away, like lnd does for its own transactions. It must not be called with
+// the lock held.
+func (f *FakeLnd) publish(tx *wire.MsgTx) error {
+	if _, err := f.chain.BroadcastTransaction(tx); err != nil {
+		return fmt.Errorf("cannot publish transaction %v", err)
+	}
+
+	f.mu.Lock()
+	f.applyTx(tx)
+	f.mu.Unlock()
+
+	return nil
+}
+
+// fundPacket adds wallet inputs and a change output to the packet until its
+// inputs pay for its outputs and the fee. Every input already present must
+// carry a WitnessUtxo. The added inputs are leased.
+func (f *FakeLnd) fundPacket(pkt *psbt.Packet, feeRate chainfee.SatPerKWeight) (int32, []*walletrpc.UtxoLease, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var weightEstimate input.TxWeightEstimator
+	inputTotal := int64(0)
+	for i, pIn := range pkt.Inputs {
+		if pIn.WitnessUtxo == nil {
+			return 0, nil, fmt.Errorf("input %d is missing its witness utxo", i)
+		}
+		inputTotal += pIn.WitnessUtxo.Value
+		weightEstimate.AddTaprootKeySpendInput(txscript.SigHashDefault)
+	}
+	outputTotal := int64(0)
+	for _, txOut := range pkt.UnsignedTx.TxOut {
+		outputTotal += txOut.Value
+		weightEstimate.AddTxOutput(txOut)
+	}
+	// Always account for a change output, dropping it only overpays
+	weightEstimate.AddP2TROutput()
+
+	var leases []*walletrpc.UtxoLease
+	for outpoint, utxo := range f.utxos {
+		fee := int64(feeRate.FeeForWeight(weightEstimate.Weight()))
+		if inputTotal >= outputTotal+fee {
+			break
+		}
+		if utxo.leased || utxo.addr.watchOnly {
+			continue
+		}
+
+		utxo.leased = true
+		f.addWalletInput(pkt, outpoint, utxo)
+		weightEstimate.AddTaprootKeySpendInput(txscript.SigHashDefault)
+		inputTotal += utxo.txOut.Value
+
+		leases = append(leases, &walletrpc.UtxoLease{
+			Id: fakeLeaseID,
+			Outpoint: &lnrpc.OutPoint{
+				TxidBytes:   outpoint.Hash[:],
+				OutputIndex: outpoint.Index,
+			},
+			PkScript: utxo.txOut.PkScript,
+			Value:    uint64(utxo.txOut.Value),
+		})
+	}
+
+	fee := int64(feeRate.FeeForWeight(weightEstimate.Weight()))
+	if inputTotal < outputTotal+fee {
+		f.release(leases)
+		return 0, nil, fmt.Errorf("insufficient funds available to construct transaction, have %d need %d",
+			inputTotal, outputTotal+fee)
+	}
+
+	changeIndex := int32(-1)
+	change := inputTotal - outputTotal - fee
+	if change >= fakeDustLimit {
+		changeAddr, err := f.newAddress(true)
+		if err != nil {
+			f.release(leases)
+			return 0, nil, err
+		}
+		derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
+			changeAddr.key, f.chainParams.HDCoinType,
+		)
+
+		changeIndex = int32(len(pkt.Outputs))
+		pkt.UnsignedTx.AddTxOut(wire.NewTxOut(change, changeAddr.pkScript))
+		pkt.Outputs = append(pkt.Outputs, psbt.POutput{
+			Bip32Derivation:        []*psbt.Bip32Derivation{derivation},
+			TaprootBip32Derivation: []*psbt.TaprootBip32Derivation{trDerivation},
+			TaprootInternalKey:     schnorr.SerializePubKey(changeAddr.key.PubKey),
+		})
+	}
+
+	return changeIndex, leases, nil
+}
+
+// addWalletInput must be called with the lock held.
+func (f *FakeLnd) addWalletInput(pkt *psbt.Packet, outpoint wire.OutPoint, utxo *fakeUtxo) {
+	derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
+		utxo.addr.key, f.chainParams.HDCoinType,
+	)
+
+	pkt.UnsignedTx.AddTxIn(wire.NewTxIn(&outpoint, nil, nil))
+	pkt.Inputs = append(pkt.Inputs, psbt.PInput{
+		WitnessUtxo:            utxo.txOut,
+		Bip32Derivation:        []*psbt.Bip32Derivation{derivation},
+		TaprootBip32Derivation: []*psbt.TaprootBip32Derivation{trDerivation},
+		TaprootInternalKey:     schnorr.SerializePubKey(utxo.addr.key.PubKey),
+		SighashType:            txscript.SigHashDefault,
+	})
+}
+
+// release must be called with the lock held.
+func (f *FakeLnd) release(leases []*walletrpc.UtxoLease) {
+	for _, lease := range leases {
+		hash, err := chainhash.NewHash(lease.Outpoint.TxidBytes)
+		if err != nil {
+			continue
+		}
+		outpoint := wire.OutPoint{Hash: *hash, Index: lease.Outpoint.OutputIndex}
+		if utxo, ok := f.utxos[outpoint]; ok {
+			utxo.leased = false
+		}
+	}
+}
+
+// inputKey finds the wallet key an input asks to be signed with.
+func (f *FakeLnd) inputKey(pIn *psbt.PInput) (keychain.KeyDescriptor, bool) {
+	for _, derivation := range pIn.Bip32Derivation {
+		pubKey, err := btcec.ParsePubKey(derivation.PubKey)
+		if err != nil {
+			continue
+		}
+		keyDesc := keychain.KeyDescriptor{PubKey: pubKey}
+		if _, err := f.signer.PrivKey(keyDesc); err == nil {
+			return keyDesc, true
+		}
+	}
+
+	// Taproot derivations only carry the x-only key, try both parities
+	for _, derivation := range pIn.TaprootBip32Derivation {
+		for _, prefix := range []byte{0x02, 0x03} {
+			pubKey, err := btcec.ParsePubKey(append([]byte{prefix}, derivation.XOnlyPubKey...))
+			if err != nil {
+				continue
+			}
+			keyDesc := keychain.KeyDescriptor{PubKey: pubKey}
+			if _, err := f.signer.PrivKey(keyDesc); err == nil {
+				return keyDesc, true
+			}
+		}
+	}
+
+	return keychain.KeyDescriptor{}, false
+}
+
+// signPacket signs every input with a known derivation. Inputs with a leaf
+// script are signed through that leaf, all others by key spend tweaked with
+// their merkle root or BIP-0086.
+func (f *FakeLnd) signPacket(ctx context.Context, pkt *psbt.Packet) ([]uint32, error) {
+	prevOutFetcher, err := taponark.PacketPrevOutFetcher(pkt)
+	if err != nil {
+		return nil, err
+	}
+	sigHashes := txscript.NewTxSigHashes(pkt.UnsignedTx, prevOutFetcher)
+
+	var signedInputs []uint32
+	for idx := range pkt.Inputs {
+		pIn := &pkt.Inputs[idx]
+		if len(pIn.FinalScriptWitness) > 0 || pIn.WitnessUtxo == nil {
+			continue
+		}
+		keyDesc, ok := f.inputKey(pIn)
+		if !ok {
+			continue
+		}
+
+		if len(pIn.TaprootLeafScript) > 0 {
+			leafScript := pIn.TaprootLeafScript[0]
+			leaf := txscript.NewTapLeaf(leafScript.LeafVersion, leafScript.Script)
+			sigs, err := f.signer.SignBtcTapscript(
+				ctx, pkt, []int{idx}, []keychain.KeyDescriptor{keyDesc},
+				[][]byte{leafScript.ControlBlock}, []txscript.TapLeaf{leaf},
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			leafHash := leaf.TapHash()
+			pIn.TaprootScriptSpendSig = append(pIn.TaprootScriptSpendSig, &psbt.TaprootScriptSpendSig{
+				XOnlyPubKey: schnorr.SerializePubKey(keyDesc.PubKey),
+				LeafHash:    leafHash[:],
+				Signature:   sigs[0],
+				SigHash:     txscript.SigHashDefault,
+			})
+		} else {
+			privKey, err := f.signer.PrivKey(keyDesc)
+			if err != nil {
+				return nil, err
+			}
+			sig, err := txscript.RawTxInTaprootSignature(
+				pkt.UnsignedTx, sigHashes, idx, pIn.WitnessUtxo.Value,
+				pIn.WitnessUtxo.PkScript, pIn.TaprootMerkleRoot,
+				txscript.SigHashDefault, privKey,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("cannot sign input %d %v", idx, err)
+			}
+			pIn.TaprootKeySpendSig = sig
+		}
+
+		signedInputs = append(signedInputs, uint32(idx))
+	}
+
+	return signedInputs, nil
+}
+
+// finalizeAndPublish signs, finalizes and broadcasts a wallet funded packet.
+func (f *FakeLnd) finalizeAndPublish(pkt *psbt.Packet) (*wire.MsgTx, error) {
+	if _, err := f.signPacket(context.TODO(), pkt); err != nil {
+		return nil, err
+	}
+	if err := psbt.MaybeFinalizeAll(pkt); err != nil {
+		return nil, fmt.Errorf("cannot finalize packet %v", err)
+	}
+	tx, err := psbt.Extract(pkt)
+	if err != nil {
+		return nil, fmt.Errorf("cannot extract transaction %v", err)
+	}
+
+	if err := f.publish(tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func (f *FakeLnd) MuSig2CreateSession(ctx context.Context, in *signrpc.MuSig2SessionRequest,
+	_ ...grpc.CallOption) (*signrpc.MuSig2SessionResponse, error) {
+
+	keyDesc := keychain.KeyDescriptor{
+		KeyLocator: keychain.KeyLocator{
+			Family: keychain.KeyFamily(in.KeyLoc.KeyFamily),
+			Index:  uint32(in.KeyLoc.KeyIndex),
+		},
+	}
+	sessID, publicNonce, err := f.signer.MuSig2CreateSession(ctx, keyDesc, in.AllSignerPubkeys, in.TaprootTweak)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.MuSig2SessionResponse{
+		SessionId:         sessID,
+		LocalPublicNonces: publicNonce,
+		Version:           in.Version,
+	}, nil
+}
+
+func (f *FakeLnd) MuSig2RegisterNonces(ctx context.Context, in *signrpc.MuSig2RegisterNoncesRequest,
+	_ ...grpc.CallOption) (*signrpc.MuSig2RegisterNoncesResponse, error) {
+
+	err := f.signer.MuSig2RegisterNonces(ctx, in.SessionId, in.OtherSignerPublicNonces)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.MuSig2RegisterNoncesResponse{HaveAllNonces: true}, nil
+}
+
+func (f *FakeLnd) MuSig2Sign(ctx context.Context, in *signrpc.MuSig2SignRequest,
+	_ ...grpc.CallOption) (*signrpc.MuSig2SignResponse, error) {
+
+	partialSig, err := f.signer.MuSig2Sign(ctx, in.SessionId, in.MessageDigest, in.Cleanup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.MuSig2SignResponse{LocalPartialSignature: partialSig}, nil
+}
+
+func (f *FakeLnd) MuSig2CombineSig(ctx context.Context, in *signrpc.MuSig2CombineSigRequest,
+	_ ...grpc.CallOption) (*signrpc.MuSig2CombineSigResponse, error) {
+
+	finalSig, err := f.signer.MuSig2CombineSigs(ctx, in.SessionId, in.OtherPartialSignatures)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.MuSig2CombineSigResponse{HaveAllSignatures: true, FinalSignature: finalSig}, nil
+}
+
+func (f *FakeLnd) MuSig2Cleanup(ctx context.Context, in *signrpc.MuSig2CleanupRequest,
+	_ ...grpc.CallOption) (*signrpc.MuSig2CleanupResponse, error) {
+
+	if err := f.signer.MuSig2Cleanup(ctx, in.SessionId); err != nil {
+		return nil, err
+	}
+
+	return &signrpc.MuSig2CleanupResponse{}, nil
+}
+
+func (f *FakeLnd) DeriveKey(_ context.Context, in *signrpc.KeyLocator,
+	_ ...grpc.CallOption) (*signrpc.KeyDescriptor, error) {
+
+	keyDesc, err := f.signer.DeriveKey(keychain.KeyLocator{
+		Family: keychain.KeyFamily(in.KeyFamily),
+		Index:  uint32(in.KeyIndex),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.KeyDescriptor{
+		RawKeyBytes: keyDesc.PubKey.SerializeCompressed(),
+		KeyLoc:      in,
+	}, nil
+}
+
+func (f *FakeLnd) DeriveNextKey(_ context.Context, in *walletrpc.KeyReq,
+	_ ...grpc.CallOption) (*signrpc.KeyDescriptor, error) {
+
+	keyDesc, err := f.signer.DeriveNextKey(keychain.KeyFamily(in.KeyFamily))
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.KeyDescriptor{
+		RawKeyBytes: keyDesc.PubKey.SerializeCompressed(),
+		KeyLoc: &signrpc.KeyLocator{
+			KeyFamily: int32(keyDesc.Family),
+			KeyIndex:  int32(keyDesc.Index),
+		},
+	}, nil
+}
+
+func (f *FakeLnd) DeriveSharedKey(_ context.Context, in *signrpc.SharedKeyRequest,
+	_ ...grpc.CallOption) (*signrpc.SharedKeyResponse, error) {
+
+	if in.KeyLoc == nil {
+		return nil, fmt.Errorf("fake lnd derives shared keys by key locator only")
+	}
+
+	pub, err := btcec.ParsePubKey(in.EphemeralPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse ephemeral key %v", err)
+	}
+
+	sharedKey, err := f.signer.ECDH(keychain.KeyLocator{
+		Family: keychain.KeyFamily(in.KeyLoc.KeyFamily),
+		Index:  uint32(in.KeyLoc.KeyIndex),
+	}, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.SharedKeyResponse{SharedKey: sharedKey[:]}, nil
+}
+
+func (f *FakeLnd) SignMessage(_ context.Context, in *signrpc.SignMessageReq,
+	_ ...grpc.CallOption) (*signrpc.SignMessageResp, error) {
+
+	if in.KeyLoc == nil || !in.SchnorrSig || len(in.Tag) == 0 {
+		return nil, fmt.Errorf("fake lnd signs tagged schnorr messages by key locator only")
+	}
+
+	sig, err := f.signer.SignMessageSchnorr(keychain.KeyLocator{
+		Family: keychain.KeyFamily(in.KeyLoc.KeyFamily),
+		Index:  uint32(in.KeyLoc.KeyIndex),
+	}, in.Msg, in.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signrpc.SignMessageResp{Signature: sig.Serialize()}, nil
+}
+
+func (f *FakeLnd) NextAddr(_ context.Context, in *walletrpc.AddrRequest,
+	_ ...grpc.CallOption) (*walletrpc.AddrResponse, error) {
+
+	if in.Type != walletrpc.AddressType_TAPROOT_PUBKEY {
+		return nil, fmt.Errorf("fake lnd only supports taproot addresses, got %v", in.Type)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	addr, err := f.newAddress(in.Change)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletrpc.AddrResponse{Addr: addr.address}, nil
+}
+
+func (f *FakeLnd) ListAddresses(_ context.Context, _ *walletrpc.ListAddressesRequest,
+	_ ...grpc.CallOption) (*walletrpc.ListAddressesResponse, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	balances := make(map[*fakeWalletAddress]int64)
+	for _, utxo := range f.utxos {
+		balances[utxo.addr] += utxo.txOut.Value
+	}
+
+	account := &walletrpc.AccountWithAddresses{
+		Name:        "default",
+		AddressType: walletrpc.AddressType_TAPROOT_PUBKEY,
+	}
+	for _, addr := range f.addresses {
+		account.Addresses = append(account.Addresses, &walletrpc.AddressProperty{
+			Address:    addr.address,
+			IsInternal: addr.internal,
+			Balance:    balances[addr],
+			PublicKey:  addr.key.PubKey.SerializeCompressed(),
+		})
+	}
+
+	return &walletrpc.ListAddressesResponse{
+		AccountWithAddresses: []*walletrpc.AccountWithAddresses{account},
+	}, nil
+}
+
+func (f *FakeLnd) SendOutputs(_ context.Context, in *walletrpc.SendOutputsRequest,
+	_ ...grpc.CallOption) (*walletrpc.SendOutputsResponse, error) {
+
+	tx := wire.NewMsgTx(2)
+	for _, output := range in.Outputs {
+		tx.AddTxOut(wire.NewTxOut(output.Value, output.PkScript))
+	}
+	pkt, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create packet %v", err)
+	}
+
+	if _, _, err := f.fundPacket(pkt, chainfee.SatPerKWeight(in.SatPerKw)); err != nil {
+		return nil, err
+	}
+
+	signedTx, err := f.finalizeAndPublish(pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := signedTx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("cannot serialize transaction %v", err)
+	}
+
+	return &walletrpc.SendOutputsResponse{RawTx: buf.Bytes()}, nil
+}
+
+func (f *FakeLnd) FundPsbt(_ context.Context, in *walletrpc.FundPsbtRequest,
+	_ ...grpc.CallOption) (*walletrpc.FundPsbtResponse, error) {
+
+	coinSelect := in.GetCoinSelect()
+	if coinSelect == nil {
+		return nil, fmt.Errorf("fake lnd only supports coin select templates")
+	}
+	pkt, err := psbt.NewFromRawBytes(bytes.NewReader(coinSelect.Psbt), false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse psbt %v", err)
+	}
+
+	var feeRate chainfee.SatPerKWeight
+	switch {
+	case in.GetSatPerVbyte() != 0:
+		feeRate = chainfee.SatPerKVByte(in.GetSatPerVbyte() * 1000).FeePerKWeight()
+	default:
+		feeRate, err = f.chain.EstimateFee(in.GetTargetConf())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changeIndex, leases, err := f.fundPacket(pkt, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pkt.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("cannot serialize psbt %v", err)
+	}
+
+	return &walletrpc.FundPsbtResponse{
+		FundedPsbt:        buf.Bytes(),
+		ChangeOutputIndex: changeIndex,
+		LockedUtxos:       leases,
+	}, nil
+}
+
+func (f *FakeLnd) SignPsbt(ctx context.Context, in *walletrpc.SignPsbtRequest,
+	_ ...grpc.CallOption) (*walletrpc.SignPsbtResponse, error) {
+
+	pkt, err := psbt.NewFromRawBytes(bytes.NewReader(in.FundedPsbt), false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse psbt %v", err)
+	}
+
+	signedInputs, err := f.signPacket(ctx, pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pkt.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("cannot serialize psbt %v", err)
+	}
+
+	return &walletrpc.SignPsbtResponse{SignedPsbt: buf.Bytes(), SignedInputs: signedInputs}, nil
+}
+
+func (f *FakeLnd) ReleaseOutput(_ context.Context, in *walletrpc.ReleaseOutputRequest,
+	_ ...grpc.CallOption) (*walletrpc.ReleaseOutputResponse, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.release([]*walletrpc.UtxoLease{{Id: in.Id, Outpoint: in.Outpoint}})
+
+	return &walletrpc.ReleaseOutputResponse{}, nil
+}
+
+func (f *FakeLnd) BumpFee(_ context.Context, in *walletrpc.BumpFeeRequest,
+	_ ...grpc.CallOption) (*walletrpc.BumpFeeResponse, error) {
+
+	hash, err := chainhash.NewHash(in.Outpoint.TxidBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse outpoint %v", err)
+	}
+	outpoint := wire.OutPoint{Hash: *hash, Index: in.Outpoint.OutputIndex}
+
+	// CPFP by sweeping the output back into the wallet
+	pkt := &psbt.Packet{UnsignedTx: wire.NewMsgTx(2)}
+	f.mu.Lock()
+	utxo, ok := f.utxos[outpoint]
+	if ok && !utxo.leased && !utxo.addr.watchOnly {
+		utxo.leased = true
+		f.addWalletInput(pkt, outpoint, utxo)
+	}
+	f.mu.Unlock()
+	if !ok || len(pkt.Inputs) == 0 {
+		return nil, fmt.Errorf("cannot bump fee of unknown or locked output %v", outpoint)
+	}
+
+	feeRate := chainfee.SatPerKVByte(in.SatPerVbyte * 1000).FeePerKWeight()
+	if _, _, err := f.fundPacket(pkt, feeRate); err != nil {
+		return nil, err
+	}
+	if _, err := f.finalizeAndPublish(pkt); err != nil {
+		return nil, err
+	}
+
+	return &walletrpc.BumpFeeResponse{Status: "Successfully registered CPFP-tx with the sweeper"}, nil
+}
+
+func (f *FakeLnd) ImportPublicKey(_ context.Context, in *walletrpc.ImportPublicKeyRequest,
+	_ ...grpc.CallOption) (*walletrpc.ImportPublicKeyResponse, error) {
+
+	if in.AddressType != walletrpc.AddressType_TAPROOT_PUBKEY {
+		return nil, fmt.Errorf("fake lnd only imports taproot keys, got %v", in.AddressType)
+	}
+	pubKey, err := schnorr.ParsePubKey(in.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse public key %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err = f.addAddress(keychain.KeyDescriptor{PubKey: pubKey}, false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletrpc.ImportPublicKeyResponse{}, nil
+}
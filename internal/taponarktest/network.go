@@ -0,0 +1,72 @@
+package taponarktest
+
+import (
+	"fmt"
+	"taponark"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/taproot-assets/address"
+)
+
+// FakeNetwork runs the Ark flow without docker: every node is a FakeTapd on
+// top of a FakeLnd, all sharing one FakeUniverse and a FakeEsploraServer that
+// mines a block for every broadcasted transaction.
+type FakeNetwork struct {
+	esplora     *FakeEsploraServer
+	universe    *FakeUniverse
+	backend     taponark.ChainBackend
+	bitcoin     taponark.BitcoinClient
+	chainParams chaincfg.Params
+	tapParams   address.ChainParams
+	timeout     time.Duration
+}
+
+func NewFakeNetwork(timeout time.Duration) *FakeNetwork {
+	esplora := NewFakeEsploraServer()
+	esplora.SetAutoMine(true)
+
+	chainParams := chaincfg.RegressionNetParams
+	backend := taponark.NewEsploraBackend(esplora.URL(), timeout)
+
+	return &FakeNetwork{
+		esplora:     esplora,
+		universe:    NewFakeUniverse(),
+		backend:     backend,
+		bitcoin:     taponark.NewBitcoinClient(backend, chainParams, timeout),
+		chainParams: chainParams,
+		tapParams:   address.RegressionNetTap,
+		timeout:     timeout,
+	}
+}
+
+func (n *FakeNetwork) BitcoinClient() taponark.BitcoinClient {
+	return n.bitcoin
+}
+
+// Esplora exposes the fake chain, e.g. to mine blocks by hand after turning
+// auto mining off.
+func (n *FakeNetwork) Esplora() *FakeEsploraServer {
+	return n.esplora
+}
+
+// NewNode creates a tapd and lnd pair whose wallet holds fundingSats.
+func (n *FakeNetwork) NewNode(fundingSats int64) (taponark.TapClient, error) {
+	signer := taponark.NewMemorySigner()
+	lnd := NewFakeLnd(signer, n.backend, n.chainParams)
+	tapd := NewFakeTapd(lnd, n.universe, n.tapParams)
+	n.esplora.Subscribe(lnd.OnBlock)
+	n.esplora.Subscribe(tapd.OnBlock)
+
+	if fundingSats > 0 {
+		if _, err := lnd.Deposit(fundingSats); err != nil {
+			return taponark.TapClient{}, fmt.Errorf("cannot fund node %v", err)
+		}
+	}
+
+	return taponark.NewTapClient(fakeCourierAddr.Host, tapd, lnd.Client(), n.chainParams, n.tapParams, n.timeout), nil
+}
+
+func (n *FakeNetwork) Close() {
+	n.esplora.Close()
+}
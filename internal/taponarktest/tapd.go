@@ -0,0 +1,898 @@
+package taponarktest
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"taponark"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/taproot-assets/address"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/commitment"
+	"github.com/lightninglabs/taproot-assets/proof"
+	"github.com/lightninglabs/taproot-assets/tappsbt"
+	"github.com/lightninglabs/taproot-assets/taprpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/assetwalletrpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/mintrpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/tapdevrpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/universerpc"
+	"github.com/lightninglabs/taproot-assets/tapsend"
+	"github.com/lightninglabs/taproot-assets/vm"
+	"github.com/lightningnetwork/lnd/keychain"
+	"google.golang.org/grpc"
+)
+
+// fakeCourierAddr is put into every address, proofs are handed over by the
+// FakeUniverse instead.
+var fakeCourierAddr = url.URL{Scheme: "universerpc", Host: "fake-universe:10029"}
+
+// FakeUniverse connects the FakeTapd nodes of a FakeNetwork. It knows the
+// genesis of every minted asset and which node owns an address, so that
+// proofs of address sends reach the receiver once the transfer confirms.
+type FakeUniverse struct {
+	mu        sync.Mutex
+	genesis   map[asset.ID]asset.Genesis
+	receivers map[string]*FakeTapd
+}
+
+func NewFakeUniverse() *FakeUniverse {
+	return &FakeUniverse{
+		genesis:   make(map[asset.ID]asset.Genesis),
+		receivers: make(map[string]*FakeTapd),
+	}
+}
+
+func (u *FakeUniverse) addGenesis(genesis asset.Genesis) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.genesis[genesis.ID()] = genesis
+}
+
+func (u *FakeUniverse) lookupGenesis(assetId asset.ID) (asset.Genesis, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	genesis, ok := u.genesis[assetId]
+	return genesis, ok
+}
+
+func (u *FakeUniverse) addReceiver(encodedAddr string, receiver *FakeTapd) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.receivers[encodedAddr] = receiver
+}
+
+// deliver hands the proof file of a confirmed address send to its receiver.
+func (u *FakeUniverse) deliver(encodedAddr string, proofFile []byte) error {
+	u.mu.Lock()
+	receiver, ok := u.receivers[encodedAddr]
+	u.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown receiver of address %s", encodedAddr)
+	}
+
+	return receiver.receiveProof(encodedAddr, proofFile)
+}
+
+// FakeTapd is an in-process stand-in for the tapd RPC services used by
+// TapClient. Mints, address sends and their proofs are built with the tapd
+// libraries on top of a FakeLnd wallet. RPCs that are not used by TapClient
+// panic.
+type FakeTapd struct {
+	taprpc.TaprootAssetsClient
+	assetwalletrpc.AssetWalletClient
+	mintrpc.MintClient
+	universerpc.UniverseClient
+	tapdevrpc.TapDevClient
+
+	lnd       *FakeLnd
+	signer    *taponark.MemorySigner
+	universe  *FakeUniverse
+	tapParams address.ChainParams
+
+	mu      sync.Mutex
+	batch   []*mintrpc.MintAsset
+	assets  []*fakeOwnedAsset
+	events  map[string]taprpc.AddrEventStatus
+	pending map[chainhash.Hash]fakeConfirmation
+
+	// scriptKeys holds the BIP-0086 script keys handed out by this node so
+	// that assets sent to them can be spent again
+	scriptKeys map[asset.SerializedKey]asset.ScriptKey
+}
+
+type fakeOwnedAsset struct {
+	asset     *asset.Asset
+	proofFile []byte
+	spent     bool
+}
+
+// fakeConfirmation runs once the transaction it was registered for is mined.
+type fakeConfirmation func(block *wire.MsgBlock, height int64, txIndex int) error
+
+func NewFakeTapd(lnd *FakeLnd, universe *FakeUniverse, tapParams address.ChainParams) *FakeTapd {
+	return &FakeTapd{
+		lnd:        lnd,
+		signer:     lnd.signer,
+		universe:   universe,
+		tapParams:  tapParams,
+		events:     make(map[string]taprpc.AddrEventStatus),
+		pending:    make(map[chainhash.Hash]fakeConfirmation),
+		scriptKeys: make(map[asset.SerializedKey]asset.ScriptKey),
+	}
+}
+
+// OnBlock runs the confirmations waiting on transactions of the block.
+func (f *FakeTapd) OnBlock(block *wire.MsgBlock, height int64) {
+	for txIndex, tx := range block.Transactions {
+		f.mu.Lock()
+		confirm, ok := f.pending[tx.TxHash()]
+		delete(f.pending, tx.TxHash())
+		f.mu.Unlock()
+
+		if !ok {
+			continue
+		}
+		if err := confirm(block, height, txIndex); err != nil {
+			log.Printf("fake tapd cannot confirm %v: %v", tx.TxHash(), err)
+		}
+	}
+}
+
+// publish registers the confirmation before broadcasting, blocks may be
+// mined as soon as the transaction hits the chain.
+func (f *FakeTapd) publish(pkt *psbt.Packet, confirm fakeConfirmation) (*wire.MsgTx, error) {
+	txHash := pkt.UnsignedTx.TxHash()
+	f.mu.Lock()
+	f.pending[txHash] = confirm
+	f.mu.Unlock()
+
+	tx, err := f.lnd.finalizeAndPublish(pkt)
+	if err != nil {
+		f.mu.Lock()
+		delete(f.pending, txHash)
+		f.mu.Unlock()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func (f *FakeTapd) nextScriptKey() (asset.ScriptKey, error) {
+	keyDesc, err := f.signer.DeriveNextKey(asset.TaprootAssetsKeyFamily)
+	if err != nil {
+		return asset.ScriptKey{}, err
+	}
+	scriptKey := asset.NewScriptKeyBip86(keyDesc)
+
+	f.mu.Lock()
+	f.scriptKeys[asset.ToSerialized(scriptKey.PubKey)] = scriptKey
+	f.mu.Unlock()
+
+	return scriptKey, nil
+}
+
+// addAsset stores the asset proven by the last proof of the file.
+func (f *FakeTapd) addAsset(proofFile []byte) (*asset.Asset, error) {
+	file, err := proof.DecodeFile(proofFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode proof file %v", err)
+	}
+	lastProof, err := file.LastProof()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get last proof %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ownedAsset := lastProof.Asset.Copy()
+	if scriptKey, ok := f.scriptKeys[asset.ToSerialized(ownedAsset.ScriptKey.PubKey)]; ok {
+		ownedAsset.ScriptKey = scriptKey
+	}
+
+	// Importing the same proof twice replaces the earlier copy
+	outpoint := lastProof.OutPoint()
+	for _, owned := range f.assets {
+		if owned.asset.ScriptKey.PubKey.IsEqual(ownedAsset.ScriptKey.PubKey) &&
+			owned.asset.ID() == ownedAsset.ID() && !owned.spent {
+
+			ownedFile, err := proof.DecodeFile(owned.proofFile)
+			if err != nil {
+				continue
+			}
+			ownedProof, err := ownedFile.LastProof()
+			if err == nil && ownedProof.OutPoint() == outpoint {
+				owned.proofFile = proofFile
+				return ownedAsset, nil
+			}
+		}
+	}
+
+	f.assets = append(f.assets, &fakeOwnedAsset{ownedAsset, proofFile, false})
+
+	return ownedAsset, nil
+}
+
+func (f *FakeTapd) receiveProof(encodedAddr string, proofFile []byte) error {
+	if _, err := f.addAsset(proofFile); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.events[encodedAddr] = taprpc.AddrEventStatus_ADDR_EVENT_STATUS_COMPLETED
+	f.mu.Unlock()
+
+	return nil
+}
+
+// anchorInput rebuilds the anchor output an asset was last committed to.
+func (f *FakeTapd) anchorInput(lastProof *proof.Proof) (tappsbt.Anchor, error) {
+	inclusionProof := lastProof.InclusionProof
+	if inclusionProof.CommitmentProof == nil {
+		return tappsbt.Anchor{}, fmt.Errorf("proof has no commitment proof")
+	}
+
+	// The proof derives the commitment of every version, pick the one the
+	// anchor output commits to
+	commitmentKeys, err := inclusionProof.DeriveByAssetInclusion(&lastProof.Asset, nil)
+	if err != nil {
+		return tappsbt.Anchor{}, fmt.Errorf("cannot derive anchor commitment %v", err)
+	}
+	anchorOut := lastProof.AnchorTx.TxOut[inclusionProof.OutputIndex]
+	var tapCommitment *commitment.TapCommitment
+	for outputKey, keyCommitment := range commitmentKeys {
+		if bytes.Equal(anchorOut.PkScript[2:], outputKey[1:]) {
+			tapCommitment = keyCommitment
+		}
+	}
+	if tapCommitment == nil {
+		return tappsbt.Anchor{}, fmt.Errorf("anchor script mismatch for %v", lastProof.OutPoint())
+	}
+
+	sibling := inclusionProof.CommitmentProof.TapSiblingPreimage
+	pkScript, merkleRoot, _, err := tapsend.AnchorOutputScript(inclusionProof.InternalKey, sibling, tapCommitment)
+	if err != nil {
+		return tappsbt.Anchor{}, fmt.Errorf("cannot create anchor script %v", err)
+	}
+
+	encodedSibling, _, err := commitment.MaybeEncodeTapscriptPreimage(sibling)
+	if err != nil {
+		return tappsbt.Anchor{}, fmt.Errorf("cannot encode tapscript sibling %v", err)
+	}
+
+	derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
+		keychain.KeyDescriptor{PubKey: inclusionProof.InternalKey},
+		f.lnd.chainParams.HDCoinType,
+	)
+
+	return tappsbt.Anchor{
+		Value:             btcutil.Amount(anchorOut.Value),
+		PkScript:          pkScript,
+		SigHashType:       txscript.SigHashDefault,
+		InternalKey:       inclusionProof.InternalKey,
+		MerkleRoot:        merkleRoot[:],
+		TapscriptSibling:  encodedSibling,
+		Bip32Derivation:   []*psbt.Bip32Derivation{derivation},
+		TrBip32Derivation: []*psbt.TaprootBip32Derivation{trDerivation},
+	}, nil
+}
+
+func (f *FakeTapd) MintAsset(_ context.Context, in *mintrpc.MintAssetRequest,
+	_ ...grpc.CallOption) (*mintrpc.MintAssetResponse, error) {
+
+	if in.Asset == nil || in.Asset.Amount == 0 {
+		return nil, fmt.Errorf("asset to mint must have an amount")
+	}
+	if in.Asset.NewGroupedAsset || len(in.Asset.GroupKey) > 0 {
+		return nil, fmt.Errorf("fake tapd does not mint grouped assets")
+	}
+
+	f.mu.Lock()
+	f.batch = append(f.batch, in.Asset)
+	f.mu.Unlock()
+
+	return &mintrpc.MintAssetResponse{}, nil
+}
+
+func (f *FakeTapd) FinalizeBatch(_ context.Context, _ *mintrpc.FinalizeBatchRequest,
+	_ ...grpc.CallOption) (*mintrpc.FinalizeBatchResponse, error) {
+
+	f.mu.Lock()
+	batch := f.batch
+	f.batch = nil
+	f.mu.Unlock()
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("no pending batch")
+	}
+
+	internalKey, err := f.signer.DeriveNextKey(asset.TaprootAssetsKeyFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	// The genesis point depends on coin selection, so fund the anchor with
+	// a placeholder script of the same size first
+	placeholder, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(internalKey.PubKey))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create anchor script %v", err)
+	}
+	anchorTx := wire.NewMsgTx(2)
+	anchorTx.AddTxOut(wire.NewTxOut(int64(tapsend.DummyAmtSats), placeholder))
+	pkt, err := psbt.NewFromUnsignedTx(anchorTx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create anchor packet %v", err)
+	}
+
+	feeRate, err := f.lnd.chain.EstimateFee(taponark.CPFP_CONF_TARGET)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := f.lnd.fundPacket(pkt, feeRate); err != nil {
+		return nil, fmt.Errorf("cannot fund mint %v", err)
+	}
+	genesisPoint := pkt.UnsignedTx.TxIn[0].PreviousOutPoint
+
+	metaReveals := make(map[asset.SerializedKey]*proof.MetaReveal)
+	newAssets := make([]*asset.Asset, 0, len(batch))
+	for _, mint := range batch {
+		metaReveal := &proof.MetaReveal{Type: proof.MetaOpaque}
+		if mint.AssetMeta != nil {
+			metaReveal.Data = mint.AssetMeta.Data
+		}
+
+		genesis := asset.Genesis{
+			FirstPrevOut: genesisPoint,
+			Tag:          mint.Name,
+			MetaHash:     metaReveal.MetaHash(),
+			OutputIndex:  0,
+			Type:         asset.Type(mint.AssetType),
+		}
+		scriptKey, err := f.nextScriptKey()
+		if err != nil {
+			return nil, err
+		}
+		version, err := taprpc.UnmarshalAssetVersion(mint.AssetVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		newAsset, err := asset.New(genesis, mint.Amount, 0, 0, scriptKey, nil, asset.WithAssetVersion(version))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create asset %v", err)
+		}
+		newAssets = append(newAssets, newAsset)
+		metaReveals[asset.ToSerialized(scriptKey.PubKey)] = metaReveal
+	}
+
+	tapCommitment, err := commitment.FromAssets(nil, newAssets...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot commit to assets %v", err)
+	}
+	pkScript, _, _, err := tapsend.AnchorOutputScript(internalKey.PubKey, nil, tapCommitment)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create anchor script %v", err)
+	}
+	pkt.UnsignedTx.TxOut[0].PkScript = pkScript
+	pkt.Outputs[0].TaprootInternalKey = schnorr.SerializePubKey(internalKey.PubKey)
+
+	confirm := func(block *wire.MsgBlock, height int64, txIndex int) error {
+		baseProof := proof.BaseProofParams{
+			Block:            block,
+			BlockHeight:      uint32(height),
+			Tx:               block.Transactions[txIndex],
+			TxIndex:          txIndex,
+			OutputIndex:      0,
+			InternalKey:      internalKey.PubKey,
+			TaprootAssetRoot: tapCommitment,
+		}
+		err := proof.AddExclusionProofs(&baseProof, baseProof.Tx, pkt.Outputs, func(idx uint32) bool {
+			return idx == 0
+		})
+		if err != nil {
+			return fmt.Errorf("cannot add exclusion proofs %v", err)
+		}
+
+		mintProofs, err := proof.NewMintingBlobs(
+			&proof.MintParams{BaseProofParams: baseProof, GenesisPoint: genesisPoint},
+			proof.MockHeaderVerifier, proof.MockMerkleVerifier, proof.MockGroupVerifier,
+			proof.MockGroupAnchorVerifier, proof.MockChainLookup,
+			proof.WithAssetMetaReveals(metaReveals),
+		)
+		if err != nil {
+			return fmt.Errorf("cannot create minting proofs %v", err)
+		}
+
+		for _, mintProof := range mintProofs {
+			proofFile, err := proof.EncodeAsProofFile(mintProof)
+			if err != nil {
+				return fmt.Errorf("cannot encode minting proof %v", err)
+			}
+			if _, err := f.addAsset(proofFile); err != nil {
+				return err
+			}
+			f.universe.addGenesis(mintProof.Asset.Genesis)
+		}
+
+		return nil
+	}
+
+	if _, err := f.publish(pkt, confirm); err != nil {
+		return nil, err
+	}
+
+	return &mintrpc.FinalizeBatchResponse{}, nil
+}
+
+func (f *FakeTapd) ListAssets(_ context.Context, in *taprpc.ListAssetRequest,
+	_ ...grpc.CallOption) (*taprpc.ListAssetResponse, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &taprpc.ListAssetResponse{}
+	for _, owned := range f.assets {
+		if owned.spent && !in.IncludeSpent {
+			continue
+		}
+		resp.Assets = append(resp.Assets, marshalFakeAsset(owned))
+	}
+
+	return resp, nil
+}
+
+func marshalFakeAsset(owned *fakeOwnedAsset) *taprpc.Asset {
+	genesis := owned.asset.Genesis
+	assetId := genesis.ID()
+
+	return &taprpc.Asset{
+		AssetGenesis: &taprpc.GenesisInfo{
+			GenesisPoint: genesis.FirstPrevOut.String(),
+			Name:         genesis.Tag,
+			MetaHash:     genesis.MetaHash[:],
+			AssetId:      assetId[:],
+			AssetType:    taprpc.AssetType(genesis.Type),
+			OutputIndex:  genesis.OutputIndex,
+		},
+		Amount:    owned.asset.Amount,
+		ScriptKey: owned.asset.ScriptKey.PubKey.SerializeCompressed(),
+		IsSpent:   owned.spent,
+	}
+}
+
+func (f *FakeTapd) ListBalances(_ context.Context, in *taprpc.ListBalancesRequest,
+	_ ...grpc.CallOption) (*taprpc.ListBalancesResponse, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	balances := make(map[string]*taprpc.AssetBalance)
+	for _, owned := range f.assets {
+		assetId := owned.asset.ID()
+		if owned.spent || (len(in.AssetFilter) > 0 && !bytes.Equal(in.AssetFilter, assetId[:])) {
+			continue
+		}
+
+		key := hex.EncodeToString(assetId[:])
+		if _, ok := balances[key]; !ok {
+			balances[key] = &taprpc.AssetBalance{
+				AssetGenesis: marshalFakeAsset(owned).AssetGenesis,
+			}
+		}
+		balances[key].Balance += owned.asset.Amount
+	}
+
+	return &taprpc.ListBalancesResponse{AssetBalances: balances}, nil
+}
+
+func (f *FakeTapd) NextScriptKey(_ context.Context, _ *assetwalletrpc.NextScriptKeyRequest,
+	_ ...grpc.CallOption) (*assetwalletrpc.NextScriptKeyResponse, error) {
+
+	scriptKey, err := f.nextScriptKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &assetwalletrpc.NextScriptKeyResponse{ScriptKey: taprpc.MarshalScriptKey(scriptKey)}, nil
+}
+
+func (f *FakeTapd) NextInternalKey(_ context.Context, _ *assetwalletrpc.NextInternalKeyRequest,
+	_ ...grpc.CallOption) (*assetwalletrpc.NextInternalKeyResponse, error) {
+
+	keyDesc, err := f.signer.DeriveNextKey(asset.TaprootAssetsKeyFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	return &assetwalletrpc.NextInternalKeyResponse{InternalKey: taprpc.MarshalKeyDescriptor(keyDesc)}, nil
+}
+
+func (f *FakeTapd) NewAddr(_ context.Context, in *taprpc.NewAddrRequest,
+	_ ...grpc.CallOption) (*taprpc.Addr, error) {
+
+	genesis, ok := f.universe.lookupGenesis(asset.ID(in.AssetId))
+	if !ok {
+		return nil, fmt.Errorf("unknown asset %x", in.AssetId)
+	}
+
+	var scriptKey asset.ScriptKey
+	if in.ScriptKey != nil {
+		unmarshalled, err := taprpc.UnmarshalScriptKey(in.ScriptKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal script key %v", err)
+		}
+		scriptKey = *unmarshalled
+	} else {
+		var err error
+		if scriptKey, err = f.nextScriptKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	var internalKey keychain.KeyDescriptor
+	if in.InternalKey != nil {
+		unmarshalled, err := taprpc.UnmarshalKeyDescriptor(in.InternalKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal internal key %v", err)
+		}
+		internalKey = unmarshalled
+	} else {
+		var err error
+		if internalKey, err = f.signer.DeriveNextKey(asset.TaprootAssetsKeyFamily); err != nil {
+			return nil, err
+		}
+	}
+
+	sibling, _, err := commitment.MaybeDecodeTapscriptPreimage(in.TapscriptSibling)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode tapscript sibling %v", err)
+	}
+
+	tapAddr, err := address.New(
+		address.V0, genesis, nil, nil, *scriptKey.PubKey, *internalKey.PubKey,
+		in.Amt, sibling, &f.tapParams, fakeCourierAddr,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create address %v", err)
+	}
+	encoded, err := tapAddr.EncodeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode address %v", err)
+	}
+
+	f.universe.addReceiver(encoded, f)
+
+	assetId := genesis.ID()
+	return &taprpc.Addr{
+		Encoded:          encoded,
+		AssetId:          assetId[:],
+		AssetType:        taprpc.AssetType(genesis.Type),
+		Amount:           in.Amt,
+		ScriptKey:        scriptKey.PubKey.SerializeCompressed(),
+		InternalKey:      internalKey.PubKey.SerializeCompressed(),
+		TapscriptSibling: in.TapscriptSibling,
+		ProofCourierAddr: fakeCourierAddr.String(),
+	}, nil
+}
+
+func (f *FakeTapd) AddrReceives(_ context.Context, in *taprpc.AddrReceivesRequest,
+	_ ...grpc.CallOption) (*taprpc.AddrReceivesResponse, error) {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resp := &taprpc.AddrReceivesResponse{}
+	for encoded, status := range f.events {
+		if in.FilterAddr != "" && in.FilterAddr != encoded {
+			continue
+		}
+		resp.Events = append(resp.Events, &taprpc.AddrEvent{
+			Addr:     &taprpc.Addr{Encoded: encoded},
+			Status:   status,
+			HasProof: true,
+		})
+	}
+
+	return resp, nil
+}
+
+// SendAsset sends to a single address the way tapd does: the input is split
+// into the recipient output and a change output, both anchored in a wallet
+// funded transaction. Proofs are delivered once it confirms.
+func (f *FakeTapd) SendAsset(_ context.Context, in *taprpc.SendAssetRequest,
+	_ ...grpc.CallOption) (*taprpc.SendAssetResponse, error) {
+
+	if len(in.TapAddrs) != 1 {
+		return nil, fmt.Errorf("fake tapd sends to exactly one address, got %d", len(in.TapAddrs))
+	}
+	encodedAddr := in.TapAddrs[0]
+	tapAddr, err := address.DecodeAddress(encodedAddr, &f.tapParams)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode address %v", err)
+	}
+
+	input, lastProof, err := f.selectAsset(tapAddr.AssetID, tapAddr.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	vPkt, err := f.addressPacket(tapAddr, input, lastProof)
+	if err != nil {
+		f.releaseAsset(input)
+		return nil, err
+	}
+
+	btcPkt, err := tapsend.PrepareAnchoringTemplate([]*tappsbt.VPacket{vPkt})
+	if err != nil {
+		f.releaseAsset(input)
+		return nil, fmt.Errorf("cannot prepare anchor transaction %v", err)
+	}
+
+	feeRate, err := f.lnd.chain.EstimateFee(taponark.CPFP_CONF_TARGET)
+	if err != nil {
+		f.releaseAsset(input)
+		return nil, err
+	}
+	if _, _, err := f.lnd.fundPacket(btcPkt, feeRate); err != nil {
+		f.releaseAsset(input)
+		return nil, fmt.Errorf("cannot fund anchor transaction %v", err)
+	}
+
+	if err := taponark.CommitVirtualPsbts(btcPkt, []*tappsbt.VPacket{vPkt}); err != nil {
+		f.releaseAsset(input)
+		return nil, err
+	}
+
+	// Encode the unconfirmed proof suffixes before they are updated with the
+	// block
+	outputs := make([]*taprpc.TransferOutput, len(vPkt.Outputs))
+	for i, vOut := range vPkt.Outputs {
+		var proofBlob bytes.Buffer
+		if err := vOut.ProofSuffix.Encode(&proofBlob); err != nil {
+			f.releaseAsset(input)
+			return nil, fmt.Errorf("cannot encode proof suffix %v", err)
+		}
+		outputs[i] = &taprpc.TransferOutput{
+			ScriptKey:        vOut.ScriptKey.PubKey.SerializeCompressed(),
+			ScriptKeyIsLocal: vOut.ScriptKey.TweakedScriptKey != nil,
+			Amount:           vOut.Amount,
+			NewProofBlob:     proofBlob.Bytes(),
+			AssetVersion:     taprpc.AssetVersion(vOut.AssetVersion),
+		}
+	}
+
+	confirm := func(block *wire.MsgBlock, height int64, txIndex int) error {
+		sendTxResult := taponark.NewBitcoinSendTxResult(block, height, txIndex)
+		for _, vOut := range vPkt.Outputs {
+			proofFile, err := taponark.AppendProof(input.proofFile, block.Transactions[txIndex], vOut.ProofSuffix, sendTxResult)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case vOut.Type.IsSplitRoot() && vOut.Amount == 0:
+				// Tombstone of a full value send
+			case vOut.Type.IsSplitRoot():
+				if _, err := f.addAsset(proofFile); err != nil {
+					return err
+				}
+			default:
+				if err := f.universe.deliver(encodedAddr, proofFile); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	anchorTx, err := f.publish(btcPkt, confirm)
+	if err != nil {
+		f.releaseAsset(input)
+		return nil, err
+	}
+
+	anchorTxHash := anchorTx.TxHash()
+	for i, vOut := range vPkt.Outputs {
+		coloredTransfer, err := taponark.ExtractColoredTransfer(btcPkt, vOut)
+		if err != nil {
+			return nil, err
+		}
+		outputs[i].Anchor = coloredTransfer.TransferOutputAnchor()
+	}
+
+	return &taprpc.SendAssetResponse{
+		Transfer: &taprpc.AssetTransfer{
+			AnchorTxHash: anchorTxHash[:],
+			Outputs:      outputs,
+		},
+	}, nil
+}
+
+// selectAsset marks an unspent asset of at least amount as spent.
+func (f *FakeTapd) selectAsset(assetId asset.ID, amount uint64) (*fakeOwnedAsset, *proof.Proof, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, owned := range f.assets {
+		if owned.spent || owned.asset.ID() != assetId || owned.asset.Amount < amount ||
+			owned.asset.ScriptKey.TweakedScriptKey == nil {
+
+			continue
+		}
+
+		file, err := proof.DecodeFile(owned.proofFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot decode proof file %v", err)
+		}
+		lastProof, err := file.LastProof()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot get last proof %v", err)
+		}
+
+		owned.spent = true
+		return owned, lastProof, nil
+	}
+
+	return nil, nil, fmt.Errorf("not enough balance of asset %x to send %d", assetId[:], amount)
+}
+
+func (f *FakeTapd) releaseAsset(owned *fakeOwnedAsset) {
+	f.mu.Lock()
+	owned.spent = false
+	f.mu.Unlock()
+}
+
+// addressPacket creates the signed virtual packet of an address send with
+// tapd's change logic.
+func (f *FakeTapd) addressPacket(tapAddr *address.Tap, input *fakeOwnedAsset,
+	lastProof *proof.Proof) (*tappsbt.VPacket, error) {
+
+	coinType := f.lnd.chainParams.HDCoinType
+
+	vPkt, err := tappsbt.FromAddresses([]*address.Tap{tapAddr}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create virtual packet %v", err)
+	}
+
+	anchor, err := f.anchorInput(lastProof)
+	if err != nil {
+		return nil, err
+	}
+	scriptKey := input.asset.ScriptKey
+	derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
+		scriptKey.TweakedScriptKey.RawKey, coinType,
+	)
+	vPkt.Inputs[0] = &tappsbt.VInput{
+		PrevID: asset.PrevID{
+			OutPoint:  lastProof.OutPoint(),
+			ID:        input.asset.ID(),
+			ScriptKey: asset.ToSerialized(scriptKey.PubKey),
+		},
+		Anchor: anchor,
+		Proof:  lastProof,
+		PInput: psbt.PInput{
+			Bip32Derivation:        []*psbt.Bip32Derivation{derivation},
+			TaprootBip32Derivation: []*psbt.TaprootBip32Derivation{trDerivation},
+			SighashType:            txscript.SigHashDefault,
+		},
+	}
+	vPkt.SetInputAsset(0, input.asset.Copy())
+
+	if input.asset.Amount > tapAddr.Amount {
+		changeKey, err := f.nextScriptKey()
+		if err != nil {
+			return nil, err
+		}
+		vPkt.Outputs[0].ScriptKey = changeKey
+		vPkt.Outputs[0].Amount = input.asset.Amount - tapAddr.Amount
+	}
+
+	for _, vOut := range vPkt.Outputs {
+		if vOut.AnchorOutputInternalKey != nil {
+			continue
+		}
+		internalKey, err := f.signer.DeriveNextKey(asset.TaprootAssetsKeyFamily)
+		if err != nil {
+			return nil, err
+		}
+		vOut.SetAnchorInternalKey(internalKey, coinType)
+	}
+
+	if err := tapsend.PrepareOutputAssets(context.TODO(), vPkt); err != nil {
+		return nil, fmt.Errorf("cannot prepare output assets %v", err)
+	}
+
+	err = tapsend.SignVirtualTransaction(vPkt, &fakeVirtualTxSigner{f.signer}, &fakeWitnessValidator{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign virtual transaction %w", err)
+	}
+
+	return vPkt, nil
+}
+
+func (f *FakeTapd) ExportProof(_ context.Context, in *taprpc.ExportProofRequest,
+	_ ...grpc.CallOption) (*taprpc.ProofFile, error) {
+
+	scriptKey, err := btcec.ParsePubKey(in.ScriptKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse script key %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// The latest asset wins, an asset can return to an earlier script key
+	for i := len(f.assets) - 1; i >= 0; i-- {
+		owned := f.assets[i]
+		assetId := owned.asset.ID()
+		if !bytes.Equal(assetId[:], in.AssetId) ||
+			!bytes.Equal(schnorr.SerializePubKey(owned.asset.ScriptKey.PubKey), schnorr.SerializePubKey(scriptKey)) {
+
+			continue
+		}
+
+		return &taprpc.ProofFile{
+			RawProofFile: owned.proofFile,
+			GenesisPoint: owned.asset.Genesis.FirstPrevOut.String(),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no proof for asset %x and script key %x", in.AssetId, in.ScriptKey)
+}
+
+func (f *FakeTapd) ImportProof(_ context.Context, in *tapdevrpc.ImportProofRequest,
+	_ ...grpc.CallOption) (*tapdevrpc.ImportProofResponse, error) {
+
+	if _, err := f.addAsset(in.ProofFile); err != nil {
+		return nil, err
+	}
+
+	return &tapdevrpc.ImportProofResponse{}, nil
+}
+
+func (f *FakeTapd) SyncUniverse(_ context.Context, _ *universerpc.SyncRequest,
+	_ ...grpc.CallOption) (*universerpc.SyncResponse, error) {
+
+	// Every node shares the same FakeUniverse
+	return &universerpc.SyncResponse{}, nil
+}
+
+// fakeVirtualTxSigner signs virtual transactions with the keys of the
+// MemorySigner, the way tapd signs with lnd.
+type fakeVirtualTxSigner struct {
+	signer *taponark.MemorySigner
+}
+
+func (s *fakeVirtualTxSigner) SignVirtualTx(signDesc *lndclient.SignDescriptor,
+	tx *wire.MsgTx, prevOut *wire.TxOut) (*schnorr.Signature, error) {
+
+	privKey, err := s.signer.PrivKey(signDesc.KeyDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	return asset.SignVirtualTx(privKey, signDesc, tx, prevOut)
+}
+
+type fakeWitnessValidator struct{}
+
+func (v *fakeWitnessValidator) ValidateWitnesses(newAsset *asset.Asset,
+	splitAssets []*commitment.SplitAsset, prevAssets commitment.InputSet) error {
+
+	return vm.ValidateWitnesses(newAsset, splitAssets, prevAssets)
+}
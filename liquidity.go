@@ -0,0 +1,49 @@
+package taponark
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+)
+
+// ROUND_FUNDING_CONF_TARGET is the confirmation target lnd uses to pick the
+// fee rate when the server funds a round.
+const ROUND_FUNDING_CONF_TARGET = 6
+
+// FundRoundLiquidity lets the server's lnd wallet fund the difference between
+// the outputs and the boarded inputs of a round packet, adding a change
+// output back to the server. Any BTC the server adds to the round root flows
+// down the tree into the users' BTC VTXOs. The Ark inputs and outputs keep
+// their positions, the indexes of the added server inputs are returned so
+// they can be signed once the Ark inputs are.
+func FundRoundLiquidity(pkt *psbt.Packet, server *TapClient) (*psbt.Packet, []int, []*walletrpc.UtxoLease, error) {
+	fundedPkt, _, leases, err := server.lndClient.FundPsbt(pkt, ROUND_FUNDING_CONF_TARGET)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot fund round from server wallet %v", err)
+	}
+
+	// Coin selection only appends inputs and the change output, make
+	// sure nothing the asset commitments depend on has moved.
+	for idx, txIn := range pkt.UnsignedTx.TxIn {
+		if fundedPkt.UnsignedTx.TxIn[idx].PreviousOutPoint != txIn.PreviousOutPoint {
+			server.lndClient.ReleaseOutputs(leases)
+			return nil, nil, nil, fmt.Errorf("funded round moved input %d", idx)
+		}
+	}
+	for idx, txOut := range pkt.UnsignedTx.TxOut {
+		fundedTxOut := fundedPkt.UnsignedTx.TxOut[idx]
+		if fundedTxOut.Value != txOut.Value || !bytes.Equal(fundedTxOut.PkScript, txOut.PkScript) {
+			server.lndClient.ReleaseOutputs(leases)
+			return nil, nil, nil, fmt.Errorf("funded round moved output %d", idx)
+		}
+	}
+
+	serverInputIndexes := make([]int, 0, len(fundedPkt.Inputs)-len(pkt.Inputs))
+	for idx := len(pkt.Inputs); idx < len(fundedPkt.Inputs); idx++ {
+		serverInputIndexes = append(serverInputIndexes, idx)
+	}
+
+	return fundedPkt, serverInputIndexes, leases, nil
+}
@@ -10,7 +10,10 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/lncfg"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
@@ -24,25 +27,40 @@ import (
 type LndClient struct {
 	client      signrpc.SignerClient
 	wallet      walletrpc.WalletKitClient
-	closeClient func()
+	closeClient func() error
+}
+
+// NewLndClient creates a client of already connected lnd services.
+func NewLndClient(client signrpc.SignerClient, wallet walletrpc.WalletKitClient) LndClient {
+	return LndClient{client: client, wallet: wallet, closeClient: func() error { return nil }}
 }
 
 func InitLndClient(config LndClientConfig, tlsCert, adminMacaroon string) LndClient {
 	hostPort := config.Host + ":" + config.Port
-	clientConn, err := NewBasicLndConn(hostPort, config.Port, tlsCert, adminMacaroon)
+	clientConn, cleanUp, err := tapeConn(config.RecordFile, config.ReplayFile,
+		func(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+			return NewBasicLndConn(hostPort, config.Port, tlsCert, adminMacaroon, opts...)
+		},
+	)
 
 	if err != nil {
-		log.Fatalf("cannot initiate client")
-	}
-
-	cleanUp := func() {
-		clientConn.Close()
+		log.Fatalf("cannot initiate client %v", err)
 	}
 
 	return LndClient{client: signrpc.NewSignerClient(clientConn), wallet: walletrpc.NewWalletKitClient(clientConn), closeClient: cleanUp}
 
 }
 
+// Close closes the connection to lnd, a replayed tape must have been served
+// to its end.
+func (lc *LndClient) Close() error {
+	if err := lc.closeClient(); err != nil {
+		return fmt.Errorf("cannot close lnd client %v", err)
+	}
+
+	return nil
+}
+
 func (lc *LndClient) SendOutput(value int64, pkscript []byte) (wire.MsgTx, error) {
 	response, err := lc.wallet.SendOutputs(context.TODO(), &walletrpc.SendOutputsRequest{
 		SatPerKw: 2000,
@@ -68,7 +86,197 @@ func (lc *LndClient) SendOutput(value int64, pkscript []byte) (wire.MsgTx, error
 	return *msgTx, nil
 }
 
-func NewBasicLndConn(lndHost string, lndRpcPort, tlsPath, macPath string) (*grpc.ClientConn, error) {
+// DeriveKey returns the key of lnd at the given family and index.
+func (lc *LndClient) DeriveKey(keyLoc keychain.KeyLocator) (keychain.KeyDescriptor, error) {
+	keyDesc, err := lc.wallet.DeriveKey(context.TODO(), &signrpc.KeyLocator{
+		KeyFamily: int32(keyLoc.Family),
+		KeyIndex:  int32(keyLoc.Index),
+	})
+	if err != nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("cannot derive key %v", err)
+	}
+
+	pubKey, err := btcec.ParsePubKey(keyDesc.RawKeyBytes)
+	if err != nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("cannot parse derived key %v", err)
+	}
+
+	return keychain.KeyDescriptor{KeyLocator: keyLoc, PubKey: pubKey}, nil
+}
+
+// DeriveNextKey returns a fresh key of lnd in the given family.
+func (lc *LndClient) DeriveNextKey(family keychain.KeyFamily) (keychain.KeyDescriptor, error) {
+	keyDesc, err := lc.wallet.DeriveNextKey(context.TODO(), &walletrpc.KeyReq{
+		KeyFamily: int32(family),
+	})
+	if err != nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("cannot derive next key %v", err)
+	}
+
+	pubKey, err := btcec.ParsePubKey(keyDesc.RawKeyBytes)
+	if err != nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("cannot parse derived key %v", err)
+	}
+
+	return keychain.KeyDescriptor{
+		KeyLocator: keychain.KeyLocator{Family: family, Index: uint32(keyDesc.KeyLoc.KeyIndex)},
+		PubKey:     pubKey,
+	}, nil
+}
+
+// DeriveSharedKey returns the hashed ECDH secret of lnd's key at the locator
+// and pub.
+func (lc *LndClient) DeriveSharedKey(keyLoc keychain.KeyLocator, pub *btcec.PublicKey) ([32]byte, error) {
+	resp, err := lc.client.DeriveSharedKey(context.TODO(), &signrpc.SharedKeyRequest{
+		EphemeralPubkey: pub.SerializeCompressed(),
+		KeyLoc: &signrpc.KeyLocator{
+			KeyFamily: int32(keyLoc.Family),
+			KeyIndex:  int32(keyLoc.Index),
+		},
+	})
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("cannot derive shared key %v", err)
+	}
+
+	var sharedKey [32]byte
+	copy(sharedKey[:], resp.SharedKey)
+	return sharedKey, nil
+}
+
+// SignMessageSchnorr returns the schnorr signature of lnd's key at the
+// locator over the tagged hash of msg.
+func (lc *LndClient) SignMessageSchnorr(keyLoc keychain.KeyLocator, msg, tag []byte) ([]byte, error) {
+	resp, err := lc.client.SignMessage(context.TODO(), &signrpc.SignMessageReq{
+		Msg: msg,
+		KeyLoc: &signrpc.KeyLocator{
+			KeyFamily: int32(keyLoc.Family),
+			KeyIndex:  int32(keyLoc.Index),
+		},
+		SchnorrSig: true,
+		Tag:        tag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign message %v", err)
+	}
+
+	return resp.Signature, nil
+}
+
+// BumpFee asks lnd to CPFP the unconfirmed transaction that created the
+// wallet owned outpoint by spending it at the given fee rate.
+func (lc *LndClient) BumpFee(outpoint wire.OutPoint, satPerVByte uint64) error {
+	_, err := lc.wallet.BumpFee(context.TODO(), &walletrpc.BumpFeeRequest{
+		Outpoint: &lnrpc.OutPoint{
+			TxidBytes:   outpoint.Hash[:],
+			OutputIndex: outpoint.Index,
+		},
+		SatPerVbyte: satPerVByte,
+		Immediate:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot bump fee of %s %v", outpoint, err)
+	}
+
+	return nil
+}
+
+// FundPsbt adds wallet inputs and a P2TR change output to the packet so that
+// its inputs cover its outputs plus fees. Inputs already present in the
+// packet don't need to belong to the wallet but must carry a WitnessUtxo.
+// The returned leases lock the added inputs until released.
+func (lc *LndClient) FundPsbt(pkt *psbt.Packet, targetConf uint32) (*psbt.Packet, int32, []*walletrpc.UtxoLease, error) {
+	var buf bytes.Buffer
+	if err := pkt.Serialize(&buf); err != nil {
+		return nil, 0, nil, fmt.Errorf("cannot serialize packet %v", err)
+	}
+
+	response, err := lc.wallet.FundPsbt(context.TODO(), &walletrpc.FundPsbtRequest{
+		Template: &walletrpc.FundPsbtRequest_CoinSelect{
+			CoinSelect: &walletrpc.PsbtCoinSelect{
+				Psbt: buf.Bytes(),
+				ChangeOutput: &walletrpc.PsbtCoinSelect_Add{
+					Add: true,
+				},
+			},
+		},
+		Fees: &walletrpc.FundPsbtRequest_TargetConf{
+			TargetConf: targetConf,
+		},
+		MinConfs:              1,
+		ChangeType:            walletrpc.ChangeAddressType_CHANGE_ADDRESS_TYPE_P2TR,
+		CoinSelectionStrategy: lnrpc.CoinSelectionStrategy_STRATEGY_USE_GLOBAL_CONFIG,
+	})
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("cannot fund psbt %v", err)
+	}
+
+	fundedPkt, err := psbt.NewFromRawBytes(bytes.NewReader(response.FundedPsbt), false)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("cannot parse funded psbt %v", err)
+	}
+
+	return fundedPkt, response.ChangeOutputIndex, response.LockedUtxos, nil
+}
+
+// SignWalletInputs signs the wallet owned inputs at the given indexes and
+// adds the signatures to the packet so they can be finalised. Derivation
+// info of every other input is hidden from lnd so that inputs of other
+// signers are left alone.
+func (lc *LndClient) SignWalletInputs(pkt *psbt.Packet, inputIndexes []int) error {
+	signPkt, err := clonePacket(pkt)
+	if err != nil {
+		return err
+	}
+
+	isWalletInput := make(map[int]bool, len(inputIndexes))
+	for _, idx := range inputIndexes {
+		isWalletInput[idx] = true
+	}
+	for idx := range signPkt.Inputs {
+		if !isWalletInput[idx] {
+			stripInputDerivations(&signPkt.Inputs[idx])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := signPkt.Serialize(&buf); err != nil {
+		return fmt.Errorf("cannot serialize packet %v", err)
+	}
+
+	response, err := lc.wallet.SignPsbt(context.TODO(), &walletrpc.SignPsbtRequest{
+		FundedPsbt: buf.Bytes(),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot sign wallet inputs %v", err)
+	}
+
+	signedPkt, err := psbt.NewFromRawBytes(bytes.NewReader(response.SignedPsbt), false)
+	if err != nil {
+		return fmt.Errorf("cannot parse signed psbt %v", err)
+	}
+
+	for _, idx := range inputIndexes {
+		pkt.Inputs[idx].TaprootKeySpendSig = signedPkt.Inputs[idx].TaprootKeySpendSig
+		pkt.Inputs[idx].PartialSigs = signedPkt.Inputs[idx].PartialSigs
+	}
+
+	return nil
+}
+
+// ReleaseOutputs unlocks inputs previously leased by FundPsbt.
+func (lc *LndClient) ReleaseOutputs(leases []*walletrpc.UtxoLease) {
+	for _, lease := range leases {
+		_, err := lc.wallet.ReleaseOutput(context.TODO(), &walletrpc.ReleaseOutputRequest{
+			Id:       lease.Id,
+			Outpoint: lease.Outpoint,
+		})
+		if err != nil {
+			log.Printf("cannot release output %v: %v", lease.Outpoint, err)
+		}
+	}
+}
+
+func NewBasicLndConn(lndHost string, lndRpcPort, tlsPath, macPath string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
 
 	creds, mac, err := parseLndTLSAndMacaroon(
 		tlsPath, macPath,
@@ -98,6 +306,7 @@ func NewBasicLndConn(lndHost string, lndRpcPort, tlsPath, macPath string) (*grpc
 			lncfg.ClientAddressDialer(lndRpcPort),
 		),
 	)
+	opts = append(opts, extraOpts...)
 	conn, err := grpc.Dial(lndHost, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to RPC server: %v",
@@ -0,0 +1,249 @@
+package taponark
+
+import (
+	"bytes"
+	"fmt"
+	"taponark/arkrpc"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/proof"
+	"github.com/lightninglabs/taproot-assets/taprpc"
+	"github.com/lightningnetwork/lnd/keychain"
+)
+
+// Conversions between the messages of the Ark service and the types of the
+// round pipeline.
+
+func rpcKeyDescriptor(keyDesc keychain.KeyDescriptor) *arkrpc.KeyDescriptor {
+	rpcKeyDesc := &arkrpc.KeyDescriptor{
+		KeyFamily: int32(keyDesc.Family),
+		KeyIndex:  int32(keyDesc.Index),
+	}
+	if keyDesc.PubKey != nil {
+		rpcKeyDesc.RawKeyBytes = keyDesc.PubKey.SerializeCompressed()
+	}
+
+	return rpcKeyDesc
+}
+
+func parseRpcKeyDescriptor(rpcKeyDesc *arkrpc.KeyDescriptor) (keychain.KeyDescriptor, error) {
+	if rpcKeyDesc == nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("missing key descriptor")
+	}
+
+	keyDesc := keychain.KeyDescriptor{
+		KeyLocator: keychain.KeyLocator{
+			Family: keychain.KeyFamily(rpcKeyDesc.KeyFamily),
+			Index:  uint32(rpcKeyDesc.KeyIndex),
+		},
+	}
+	if len(rpcKeyDesc.RawKeyBytes) > 0 {
+		pubKey, err := btcec.ParsePubKey(rpcKeyDesc.RawKeyBytes)
+		if err != nil {
+			return keychain.KeyDescriptor{}, fmt.Errorf("cannot parse key %v", err)
+		}
+		keyDesc.PubKey = pubKey
+	}
+
+	return keyDesc, nil
+}
+
+func tapKeyDescriptor(rpcKeyDesc *arkrpc.KeyDescriptor) *taprpc.KeyDescriptor {
+	return &taprpc.KeyDescriptor{
+		RawKeyBytes: rpcKeyDesc.RawKeyBytes,
+		KeyLoc: &taprpc.KeyLocator{
+			KeyFamily: rpcKeyDesc.KeyFamily,
+			KeyIndex:  rpcKeyDesc.KeyIndex,
+		},
+	}
+}
+
+func tapScriptKey(rpcScriptKey *arkrpc.ScriptKey) *taprpc.ScriptKey {
+	scriptKey := &taprpc.ScriptKey{
+		PubKey:   rpcScriptKey.PubKey,
+		TapTweak: rpcScriptKey.TapTweak,
+	}
+	if rpcScriptKey.KeyDesc != nil {
+		scriptKey.KeyDesc = tapKeyDescriptor(rpcScriptKey.KeyDesc)
+	}
+
+	return scriptKey
+}
+
+func rpcTapKeyDescriptor(keyDesc *taprpc.KeyDescriptor) *arkrpc.KeyDescriptor {
+	rpcKeyDesc := &arkrpc.KeyDescriptor{RawKeyBytes: keyDesc.RawKeyBytes}
+	if keyDesc.KeyLoc != nil {
+		rpcKeyDesc.KeyFamily = keyDesc.KeyLoc.KeyFamily
+		rpcKeyDesc.KeyIndex = keyDesc.KeyLoc.KeyIndex
+	}
+
+	return rpcKeyDesc
+}
+
+func rpcTapScriptKey(scriptKey *taprpc.ScriptKey) *arkrpc.ScriptKey {
+	rpcScriptKey := &arkrpc.ScriptKey{
+		PubKey:   scriptKey.PubKey,
+		TapTweak: scriptKey.TapTweak,
+	}
+	if scriptKey.KeyDesc != nil {
+		rpcScriptKey.KeyDesc = rpcTapKeyDescriptor(scriptKey.KeyDesc)
+	}
+
+	return rpcScriptKey
+}
+
+func rpcOutputType(outputType OutputType) arkrpc.OutputType {
+	switch outputType {
+	case OutputTypeBTC:
+		return arkrpc.OutputType_OUTPUT_TYPE_BTC
+	case OutputTypeColored:
+		return arkrpc.OutputType_OUTPUT_TYPE_COLORED
+	default:
+		return arkrpc.OutputType_OUTPUT_TYPE_ASSET
+	}
+}
+
+func parseRpcOutputType(outputType arkrpc.OutputType) OutputType {
+	switch outputType {
+	case arkrpc.OutputType_OUTPUT_TYPE_BTC:
+		return OutputTypeBTC
+	case arkrpc.OutputType_OUTPUT_TYPE_COLORED:
+		return OutputTypeColored
+	default:
+		return OutputTypeAsset
+	}
+}
+
+// rpcTreeNode encodes the node and every node below it, ownerKey names the
+// participant owning a leaf output.
+func rpcTreeNode(node *RoundTreeNode, ownerKey func(*TapClient) []byte) (*arkrpc.TreeNode, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	err := node.Transaction.Serialize(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize tree transaction %v", err)
+	}
+
+	rpcNode := &arkrpc.TreeNode{
+		Leaf:  node.NodeType == NodeTypeLeaf,
+		RawTx: buf.Bytes(),
+	}
+	outputs := make([]*arkrpc.TreeOutput, 2)
+	for i, output := range []NodeOutput{node.LeftOutput, node.RightOutput} {
+		rpcOutput := &arkrpc.TreeOutput{
+			Type:        rpcOutputType(output.OutputType),
+			AssetAmount: output.AssetAmount,
+			BtcAmount:   output.BTCAmount,
+		}
+
+		if output.AssetProof != nil {
+			var proofBuf bytes.Buffer
+			err := output.AssetProof.Encode(&proofBuf)
+			if err != nil {
+				return nil, fmt.Errorf("cannot encode tree asset proof %v", err)
+			}
+			rpcOutput.AssetProof = proofBuf.Bytes()
+		}
+
+		if output.Owner != nil {
+			rpcOutput.Owner = ownerKey(output.Owner)
+		}
+		if output.Address != nil {
+			rpcOutput.Address, err = output.Address.Encode()
+			if err != nil {
+				return nil, fmt.Errorf("cannot encode tree output address %v", err)
+			}
+		}
+
+		rpcOutput.Node, err = rpcTreeNode(output.Node, ownerKey)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs[i] = rpcOutput
+	}
+	rpcNode.Left, rpcNode.Right = outputs[0], outputs[1]
+
+	return rpcNode, nil
+}
+
+// parseRpcTreeNode decodes a tree encoded by rpcTreeNode, owner maps the key
+// of a leaf output's owner to its client, nil for other participants.
+func parseRpcTreeNode(rpcNode *arkrpc.TreeNode, owner func(key []byte) *TapClient) (*RoundTreeNode, error) {
+	if rpcNode == nil {
+		return nil, nil
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	err := tx.Deserialize(bytes.NewReader(rpcNode.RawTx))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode tree transaction %v", err)
+	}
+
+	node := &RoundTreeNode{NodeType: NodeTypeBranch, Transaction: tx}
+	if rpcNode.Leaf {
+		node.NodeType = NodeTypeLeaf
+	}
+
+	outputs := make([]NodeOutput, 2)
+	for i, rpcOutput := range []*arkrpc.TreeOutput{rpcNode.Left, rpcNode.Right} {
+		if rpcOutput == nil {
+			return nil, fmt.Errorf("tree transaction %s misses an output", tx.TxHash())
+		}
+
+		output := NodeOutput{
+			OutputType:  parseRpcOutputType(rpcOutput.Type),
+			AssetAmount: rpcOutput.AssetAmount,
+			BTCAmount:   rpcOutput.BtcAmount,
+		}
+
+		if len(rpcOutput.AssetProof) > 0 {
+			output.AssetProof = &proof.Proof{}
+			err := output.AssetProof.Decode(bytes.NewReader(rpcOutput.AssetProof))
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode tree asset proof %v", err)
+			}
+		}
+
+		if len(rpcOutput.Owner) > 0 {
+			output.Owner = owner(rpcOutput.Owner)
+		}
+		if rpcOutput.Address != "" {
+			addr, err := DecodeArkAddress(rpcOutput.Address)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode tree output address %v", err)
+			}
+			output.Address = &addr
+		}
+
+		output.Node, err = parseRpcTreeNode(rpcOutput.Node, owner)
+		if err != nil {
+			return nil, err
+		}
+
+		// Every node must spend the output of its parent
+		if output.Node != nil && !spendsTx(output.Node.Transaction, tx.TxHash()) {
+			return nil, fmt.Errorf("tree transaction %s does not spend its parent %s", output.Node.Transaction.TxHash(), tx.TxHash())
+		}
+
+		outputs[i] = output
+	}
+	node.LeftOutput, node.RightOutput = outputs[0], outputs[1]
+
+	return node, nil
+}
+
+func spendsTx(tx *wire.MsgTx, parent chainhash.Hash) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.PreviousOutPoint.Hash == parent {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,441 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/commitment"
+	"github.com/lightninglabs/taproot-assets/tappsbt"
+	"github.com/lightninglabs/taproot-assets/tapscript"
+	"github.com/lightninglabs/taproot-assets/tapsend"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+)
+
+var (
+	// ErrInvalidNonce is returned when a signer hands out a malformed
+	// public nonce.
+	ErrInvalidNonce = errors.New("invalid public nonce")
+
+	// ErrInvalidPartialSig is returned when a partial signature does not
+	// verify against the signer's key and nonce.
+	ErrInvalidPartialSig = errors.New("invalid partial signature")
+
+	// ErrSignerTimeout is returned when a signer does not answer within
+	// SIGNER_TIMEOUT.
+	ErrSignerTimeout = errors.New("signer timed out")
+)
+
+// SignerError blames a single participant of a signing session. A coordinator
+// can use errors.As to find the misbehaving signer, exclude it and retry.
+type SignerError struct {
+	SignerIndex int
+	SignerKey   *btcec.PublicKey
+	Signer      Signer
+	Err         error
+}
+
+func (e *SignerError) Error() string {
+	return fmt.Sprintf("signer %d (%x): %v", e.SignerIndex,
+		e.SignerKey.SerializeCompressed(), e.Err)
+}
+
+func (e *SignerError) Unwrap() error {
+	return e.Err
+}
+
+// MuSig2Signer is one participant of a MuSig2 signing session.
+type MuSig2Signer struct {
+	signer Signer
+	key    keychain.KeyDescriptor
+}
+
+// MuSig2Session is an n-party MuSig2 signing session backed by one Signer
+// session per signer. The last signer is the coordinator which aggregates the
+// partial signatures of everybody else.
+//
+// Nonces are generated by the signers and never leave them. A session signs
+// exactly one message, after which every signer session is cleaned up, so a
+// nonce can never be reused.
+type MuSig2Session struct {
+	signers      []MuSig2Signer
+	sessionIds   [][]byte
+	publicNonces [][]byte
+
+	// signOpts mirror the tweak of the signer sessions and are used to verify
+	// partial signatures before they are combined
+	signOpts []musig2.SignOption
+
+	// open tracks the signer sessions that still hold a secret nonce
+	open   []bool
+	signed bool
+}
+
+// NewMuSig2Session opens a session for every signer over the aggregate of all
+// signer keys. Callers must Close the session once done with it.
+func NewMuSig2Session(signers []MuSig2Signer, tweak *signrpc.TaprootTweakDesc) (*MuSig2Session, error) {
+	if len(signers) < 2 {
+		return nil, fmt.Errorf("musig2 session needs at least 2 signers, got %d", len(signers))
+	}
+
+	allSignerKeys := make([][]byte, len(signers))
+	for i, signer := range signers {
+		allSignerKeys[i] = signer.key.PubKey.SerializeCompressed()
+	}
+
+	session := &MuSig2Session{
+		signers:      signers,
+		sessionIds:   make([][]byte, len(signers)),
+		publicNonces: make([][]byte, len(signers)),
+		open:         make([]bool, len(signers)),
+		signOpts:     muSig2SignOptions(tweak),
+	}
+
+	for i, signer := range signers {
+		created, err := callSigner(func(ctx context.Context) ([2][]byte, error) {
+			sessionId, publicNonce, err := signer.signer.MuSig2CreateSession(
+				ctx, signer.key, allSignerKeys, tweak,
+			)
+			return [2][]byte{sessionId, publicNonce}, err
+		})
+		if err != nil {
+			session.Close()
+			return nil, session.blame(i, err)
+		}
+
+		session.sessionIds[i] = created[0]
+		session.publicNonces[i] = created[1]
+		session.open[i] = true
+	}
+
+	return session, nil
+}
+
+// ExchangeNonces checks the public nonce of every signer and hands it to all
+// other signers.
+func (s *MuSig2Session) ExchangeNonces() error {
+	for i, publicNonce := range s.publicNonces {
+		if err := checkPublicNonce(publicNonce); err != nil {
+			return s.blame(i, err)
+		}
+	}
+
+	for i, signer := range s.signers {
+		_, err := callSigner(func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, signer.signer.MuSig2RegisterNonces(ctx, s.sessionIds[i], s.otherValues(s.publicNonces, i))
+		})
+		if err != nil {
+			return s.blame(i, err)
+		}
+	}
+
+	return nil
+}
+
+// Sign collects a partial signature over sigHash from every signer and lets
+// the coordinator combine them into the final schnorr signature. A session
+// can only sign once, even if the first attempt failed.
+func (s *MuSig2Session) Sign(sigHash []byte) ([]byte, error) {
+	if s.signed {
+		return nil, fmt.Errorf("musig2 session already used, nonces cannot be reused")
+	}
+	s.signed = true
+	defer s.Close()
+
+	coordinator := len(s.signers) - 1
+
+	partialSigs := make([][]byte, len(s.signers))
+	for i, signer := range s.signers {
+		// The coordinator keeps its session around to combine
+		cleanup := i != coordinator
+		partialSig, err := callSigner(func(ctx context.Context) ([]byte, error) {
+			return signer.signer.MuSig2Sign(ctx, s.sessionIds[i], sigHash, cleanup)
+		})
+		if err != nil {
+			return nil, s.blame(i, err)
+		}
+		if cleanup {
+			s.open[i] = false
+		}
+		partialSigs[i] = partialSig
+	}
+
+	// Check every partial signature before aggregation so a bad one is
+	// attributed to its signer instead of surfacing as an invalid witness
+	for i, partialSig := range partialSigs {
+		if err := s.verifyPartialSig(i, partialSig, sigHash); err != nil {
+			return nil, s.blame(i, err)
+		}
+	}
+
+	finalSig, err := callSigner(func(ctx context.Context) ([]byte, error) {
+		return s.signers[coordinator].signer.MuSig2CombineSigs(
+			ctx, s.sessionIds[coordinator], s.otherValues(partialSigs, coordinator),
+		)
+	})
+	if err != nil {
+		return nil, s.blame(coordinator, err)
+	}
+
+	return finalSig, nil
+}
+
+// Close removes every signer session that still holds a secret nonce. It is
+// safe to call multiple times.
+func (s *MuSig2Session) Close() {
+	for i, signer := range s.signers {
+		if !s.open[i] {
+			continue
+		}
+
+		err := signer.signer.MuSig2Cleanup(context.TODO(), s.sessionIds[i])
+		if err != nil {
+			log.Printf("cannot cleanup musig2 session of signer %d %v", i, err)
+			continue
+		}
+		s.open[i] = false
+	}
+}
+
+// verifyPartialSig checks the partial signature of a signer against its key
+// and public nonce.
+func (s *MuSig2Session) verifyPartialSig(signerIndex int, partialSig []byte, sigHash []byte) error {
+	if len(partialSig) != 32 {
+		return fmt.Errorf("%w: expected 32 bytes, got %d", ErrInvalidPartialSig, len(partialSig))
+	}
+	if len(sigHash) != 32 {
+		return fmt.Errorf("cannot verify sighash of %d bytes", len(sigHash))
+	}
+
+	var sig musig2.PartialSignature
+	if err := sig.Decode(bytes.NewReader(partialSig)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPartialSig, err)
+	}
+
+	publicNonces := make([][musig2.PubNonceSize]byte, len(s.publicNonces))
+	for i, publicNonce := range s.publicNonces {
+		copy(publicNonces[i][:], publicNonce)
+	}
+	combinedNonce, err := musig2.AggregateNonces(publicNonces)
+	if err != nil {
+		return fmt.Errorf("cannot aggregate nonces %v", err)
+	}
+
+	keySet := make([]*btcec.PublicKey, len(s.signers))
+	for i, signer := range s.signers {
+		keySet[i] = signer.key.PubKey
+	}
+
+	var msg [32]byte
+	copy(msg[:], sigHash)
+
+	if !sig.Verify(publicNonces[signerIndex], combinedNonce, keySet,
+		s.signers[signerIndex].key.PubKey, msg, s.signOpts...) {
+
+		return ErrInvalidPartialSig
+	}
+
+	return nil
+}
+
+func (s *MuSig2Session) blame(signerIndex int, err error) error {
+	signer := s.signers[signerIndex]
+	return &SignerError{signerIndex, signer.key.PubKey, signer.signer, err}
+}
+
+// callSigner runs a call into a signer, giving up after SIGNER_TIMEOUT so one
+// unresponsive participant cannot stall everybody else. The context of the
+// call is cancelled on timeout, a late result is dropped.
+func callSigner[T any](call func(ctx context.Context) (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SIGNER_TIMEOUT)
+	defer cancel()
+
+	done := make(chan result, 1)
+	go func() {
+		value, err := call(ctx)
+		done <- result{value, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ErrSignerTimeout
+	}
+}
+
+// checkPublicNonce makes sure the nonce holds two valid points.
+func checkPublicNonce(publicNonce []byte) error {
+	if len(publicNonce) != musig2.PubNonceSize {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidNonce, musig2.PubNonceSize, len(publicNonce))
+	}
+
+	for _, point := range [][]byte{publicNonce[:33], publicNonce[33:]} {
+		if _, err := btcec.ParsePubKey(point); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidNonce, err)
+		}
+	}
+
+	return nil
+}
+
+// muSig2SignOptions maps the signer session tweak onto the options needed to
+// verify partial signatures locally. Signers always sort the keys.
+func muSig2SignOptions(tweak *signrpc.TaprootTweakDesc) []musig2.SignOption {
+	signOpts := []musig2.SignOption{musig2.WithSortedKeys()}
+	switch {
+	case tweak == nil:
+	case tweak.KeySpendOnly:
+		signOpts = append(signOpts, musig2.WithBip86SignTweak())
+	default:
+		signOpts = append(signOpts, musig2.WithTaprootSignTweak(tweak.ScriptRoot))
+	}
+
+	return signOpts
+}
+
+func (s *MuSig2Session) otherValues(values [][]byte, skip int) [][]byte {
+	others := make([][]byte, 0, len(values)-1)
+	for i, value := range values {
+		if i != skip {
+			others = append(others, value)
+		}
+	}
+
+	return others
+}
+
+// virtualTxSigner signs a virtual asset transaction and skips the validation
+// of its witnesses, the leaf witness is only complete once the caller pushes
+// the rest of its stack.
+type virtualTxSigner interface {
+	tapscript.Signer
+	tapscript.WitnessValidator
+}
+
+// muSig2SessionSigner signs a virtual asset transaction through the
+// cooperative MuSig2 leaf of an Ark asset script.
+type muSig2SessionSigner struct {
+	session    *MuSig2Session
+	leafToSign txscript.TapLeaf
+}
+
+func (m *muSig2SessionSigner) ValidateWitnesses(*asset.Asset,
+	[]*commitment.SplitAsset, commitment.InputSet) error {
+
+	return nil
+}
+
+func (m *muSig2SessionSigner) SignVirtualTx(_ *lndclient.SignDescriptor,
+	tx *wire.MsgTx, prevOut *wire.TxOut) (*schnorr.Signature, error) {
+
+	prevOutputFetcher := txscript.NewCannedPrevOutputFetcher(
+		prevOut.PkScript, prevOut.Value,
+	)
+	sighashes := txscript.NewTxSigHashes(tx, prevOutputFetcher)
+
+	sigHash, err := txscript.CalcTapscriptSignaturehash(
+		sighashes, txscript.SigHashDefault, tx, 0, prevOutputFetcher,
+		m.leafToSign,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	finalSig, err := m.session.Sign(sigHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return schnorr.ParseSignature(finalSig)
+}
+
+func (m *muSig2SessionSigner) Execute(*asset.Asset, []*commitment.SplitAsset,
+	commitment.InputSet) error {
+
+	return nil
+}
+
+// leafKeySigner signs a virtual asset transaction through a leaf of a single
+// key, e.g. a unilateral leaf, with SignBtcTapscript of the key's Signer.
+type leafKeySigner struct {
+	signer       Signer
+	key          keychain.KeyDescriptor
+	leafToSign   txscript.TapLeaf
+	controlBlock []byte
+}
+
+func (l *leafKeySigner) ValidateWitnesses(*asset.Asset,
+	[]*commitment.SplitAsset, commitment.InputSet) error {
+
+	return nil
+}
+
+func (l *leafKeySigner) SignVirtualTx(_ *lndclient.SignDescriptor,
+	tx *wire.MsgTx, prevOut *wire.TxOut) (*schnorr.Signature, error) {
+
+	pkt, err := psbt.NewFromUnsignedTx(tx.Copy())
+	if err != nil {
+		return nil, fmt.Errorf("cannot create virtual tx packet %v", err)
+	}
+	pkt.Inputs[0].WitnessUtxo = prevOut
+
+	sigs, err := callSigner(func(ctx context.Context) ([][]byte, error) {
+		return l.signer.SignBtcTapscript(
+			ctx, pkt, []int{0},
+			[]keychain.KeyDescriptor{l.key},
+			[][]byte{l.controlBlock},
+			[]txscript.TapLeaf{l.leafToSign},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schnorr.ParseSignature(sigs[0])
+}
+
+// signAssetTransfer signs the virtual transaction with the virtual signer,
+// e.g. the MuSig2 session of all cosigners of the leaf. The derivation is
+// only used to mark the input as ours, the signers ignore its path.
+func signAssetTransfer(assetTransferPacket *tappsbt.VPacket, virtualSigner virtualTxSigner,
+	localScriptKey keychain.KeyDescriptor) error {
+
+	vIn := assetTransferPacket.Inputs[0]
+	derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
+		keychain.KeyDescriptor{
+			PubKey: localScriptKey.PubKey,
+		}, 0,
+	)
+	vIn.Bip32Derivation = []*psbt.Bip32Derivation{derivation}
+	vIn.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{
+		trDerivation,
+	}
+
+	// Note: This also adds Split Commitment Root to Split Asset
+	err := tapsend.SignVirtualTransaction(
+		assetTransferPacket, virtualSigner, virtualSigner,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot Sign Virtual Transaction %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package taponark
+
+import (
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/taproot-assets/address"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfig reads the config-{network}.yaml file of the network from the
+// working directory.
+func LoadConfig(network string) Config {
+	// Read the YAML configuration file
+	configFile := "config-regtest.yaml"
+
+	if network == "signet" {
+		configFile = "config-signet.yaml"
+	} else if network == "mutinynet" {
+		configFile = "config-mutinynet.yaml"
+	} else if network == "offline" {
+		configFile = "config-offline.yaml"
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	// Create an instance of the Config struct
+	var config Config
+
+	// Unmarshal the YAML data into the struct
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	return config
+}
+
+// NetworkParams returns the chain and Taproot Asset parameters of a docker
+// network.
+func NetworkParams(network string, config Config) (chaincfg.Params, address.ChainParams) {
+	chainParams := chaincfg.RegressionNetParams
+	tapParams := address.RegressionNetTap
+	if network == "signet" {
+		chainParams = chaincfg.SigNetParams
+		tapParams = address.SigNetTap
+	} else if network == "mutinynet" {
+		if config.SignetChallenge == nil {
+			log.Panicln("Signet Challenge Must be Present in Signet")
+		}
+
+		signetChallenge, err := hex.DecodeString(*config.SignetChallenge)
+		if err != nil {
+			log.Panicf("Signet Challenge cannot be decoded %v", err)
+		}
+
+		chainParams = chaincfg.CustomSignetParams(signetChallenge, []chaincfg.DNSSeed{})
+		tapParams = address.SigNetTap
+	}
+
+	return chainParams, tapParams
+}
+
+// DeriveLndTlsAndMacaroonHex reads the daemon credentials from its docker
+// volume. A client replaying a gRPC tape never dials, so it needs none.
+func DeriveLndTlsAndMacaroonHex(clientConfig LndClientConfig, network string) (string, string) {
+	if clientConfig.ReplayFile != "" {
+		return "", ""
+	}
+	container := clientConfig.Container
+	tlsPath := filepath.Join("data", network, "volumes", "lnd", container, "tls.cert")
+	tlsBytes, err := os.ReadFile(tlsPath)
+	if err != nil {
+		log.Fatalf("failed to read TLS certificate: %v", err)
+	}
+	tlsHex := hex.EncodeToString(tlsBytes)
+
+	// Read macaroon file
+	macaroonFileDir := network
+	if network == "mutinynet" {
+		macaroonFileDir = "signet"
+	}
+	macaroonPath := filepath.Join("data", network, "volumes", "lnd", container, "data", "chain", "bitcoin", macaroonFileDir, "admin.macaroon")
+	macaroonBytes, err := os.ReadFile(macaroonPath)
+	if err != nil {
+		log.Fatalf("failed to read macaroon file: %v", err)
+	}
+	macaroonHex := hex.EncodeToString(macaroonBytes)
+	return tlsHex, macaroonHex
+}
+
+// DeriveTapTlsAndMacaroonHex reads the daemon credentials from its docker
+// volume. A client replaying a gRPC tape never dials, so it needs none.
+func DeriveTapTlsAndMacaroonHex(clientConfig TapClientConfig, network string) (string, string) {
+	if clientConfig.ReplayFile != "" {
+		return "", ""
+	}
+	container := clientConfig.Container
+	tlsPath := filepath.Join("data", network, "volumes", "tapd", container, "tls.cert")
+	tlsBytes, err := os.ReadFile(tlsPath)
+	if err != nil {
+		log.Fatalf("failed to read TLS certificate: %v", err)
+	}
+	tlsHex := hex.EncodeToString(tlsBytes)
+
+	// Read macaroon file
+	macaroonFileDir := network
+	if network == "mutinynet" {
+		macaroonFileDir = "signet"
+	}
+	macaroonPath := filepath.Join("data", network, "volumes", "tapd", container, "data", macaroonFileDir, "admin.macaroon")
+	macaroonBytes, err := os.ReadFile(macaroonPath)
+	if err != nil {
+		log.Fatalf("failed to read macaroon file: %v", err)
+	}
+	macaroonHex := hex.EncodeToString(macaroonBytes)
+	return tlsHex, macaroonHex
+}
+
+// InitNode connects the tapd and lnd daemons of one docker network node.
+func InitNode(network, universeHost string, tapConfig TapClientConfig, lndConfig LndClientConfig, config Config) TapClient {
+	chainParams, tapParams := NetworkParams(network, config)
+	timeout := time.Duration(config.Timeout) * time.Minute
+
+	tapTlsHex, tapMacaroonHex := DeriveTapTlsAndMacaroonHex(tapConfig, network)
+	lndTlsHex, lndMacaroonHex := DeriveLndTlsAndMacaroonHex(lndConfig, network)
+	lndClient := InitLndClient(lndConfig, lndTlsHex, lndMacaroonHex)
+	return InitTapClient(universeHost, tapConfig, lndClient, tapTlsHex, tapMacaroonHex, chainParams, tapParams, timeout)
+}
@@ -0,0 +1,315 @@
+package taponark
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/proof"
+)
+
+// Payment requests use the prefix of addresses of their network followed by
+// this suffix.
+const paymentRequestHrpSuffix = "req"
+
+// PaymentRequest asks for Amount of the asset paid to Address until Expiry.
+// The receiver signs it with the VTXO key of the address.
+type PaymentRequest struct {
+	AssetId   []byte
+	Amount    uint64
+	Address   ArkAddress
+	Expiry    time.Time
+	Memo      string
+	Signature []byte
+}
+
+// PaymentReceipt proves a paid request, its proof file ends in the transfer
+// of the asset to the VTXO of the request's address at Outpoint.
+type PaymentReceipt struct {
+	Request      PaymentRequest
+	Outpoint     string
+	GenesisPoint string
+	ProofFile    []byte
+}
+
+// NewPaymentRequest returns a request of the receiver for amount of the asset
+// to one of its addresses, valid for expiry.
+func NewPaymentRequest(receiver *TapClient, addr ArkAddress, assetId []byte, amount uint64, expiry time.Duration, memo string) (PaymentRequest, error) {
+	request := PaymentRequest{
+		AssetId: assetId,
+		Amount:  amount,
+		Address: addr,
+		Expiry:  time.Now().Add(expiry).Truncate(time.Second),
+		Memo:    memo,
+	}
+	err := request.check()
+	if err != nil {
+		return PaymentRequest{}, err
+	}
+
+	vtxoKey, err := receiver.ArkVtxoKey(addr)
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("ark address does not belong to the receiver %v", err)
+	}
+
+	request.Signature, err = receiver.lndClient.SignMessageSchnorr(vtxoKey.KeyLocator, request.signedPayload(), []byte(PAYMENT_REQUEST_TAG))
+	if err != nil {
+		return PaymentRequest{}, err
+	}
+
+	return request, nil
+}
+
+// check verifies the request fits its address.
+func (r PaymentRequest) check() error {
+	if len(r.AssetId) != len(asset.ID{}) {
+		return fmt.Errorf("invalid asset id length %d", len(r.AssetId))
+	}
+	if r.Amount == 0 {
+		return fmt.Errorf("payment request has no amount")
+	}
+	if len(r.Memo) > MAX_PAYMENT_REQUEST_MEMO {
+		return fmt.Errorf("memo longer than %d bytes", MAX_PAYMENT_REQUEST_MEMO)
+	}
+	if len(r.Address.AssetId) != 0 && !bytes.Equal(r.Address.AssetId, r.AssetId) {
+		return fmt.Errorf("ark address receives asset %x, not %x", r.Address.AssetId, r.AssetId)
+	}
+	if r.Address.Amount != 0 && r.Address.Amount != r.Amount {
+		return fmt.Errorf("ark address requests %d, not %d", r.Address.Amount, r.Amount)
+	}
+
+	return nil
+}
+
+// signedPayload serializes every field of the request but its signature. The
+// address comes last, its payload runs to the signature.
+func (r PaymentRequest) signedPayload() []byte {
+	var payload bytes.Buffer
+	payload.WriteByte(PAYMENT_REQUEST_VERSION)
+	payload.Write(r.AssetId)
+	binary.Write(&payload, binary.BigEndian, r.Amount)
+	binary.Write(&payload, binary.BigEndian, r.Expiry.Unix())
+	binary.Write(&payload, binary.BigEndian, uint16(len(r.Memo)))
+	payload.WriteString(r.Memo)
+	payload.Write(r.Address.payload())
+
+	return payload.Bytes()
+}
+
+// Encode returns the bech32m encoding of the signed request.
+func (r PaymentRequest) Encode() (string, error) {
+	hrp, ok := arkAddressHrps[r.Address.Network]
+	if !ok {
+		return "", fmt.Errorf("no payment requests on network %s", r.Address.Network)
+	}
+
+	return encodeBech32m(hrp+paymentRequestHrpSuffix, append(r.signedPayload(), r.Signature...))
+}
+
+// DecodePaymentRequest parses a bech32m encoded payment request, it still
+// needs to be verified.
+func DecodePaymentRequest(encoded string) (PaymentRequest, error) {
+	hrp, raw, err := decodeBech32m(encoded)
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("cannot decode payment request %v", err)
+	}
+
+	network, err := hrpNetwork(hrp, paymentRequestHrpSuffix)
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("cannot decode payment request %v", err)
+	}
+
+	if len(raw) < schnorr.SignatureSize {
+		return PaymentRequest{}, fmt.Errorf("payment request is not signed")
+	}
+	signature := raw[len(raw)-schnorr.SignatureSize:]
+	payload := bytes.NewReader(raw[:len(raw)-schnorr.SignatureSize])
+
+	version, err := payload.ReadByte()
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("empty payment request")
+	}
+	if version != PAYMENT_REQUEST_VERSION {
+		return PaymentRequest{}, fmt.Errorf("unknown payment request version %d", version)
+	}
+
+	request := PaymentRequest{AssetId: make([]byte, len(asset.ID{})), Signature: signature}
+	n, _ := payload.Read(request.AssetId)
+	if n != len(request.AssetId) {
+		return PaymentRequest{}, fmt.Errorf("invalid payment request asset id")
+	}
+
+	var expiry int64
+	var memoLen uint16
+	err = binary.Read(payload, binary.BigEndian, &request.Amount)
+	if err == nil {
+		err = binary.Read(payload, binary.BigEndian, &expiry)
+	}
+	if err == nil {
+		err = binary.Read(payload, binary.BigEndian, &memoLen)
+	}
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("invalid payment request %v", err)
+	}
+	request.Expiry = time.Unix(expiry, 0)
+
+	memo := make([]byte, memoLen)
+	n, _ = payload.Read(memo)
+	if n != len(memo) {
+		return PaymentRequest{}, fmt.Errorf("invalid payment request memo")
+	}
+	request.Memo = string(memo)
+
+	addrPayload := make([]byte, payload.Len())
+	payload.Read(addrPayload)
+	request.Address, err = parseArkAddress(network, addrPayload)
+	if err != nil {
+		return PaymentRequest{}, err
+	}
+
+	return request, nil
+}
+
+// Verify checks the request is signed by the receiver of its address and not
+// expired at now.
+func (r PaymentRequest) Verify(now time.Time) error {
+	err := r.verifySignature()
+	if err != nil {
+		return err
+	}
+
+	if now.After(r.Expiry) {
+		return fmt.Errorf("payment request expired at %s", r.Expiry)
+	}
+
+	return nil
+}
+
+func (r PaymentRequest) verifySignature() error {
+	err := r.check()
+	if err != nil {
+		return err
+	}
+
+	signature, err := schnorr.ParseSignature(r.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid payment request signature %v", err)
+	}
+
+	digest := chainhash.TaggedHash([]byte(PAYMENT_REQUEST_TAG), r.signedPayload())
+	if !signature.Verify(digest[:], r.Address.VtxoKey) {
+		return fmt.Errorf("payment request is not signed by the receiver")
+	}
+
+	return nil
+}
+
+// CheckPayable checks the request is valid and asks for amount. Out of round
+// payments carry no change, so the boarding paying a request must be of
+// exactly its amount. Payers check it before boarding.
+func (r PaymentRequest) CheckPayable(amount uint64) error {
+	err := r.Verify(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if amount != r.Amount {
+		return fmt.Errorf("request asks for %d but %d is boarded, payments carry no change so board exactly the requested amount", r.Amount, amount)
+	}
+
+	return nil
+}
+
+// PayRequest verifies the request and pays it out of round with the boarded
+// asset and BTC of the boarding user, which must match the requested amount.
+// The receiver claims the returned VTXO, the receipt proves the payment. Once
+// the payment is broadcast the VTXO and receipt are always returned, a
+// receipt that does not verify comes with a ReceiptVerificationError.
+func PayRequest(request PaymentRequest, onboardTransfer ArkBoardingTransfer, server *TapClient, bitcoinClient *BitcoinClient) (ArkAddressVtxo, PaymentReceipt, error) {
+	err := request.CheckPayable(onboardTransfer.AssetTransferDetails.assetBoardingAmount)
+	if err != nil {
+		return ArkAddressVtxo{}, PaymentReceipt{}, err
+	}
+
+	vtxo, err := SendToArkAddress(request.AssetId, onboardTransfer, request.Address, server, bitcoinClient)
+	if err != nil {
+		return ArkAddressVtxo{}, PaymentReceipt{}, err
+	}
+
+	receipt := PaymentReceipt{request, vtxo.Outpoint(), vtxo.GenesisPoint, vtxo.ProofFile}
+	err = receipt.Verify(bitcoinClient)
+	if err != nil {
+		return vtxo, receipt, &ReceiptVerificationError{receipt.Outpoint, err}
+	}
+
+	return vtxo, receipt, nil
+}
+
+// ReceiptVerificationError is returned by PayRequest when the request was paid
+// but its receipt does not verify. The payment is final, the request must not
+// be paid again.
+type ReceiptVerificationError struct {
+	Outpoint string
+	Err      error
+}
+
+func (e *ReceiptVerificationError) Error() string {
+	return fmt.Sprintf("paid to %s but the receipt does not verify: %v", e.Outpoint, e.Err)
+}
+
+func (e *ReceiptVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Verify checks the receipt's request is signed by the receiver and the proof
+// file, verified against the chain, transfers the requested asset and amount
+// to the VTXO of its address in a transaction confirmed at Outpoint.
+func (r PaymentReceipt) Verify(bitcoinClient *BitcoinClient) error {
+	err := r.Request.verifySignature()
+	if err != nil {
+		return err
+	}
+
+	proofFile, err := proof.DecodeFile(r.ProofFile)
+	if err != nil {
+		return fmt.Errorf("cannot decode receipt proof file %v", err)
+	}
+	lastProof, err := VerifyProofFile(proofFile, bitcoinClient.backend)
+	if err != nil {
+		return fmt.Errorf("invalid receipt proof file %v", err)
+	}
+
+	policy, err := r.Request.Address.vtxoPolicy()
+	if err != nil {
+		return fmt.Errorf("cannot create ark address policy %v", err)
+	}
+
+	paidAsset := lastProof.Asset
+	assetId := paidAsset.ID()
+	switch {
+	case !bytes.Equal(assetId[:], r.Request.AssetId):
+		return fmt.Errorf("receipt pays asset %x, not %x", assetId[:], r.Request.AssetId)
+	case paidAsset.Amount != r.Request.Amount:
+		return fmt.Errorf("receipt pays %d, not %d", paidAsset.Amount, r.Request.Amount)
+	case !paidAsset.ScriptKey.PubKey.IsEqual(policy.AssetScriptKey().PubKey):
+		return fmt.Errorf("receipt does not pay the vtxo of the ark address")
+	case lastProof.OutPoint().String() != r.Outpoint:
+		return fmt.Errorf("receipt proof anchors at %s, not %s", lastProof.OutPoint(), r.Outpoint)
+	}
+
+	anchorTxid := lastProof.AnchorTx.TxHash()
+	status, err := bitcoinClient.backend.GetTransactionStatus(&anchorTxid)
+	if err != nil {
+		return fmt.Errorf("cannot get status of receipt anchor %s %v", anchorTxid, err)
+	}
+	anchorBlock := lastProof.BlockHeader.BlockHash()
+	if !status.Confirmed || !status.BlockHash.IsEqual(&anchorBlock) {
+		return fmt.Errorf("receipt anchor %s is not confirmed in block %s", anchorTxid, anchorBlock)
+	}
+
+	return nil
+}
@@ -0,0 +1,550 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lightninglabs/taproot-assets/asset"
+	"github.com/lightninglabs/taproot-assets/commitment"
+	"github.com/lightninglabs/taproot-assets/tappsbt"
+	"github.com/lightninglabs/taproot-assets/tapsend"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+)
+
+// Names of the leaves used by the Ark outputs. Policies are free to use any
+// other name for their own leaves.
+const (
+	ArkLeafCollaborative = "collaborative"
+	ArkLeafExit          = "exit"
+	ArkLeafSweep         = "sweep"
+	ArkLeafClaim         = "claim"
+	ArkLeafRefund        = "refund"
+
+	ArkLeafUnilateralClaim  = "unilateral-claim"
+	ArkLeafUnilateralRefund = "unilateral-refund"
+)
+
+// ArkLeaf is a named spending condition of an Ark output.
+type ArkLeaf struct {
+	Name string
+	Leaf txscript.TapLeaf
+}
+
+// ArkScriptPolicy is the tapscript tree of an Ark output built from any
+// number of named leaves. The same policy yields the asset script key and the
+// BTC tapscript sibling committed next to the Taproot Asset commitment.
+type ArkScriptPolicy struct {
+	leaves []ArkLeaf
+	tree   *txscript.IndexedTapScriptTree
+}
+
+func NewArkLeaf(name string, script []byte) ArkLeaf {
+	return ArkLeaf{name, txscript.NewBaseTapLeaf(script)}
+}
+
+// MultisigLeaf returns an n-of-n OP_CHECKSIGADD leaf of the keys.
+func MultisigLeaf(name string, keys []*btcec.PublicKey) (ArkLeaf, error) {
+	if len(keys) == 0 {
+		return ArkLeaf{}, fmt.Errorf("leaf %s has no keys", name)
+	}
+
+	builder := txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(keys[0])).
+		AddOp(txscript.OP_CHECKSIG)
+	for _, key := range keys[1:] {
+		builder.AddData(schnorr.SerializePubKey(key)).
+			AddOp(txscript.OP_CHECKSIGADD)
+	}
+
+	script, err := builder.AddInt64(int64(len(keys))).
+		AddOp(txscript.OP_EQUAL).
+		Script()
+	if err != nil {
+		return ArkLeaf{}, fmt.Errorf("failed to create %s script: %w", name, err)
+	}
+
+	return NewArkLeaf(name, script), nil
+}
+
+// CheckSigLeaf returns a leaf spendable by a single signature of the key, e.g.
+// a MuSig2 aggregate of the cosigners.
+func CheckSigLeaf(name string, key *btcec.PublicKey) (ArkLeaf, error) {
+	script, err := txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(key)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return ArkLeaf{}, fmt.Errorf("failed to create %s script: %w", name, err)
+	}
+
+	return NewArkLeaf(name, script), nil
+}
+
+// AbsoluteTimelockLeaf returns a leaf the key can spend alone once the chain
+// reaches lockHeight.
+func AbsoluteTimelockLeaf(name string, key *btcec.PublicKey, lockHeight uint32) (ArkLeaf, error) {
+	script, err := txscript.NewScriptBuilder().
+		AddInt64(int64(lockHeight)).
+		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
+		AddOp(txscript.OP_DROP).
+		AddData(schnorr.SerializePubKey(key)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return ArkLeaf{}, fmt.Errorf("failed to create %s script: %w", name, err)
+	}
+
+	return NewArkLeaf(name, script), nil
+}
+
+// RelativeTimelockLeaf returns a leaf the key can spend alone once the output
+// is delay blocks deep.
+func RelativeTimelockLeaf(name string, key *btcec.PublicKey, delay uint32) (ArkLeaf, error) {
+	script, err := txscript.NewScriptBuilder().
+		AddInt64(int64(delay)).
+		AddOp(txscript.OP_CHECKSEQUENCEVERIFY).
+		AddOp(txscript.OP_DROP).
+		AddData(schnorr.SerializePubKey(key)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return ArkLeaf{}, fmt.Errorf("failed to create %s script: %w", name, err)
+	}
+
+	return NewArkLeaf(name, script), nil
+}
+
+// HashlockLeaf returns a leaf the key can spend by revealing the preimage of
+// the SHA256 paymentHash.
+func HashlockLeaf(name string, paymentHash []byte, key *btcec.PublicKey) (ArkLeaf, error) {
+	script, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_SIZE).
+		AddInt64(32).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_SHA256).
+		AddData(paymentHash).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddData(schnorr.SerializePubKey(key)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return ArkLeaf{}, fmt.Errorf("failed to create %s script: %w", name, err)
+	}
+
+	return NewArkLeaf(name, script), nil
+}
+
+// ConditionLeaf returns a leaf the key can spend when the condition script
+// leaves a true value on the stack.
+func ConditionLeaf(name string, condition []byte, key *btcec.PublicKey) (ArkLeaf, error) {
+	script, err := txscript.NewScriptBuilder().
+		AddOps(condition).
+		AddOp(txscript.OP_VERIFY).
+		AddData(schnorr.SerializePubKey(key)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return ArkLeaf{}, fmt.Errorf("failed to create %s script: %w", name, err)
+	}
+
+	return NewArkLeaf(name, script), nil
+}
+
+// NewArkScriptPolicy assembles the leaves into a tapscript tree. Leaves keep
+// their order, so two leaves form a single branch.
+func NewArkScriptPolicy(leaves ...ArkLeaf) (ArkScriptPolicy, error) {
+	if len(leaves) == 0 {
+		return ArkScriptPolicy{}, fmt.Errorf("script policy has no leaves")
+	}
+
+	names := make(map[string]struct{}, len(leaves))
+	hashes := make(map[[32]byte]struct{}, len(leaves))
+	tapLeaves := make([]txscript.TapLeaf, len(leaves))
+	for i, leaf := range leaves {
+		if _, ok := names[leaf.Name]; ok {
+			return ArkScriptPolicy{}, fmt.Errorf("duplicate leaf %s", leaf.Name)
+		}
+		if _, ok := hashes[leaf.Leaf.TapHash()]; ok {
+			return ArkScriptPolicy{}, fmt.Errorf("leaf %s repeats the script of another leaf", leaf.Name)
+		}
+		names[leaf.Name] = struct{}{}
+		hashes[leaf.Leaf.TapHash()] = struct{}{}
+		tapLeaves[i] = leaf.Leaf
+	}
+
+	return ArkScriptPolicy{
+		leaves: append([]ArkLeaf{}, leaves...),
+		tree:   txscript.AssembleTaprootScriptTree(tapLeaves...),
+	}, nil
+}
+
+func (p ArkScriptPolicy) Leaves() []ArkLeaf {
+	return append([]ArkLeaf{}, p.leaves...)
+}
+
+func (p ArkScriptPolicy) HasLeaf(name string) bool {
+	_, err := p.Leaf(name)
+	return err == nil
+}
+
+func (p ArkScriptPolicy) Leaf(name string) (txscript.TapLeaf, error) {
+	for _, leaf := range p.leaves {
+		if leaf.Name == name {
+			return leaf.Leaf, nil
+		}
+	}
+
+	return txscript.TapLeaf{}, fmt.Errorf("script policy has no %s leaf", name)
+}
+
+func (p ArkScriptPolicy) RootHash() [32]byte {
+	return p.tree.RootNode.TapHash()
+}
+
+// TapscriptSibling returns the preimage of the policy tree for anchoring it
+// next to a Taproot Asset commitment.
+func (p ArkScriptPolicy) TapscriptSibling() (*commitment.TapscriptPreimage, error) {
+	switch root := p.tree.RootNode.(type) {
+	case txscript.TapLeaf:
+		return commitment.NewPreimageFromLeaf(root)
+	case txscript.TapBranch:
+		preimage := commitment.NewPreimageFromBranch(root)
+		return &preimage, nil
+	default:
+		return nil, fmt.Errorf("unknown tapscript node %T", root)
+	}
+}
+
+// ControlBlock proves the named leaf under internalKey. The hashes of nodes
+// above the policy tree, like the Taproot Asset root of an anchor output, are
+// appended to the inclusion proof from the bottom up.
+func (p ArkScriptPolicy) ControlBlock(name string, internalKey *btcec.PublicKey, upperHashes ...[]byte) (*txscript.ControlBlock, error) {
+	leaf, err := p.Leaf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	proofIndex, ok := p.tree.LeafProofIndex[leaf.TapHash()]
+	if !ok {
+		return nil, fmt.Errorf("leaf %s is missing from the script tree", name)
+	}
+
+	inclusionProof := append([]byte{}, p.tree.LeafMerkleProofs[proofIndex].InclusionProof...)
+	for _, hash := range upperHashes {
+		inclusionProof = append(inclusionProof, hash...)
+	}
+
+	controlBlock := &txscript.ControlBlock{
+		LeafVersion:    leaf.LeafVersion,
+		InternalKey:    internalKey,
+		InclusionProof: inclusionProof,
+	}
+
+	rootHash := controlBlock.RootHash(leaf.Script)
+	tapKey := txscript.ComputeTaprootOutputKey(internalKey, rootHash)
+	if tapKey.SerializeCompressed()[0] ==
+		secp256k1.PubKeyFormatCompressedOdd {
+
+		controlBlock.OutputKeyYIsOdd = true
+	}
+
+	return controlBlock, nil
+}
+
+// AssetScriptKey returns the asset script key committing to the policy under
+// the NUMS internal key, leaving only the script paths spendable.
+func (p ArkScriptPolicy) AssetScriptKey() asset.ScriptKey {
+	internalKey := asset.NUMSPubKey
+	merkleRootHash := p.RootHash()
+	tapKey := txscript.ComputeTaprootOutputKey(
+		internalKey, merkleRootHash[:],
+	)
+
+	return asset.ScriptKey{
+		PubKey: tapKey,
+		TweakedScriptKey: &asset.TweakedScriptKey{
+			RawKey: keychain.KeyDescriptor{
+				PubKey: internalKey,
+			},
+			Tweak: merkleRootHash[:],
+		},
+	}
+}
+
+// policyOutput is an asset VTXO and its BTC anchored under the leaves of a
+// script policy, spent out of round by a MuSig2 session of the signers of one
+// leaf, or by the key of its only signer. VHTLCs and Ark address VTXOs are policy outputs.
+type policyOutput struct {
+	server         *TapClient
+	arkBtcScript   ArkBtcScript
+	arkAssetScript ArkAssetScript
+	assetId        []byte
+	transfer       ColoredTransfer
+	ProofFile      []byte
+	GenesisPoint   string
+}
+
+// lockBoardingTransfer moves the boarded asset and BTC of the boarding user
+// into a policy output, co-signed by the user and the server, and broadcasts
+// it.
+func lockBoardingTransfer(assetId []byte, onboardTransfer ArkBoardingTransfer, arkAssetScript ArkAssetScript, arkBtcScript ArkBtcScript, server *TapClient, bitcoinClient *BitcoinClient) (policyOutput, error) {
+	if onboardTransfer.BtcAmount() == 0 {
+		return policyOutput{}, fmt.Errorf("asset was boarded without btc")
+	}
+
+	// Asset Transfer From The Boarding Output
+	assetAmount := onboardTransfer.AssetTransferDetails.assetBoardingAmount
+	assetTransferPkt := tappsbt.ForInteractiveSend(asset.ID(assetId), assetAmount, arkAssetScript.tapScriptKey, 0, 0, 0,
+		keychain.KeyDescriptor{
+			PubKey: arkBtcScript.internalKey,
+		}, asset.V0, &server.tapParams)
+
+	scriptBranchPreimage, err := arkBtcScript.tapscriptSibling()
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot create policy tapscript sibling %v", err)
+	}
+	assetTransferPkt.Outputs[0].AnchorOutputTapscriptSibling = scriptBranchPreimage
+
+	err = insertAssetInputInPacket(assetTransferPkt, 0, onboardTransfer.AssetTransferDetails.AssetTransferOutput, assetId)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot insert boarding asset input %v", err)
+	}
+	err = tapsend.PrepareOutputAssets(context.TODO(), assetTransferPkt)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot prepare Output %v", err)
+	}
+	err = InsertAssetTransferWitness(onboardTransfer.AssetTransferDetails.ArkSpendingDetails, assetTransferPkt)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot sign policy asset transfer %w", err)
+	}
+
+	vPackets := []*tappsbt.VPacket{assetTransferPkt}
+	transferBtcPkt, err := tapsend.PrepareAnchoringTemplate(vPackets)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot prepare TransferBtc Packet %v", err)
+	}
+
+	// Add Boarded Btc Input
+	btcAmount := onboardTransfer.btcTransferDetails.btcBoardingAmount + DUMMY_ASSET_BTC_AMOUNT - FEE
+	transferBtcPkt.UnsignedTx.TxOut[0].Value = int64(btcAmount)
+	addBtcInputToPSBT(transferBtcPkt, onboardTransfer.btcTransferDetails)
+
+	err = server.CommitVirtualPsbts(transferBtcPkt, vPackets)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot commit policy transfer %v", err)
+	}
+
+	spendingDetailsLists := []ArkSpendingDetails{
+		onboardTransfer.AssetTransferDetails.ArkSpendingDetails,
+		onboardTransfer.btcTransferDetails.arkSpendingDetails,
+	}
+	btcTxWitnessList, err := CreateBtcWitness(spendingDetailsLists, transferBtcPkt, len(spendingDetailsLists))
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("cannot Create BTC Witness %w", err)
+	}
+
+	transfer, err := finalizeTransfer(transferBtcPkt, btcTxWitnessList, assetTransferPkt.Outputs[0])
+	if err != nil {
+		return policyOutput{}, err
+	}
+
+	btcControlBlock, err := extractControlBlock(arkBtcScript, transfer.taprootAssetRoot)
+	if err != nil {
+		return policyOutput{}, err
+	}
+	arkBtcScript.controlBlock = btcControlBlock
+
+	sendTxResult, err := bitcoinClient.SendTransaction(transfer.finalTx)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("failed to broadcast policy transaction %v", err)
+	}
+
+	proofFile, err := AppendProof(onboardTransfer.AssetTransferDetails.RawProofFile, transfer.finalTx, transfer.transferProof, sendTxResult)
+	if err != nil {
+		return policyOutput{}, fmt.Errorf("failed to update policy proof %v", err)
+	}
+
+	return policyOutput{
+		server:         server,
+		arkBtcScript:   arkBtcScript,
+		arkAssetScript: arkAssetScript,
+		assetId:        assetId,
+		transfer:       transfer,
+		ProofFile:      proofFile,
+		GenesisPoint:   onboardTransfer.AssetTransferDetails.GenesisPoint,
+	}, nil
+}
+
+// spend moves the asset and BTC of the output to the recipient through the
+// named leaf, signed by a MuSig2 session of the signers, or the key of a single
+// signer, on both the asset and the BTC side. The stack elements are pushed after the signature. A relativeLock
+// in blocks is set on both the asset and the anchor input for CSV leaves.
+func (o policyOutput) spend(leafName string, signers []Cosigner, recipient *TapClient, lockTime, relativeLock uint32, bitcoinClient *BitcoinClient, stack ...[]byte) ([]byte, error) {
+	scriptKey, internalKey, err := recipient.GetNextKeys()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get next keys %v", err)
+	}
+
+	fundedPkt := tappsbt.ForInteractiveSend(asset.ID(o.assetId), o.transfer.assetAmount, scriptKey, uint64(lockTime), uint64(relativeLock), 0,
+		internalKey, asset.V0, &o.server.tapParams)
+	fundedPkt.Outputs[0].Type = tappsbt.TypeSimple
+
+	err = createAndSetInputIntermediate(fundedPkt, o.transfer, o.assetId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot insert policy output input %v", err)
+	}
+	err = tapsend.PrepareOutputAssets(context.TODO(), fundedPkt)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare Output %v", err)
+	}
+	err = insertAssetLeafWitness(o.arkAssetScript, leafName, signers, fundedPkt, stack...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign %s asset transfer %w", leafName, err)
+	}
+
+	vPackets := []*tappsbt.VPacket{fundedPkt}
+	transferBtcPkt, err := tapsend.PrepareAnchoringTemplate(vPackets)
+	if err != nil {
+		return nil, fmt.Errorf("cannot prepare TransferBtc Packet %v", err)
+	}
+	transferBtcPkt.UnsignedTx.TxOut[0].Value = o.transfer.anchorValue - int64(FEE)
+	if lockTime > 0 {
+		transferBtcPkt.UnsignedTx.LockTime = lockTime
+		transferBtcPkt.UnsignedTx.TxIn[0].Sequence = wire.MaxTxInSequenceNum - 1
+	}
+	if relativeLock > 0 {
+		transferBtcPkt.UnsignedTx.Version = 2
+		transferBtcPkt.UnsignedTx.TxIn[0].Sequence = relativeLock
+	}
+
+	// Committing is local, unilateral spends go without the server
+	err = CommitVirtualPsbts(transferBtcPkt, vPackets)
+	if err != nil {
+		return nil, fmt.Errorf("cannot commit %s transfer %v", leafName, err)
+	}
+
+	btcTxWitness, err := o.btcLeafWitness(leafName, signers, transferBtcPkt, stack...)
+	if err != nil {
+		return nil, err
+	}
+
+	spendTransfer, err := finalizeTransfer(transferBtcPkt, []wire.TxWitness{btcTxWitness}, fundedPkt.Outputs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	sendTxResult, err := bitcoinClient.SendTransaction(spendTransfer.finalTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast %s transaction %v", leafName, err)
+	}
+
+	log.Printf("Spend %s TxId %s", leafName, spendTransfer.finalTx.TxHash().String())
+
+	return AppendProof(o.ProofFile, spendTransfer.finalTx, spendTransfer.transferProof, sendTxResult)
+}
+
+// btcLeafWitness signs the anchor input of the output through the named leaf, whose
+// key is the MuSig2 aggregate of the signers' internal keys or the internal key
+// of a single signer.
+func (o policyOutput) btcLeafWitness(leafName string, signers []Cosigner, btcPacket *psbt.Packet, stack ...[]byte) (wire.TxWitness, error) {
+	leaf, err := o.arkBtcScript.policy.Leaf(leafName)
+	if err != nil {
+		return nil, err
+	}
+
+	controlBlock, err := o.arkBtcScript.ControlBlock(leafName, o.transfer.taprootAssetRoot)
+	if err != nil {
+		return nil, err
+	}
+	controlBlockBytes, err := controlBlock.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert control block to bytes %v", err)
+	}
+
+	if len(signers) == 1 {
+		sigs, err := callSigner(func(ctx context.Context) ([][]byte, error) {
+			return signers[0].signer.SignBtcTapscript(
+				ctx, btcPacket, []int{0},
+				[]keychain.KeyDescriptor{signers[0].internalKey},
+				[][]byte{controlBlockBytes},
+				[]txscript.TapLeaf{leaf},
+			)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign btc %s leaf: %w", leafName, err)
+		}
+
+		txWitness := append(wire.TxWitness{sigs[0]}, stack...)
+		return append(txWitness, leaf.Script, controlBlockBytes), nil
+	}
+
+	muSig2Signers := make([]MuSig2Signer, len(signers))
+	for i, signer := range signers {
+		muSig2Signers[i] = MuSig2Signer{signer.signer, signer.internalKey}
+	}
+
+	session, err := NewMuSig2Session(muSig2Signers, &signrpc.TaprootTweakDesc{KeySpendOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create btc signing session: %w", err)
+	}
+	defer session.Close()
+
+	if err := session.ExchangeNonces(); err != nil {
+		return nil, fmt.Errorf("failed to exchange btc nonces: %w", err)
+	}
+
+	prevOutFetcher, err := PacketPrevOutFetcher(btcPacket)
+	if err != nil {
+		return nil, err
+	}
+	sigHashes := txscript.NewTxSigHashes(btcPacket.UnsignedTx, prevOutFetcher)
+	sigHash, err := txscript.CalcTapscriptSignaturehash(
+		sigHashes, txscript.SigHashDefault, btcPacket.UnsignedTx, 0,
+		prevOutFetcher, leaf,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute tapscript sighash %v", err)
+	}
+
+	finalSig, err := session.Sign(sigHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign btc %s leaf: %w", leafName, err)
+	}
+
+	txWitness := append(wire.TxWitness{finalSig}, stack...)
+	return append(txWitness, leaf.Script, controlBlockBytes), nil
+}
+
+// finalizeTransfer sets the witnesses of the packet inputs, finalizes it and
+// extracts the transfer of the asset output.
+func finalizeTransfer(btcPacket *psbt.Packet, txWitnesses []wire.TxWitness, assetOutput *tappsbt.VOutput) (ColoredTransfer, error) {
+	for i, txWitness := range txWitnesses {
+		var buf bytes.Buffer
+		err := psbt.WriteTxWitness(&buf, txWitness)
+		if err != nil {
+			return ColoredTransfer{}, fmt.Errorf("failed to write BTC witness for input %v", err)
+		}
+		btcPacket.Inputs[i].FinalScriptWitness = buf.Bytes()
+	}
+
+	err := psbt.MaybeFinalizeAll(btcPacket)
+	if err != nil {
+		return ColoredTransfer{}, fmt.Errorf("failed to finalise Psbt %v", err)
+	}
+
+	transfer, err := ExtractColoredTransfer(btcPacket, assetOutput)
+	if err != nil {
+		return ColoredTransfer{}, fmt.Errorf("cannot Extract Colored Transfer %v", err)
+	}
+
+	return transfer, nil
+}
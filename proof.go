@@ -3,8 +3,13 @@ package taponark
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/asset"
 	"github.com/lightninglabs/taproot-assets/proof"
 	"github.com/lightninglabs/taproot-assets/taprpc/tapdevrpc"
 )
@@ -48,3 +53,116 @@ func SubmitProof(genesisPoint string, proofFile []byte, user *TapClient) error {
 	})
 	return err
 }
+
+// VerifyProofFile verifies every proof of the file against the chain of the
+// backend and returns the last one.
+func VerifyProofFile(proofFile *proof.File, backend ChainBackend) (*proof.Proof, error) {
+	firstProof, err := proofFile.ProofAt(0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get genesis proof %v", err)
+	}
+
+	// The group of the asset is fixed by its genesis proof
+	groupVerifier := func(groupKey *btcec.PublicKey) error {
+		if firstProof.Asset.GroupKey == nil || !firstProof.Asset.GroupKey.GroupPubKey.IsEqual(groupKey) {
+			return fmt.Errorf("group key %x is not the group of the genesis", groupKey.SerializeCompressed())
+		}
+		return nil
+	}
+
+	lookup := chainLookup{backend, proofFile}
+	_, err = proofFile.Verify(context.TODO(), lookup.verifyHeader, proof.DefaultMerkleVerifier, groupVerifier, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify proof file %v", err)
+	}
+
+	return proofFile.LastProof()
+}
+
+// chainLookup looks up the blocks of a proof file in a chain backend.
+type chainLookup struct {
+	backend   ChainBackend
+	proofFile *proof.File
+}
+
+// verifyHeader accepts a block header the backend has at the given height.
+func (c chainLookup) verifyHeader(header wire.BlockHeader, height uint32) error {
+	blockHash := header.BlockHash()
+	block, err := c.backend.GetBlock(&blockHash)
+	if err != nil {
+		return fmt.Errorf("block %s is not in the chain %v", blockHash, err)
+	}
+	if len(block.Transactions) == 0 {
+		return fmt.Errorf("block %s has no transactions", blockHash)
+	}
+
+	// The height of a block is the one of its transactions
+	txid := block.Transactions[0].TxHash()
+	status, err := c.backend.GetTransactionStatus(&txid)
+	if err != nil {
+		return fmt.Errorf("cannot get status of transaction %s %v", txid, err)
+	}
+	if !status.Confirmed || !status.BlockHash.IsEqual(&blockHash) || status.BlockHeight != int64(height) {
+		return fmt.Errorf("block %s is not at height %d", blockHash, height)
+	}
+
+	return nil
+}
+
+// TxBlockHeight returns the height of the block confirming the transaction.
+func (c chainLookup) TxBlockHeight(_ context.Context, txid chainhash.Hash) (uint32, error) {
+	status, err := c.backend.GetTransactionStatus(&txid)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get status of transaction %s %v", txid, err)
+	}
+	if !status.Confirmed {
+		return 0, fmt.Errorf("transaction %s is not confirmed", txid)
+	}
+
+	return uint32(status.BlockHeight), nil
+}
+
+// MeanBlockTimestamp returns the median timestamp of the block at the given
+// height and the ones before it. The block must anchor a proof of the file.
+func (c chainLookup) MeanBlockTimestamp(_ context.Context, height uint32) (time.Time, error) {
+	var blockHash *chainhash.Hash
+	for i := c.proofFile.NumProofs() - 1; i >= 0; i-- {
+		p, err := c.proofFile.ProofAt(uint32(i))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot get proof %v", err)
+		}
+		if p.BlockHeight == height {
+			hash := p.BlockHeader.BlockHash()
+			blockHash = &hash
+			break
+		}
+	}
+	if blockHash == nil {
+		return time.Time{}, fmt.Errorf("no proof anchored at height %d", height)
+	}
+
+	var timestamps []int64
+	for len(timestamps) < MEDIAN_TIME_BLOCKS && len(timestamps) <= int(height) {
+		block, err := c.backend.GetBlock(blockHash)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot get block %s %v", blockHash, err)
+		}
+		timestamps = append(timestamps, block.Header.Timestamp.Unix())
+		blockHash = &block.Header.PrevBlock
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	return time.Unix(timestamps[len(timestamps)/2], 0), nil
+}
+
+// CurrentHeight returns the height of the chain tip.
+func (c chainLookup) CurrentHeight(context.Context) (uint32, error) {
+	height, err := c.backend.GetBlockCount()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get block count %v", err)
+	}
+
+	return uint32(height), nil
+}
+
+var _ asset.ChainLookup = chainLookup{}
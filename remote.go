@@ -0,0 +1,375 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"taponark/arkrpc"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/lightninglabs/taproot-assets/taprpc/assetwalletrpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/tapdevrpc"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrParticipantOffline is returned for requests to a participant without an
+// open event stream.
+var ErrParticipantOffline = errors.New("participant is not subscribed")
+
+// remoteParticipant is a user of the server daemon. The round pipeline sees it
+// as a TapClient, every key, nonce and signature it asks that client for is
+// requested on the participant's event stream and answered by a Submit call.
+type remoteParticipant struct {
+	key    *btcec.PublicKey
+	client TapClient
+
+	mu      sync.Mutex
+	events  chan *arkrpc.RoundEvent
+	pending map[string]chan proto.Message
+}
+
+var _ Signer = (*remoteSigner)(nil)
+
+func newRemoteParticipant(key *btcec.PublicKey, server *TapClient) *remoteParticipant {
+	p := &remoteParticipant{
+		key:     key,
+		pending: make(map[string]chan proto.Message),
+	}
+
+	wallet := &remoteWallet{participant: p}
+	p.client = TapClient{
+		wallet:      wallet,
+		devclient:   wallet,
+		lndClient:   NewLndClient(nil, wallet),
+		signer:      &remoteSigner{participant: p, nonces: make(map[string][][]byte)},
+		closeClient: func() error { return nil },
+		chainParams: server.chainParams,
+		tapParams:   server.tapParams,
+		timeout:     server.timeout,
+	}
+
+	return p
+}
+
+// subscribe opens the event stream of the participant, a previous stream is
+// closed.
+func (p *remoteParticipant) subscribe() chan *arkrpc.RoundEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.events != nil {
+		close(p.events)
+	}
+	p.events = make(chan *arkrpc.RoundEvent, PARTICIPANT_EVENT_BUFFER)
+
+	return p.events
+}
+
+func (p *remoteParticipant) unsubscribe(events chan *arkrpc.RoundEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.events == events {
+		close(p.events)
+		p.events = nil
+	}
+}
+
+// send queues the event without blocking the round on a slow stream.
+func (p *remoteParticipant) send(event *arkrpc.RoundEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.events == nil {
+		return ErrParticipantOffline
+	}
+
+	select {
+	case p.events <- event:
+		return nil
+	default:
+		return fmt.Errorf("event stream of participant %x is full", p.key.SerializeCompressed())
+	}
+}
+
+// deliver hands a Submit call to the request waiting for it.
+func (p *remoteParticipant) deliver(requestId string, response proto.Message) error {
+	p.mu.Lock()
+	waiting, ok := p.pending[requestId]
+	delete(p.pending, requestId)
+	p.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no pending request %s", requestId)
+	}
+
+	waiting <- response
+	return nil
+}
+
+// remoteCall sends the event built for a fresh request id and waits for its
+// answer until SIGNER_TIMEOUT or until ctx is done.
+func remoteCall[T proto.Message](ctx context.Context, p *remoteParticipant, event func(requestId string) *arkrpc.RoundEvent) (T, error) {
+	var empty T
+
+	requestId, err := RandomHexString(8)
+	if err != nil {
+		return empty, fmt.Errorf("cannot create request id %v", err)
+	}
+
+	waiting := make(chan proto.Message, 1)
+	p.mu.Lock()
+	p.pending[requestId] = waiting
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, requestId)
+		p.mu.Unlock()
+	}()
+
+	err = p.send(event(requestId))
+	if err != nil {
+		return empty, err
+	}
+
+	select {
+	case response := <-waiting:
+		answer, ok := response.(T)
+		if !ok {
+			return empty, fmt.Errorf("request %s answered with %T", requestId, response)
+		}
+		return answer, nil
+
+	case <-ctx.Done():
+		return empty, ErrSignerTimeout
+
+	case <-time.After(SIGNER_TIMEOUT):
+		return empty, ErrSignerTimeout
+	}
+}
+
+func remoteError(message string) error {
+	if message == "" {
+		return nil
+	}
+
+	return fmt.Errorf("participant failed request: %s", message)
+}
+
+// remoteWallet stands in for the tapd and lnd wallets of a participant. Only
+// the calls made on users by the round pipeline are implemented, the others
+// return codes.Unimplemented, see remote_unimplemented.go.
+type remoteWallet struct {
+	participant *remoteParticipant
+}
+
+func (w *remoteWallet) requestKey(ctx context.Context, keyType arkrpc.KeyType) (*arkrpc.SubmitKeysRequest, error) {
+	resp, err := remoteCall[*arkrpc.SubmitKeysRequest](ctx, w.participant, func(requestId string) *arkrpc.RoundEvent {
+		return &arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_KeyRequest{
+			KeyRequest: &arkrpc.KeyRequest{RequestId: requestId, Type: keyType},
+		}}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, remoteError(resp.Error)
+}
+
+func (w *remoteWallet) NextScriptKey(ctx context.Context, _ *assetwalletrpc.NextScriptKeyRequest,
+	_ ...grpc.CallOption) (*assetwalletrpc.NextScriptKeyResponse, error) {
+
+	resp, err := w.requestKey(ctx, arkrpc.KeyType_KEY_TYPE_SCRIPT)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ScriptKey == nil {
+		return nil, fmt.Errorf("participant sent no script key")
+	}
+
+	return &assetwalletrpc.NextScriptKeyResponse{ScriptKey: tapScriptKey(resp.ScriptKey)}, nil
+}
+
+func (w *remoteWallet) NextInternalKey(ctx context.Context, _ *assetwalletrpc.NextInternalKeyRequest,
+	_ ...grpc.CallOption) (*assetwalletrpc.NextInternalKeyResponse, error) {
+
+	resp, err := w.requestKey(ctx, arkrpc.KeyType_KEY_TYPE_INTERNAL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.InternalKey == nil {
+		return nil, fmt.Errorf("participant sent no internal key")
+	}
+
+	return &assetwalletrpc.NextInternalKeyResponse{InternalKey: tapKeyDescriptor(resp.InternalKey)}, nil
+}
+
+// ImportProof hands the proof of a collaborative exit to the participant.
+func (w *remoteWallet) ImportProof(_ context.Context, in *tapdevrpc.ImportProofRequest,
+	_ ...grpc.CallOption) (*tapdevrpc.ImportProofResponse, error) {
+
+	err := w.participant.send(&arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_Proof{
+		Proof: &arkrpc.ProofEvent{GenesisPoint: in.GenesisPoint, ProofFile: in.ProofFile},
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tapdevrpc.ImportProofResponse{}, nil
+}
+
+// ImportPublicKey asks the participant to watch the key of a BTC VTXO.
+func (w *remoteWallet) ImportPublicKey(_ context.Context, in *walletrpc.ImportPublicKeyRequest,
+	_ ...grpc.CallOption) (*walletrpc.ImportPublicKeyResponse, error) {
+
+	err := w.participant.send(&arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_WatchKey{
+		WatchKey: &arkrpc.WatchKeyEvent{Pubkey: in.PublicKey},
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletrpc.ImportPublicKeyResponse{}, nil
+}
+
+// remoteSigner signs through the participant. The nonces of the other signers
+// are kept until the partial signature is requested, so that both travel in
+// one request.
+type remoteSigner struct {
+	participant *remoteParticipant
+
+	mu     sync.Mutex
+	nonces map[string][][]byte
+}
+
+func (s *remoteSigner) SignBtcTapscript(ctx context.Context, pkt *psbt.Packet, inputIndexes []int,
+	keys []keychain.KeyDescriptor, controlBlocks [][]byte,
+	tapLeaves []txscript.TapLeaf) ([][]byte, error) {
+
+	var buf bytes.Buffer
+	err := pkt.Serialize(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize packet %v", err)
+	}
+
+	request := &arkrpc.TapscriptSigRequest{
+		Psbt:          buf.Bytes(),
+		ControlBlocks: controlBlocks,
+	}
+	for i, inputIndex := range inputIndexes {
+		request.InputIndexes = append(request.InputIndexes, uint32(inputIndex))
+		request.Keys = append(request.Keys, rpcKeyDescriptor(keys[i]))
+		request.TapLeaves = append(request.TapLeaves, &arkrpc.TapLeaf{
+			Script:      tapLeaves[i].Script,
+			LeafVersion: uint32(tapLeaves[i].LeafVersion),
+		})
+	}
+
+	resp, err := remoteCall[*arkrpc.SubmitSignaturesRequest](ctx, s.participant, func(requestId string) *arkrpc.RoundEvent {
+		request.RequestId = requestId
+		return &arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_TapscriptSigRequest{TapscriptSigRequest: request}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := remoteError(resp.Error); err != nil {
+		return nil, err
+	}
+	if len(resp.Signatures) != len(inputIndexes) {
+		return nil, fmt.Errorf("participant sent %d signatures for %d inputs", len(resp.Signatures), len(inputIndexes))
+	}
+
+	return resp.Signatures, nil
+}
+
+func (s *remoteSigner) MuSig2CreateSession(ctx context.Context, localKey keychain.KeyDescriptor, allSignerKeys [][]byte,
+	tweak *signrpc.TaprootTweakDesc) ([]byte, []byte, error) {
+
+	request := &arkrpc.NonceRequest{
+		LocalKey:      rpcKeyDescriptor(localKey),
+		AllSignerKeys: allSignerKeys,
+	}
+	if tweak != nil {
+		request.Tweak = &arkrpc.TaprootTweak{ScriptRoot: tweak.ScriptRoot, KeySpendOnly: tweak.KeySpendOnly}
+	}
+
+	resp, err := remoteCall[*arkrpc.SubmitNonceRequest](ctx, s.participant, func(requestId string) *arkrpc.RoundEvent {
+		request.RequestId = requestId
+		return &arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_NonceRequest{NonceRequest: request}}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := remoteError(resp.Error); err != nil {
+		return nil, nil, err
+	}
+
+	return resp.SessionId, resp.PublicNonce, nil
+}
+
+func (s *remoteSigner) MuSig2RegisterNonces(_ context.Context, sessID []byte, otherNonces [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nonces[hex.EncodeToString(sessID)] = otherNonces
+	return nil
+}
+
+func (s *remoteSigner) MuSig2Sign(ctx context.Context, sessID, sigHash []byte, cleanup bool) ([]byte, error) {
+	s.mu.Lock()
+	otherNonces, ok := s.nonces[hex.EncodeToString(sessID)]
+	delete(s.nonces, hex.EncodeToString(sessID))
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no nonces registered for session %x", sessID)
+	}
+
+	resp, err := remoteCall[*arkrpc.SubmitSignaturesRequest](ctx, s.participant, func(requestId string) *arkrpc.RoundEvent {
+		return &arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_PartialSigRequest{
+			PartialSigRequest: &arkrpc.PartialSigRequest{
+				RequestId:   requestId,
+				SessionId:   sessID,
+				OtherNonces: otherNonces,
+				SigHash:     sigHash,
+				Cleanup:     cleanup,
+			},
+		}}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := remoteError(resp.Error); err != nil {
+		return nil, err
+	}
+	if len(resp.Signatures) != 1 {
+		return nil, fmt.Errorf("participant sent %d partial signatures", len(resp.Signatures))
+	}
+
+	return resp.Signatures[0], nil
+}
+
+// MuSig2CombineSigs is never called, the server combines every session.
+func (s *remoteSigner) MuSig2CombineSigs(_ context.Context, sessID []byte, otherPartialSigs [][]byte) ([]byte, error) {
+	return nil, fmt.Errorf("remote participants do not combine signatures")
+}
+
+func (s *remoteSigner) MuSig2Cleanup(_ context.Context, sessID []byte) error {
+	s.mu.Lock()
+	delete(s.nonces, hex.EncodeToString(sessID))
+	s.mu.Unlock()
+
+	return s.participant.send(&arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_CleanupRequest{
+		CleanupRequest: &arkrpc.CleanupRequest{SessionId: sessID},
+	}})
+}
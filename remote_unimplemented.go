@@ -0,0 +1,191 @@
+package taponark
+
+import (
+	"context"
+
+	"github.com/lightninglabs/taproot-assets/taprpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/assetwalletrpc"
+	"github.com/lightninglabs/taproot-assets/taprpc/tapdevrpc"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The wallet calls the round pipeline never makes on remote participants. A
+// participant's wallet stays with its own daemon, these fail with
+// codes.Unimplemented instead of reaching a wallet the server does not have.
+
+var (
+	_ assetwalletrpc.AssetWalletClient = (*remoteWallet)(nil)
+	_ tapdevrpc.TapDevClient           = (*remoteWallet)(nil)
+	_ walletrpc.WalletKitClient        = (*remoteWallet)(nil)
+)
+
+func remoteUnimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "%s is not available on remote participants", method)
+}
+
+// AssetWalletClient calls.
+
+func (w *remoteWallet) FundVirtualPsbt(context.Context, *assetwalletrpc.FundVirtualPsbtRequest, ...grpc.CallOption) (*assetwalletrpc.FundVirtualPsbtResponse, error) {
+	return nil, remoteUnimplemented("FundVirtualPsbt")
+}
+
+func (w *remoteWallet) SignVirtualPsbt(context.Context, *assetwalletrpc.SignVirtualPsbtRequest, ...grpc.CallOption) (*assetwalletrpc.SignVirtualPsbtResponse, error) {
+	return nil, remoteUnimplemented("SignVirtualPsbt")
+}
+
+func (w *remoteWallet) AnchorVirtualPsbts(context.Context, *assetwalletrpc.AnchorVirtualPsbtsRequest, ...grpc.CallOption) (*taprpc.SendAssetResponse, error) {
+	return nil, remoteUnimplemented("AnchorVirtualPsbts")
+}
+
+func (w *remoteWallet) CommitVirtualPsbts(context.Context, *assetwalletrpc.CommitVirtualPsbtsRequest, ...grpc.CallOption) (*assetwalletrpc.CommitVirtualPsbtsResponse, error) {
+	return nil, remoteUnimplemented("CommitVirtualPsbts")
+}
+
+func (w *remoteWallet) PublishAndLogTransfer(context.Context, *assetwalletrpc.PublishAndLogRequest, ...grpc.CallOption) (*taprpc.SendAssetResponse, error) {
+	return nil, remoteUnimplemented("PublishAndLogTransfer")
+}
+
+func (w *remoteWallet) QueryInternalKey(context.Context, *assetwalletrpc.QueryInternalKeyRequest, ...grpc.CallOption) (*assetwalletrpc.QueryInternalKeyResponse, error) {
+	return nil, remoteUnimplemented("QueryInternalKey")
+}
+
+func (w *remoteWallet) QueryScriptKey(context.Context, *assetwalletrpc.QueryScriptKeyRequest, ...grpc.CallOption) (*assetwalletrpc.QueryScriptKeyResponse, error) {
+	return nil, remoteUnimplemented("QueryScriptKey")
+}
+
+func (w *remoteWallet) ProveAssetOwnership(context.Context, *assetwalletrpc.ProveAssetOwnershipRequest, ...grpc.CallOption) (*assetwalletrpc.ProveAssetOwnershipResponse, error) {
+	return nil, remoteUnimplemented("ProveAssetOwnership")
+}
+
+func (w *remoteWallet) VerifyAssetOwnership(context.Context, *assetwalletrpc.VerifyAssetOwnershipRequest, ...grpc.CallOption) (*assetwalletrpc.VerifyAssetOwnershipResponse, error) {
+	return nil, remoteUnimplemented("VerifyAssetOwnership")
+}
+
+func (w *remoteWallet) RemoveUTXOLease(context.Context, *assetwalletrpc.RemoveUTXOLeaseRequest, ...grpc.CallOption) (*assetwalletrpc.RemoveUTXOLeaseResponse, error) {
+	return nil, remoteUnimplemented("RemoveUTXOLease")
+}
+
+func (w *remoteWallet) DeclareScriptKey(context.Context, *assetwalletrpc.DeclareScriptKeyRequest, ...grpc.CallOption) (*assetwalletrpc.DeclareScriptKeyResponse, error) {
+	return nil, remoteUnimplemented("DeclareScriptKey")
+}
+
+// TapDevClient calls.
+
+func (w *remoteWallet) SubscribeSendAssetEventNtfns(context.Context, *tapdevrpc.SubscribeSendAssetEventNtfnsRequest, ...grpc.CallOption) (tapdevrpc.TapDev_SubscribeSendAssetEventNtfnsClient, error) {
+	return nil, remoteUnimplemented("SubscribeSendAssetEventNtfns")
+}
+
+func (w *remoteWallet) SubscribeReceiveAssetEventNtfns(context.Context, *tapdevrpc.SubscribeReceiveAssetEventNtfnsRequest, ...grpc.CallOption) (tapdevrpc.TapDev_SubscribeReceiveAssetEventNtfnsClient, error) {
+	return nil, remoteUnimplemented("SubscribeReceiveAssetEventNtfns")
+}
+
+// WalletKitClient calls.
+
+func (w *remoteWallet) ListUnspent(context.Context, *walletrpc.ListUnspentRequest, ...grpc.CallOption) (*walletrpc.ListUnspentResponse, error) {
+	return nil, remoteUnimplemented("ListUnspent")
+}
+
+func (w *remoteWallet) LeaseOutput(context.Context, *walletrpc.LeaseOutputRequest, ...grpc.CallOption) (*walletrpc.LeaseOutputResponse, error) {
+	return nil, remoteUnimplemented("LeaseOutput")
+}
+
+func (w *remoteWallet) ReleaseOutput(context.Context, *walletrpc.ReleaseOutputRequest, ...grpc.CallOption) (*walletrpc.ReleaseOutputResponse, error) {
+	return nil, remoteUnimplemented("ReleaseOutput")
+}
+
+func (w *remoteWallet) ListLeases(context.Context, *walletrpc.ListLeasesRequest, ...grpc.CallOption) (*walletrpc.ListLeasesResponse, error) {
+	return nil, remoteUnimplemented("ListLeases")
+}
+
+func (w *remoteWallet) DeriveNextKey(context.Context, *walletrpc.KeyReq, ...grpc.CallOption) (*signrpc.KeyDescriptor, error) {
+	return nil, remoteUnimplemented("DeriveNextKey")
+}
+
+func (w *remoteWallet) DeriveKey(context.Context, *signrpc.KeyLocator, ...grpc.CallOption) (*signrpc.KeyDescriptor, error) {
+	return nil, remoteUnimplemented("DeriveKey")
+}
+
+func (w *remoteWallet) NextAddr(context.Context, *walletrpc.AddrRequest, ...grpc.CallOption) (*walletrpc.AddrResponse, error) {
+	return nil, remoteUnimplemented("NextAddr")
+}
+
+func (w *remoteWallet) GetTransaction(context.Context, *walletrpc.GetTransactionRequest, ...grpc.CallOption) (*lnrpc.Transaction, error) {
+	return nil, remoteUnimplemented("GetTransaction")
+}
+
+func (w *remoteWallet) ListAccounts(context.Context, *walletrpc.ListAccountsRequest, ...grpc.CallOption) (*walletrpc.ListAccountsResponse, error) {
+	return nil, remoteUnimplemented("ListAccounts")
+}
+
+func (w *remoteWallet) RequiredReserve(context.Context, *walletrpc.RequiredReserveRequest, ...grpc.CallOption) (*walletrpc.RequiredReserveResponse, error) {
+	return nil, remoteUnimplemented("RequiredReserve")
+}
+
+func (w *remoteWallet) ListAddresses(context.Context, *walletrpc.ListAddressesRequest, ...grpc.CallOption) (*walletrpc.ListAddressesResponse, error) {
+	return nil, remoteUnimplemented("ListAddresses")
+}
+
+func (w *remoteWallet) SignMessageWithAddr(context.Context, *walletrpc.SignMessageWithAddrRequest, ...grpc.CallOption) (*walletrpc.SignMessageWithAddrResponse, error) {
+	return nil, remoteUnimplemented("SignMessageWithAddr")
+}
+
+func (w *remoteWallet) VerifyMessageWithAddr(context.Context, *walletrpc.VerifyMessageWithAddrRequest, ...grpc.CallOption) (*walletrpc.VerifyMessageWithAddrResponse, error) {
+	return nil, remoteUnimplemented("VerifyMessageWithAddr")
+}
+
+func (w *remoteWallet) ImportAccount(context.Context, *walletrpc.ImportAccountRequest, ...grpc.CallOption) (*walletrpc.ImportAccountResponse, error) {
+	return nil, remoteUnimplemented("ImportAccount")
+}
+
+func (w *remoteWallet) ImportTapscript(context.Context, *walletrpc.ImportTapscriptRequest, ...grpc.CallOption) (*walletrpc.ImportTapscriptResponse, error) {
+	return nil, remoteUnimplemented("ImportTapscript")
+}
+
+func (w *remoteWallet) PublishTransaction(context.Context, *walletrpc.Transaction, ...grpc.CallOption) (*walletrpc.PublishResponse, error) {
+	return nil, remoteUnimplemented("PublishTransaction")
+}
+
+func (w *remoteWallet) RemoveTransaction(context.Context, *walletrpc.GetTransactionRequest, ...grpc.CallOption) (*walletrpc.RemoveTransactionResponse, error) {
+	return nil, remoteUnimplemented("RemoveTransaction")
+}
+
+func (w *remoteWallet) SendOutputs(context.Context, *walletrpc.SendOutputsRequest, ...grpc.CallOption) (*walletrpc.SendOutputsResponse, error) {
+	return nil, remoteUnimplemented("SendOutputs")
+}
+
+func (w *remoteWallet) EstimateFee(context.Context, *walletrpc.EstimateFeeRequest, ...grpc.CallOption) (*walletrpc.EstimateFeeResponse, error) {
+	return nil, remoteUnimplemented("EstimateFee")
+}
+
+func (w *remoteWallet) PendingSweeps(context.Context, *walletrpc.PendingSweepsRequest, ...grpc.CallOption) (*walletrpc.PendingSweepsResponse, error) {
+	return nil, remoteUnimplemented("PendingSweeps")
+}
+
+func (w *remoteWallet) BumpFee(context.Context, *walletrpc.BumpFeeRequest, ...grpc.CallOption) (*walletrpc.BumpFeeResponse, error) {
+	return nil, remoteUnimplemented("BumpFee")
+}
+
+func (w *remoteWallet) ListSweeps(context.Context, *walletrpc.ListSweepsRequest, ...grpc.CallOption) (*walletrpc.ListSweepsResponse, error) {
+	return nil, remoteUnimplemented("ListSweeps")
+}
+
+func (w *remoteWallet) LabelTransaction(context.Context, *walletrpc.LabelTransactionRequest, ...grpc.CallOption) (*walletrpc.LabelTransactionResponse, error) {
+	return nil, remoteUnimplemented("LabelTransaction")
+}
+
+func (w *remoteWallet) FundPsbt(context.Context, *walletrpc.FundPsbtRequest, ...grpc.CallOption) (*walletrpc.FundPsbtResponse, error) {
+	return nil, remoteUnimplemented("FundPsbt")
+}
+
+func (w *remoteWallet) SignPsbt(context.Context, *walletrpc.SignPsbtRequest, ...grpc.CallOption) (*walletrpc.SignPsbtResponse, error) {
+	return nil, remoteUnimplemented("SignPsbt")
+}
+
+func (w *remoteWallet) FinalizePsbt(context.Context, *walletrpc.FinalizePsbtRequest, ...grpc.CallOption) (*walletrpc.FinalizePsbtResponse, error) {
+	return nil, remoteUnimplemented("FinalizePsbt")
+}
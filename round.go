@@ -7,11 +7,13 @@ import (
 	"log"
 
 	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/lightninglabs/taproot-assets/asset"
-	"github.com/lightninglabs/taproot-assets/commitment"
 	"github.com/lightninglabs/taproot-assets/tappsbt"
 	"github.com/lightninglabs/taproot-assets/tapsend"
 	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
 )
 
 type Round struct {
@@ -19,67 +21,198 @@ type Round struct {
 	RoundTree              RoundTree
 	assetTransferProofFile []byte
 	GenesisPoint           string
+	cpfpOutpoint           wire.OutPoint
 }
 
-func ConstructAndBroadcastRound(assetId []byte, onboardTransfer ArkBoardingTransfer, user, server *TapClient, bitcoinClient BitcoinClient) (Round, error) {
-	roundSpendingDetails, err := CreateRoundSpendingDetails(user, server)
+// Txid is the id of the round transaction.
+func (r Round) Txid() chainhash.Hash {
+	return r.roundTransfer.finalTx.TxHash()
+}
+
+// AssetId is the id of the asset the round settled.
+func (r Round) AssetId() []byte {
+	assetId := r.roundTransfer.transferProof.Asset.ID()
+	return assetId[:]
+}
+
+// roundTreeBuilder constructs the tree spending the round output. Returning an
+// error abandons the round before any BTC input of it is signed.
+type roundTreeBuilder func(roundTransfer ColoredTransfer, roundSpendingDetails ArkSpendingDetails) (RoundTree, error)
+
+// ConstructAndBroadcastRound builds the round transaction and its tree. Every
+// leaf owner receives one leaf of the tree and co-signs every node above it.
+// When serverBtcAmount is non zero the server funds that much extra BTC into
+// the round from its own wallet, which ends up in the users' BTC VTXOs. An
+// asset boarded without BTC needs the server to fund the round.
+// Collaborative exits are paid out by the round transaction itself, the tree
+// splits what remains.
+func ConstructAndBroadcastRound(assetId []byte, onboardTransfer ArkBoardingTransfer, serverBtcAmount uint64, leafOwners []*TapClient, server *TapClient, bitcoinClient BitcoinClient, exits ...CollaborativeExit) (Round, error) {
+	buildTree := func(roundTransfer ColoredTransfer, roundSpendingDetails ArkSpendingDetails) (RoundTree, error) {
+		// construct the round tree, one leaf per leaf owner
+		return ConstructRoundTree(roundTransfer, roundSpendingDetails, assetId, leafOwners, server, ROUND_TREE_LEVEL)
+	}
+
+	// An asset boarded without BTC is funded by the server alone
+	var btcTransfers []BtcTransferDetails
+	if onboardTransfer.BtcAmount() > 0 {
+		btcTransfers = append(btcTransfers, onboardTransfer.btcTransferDetails)
+	}
+	return constructAndBroadcastRound(assetId, onboardTransfer, btcTransfers, serverBtcAmount, exits, leafOwners, buildTree, server, bitcoinClient)
+}
+
+// constructAndBroadcastRound moves the boarded asset of assetTransfer and the
+// boarded BTC of btcTransfers into a round output co-signed by roundOwners.
+// The BTC inputs are only signed once buildTree returned the tree below it.
+func constructAndBroadcastRound(assetId []byte, assetTransfer ArkBoardingTransfer, btcTransfers []BtcTransferDetails, serverBtcAmount uint64, exits []CollaborativeExit, roundOwners []*TapClient, buildTree roundTreeBuilder, server *TapClient, bitcoinClient BitcoinClient) (Round, error) {
+	// The round keeps the BTC spend path the user boarded with
+	spendPath := assetTransfer.AssetTransferDetails.ArkSpendingDetails.arkBtcScript.spendPath
+	for _, btcTransfer := range btcTransfers {
+		if btcTransfer.arkSpendingDetails.arkBtcScript.spendPath != spendPath {
+			return Round{}, fmt.Errorf("round inputs were boarded with different spend paths")
+		}
+	}
+
+	roundSpendingDetails, err := CreateRoundSpendingDetails(roundOwners, server, spendPath)
 	if err != nil {
 		return Round{}, fmt.Errorf("cannot create Round Spending Details %v", err)
 	}
 
+	exitAssetAmount, exitBtcAmount, err := collaborativeExitAmounts(assetId, exits)
+	if err != nil {
+		return Round{}, err
+	}
+
 	// Create Asset Transfer
-	boardingAssetAmount := onboardTransfer.AssetTransferDetails.assetBoardingAmount
-	onboardAssetSpendingDetails := onboardTransfer.AssetTransferDetails.ArkSpendingDetails
+	boardingAssetAmount := assetTransfer.AssetTransferDetails.assetBoardingAmount
+	if exitAssetAmount >= boardingAssetAmount {
+		return Round{}, fmt.Errorf("collaborative exits take %d of %d asset, leaving none for the round", exitAssetAmount, boardingAssetAmount)
+	}
+	roundAssetAmount := boardingAssetAmount - exitAssetAmount
+	onboardAssetSpendingDetails := assetTransfer.AssetTransferDetails.ArkSpendingDetails
 
 	// Prepare an asset Transfer Packet
 	assetTransferPkt := tappsbt.ForInteractiveSend(
 		asset.ID(assetId),
-		boardingAssetAmount,
+		roundAssetAmount,
 		roundSpendingDetails.arkAssetScript.tapScriptKey,
 		0, 0, 0,
 		keychain.KeyDescriptor{
-			PubKey: asset.NUMSPubKey,
+			PubKey: roundSpendingDetails.arkBtcScript.internalKey,
 		},
 		asset.V0,
 		&server.tapParams)
 
 	// Insert Ark round Spending Script Path
-	scriptBranchPreimage := commitment.NewPreimageFromBranch(roundSpendingDetails.arkBtcScript.Branch)
-	assetTransferPkt.Outputs[ROUND_ROOT_ASSET_OUTPUT_INDEX].AnchorOutputTapscriptSibling = &scriptBranchPreimage
+	scriptBranchPreimage, err := roundSpendingDetails.arkBtcScript.tapscriptSibling()
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot create round tapscript sibling %v", err)
+	}
+	assetTransferPkt.Outputs[ROUND_ROOT_ASSET_OUTPUT_INDEX].AnchorOutputTapscriptSibling = scriptBranchPreimage
+
+	exitOutputIndexes, err := addExitAssetOutputs(assetTransferPkt, exits, &server.tapParams)
+	if err != nil {
+		return Round{}, err
+	}
 
 	// Add asset input details
-	insertAssetInputInPacket(assetTransferPkt, ASSET_ANCHOR_ROUND_ROOT_INPUT_INDEX, onboardTransfer.AssetTransferDetails.AssetTransferOutput, assetId)
+	insertAssetInputInPacket(assetTransferPkt, ASSET_ANCHOR_ROUND_ROOT_INPUT_INDEX, assetTransfer.AssetTransferDetails.AssetTransferOutput, assetId)
 	err = tapsend.PrepareOutputAssets(context.TODO(), assetTransferPkt)
 	if err != nil {
 		return Round{}, fmt.Errorf("cannot prepare Output %v", err)
 	}
-	// Insert asset witness details
-	InsertAssetTransferWitness(onboardAssetSpendingDetails, assetTransferPkt, onboardTransfer.user, server)
+	// Insert asset witness details, the asset leaf commits to it so it has
+	// to be known before the round txid. It is worthless without the
+	// signature of the BTC anchor it sits in.
+	err = InsertAssetTransferWitness(onboardAssetSpendingDetails, assetTransferPkt)
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot sign round asset transfer %w", err)
+	}
 	assetTransferPktList := []*tappsbt.VPacket{assetTransferPkt}
 	transferPsbt, err := tapsend.PrepareAnchoringTemplate(assetTransferPktList)
 	if err != nil {
 		return Round{}, fmt.Errorf("cannot prepare TransferBtc Packet %v", err)
 	}
 
-	//2. Add Boarded Btc Input
-	btcAmount := onboardTransfer.btcTransferDetails.btcBoardingAmount + DUMMY_ASSET_BTC_AMOUNT - FEE
+	//2. Add Boarded Btc Inputs
+	btcAmount := DUMMY_ASSET_BTC_AMOUNT + serverBtcAmount
+	for _, btcTransfer := range btcTransfers {
+		btcAmount += btcTransfer.btcBoardingAmount
+		addBtcInputToPSBT(transferPsbt, btcTransfer)
+	}
+	if FEE+ROUND_CPFP_ANCHOR_AMOUNT+exitBtcAmount >= btcAmount {
+		return Round{}, fmt.Errorf("fees and collaborative exits take %d of %d sats, leaving none for the round", FEE+ROUND_CPFP_ANCHOR_AMOUNT+exitBtcAmount, btcAmount)
+	}
+	btcAmount -= FEE + ROUND_CPFP_ANCHOR_AMOUNT + exitBtcAmount
 	transferPsbt.UnsignedTx.TxOut[ROUND_ROOT_ANCHOR_OUTPUT_INDEX].Value = int64(btcAmount)
-	addBtcInputToPSBT(transferPsbt, onboardTransfer.btcTransferDetails)
+
+	// Add Server CPFP Anchor Output
+	cpfpInternalKey, err := server.GetBtcInternalKey()
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot get cpfp anchor key %v", err)
+	}
+	err = addBtcOutput(transferPsbt, ROUND_CPFP_ANCHOR_AMOUNT, cpfpInternalKey)
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot add cpfp anchor output %v", err)
+	}
+	cpfpOutputIndex := uint32(len(transferPsbt.UnsignedTx.TxOut) - 1)
+
+	// Add Collaborative Exit Outputs
+	err = addExitBtcOutputs(transferPsbt, exits, exitOutputIndexes)
+	if err != nil {
+		return Round{}, err
+	}
+
+	// Fund Server Liquidity
+	var serverInputIndexes []int
+	var fundingLeases []*walletrpc.UtxoLease
+	if serverBtcAmount > 0 {
+		transferPsbt, serverInputIndexes, fundingLeases, err = FundRoundLiquidity(transferPsbt, server)
+		if err != nil {
+			return Round{}, err
+		}
+
+		// Unlock the server inputs if the round is abandoned
+		defer func() {
+			server.lndClient.ReleaseOutputs(fundingLeases)
+		}()
+	}
 
 	// Commit Asset Transfer To Psbt
-	server.CommitVirtualPsbts(
+	err = server.CommitVirtualPsbts(
 		transferPsbt, assetTransferPktList,
 	)
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot commit round asset transfer %v", err)
+	}
+
+	roundTransfer, err := unsignedColoredTransfer(transferPsbt, assetTransferPktList[0].Outputs[0])
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot Derive Unpublished Chain Transfer %v", err)
+	}
 
-	inputLength := 2
-	spendingDetailsLists := make([]ArkSpendingDetails, inputLength)
-	spendingDetailsLists[0] = onboardTransfer.AssetTransferDetails.ArkSpendingDetails
-	spendingDetailsLists[1] = onboardTransfer.btcTransferDetails.arkSpendingDetails
+	// Insert Control Block
+	btcControlBlock, err := extractControlBlock(roundSpendingDetails.arkBtcScript, roundTransfer.taprootAssetRoot)
+	if err != nil {
+		return Round{}, err
+	}
+	roundSpendingDetails.arkBtcScript.controlBlock = btcControlBlock
+
+	roundTree, err := buildTree(roundTransfer, roundSpendingDetails)
+	if err != nil {
+		return Round{}, fmt.Errorf("cannot construct round tree, %w", err)
+	}
+
+	inputLength := 1 + len(btcTransfers)
+	spendingDetailsLists := make([]ArkSpendingDetails, 0, inputLength)
+	spendingDetailsLists = append(spendingDetailsLists, onboardAssetSpendingDetails)
+	for _, btcTransfer := range btcTransfers {
+		spendingDetailsLists = append(spendingDetailsLists, btcTransfer.arkSpendingDetails)
+	}
 
 	// Sign BTC inputs
-	btcAssetTxWitnessList, err := CreateBtcWitness(spendingDetailsLists, transferPsbt, inputLength, onboardTransfer.user, server)
+	btcAssetTxWitnessList, err := CreateBtcWitness(spendingDetailsLists, transferPsbt, inputLength)
 	if err != nil {
-		return Round{}, fmt.Errorf("cannot Create BTC Witness %v", err)
+		return Round{}, fmt.Errorf("cannot Create BTC Witness %w", err)
 	}
 
 	for i := 0; i < inputLength; i++ {
@@ -91,37 +224,60 @@ func ConstructAndBroadcastRound(assetId []byte, onboardTransfer ArkBoardingTrans
 		transferPsbt.Inputs[i].FinalScriptWitness = buf.Bytes()
 	}
 
+	// Sign Server Funding Inputs
+	if len(serverInputIndexes) > 0 {
+		err = server.lndClient.SignWalletInputs(transferPsbt, serverInputIndexes)
+		if err != nil {
+			return Round{}, fmt.Errorf("cannot sign server funding inputs %v", err)
+		}
+	}
+
 	// Finalise Transfer PSBT
 	if err = psbt.MaybeFinalizeAll(transferPsbt); err != nil {
 		return Round{}, fmt.Errorf("failed to finalise Psbt %v", err)
 	}
 
-	roundTransfer, err := ExtractColoredTransfer(transferPsbt, assetTransferPktList[0].Outputs[0])
+	roundTransfer.finalTx, err = psbt.Extract(transferPsbt)
 	if err != nil {
-		return Round{}, fmt.Errorf("cannot Derive Unpublished Chain Transfer %v", err)
+		return Round{}, fmt.Errorf("cannot extract round transaction %v", err)
 	}
 
-	// Insert Control Block
-	btcControlBlock := extractControlBlock(roundSpendingDetails.arkBtcScript, roundTransfer.taprootAssetRoot)
-	roundSpendingDetails.arkBtcScript.controlBlock = btcControlBlock
+	// Reject the round before broadcast if any exit transaction would not
+	// be spendable or relayable
+	err = ValidateRoundTree(roundTransfer.finalTx, roundTree)
+	if err != nil {
+		return Round{}, fmt.Errorf("round tree failed validation %v", err)
+	}
 
-	// construct a two level roundtree
-	roundTree, err := ConstructRoundTree(roundTransfer, roundSpendingDetails, assetId, user, server, 2)
+	err = bitcoinClient.CheckMempoolAccept(roundTransfer.finalTx)
 	if err != nil {
-		return Round{}, fmt.Errorf("cannot construct round tree, %v", err)
+		return Round{}, fmt.Errorf("round transaction failed mempool acceptance %v", err)
 	}
 
-	sendTxResult, err := bitcoinClient.SendTransaction(roundTransfer.finalTx)
+	txhash, err := bitcoinClient.BroadcastTransaction(roundTransfer.finalTx)
 	if err != nil {
 		return Round{}, fmt.Errorf("failed to broadcast round transaction %v", err)
 	}
+	// The broadcast round can still confirm, its inputs stay leased
+	fundingLeases = nil
+
+	cpfpOutpoint := wire.OutPoint{Hash: roundTransfer.finalTx.TxHash(), Index: cpfpOutputIndex}
+	sendTxResult, err := confirmRoundTransaction(txhash, cpfpOutpoint, server, bitcoinClient)
+	if err != nil {
+		return Round{}, &RoundBroadcastError{txhash, fmt.Errorf("failed to confirm round transaction %v", err)}
+	}
 
-	rootProofFile, err := AppendProof(onboardTransfer.AssetTransferDetails.RawProofFile, roundTransfer.finalTx, roundTransfer.transferProof, sendTxResult)
+	rootProofFile, err := AppendProof(assetTransfer.AssetTransferDetails.RawProofFile, roundTransfer.finalTx, roundTransfer.transferProof, sendTxResult)
 	if err != nil {
-		return Round{}, fmt.Errorf("failed to update round proof %v", err)
+		return Round{}, &RoundBroadcastError{txhash, fmt.Errorf("failed to update round proof %v", err)}
 	}
 
-	genesisPoint := onboardTransfer.AssetTransferDetails.GenesisPoint
+	genesisPoint := assetTransfer.AssetTransferDetails.GenesisPoint
+
+	err = deliverExitProofs(assetTransfer.AssetTransferDetails.RawProofFile, genesisPoint, roundTransfer.finalTx, sendTxResult, assetTransferPkt, exits, exitOutputIndexes)
+	if err != nil {
+		return Round{}, &RoundBroadcastError{txhash, err}
+	}
 
 	log.Printf("\nRound Transaction Hash %s", roundTransfer.finalTx.TxHash().String())
 
@@ -130,23 +286,152 @@ func ConstructAndBroadcastRound(assetId []byte, onboardTransfer ArkBoardingTrans
 		roundTree,
 		rootProofFile,
 		genesisPoint,
+		cpfpOutpoint,
 	}, nil
 }
 
+// RoundBroadcastError is returned when a round fails after its transaction was
+// broadcast. The transaction can still confirm, so its inputs must not be
+// spent by another round.
+type RoundBroadcastError struct {
+	Txid chainhash.Hash
+	Err  error
+}
+
+func (e *RoundBroadcastError) Error() string {
+	return fmt.Sprintf("round %s failed after broadcast: %v", e.Txid, e.Err)
+}
+
+func (e *RoundBroadcastError) Unwrap() error {
+	return e.Err
+}
+
+// confirmRoundTransaction waits for the broadcast round transaction to
+// confirm. The tree commits to the round txid so the transaction itself
+// cannot be replaced, instead every time the wait times out the server spends
+// its anchor output in a higher fee child.
+func confirmRoundTransaction(txhash chainhash.Hash, cpfpOutpoint wire.OutPoint, server *TapClient, bitcoinClient BitcoinClient) (BitcoinSendTxResult, error) {
+	var satPerVByte uint64
+	for attempt := 0; ; attempt++ {
+		sendTxResult, err := bitcoinClient.WaitForInclusion(txhash)
+		if err == nil {
+			return sendTxResult, nil
+		}
+
+		if attempt == ROUND_MAX_FEE_BUMPS {
+			return BitcoinSendTxResult{}, fmt.Errorf("round not confirmed after %d fee bumps %v", attempt, err)
+		}
+
+		satPerVByte = nextCpfpFeeRate(bitcoinClient, satPerVByte)
+		log.Printf("round transaction %s not confirmed, bumping fee to %d sat/vbyte", txhash, satPerVByte)
+
+		err = server.lndClient.BumpFee(cpfpOutpoint, satPerVByte)
+		if err != nil {
+			return BitcoinSendTxResult{}, err
+		}
+	}
+}
+
+// BumpRoundFee CPFPs the round transaction by spending the server's anchor
+// output at the given fee rate.
+func BumpRoundFee(round Round, satPerVByte uint64, server *TapClient) error {
+	return server.lndClient.BumpFee(round.cpfpOutpoint, satPerVByte)
+}
+
+// nextCpfpFeeRate returns the fee rate for the next CPFP attempt. It follows
+// the backend estimate but always exceeds the previous attempt so that each
+// child can replace the last.
+func nextCpfpFeeRate(bitcoinClient BitcoinClient, prevSatPerVByte uint64) uint64 {
+	satPerVByte := uint64(MIN_CPFP_SAT_PER_VBYTE)
+
+	feeRate, err := bitcoinClient.EstimateFee(CPFP_CONF_TARGET)
+	if err != nil {
+		log.Printf("cannot estimate fee, using minimum cpfp fee rate %v", err)
+	} else if estimate := uint64(feeRate.FeePerVByte()); estimate > satPerVByte {
+		satPerVByte = estimate
+	}
+
+	if satPerVByte <= prevSatPerVByte {
+		satPerVByte = prevSatPerVByte * 2
+	}
+
+	return satPerVByte
+}
+
 func ExitRoundAndAppendProof(round Round, bitcoinClient *BitcoinClient) ([][]byte, error) {
+	return ExitRoundVtxos(round, nil, bitcoinClient)
+}
+
+// ExitRoundVtxos broadcasts only the branches of the tree leading to a leaf
+// output of owner and returns the proof files of owner's asset VTXOs. A nil
+// owner exits the whole tree.
+func ExitRoundVtxos(round Round, owner *TapClient, bitcoinClient *BitcoinClient) ([][]byte, error) {
+	exitsBranch := func(node *RoundTreeNode) bool {
+		return owner == nil || ownsLeaf(node, owner)
+	}
+	exitsOutput := func(output NodeOutput) bool {
+		return owner == nil || output.Owner == owner
+	}
+
+	return exitRoundBranches(round, exitsBranch, exitsOutput, bitcoinClient)
+}
+
+// ExitRoundLeaf broadcasts the branch of the tree leading to the leaf at index,
+// counted from the left, and returns the proof files of its asset VTXOs.
+// Transactions of the branch mined by earlier exits are not sent again.
+func ExitRoundLeaf(round Round, index int, bitcoinClient *BitcoinClient) ([][]byte, error) {
+	leaves := RoundLeaves(round.RoundTree.Root)
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("round has no leaf %d, it has %d leaves", index, len(leaves))
+	}
+	leaf := leaves[index]
+
+	exitsBranch := func(node *RoundTreeNode) bool {
+		contains := false
+		forEachLeaf(node, func(other *RoundTreeNode) {
+			if other == leaf {
+				contains = true
+			}
+		})
+		return contains
+	}
+	exitsOutput := func(NodeOutput) bool {
+		return true
+	}
+
+	return exitRoundBranches(round, exitsBranch, exitsOutput, bitcoinClient)
+}
+
+// RoundLeaves returns the leaves of the tree below node from the left.
+func RoundLeaves(node *RoundTreeNode) []*RoundTreeNode {
+	var leaves []*RoundTreeNode
+	forEachLeaf(node, func(leaf *RoundTreeNode) {
+		leaves = append(leaves, leaf)
+	})
+
+	return leaves
+}
+
+// exitRoundBranches broadcasts the branches of the tree exitsBranch accepts
+// and returns the proof files of the asset VTXOs exitsOutput accepts.
+func exitRoundBranches(round Round, exitsBranch func(node *RoundTreeNode) bool, exitsOutput func(output NodeOutput) bool, bitcoinClient *BitcoinClient) ([][]byte, error) {
 	assetVtxoProofList := make([][]byte, 0)
 
 	var traverseRecursively func(node *RoundTreeNode, parentProofFile []byte) error
 
 	traverseRecursively = func(node *RoundTreeNode, parentProofFile []byte) error {
-		sendTransactionResult, err := bitcoinClient.SendTransaction(node.Transaction)
+		if !exitsBranch(node) {
+			return nil
+		}
+
+		sendTransactionResult, err := bitcoinClient.SendTransactionOnce(node.Transaction)
 		if err != nil {
 			return fmt.Errorf("failed to broadcast exit  transaction: %w", err)
 		}
 
 		if node.NodeType == NodeTypeLeaf {
 			for _, output := range []NodeOutput{node.LeftOutput, node.RightOutput} {
-				if output.OutputType == OutputTypeAsset {
+				if output.OutputType == OutputTypeAsset && exitsOutput(output) {
 					if parentProofFile == nil {
 						return fmt.Errorf("parent proof file is nil for leaf node")
 					}
@@ -192,3 +477,15 @@ func ExitRoundAndAppendProof(round Round, bitcoinClient *BitcoinClient) ([][]byt
 	return assetVtxoProofList, nil
 
 }
+
+// ownsLeaf reports whether a leaf output below node belongs to owner.
+func ownsLeaf(node *RoundTreeNode, owner *TapClient) bool {
+	owned := false
+	forEachLeaf(node, func(leaf *RoundTreeNode) {
+		if leaf.LeftOutput.Owner == owner || leaf.RightOutput.Owner == owner {
+			owned = true
+		}
+	})
+
+	return owned
+}
@@ -0,0 +1,331 @@
+package taponark_test
+
+import (
+	"taponark"
+	"taponark/internal/taponarktest"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTimeout     = time.Minute
+	testNodeFunding = 10_000_000
+	testAssetAmount = 40
+	testBtcAmount   = 100_000
+	testRoundLeaves = 2
+)
+
+// testNetwork is a server, a boarding user and an exit user on a fake chain.
+// The boarding user has minted an asset.
+type testNetwork struct {
+	esplora  *taponarktest.FakeEsploraServer
+	chain    taponark.ChainBackend
+	bitcoin  taponark.BitcoinClient
+	server   taponark.TapClient
+	boarding taponark.TapClient
+	exit     taponark.TapClient
+	assetId  []byte
+}
+
+func newTestNetwork(t *testing.T) *testNetwork {
+	t.Helper()
+
+	network := taponarktest.NewFakeNetwork(testTimeout)
+	t.Cleanup(network.Close)
+
+	nodes := make([]taponark.TapClient, 3)
+	for i := range nodes {
+		node, err := network.NewNode(testNodeFunding)
+		require.NoError(t, err)
+		nodes[i] = node
+	}
+
+	n := &testNetwork{
+		esplora:  network.Esplora(),
+		chain:    taponark.NewEsploraBackend(network.Esplora().URL(), testTimeout),
+		bitcoin:  network.BitcoinClient(),
+		server:   nodes[0],
+		boarding: nodes[1],
+		exit:     nodes[2],
+	}
+
+	assetId, err := n.boarding.CreateAsset()
+	require.NoError(t, err)
+	require.NoError(t, n.server.Sync())
+	require.NoError(t, n.exit.Sync())
+	n.assetId = assetId
+
+	return n
+}
+
+// board boards testAssetAmount of the asset with testBtcAmount sats.
+func (n *testNetwork) board(t *testing.T, spendPath taponark.BtcSpendPath) taponark.ArkBoardingTransfer {
+	t.Helper()
+
+	boarding, err := taponark.OnboardUser(n.assetId, testAssetAmount, testBtcAmount, spendPath, &n.boarding, &n.server, &n.bitcoin)
+	require.NoError(t, err)
+
+	return boarding
+}
+
+// runRound settles the intent in a round of the server.
+func (n *testNetwork) runRound(t *testing.T, intent taponark.RoundIntent) taponark.Round {
+	t.Helper()
+
+	coordinator := taponark.NewRoundCoordinator(taponark.RoundCoordinatorConfig{MaxSigningAttempts: 1}, &n.server, n.bitcoin)
+	require.NoError(t, coordinator.OpenRegistration())
+	_, err := coordinator.RegisterIntent(intent)
+	require.NoError(t, err)
+
+	round, err := coordinator.RunRound()
+	require.NoError(t, err)
+
+	return round
+}
+
+// balance returns the asset and BTC balance of the client.
+func (n *testNetwork) balance(t *testing.T, client *taponark.TapClient) (uint64, int64) {
+	t.Helper()
+
+	assetBalance, btcBalance, err := client.GetBalance(n.assetId)
+	require.NoError(t, err)
+
+	return assetBalance, btcBalance
+}
+
+// requireConfirmed checks that the transaction is mined and returns it as
+// the chain has it, witnesses included.
+func (n *testNetwork) requireConfirmed(t *testing.T, txid chainhash.Hash) *wire.MsgTx {
+	t.Helper()
+
+	status, err := n.chain.GetTransactionStatus(&txid)
+	require.NoError(t, err)
+	require.True(t, status.Confirmed, "tx %s not confirmed", txid)
+
+	tx, err := n.chain.GetTransaction(&txid)
+	require.NoError(t, err)
+
+	return tx
+}
+
+// requireValidWitnesses runs the script of every input of the mined tx
+// against the output it spends.
+func (n *testNetwork) requireValidWitnesses(t *testing.T, tx *wire.MsgTx) {
+	t.Helper()
+
+	for i, txIn := range tx.TxIn {
+		require.NotEmpty(t, txIn.Witness, "input %d of %s has no witness", i, tx.TxHash())
+		require.NoError(t, n.scriptEngine(t, tx, i).Execute(), "input %d of %s", i, tx.TxHash())
+	}
+}
+
+// scriptEngine returns a script engine validating the input of tx against the
+// output it spends on the chain.
+func (n *testNetwork) scriptEngine(t *testing.T, tx *wire.MsgTx, inputIndex int) *txscript.Engine {
+	t.Helper()
+
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for _, txIn := range tx.TxIn {
+		prevTx, err := n.chain.GetTransaction(&txIn.PreviousOutPoint.Hash)
+		require.NoError(t, err)
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, prevTx.TxOut[txIn.PreviousOutPoint.Index])
+	}
+
+	prevOut := prevOuts.FetchPrevOutput(tx.TxIn[inputIndex].PreviousOutPoint)
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	engine, err := txscript.NewEngine(prevOut.PkScript, tx, inputIndex, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, prevOuts)
+	require.NoError(t, err)
+
+	return engine
+}
+
+// requireTreeShape checks a tree of testRoundLeaves leaves splitting the
+// boarded asset evenly, whose leaves pay their BTC and asset to the exit
+// user.
+func requireTreeShape(t *testing.T, round taponark.Round) {
+	t.Helper()
+
+	root := round.RoundTree.Root
+	require.NotNil(t, root)
+	require.Equal(t, taponark.NodeTypeBranch, root.NodeType)
+	require.Len(t, root.Transaction.TxIn, 1)
+	require.Equal(t, round.Txid(), root.Transaction.TxIn[0].PreviousOutPoint.Hash)
+
+	leaves := taponark.RoundLeaves(root)
+	require.Len(t, leaves, testRoundLeaves)
+
+	var leafAssetAmount uint64
+	for _, leaf := range leaves {
+		require.Equal(t, taponark.NodeTypeLeaf, leaf.NodeType)
+		require.Nil(t, leaf.LeftChild())
+		require.Nil(t, leaf.RightChild())
+		require.Equal(t, taponark.OutputTypeAsset, leaf.LeftOutput.OutputType)
+		require.Equal(t, taponark.OutputTypeBTC, leaf.RightOutput.OutputType)
+		require.Positive(t, leaf.RightOutput.BTCAmount)
+
+		leafAssetAmount += leaf.LeftOutput.AssetAmount
+	}
+	require.EqualValues(t, testAssetAmount, leafAssetAmount)
+}
+
+func TestRoundBoardAndExit(t *testing.T) {
+	for _, spendPath := range []taponark.BtcSpendPath{taponark.BtcSpendPathScript, taponark.BtcSpendPathMuSig2} {
+		t.Run(spendPath.String(), func(t *testing.T) {
+			n := newTestNetwork(t)
+			boarding := n.board(t, spendPath)
+
+			boardingAsset, _ := n.balance(t, &n.boarding)
+			_, exitBtc := n.balance(t, &n.exit)
+
+			round := n.runRound(t, taponark.RoundIntent{
+				AssetId:    n.assetId,
+				Boarding:   boarding,
+				LeafOwners: []*taponark.TapClient{&n.exit, &n.exit},
+			})
+			n.requireConfirmed(t, round.Txid())
+			requireTreeShape(t, round)
+
+			// Exit the left leaf only, the right branch stays off chain
+			proofFiles, err := taponark.ExitRoundLeaf(round, 0, &n.bitcoin)
+			require.NoError(t, err)
+			require.Len(t, proofFiles, 1)
+
+			root := round.RoundTree.Root
+			leaf := taponark.RoundLeaves(root)[0]
+			for _, node := range []*taponark.RoundTreeNode{root, leaf} {
+				n.requireValidWitnesses(t, n.requireConfirmed(t, node.Transaction.TxHash()))
+			}
+			rightLeafTxid := taponark.RoundLeaves(root)[1].Transaction.TxHash()
+			_, err = n.chain.GetTransactionStatus(&rightLeafTxid)
+			require.ErrorIs(t, err, taponark.ErrTxNotFound)
+
+			require.NoError(t, taponark.SubmitProof(round.GenesisPoint, proofFiles[0], &n.exit))
+
+			assetBalance, btcBalance := n.balance(t, &n.exit)
+			require.Equal(t, leaf.LeftOutput.AssetAmount, assetBalance)
+			require.Equal(t, exitBtc+leaf.RightOutput.BTCAmount, btcBalance)
+
+			// The boarded asset left the boarding user for good
+			boardingAssetAfter, _ := n.balance(t, &n.boarding)
+			require.Equal(t, boardingAsset, boardingAssetAfter)
+		})
+	}
+}
+
+func TestRoundCollaborativeExit(t *testing.T) {
+	n := newTestNetwork(t)
+	boarding := n.board(t, taponark.BtcSpendPathScript)
+
+	const exitAssetAmount = 10
+	const exitBtcAmount = 20_000
+
+	assetAddr, err := n.exit.GetAssetAddress(n.assetId, exitAssetAmount)
+	require.NoError(t, err)
+	btcInternalKey, err := n.exit.GetBtcInternalKey()
+	require.NoError(t, err)
+	_, exitBtc := n.balance(t, &n.exit)
+
+	round := n.runRound(t, taponark.RoundIntent{
+		AssetId:    n.assetId,
+		Boarding:   boarding,
+		LeafOwners: []*taponark.TapClient{&n.exit, &n.exit},
+		Exits: []taponark.CollaborativeExit{{
+			AssetAddr:      assetAddr,
+			Recipient:      &n.exit,
+			BtcInternalKey: btcInternalKey,
+			BtcAmount:      exitBtcAmount,
+		}},
+	})
+	roundTx := n.requireConfirmed(t, round.Txid())
+	n.requireValidWitnesses(t, roundTx)
+
+	// The exit is paid by the round transaction, the tree holds the rest
+	leaves := taponark.RoundLeaves(round.RoundTree.Root)
+	require.Len(t, leaves, testRoundLeaves)
+	var leafAssetAmount uint64
+	for _, leaf := range leaves {
+		leafAssetAmount += leaf.LeftOutput.AssetAmount
+	}
+	require.EqualValues(t, testAssetAmount-exitAssetAmount, leafAssetAmount)
+
+	assetBalance, btcBalance := n.balance(t, &n.exit)
+	require.EqualValues(t, exitAssetAmount, assetBalance)
+	require.Equal(t, exitBtc+exitBtcAmount, btcBalance)
+}
+
+func TestRoundFailingAfterBroadcastSettlesIntent(t *testing.T) {
+	n := newTestNetwork(t)
+	boarding := n.board(t, taponark.BtcSpendPathScript)
+	intent := taponark.RoundIntent{
+		AssetId:    n.assetId,
+		Boarding:   boarding,
+		LeafOwners: []*taponark.TapClient{&n.exit, &n.exit},
+	}
+
+	// The round is broadcast but never mined in time
+	bitcoin := taponark.NewBitcoinClient(n.chain, chaincfg.RegressionNetParams, time.Second)
+	coordinator := taponark.NewRoundCoordinator(taponark.RoundCoordinatorConfig{MaxSigningAttempts: 2}, &n.server, bitcoin)
+	require.NoError(t, coordinator.OpenRegistration())
+	_, err := coordinator.RegisterIntent(intent)
+	require.NoError(t, err)
+
+	n.esplora.SetAutoMine(false)
+	_, err = coordinator.RunRound()
+	var broadcastErr *taponark.RoundBroadcastError
+	require.ErrorAs(t, err, &broadcastErr)
+	require.Equal(t, taponark.RoundPhaseFailed, coordinator.Phase())
+
+	// The intent is not retried, its boarding inputs belong to the broadcast
+	// round
+	require.Zero(t, coordinator.Pending())
+	require.NoError(t, coordinator.OpenRegistration())
+	_, err = coordinator.RegisterIntent(intent)
+	require.ErrorIs(t, err, taponark.ErrBoardingSettled)
+
+	n.esplora.MineBlock()
+	n.requireConfirmed(t, broadcastErr.Txid)
+}
+
+func TestRoundAssetOnlyBoarding(t *testing.T) {
+	n := newTestNetwork(t)
+	boarding, err := taponark.OnboardAssetUser(n.assetId, testAssetAmount, taponark.BtcSpendPathScript, &n.boarding, &n.server, &n.bitcoin)
+	require.NoError(t, err)
+	require.Zero(t, boarding.BtcAmount())
+
+	intent := taponark.RoundIntent{
+		AssetId:    n.assetId,
+		Boarding:   boarding,
+		LeafOwners: []*taponark.TapClient{&n.exit, &n.exit},
+	}
+
+	// Without server liquidity nothing pays for the tree
+	coordinator := taponark.NewRoundCoordinator(taponark.RoundCoordinatorConfig{MaxSigningAttempts: 1}, &n.server, n.bitcoin)
+	require.NoError(t, coordinator.OpenRegistration())
+	_, err = coordinator.RegisterIntent(intent)
+	require.ErrorContains(t, err, "carries no btc")
+
+	coordinator = taponark.NewRoundCoordinator(taponark.RoundCoordinatorConfig{MaxSigningAttempts: 1, ServerBtcAmount: testBtcAmount}, &n.server, n.bitcoin)
+	require.NoError(t, coordinator.OpenRegistration())
+	_, err = coordinator.RegisterIntent(intent)
+	require.NoError(t, err)
+	round, err := coordinator.RunRound()
+	require.NoError(t, err)
+
+	n.requireValidWitnesses(t, n.requireConfirmed(t, round.Txid()))
+	requireTreeShape(t, round)
+
+	proofFiles, err := taponark.ExitRoundVtxos(round, &n.exit, &n.bitcoin)
+	require.NoError(t, err)
+	for _, proofFile := range proofFiles {
+		require.NoError(t, taponark.SubmitProof(round.GenesisPoint, proofFile, &n.exit))
+	}
+
+	assetBalance, _ := n.balance(t, &n.exit)
+	require.EqualValues(t, testAssetAmount, assetBalance)
+}
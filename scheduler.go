@@ -0,0 +1,116 @@
+package taponark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+type RoundSchedulerConfig struct {
+	// Interval between scheduled rounds, zero only runs rounds once
+	// MinIntents are pending.
+	Interval time.Duration
+	// MinIntents starts a round early as soon as that many intents are
+	// pending, zero only runs rounds on the interval.
+	MinIntents int
+}
+
+// RoundScheduler runs the rounds of a RoundCoordinator on its own. A round
+// starts every Interval, or as soon as MinIntents intents are pending,
+// rounds without intents are skipped. Rounds never overlap, so no boarding
+// input is spent by two rounds. Intents registered while a round is signing
+// are not queued, the coordinator refuses them with ErrRegistrationClosed and
+// they must be registered again once the round finished and registration
+// reopened.
+type RoundScheduler struct {
+	cfg         RoundSchedulerConfig
+	coordinator *RoundCoordinator
+
+	// onRound is called with the outcome of every round that was run
+	onRound func(Round, error)
+
+	done chan struct{}
+}
+
+func NewRoundScheduler(cfg RoundSchedulerConfig, coordinator *RoundCoordinator, onRound func(Round, error)) (*RoundScheduler, error) {
+	if cfg.Interval <= 0 && cfg.MinIntents <= 0 {
+		return nil, fmt.Errorf("round scheduler needs an interval or a minimum of intents")
+	}
+
+	return &RoundScheduler{
+		cfg:         cfg,
+		coordinator: coordinator,
+		onRound:     onRound,
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Start runs rounds in the background until ctx is cancelled.
+func (s *RoundScheduler) Start(ctx context.Context) error {
+	err := s.coordinator.OpenRegistration()
+	if err != nil {
+		return err
+	}
+
+	go s.run(ctx)
+	return nil
+}
+
+// Done is closed once the scheduler stopped.
+func (s *RoundScheduler) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *RoundScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	var tick <-chan time.Time
+	if s.cfg.Interval > 0 {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-tick:
+			s.runRound()
+
+		case <-s.coordinator.registered:
+			if s.cfg.MinIntents > 0 && s.coordinator.Pending() >= s.cfg.MinIntents {
+				s.runRound()
+			}
+		}
+	}
+}
+
+func (s *RoundScheduler) runRound() {
+	if s.coordinator.Pending() == 0 {
+		log.Println("Skipping round, no intents pending")
+		return
+	}
+
+	// A round started outside the scheduler may still be signing
+	err := s.coordinator.OpenRegistration()
+	if err != nil {
+		log.Printf("Skipping round %v", err)
+		return
+	}
+
+	round, err := s.coordinator.RunRound()
+	if errors.Is(err, ErrNoIntents) {
+		log.Println("Skipping round, no intents pending")
+	} else if s.onRound != nil {
+		s.onRound(round, err)
+	}
+
+	err = s.coordinator.OpenRegistration()
+	if err != nil {
+		log.Printf("cannot reopen round registration %v", err)
+	}
+}
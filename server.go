@@ -0,0 +1,669 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"taponark/arkrpc"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/address"
+	"github.com/lightninglabs/taproot-assets/taprpc"
+	"github.com/lightningnetwork/lnd/keychain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type ArkServerConfig struct {
+	// Network is reported to users, e.g. "regtest".
+	Network     string
+	SpendPath   BtcSpendPath
+	Coordinator RoundCoordinatorConfig
+	Scheduler   RoundSchedulerConfig
+}
+
+// ArkServer is the Ark server daemon behind the arkrpc service. It owns only
+// the server's keys, users board, register intents and sign their part of
+// every round over the service.
+type ArkServer struct {
+	arkrpc.UnimplementedArkServiceServer
+
+	cfg           ArkServerConfig
+	server        *TapClient
+	bitcoinClient BitcoinClient
+	arkKey        keychain.KeyDescriptor
+	coordinator   *RoundCoordinator
+	scheduler     *RoundScheduler
+
+	mu           sync.Mutex
+	participants map[string]*remoteParticipant
+	boardings    map[string]*remoteBoarding
+	rounds       []Round
+}
+
+// remoteBoarding tracks a boarding through its asset and BTC transfers.
+type remoteBoarding struct {
+	participant *remoteParticipant
+	assetId     []byte
+	assetAmount uint64
+	btcAmount   uint64
+
+	assetAddr           *taprpc.Addr
+	assetDetails        ArkSpendingDetails
+	assetTransferOutput *taprpc.TransferOutput
+
+	btcDetails  ArkSpendingDetails
+	btcPkScript []byte
+	btcTxid     *chainhash.Hash
+
+	// transfer is set once both transfers confirmed, confirmErr if they did
+	// not
+	transfer   *ArkBoardingTransfer
+	confirmErr error
+}
+
+func NewArkServer(cfg ArkServerConfig, server *TapClient, bitcoinClient BitcoinClient) (*ArkServer, error) {
+	arkKey, err := server.GetArkKey()
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive server ark key %v", err)
+	}
+
+	s := &ArkServer{
+		cfg:           cfg,
+		server:        server,
+		bitcoinClient: bitcoinClient,
+		arkKey:        arkKey,
+		participants:  make(map[string]*remoteParticipant),
+		boardings:     make(map[string]*remoteBoarding),
+	}
+
+	cfg.Coordinator.OnPhase = s.publishPhase
+	s.coordinator = NewRoundCoordinator(cfg.Coordinator, server, bitcoinClient)
+
+	s.scheduler, err = NewRoundScheduler(cfg.Scheduler, s.coordinator, s.onRound)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Start runs rounds until ctx is cancelled.
+func (s *ArkServer) Start(ctx context.Context) error {
+	return s.scheduler.Start(ctx)
+}
+
+// ArkKey is the identity key of the server.
+func (s *ArkServer) ArkKey() *btcec.PublicKey {
+	return s.arkKey.PubKey
+}
+
+func (s *ArkServer) GetInfo(_ context.Context, _ *arkrpc.GetInfoRequest) (*arkrpc.GetInfoResponse, error) {
+	return &arkrpc.GetInfoResponse{
+		Network:      s.cfg.Network,
+		Pubkey:       s.arkKey.PubKey.SerializeCompressed(),
+		BtcSpendPath: s.cfg.SpendPath.String(),
+	}, nil
+}
+
+func (s *ArkServer) SubscribeEvents(in *arkrpc.SubscribeEventsRequest, stream arkrpc.ArkService_SubscribeEventsServer) error {
+	key, err := authenticate(stream.Context(), in.ParticipantKey)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	participant, ok := s.participants[hex.EncodeToString(in.ParticipantKey)]
+	if !ok {
+		participant = newRemoteParticipant(key, s.server)
+		s.participants[hex.EncodeToString(in.ParticipantKey)] = participant
+	}
+	s.mu.Unlock()
+
+	events := participant.subscribe()
+	defer participant.unsubscribe(events)
+
+	// The headers tell the participant its subscription is open
+	err = stream.SendHeader(metadata.MD{})
+	if err != nil {
+		return err
+	}
+	log.Printf("Participant %x subscribed", in.ParticipantKey)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case event, ok := <-events:
+			// A newer stream of the participant replaced this one
+			if !ok {
+				return nil
+			}
+
+			err := stream.Send(event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *ArkServer) NewBoardingAddress(ctx context.Context, in *arkrpc.NewBoardingAddressRequest) (*arkrpc.NewBoardingAddressResponse, error) {
+	participant, err := s.caller(ctx, in.ParticipantKey)
+	if err != nil {
+		return nil, err
+	}
+	if in.BtcAmount == 0 {
+		return nil, status.Error(codes.InvalidArgument, "boarding needs a btc amount")
+	}
+
+	boardingId, err := RandomHexString(8)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create boarding id %v", err)
+	}
+
+	boarding := &remoteBoarding{
+		participant: participant,
+		assetId:     in.AssetId,
+		assetAmount: in.AssetAmount,
+		btcAmount:   in.BtcAmount,
+	}
+	resp := &arkrpc.NewBoardingAddressResponse{BoardingId: boardingId}
+
+	if in.AssetAmount > 0 {
+		// Learn the asset from the universe before issuing an address for it
+		err = s.server.Sync()
+		if err != nil {
+			return nil, err
+		}
+
+		boarding.assetAddr, boarding.assetDetails, err = newBoardingAssetAddress(in.AssetId, in.AssetAmount, s.cfg.SpendPath, &participant.client, s.server)
+		if err != nil {
+			return nil, err
+		}
+		resp.AssetAddress = boarding.assetAddr.Encoded
+	} else {
+		resp.BtcAddress, boarding.btcDetails, boarding.btcPkScript, err = s.newBoardingBtcAddress(&participant.client, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	s.boardings[boardingId] = boarding
+	s.mu.Unlock()
+
+	return resp, nil
+}
+
+// SubmitBoardingAsset takes the asset transfer of a boarding and returns the
+// BTC boarding address proven under its asset root.
+func (s *ArkServer) SubmitBoardingAsset(ctx context.Context, in *arkrpc.SubmitBoardingAssetRequest) (*arkrpc.SubmitBoardingAssetResponse, error) {
+	boarding, err := s.boarding(ctx, in.BoardingId)
+	if err != nil {
+		return nil, err
+	}
+
+	transferOutput := &taprpc.TransferOutput{}
+	err = proto.Unmarshal(in.TransferOutput, transferOutput)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode transfer output %v", err)
+	}
+	if transferOutput.Anchor == nil || transferOutput.Amount != boarding.assetAmount {
+		return nil, status.Errorf(codes.InvalidArgument, "transfer output does not pay %d asset", boarding.assetAmount)
+	}
+
+	// Claim the boarding for this transfer, the output is derived without the
+	// lock since it asks the participant for keys
+	s.mu.Lock()
+	if boarding.assetAddr == nil || boarding.assetTransferOutput != nil {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.FailedPrecondition, "boarding %s expects no asset transfer", in.BoardingId)
+	}
+	boarding.assetTransferOutput = transferOutput
+	assetDetails := boarding.assetDetails
+	s.mu.Unlock()
+
+	var (
+		btcAddress  string
+		btcDetails  ArkSpendingDetails
+		btcPkScript []byte
+	)
+	controlBlock, err := extractControlBlock(assetDetails.arkBtcScript, transferOutput.Anchor.TaprootAssetRoot)
+	if err == nil {
+		btcAddress, btcDetails, btcPkScript, err = s.newBoardingBtcAddress(&boarding.participant.client, transferOutput.Anchor.TaprootAssetRoot)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		boarding.assetTransferOutput = nil
+		return nil, err
+	}
+	boarding.assetDetails.arkBtcScript.controlBlock = controlBlock
+	boarding.btcDetails = btcDetails
+	boarding.btcPkScript = btcPkScript
+
+	return &arkrpc.SubmitBoardingAssetResponse{BtcAddress: btcAddress}, nil
+}
+
+// SubmitBoardingBtc takes the BTC transfer of a boarding and returns once it
+// is accepted, the transfers are confirmed in the background and the boarding
+// joins rounds once they are.
+func (s *ArkServer) SubmitBoardingBtc(ctx context.Context, in *arkrpc.SubmitBoardingBtcRequest) (*arkrpc.SubmitBoardingBtcResponse, error) {
+	boarding, err := s.boarding(ctx, in.BoardingId)
+	if err != nil {
+		return nil, err
+	}
+
+	boardingTx := wire.NewMsgTx(wire.TxVersion)
+	err = boardingTx.Deserialize(bytes.NewReader(in.RawTx))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode boarding transaction %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if boarding.btcPkScript == nil || boarding.btcTxid != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "boarding %s expects no btc transfer", in.BoardingId)
+	}
+
+	btcTransfer, err := findBoardingBtcOutput(boardingTx, boarding.btcPkScript, boarding.btcDetails)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if btcTransfer.btcBoardingAmount != boarding.btcAmount {
+		return nil, status.Errorf(codes.InvalidArgument, "boarding output pays %d sats instead of %d", btcTransfer.btcBoardingAmount, boarding.btcAmount)
+	}
+
+	txid := boardingTx.TxHash()
+	boarding.btcTxid = &txid
+	go s.confirmBoarding(in.BoardingId, boarding, btcTransfer)
+
+	log.Printf("Boarding %s accepted", in.BoardingId)
+	return &arkrpc.SubmitBoardingBtcResponse{}, nil
+}
+
+// confirmBoarding waits for the transfers of an accepted boarding and exports
+// its asset, the boarding can register intents once it returns.
+func (s *ArkServer) confirmBoarding(boardingId string, boarding *remoteBoarding, btcTransfer BtcTransferDetails) {
+	transfer, err := s.confirmBoardingTransfers(boarding, btcTransfer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Printf("Boarding %s failed: %v", boardingId, err)
+		boarding.confirmErr = err
+		return
+	}
+
+	log.Printf("Boarding %s confirmed", boardingId)
+	boarding.transfer = &transfer
+}
+
+func (s *ArkServer) confirmBoardingTransfers(boarding *remoteBoarding, btcTransfer BtcTransferDetails) (ArkBoardingTransfer, error) {
+	transfer := ArkBoardingTransfer{AssetTransferDetails{}, btcTransfer, &boarding.participant.client}
+	txid := btcTransfer.outpoint.Hash
+	if boarding.assetAddr == nil {
+		err := s.bitcoinClient.WaitForConfirmation(txid)
+		if err != nil {
+			return ArkBoardingTransfer{}, fmt.Errorf("BTC confirmation failed: %w", err)
+		}
+
+		return transfer, nil
+	}
+
+	err := waitForTransfers(&s.bitcoinClient, s.server, txid, boarding.assetAddr)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	transfer.AssetTransferDetails, err = exportBoardingAsset(boarding.assetId, boarding.assetTransferOutput, boarding.assetDetails, boarding.assetAmount, s.server)
+	if err != nil {
+		return ArkBoardingTransfer{}, err
+	}
+
+	return transfer, nil
+}
+
+func (s *ArkServer) RegisterIntent(ctx context.Context, in *arkrpc.RegisterIntentRequest) (*arkrpc.RegisterIntentResponse, error) {
+	boarding, err := s.boarding(ctx, in.BoardingId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	transfer, btcTxid, confirmErr := boarding.transfer, boarding.btcTxid, boarding.confirmErr
+	s.mu.Unlock()
+	switch {
+	case btcTxid == nil:
+		return nil, status.Errorf(codes.FailedPrecondition, "boarding %s has no btc transfer", in.BoardingId)
+	case confirmErr != nil:
+		return nil, status.Errorf(codes.FailedPrecondition, "boarding %s failed %v", in.BoardingId, confirmErr)
+	case transfer == nil:
+		// Retried by the client until the boarding confirmed
+		return nil, status.Errorf(codes.Unavailable, "boarding %s is not confirmed yet", in.BoardingId)
+	}
+
+	leafOwners := make([]*TapClient, 1<<(ROUND_TREE_LEVEL-1))
+	for i := range leafOwners {
+		leafOwners[i] = &boarding.participant.client
+	}
+	if len(in.LeafOwners) > 0 {
+		if len(in.LeafOwners) != 1 && len(in.LeafOwners) != len(leafOwners) {
+			return nil, status.Errorf(codes.InvalidArgument, "intent needs 1 or %d leaf owners, got %d", len(leafOwners), len(in.LeafOwners))
+		}
+		for i := range leafOwners {
+			owner, err := s.participant(in.LeafOwners[i%len(in.LeafOwners)])
+			if err != nil {
+				return nil, err
+			}
+			leafOwners[i] = &owner.client
+		}
+	}
+
+	exits := make([]CollaborativeExit, len(in.Exits))
+	for i, rpcExit := range in.Exits {
+		exits[i], err = s.parseExit(rpcExit, boarding.participant)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid exit %d %v", i, err)
+		}
+	}
+
+	var addr *ArkAddress
+	if in.Address != "" {
+		if len(in.LeafOwners) > 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "intent paying an ark address takes no leaf owners")
+		}
+		decoded, err := DecodeArkAddress(in.Address)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		err = checkRoundPayment(decoded, boarding.assetId, *transfer, exits, s.server)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		addr, leafOwners = &decoded, nil
+	}
+
+	intentId, err := s.coordinator.RegisterIntent(RoundIntent{
+		AssetId:    boarding.assetId,
+		Boarding:   *transfer,
+		LeafOwners: leafOwners,
+		Address:    addr,
+		Exits:      exits,
+	})
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return &arkrpc.RegisterIntentResponse{IntentId: intentId}, nil
+}
+
+func (s *ArkServer) GetRoundTree(ctx context.Context, in *arkrpc.GetRoundTreeRequest) (*arkrpc.GetRoundTreeResponse, error) {
+	_, err := s.caller(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	txid, err := chainhash.NewHashFromStr(in.RoundTxid)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot parse round txid %v", err)
+	}
+
+	round, ok := s.round(*txid)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown round %s", in.RoundTxid)
+	}
+
+	root, err := rpcTreeNode(round.RoundTree.Root, s.ownerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &arkrpc.GetRoundTreeResponse{
+		RoundTxid:      in.RoundTxid,
+		GenesisPoint:   round.GenesisPoint,
+		RoundProofFile: round.assetTransferProofFile,
+		Root:           root,
+	}, nil
+}
+
+func (s *ArkServer) ListVtxos(ctx context.Context, in *arkrpc.ListVtxosRequest) (*arkrpc.ListVtxosResponse, error) {
+	participant, err := s.caller(ctx, in.ParticipantKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	rounds := append([]Round(nil), s.rounds...)
+	s.mu.Unlock()
+
+	resp := &arkrpc.ListVtxosResponse{}
+	for _, round := range rounds {
+		roundTxid := round.Txid().String()
+		forEachLeaf(round.RoundTree.Root, func(leaf *RoundTreeNode) {
+			for _, output := range []NodeOutput{leaf.LeftOutput, leaf.RightOutput} {
+				if output.Owner != &participant.client {
+					continue
+				}
+
+				resp.Vtxos = append(resp.Vtxos, &arkrpc.Vtxo{
+					RoundTxid:   roundTxid,
+					Outpoint:    leafOutpoint(leaf, output).String(),
+					Type:        rpcOutputType(output.OutputType),
+					AssetAmount: output.AssetAmount,
+					BtcAmount:   output.BTCAmount,
+				})
+			}
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *ArkServer) SubmitKeys(ctx context.Context, in *arkrpc.SubmitKeysRequest) (*arkrpc.SubmitResponse, error) {
+	return s.deliver(ctx, in.RequestId, in)
+}
+
+func (s *ArkServer) SubmitNonce(ctx context.Context, in *arkrpc.SubmitNonceRequest) (*arkrpc.SubmitResponse, error) {
+	return s.deliver(ctx, in.RequestId, in)
+}
+
+func (s *ArkServer) SubmitSignatures(ctx context.Context, in *arkrpc.SubmitSignaturesRequest) (*arkrpc.SubmitResponse, error) {
+	return s.deliver(ctx, in.RequestId, in)
+}
+
+// deliver hands the answer to the request of the caller waiting for it, a
+// participant cannot answer the requests of another.
+func (s *ArkServer) deliver(ctx context.Context, requestId string, response proto.Message) (*arkrpc.SubmitResponse, error) {
+	participant, err := s.caller(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = participant.deliver(requestId, response)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return &arkrpc.SubmitResponse{}, nil
+}
+
+// authenticate returns the Ark key of the caller, key must be that key if
+// set.
+func authenticate(ctx context.Context, key []byte) (*btcec.PublicKey, error) {
+	callerKey, err := peerKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) > 0 && !bytes.Equal(key, callerKey.SerializeCompressed()) {
+		return nil, status.Errorf(codes.PermissionDenied, "participant %x is not the key of the connection", key)
+	}
+
+	return callerKey, nil
+}
+
+// caller returns the subscribed participant making the call.
+func (s *ArkServer) caller(ctx context.Context, key []byte) (*remoteParticipant, error) {
+	callerKey, err := authenticate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.participant(callerKey.SerializeCompressed())
+}
+
+func (s *ArkServer) participant(key []byte) (*remoteParticipant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	participant, ok := s.participants[hex.EncodeToString(key)]
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "participant %x is not subscribed", key)
+	}
+
+	return participant, nil
+}
+
+// boarding returns a boarding of the caller.
+func (s *ArkServer) boarding(ctx context.Context, boardingId string) (*remoteBoarding, error) {
+	participant, err := s.caller(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	boarding, ok := s.boardings[boardingId]
+	if !ok || boarding.participant != participant {
+		return nil, status.Errorf(codes.NotFound, "unknown boarding %s", boardingId)
+	}
+
+	return boarding, nil
+}
+
+func (s *ArkServer) round(txid chainhash.Hash) (Round, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, round := range s.rounds {
+		if round.Txid() == txid {
+			return round, true
+		}
+	}
+
+	return Round{}, false
+}
+
+// ownerKey names the participant behind a leaf owner.
+func (s *ArkServer) ownerKey(owner *TapClient) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, participant := range s.participants {
+		if &participant.client == owner {
+			return participant.key.SerializeCompressed()
+		}
+	}
+
+	return nil
+}
+
+// newBoardingBtcAddress derives the BTC boarding output of a boarding of the
+// participant, its spend leaf is proven under the asset root of the boarded
+// asset.
+func (s *ArkServer) newBoardingBtcAddress(participant *TapClient, taprootAssetRoot []byte) (string, ArkSpendingDetails, []byte, error) {
+	btcDetails, pkScript, err := newBoardingBtcOutput(taprootAssetRoot, s.cfg.SpendPath, participant, s.server)
+	if err != nil {
+		return "", ArkSpendingDetails{}, nil, err
+	}
+
+	addr, err := btcutil.NewAddressTaproot(pkScript[2:], &s.server.chainParams)
+	if err != nil {
+		return "", ArkSpendingDetails{}, nil, fmt.Errorf("cannot encode boarding address %v", err)
+	}
+
+	return addr.EncodeAddress(), btcDetails, pkScript, nil
+}
+
+func (s *ArkServer) parseExit(rpcExit *arkrpc.CollaborativeExit, recipient *remoteParticipant) (CollaborativeExit, error) {
+	exit := CollaborativeExit{BtcAmount: rpcExit.BtcAmount}
+
+	if rpcExit.AssetAddress != "" {
+		tapAddr, err := address.DecodeAddress(rpcExit.AssetAddress, &s.server.tapParams)
+		if err != nil {
+			return CollaborativeExit{}, fmt.Errorf("cannot decode asset address %v", err)
+		}
+
+		exit.AssetAddr = &taprpc.Addr{
+			Encoded: rpcExit.AssetAddress,
+			AssetId: tapAddr.AssetID[:],
+			Amount:  tapAddr.Amount,
+		}
+		exit.Recipient = &recipient.client
+	}
+
+	if rpcExit.BtcAmount > 0 {
+		btcInternalKey, err := btcec.ParsePubKey(rpcExit.BtcInternalKey)
+		if err != nil {
+			return CollaborativeExit{}, fmt.Errorf("cannot parse btc internal key %v", err)
+		}
+		exit.BtcInternalKey = btcInternalKey
+	}
+
+	return exit, nil
+}
+
+// publishPhase sends every phase but finalized to all participants, the
+// finalized event carries the round transaction and is sent by onRound.
+func (s *ArkServer) publishPhase(phase RoundPhase) {
+	if phase == RoundPhaseFinalized {
+		return
+	}
+
+	s.publish(&arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_Phase{
+		Phase: &arkrpc.RoundPhaseEvent{Phase: phase.String()},
+	}})
+}
+
+func (s *ArkServer) onRound(round Round, err error) {
+	if err != nil {
+		log.Printf("Scheduled round failed: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.rounds = append(s.rounds, round)
+	s.mu.Unlock()
+
+	log.Printf("Round %s finalized", round.Txid())
+	s.publish(&arkrpc.RoundEvent{Event: &arkrpc.RoundEvent_Phase{
+		Phase: &arkrpc.RoundPhaseEvent{
+			Phase:     RoundPhaseFinalized.String(),
+			RoundTxid: round.Txid().String(),
+		},
+	}})
+}
+
+func (s *ArkServer) publish(event *arkrpc.RoundEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, participant := range s.participants {
+		// Offline participants catch up through ListVtxos
+		_ = participant.send(event)
+	}
+}
@@ -0,0 +1,247 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/lightninglabs/taproot-assets/fn"
+	"github.com/lightninglabs/taproot-assets/tappsbt"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+)
+
+// Signer produces every signature of the cooperative Ark spending paths: BTC
+// tapscript signatures and MuSig2 sessions, which also back the asset virtual
+// transaction signatures. Keys are identified by their descriptor, sessions
+// by the id returned on creation. Calls give up once ctx is done and never
+// modify the packets they are handed.
+type Signer interface {
+	// SignBtcTapscript signs each of the inputIndexes with the matching key
+	// through the matching tapscript leaf and control block.
+	SignBtcTapscript(ctx context.Context, pkt *psbt.Packet, inputIndexes []int,
+		keys []keychain.KeyDescriptor, controlBlocks [][]byte,
+		tapLeaves []txscript.TapLeaf) ([][]byte, error)
+
+	// MuSig2CreateSession opens a session of localKey over the sorted
+	// allSignerKeys and returns its id and the local public nonce.
+	MuSig2CreateSession(ctx context.Context, localKey keychain.KeyDescriptor,
+		allSignerKeys [][]byte, tweak *signrpc.TaprootTweakDesc) ([]byte, []byte, error)
+
+	// MuSig2RegisterNonces hands the public nonces of all other signers to
+	// the session.
+	MuSig2RegisterNonces(ctx context.Context, sessID []byte, otherNonces [][]byte) error
+
+	// MuSig2Sign returns the 32 byte partial signature over sigHash. The
+	// session is removed if cleanup is set.
+	MuSig2Sign(ctx context.Context, sessID, sigHash []byte, cleanup bool) ([]byte, error)
+
+	// MuSig2CombineSigs aggregates the local and all other partial
+	// signatures into the final schnorr signature.
+	MuSig2CombineSigs(ctx context.Context, sessID []byte, otherPartialSigs [][]byte) ([]byte, error)
+
+	// MuSig2Cleanup removes the session and its secret nonce.
+	MuSig2Cleanup(ctx context.Context, sessID []byte) error
+}
+
+var (
+	_ Signer = (*LndSigner)(nil)
+	_ Signer = (*MemorySigner)(nil)
+)
+
+// LndSigner signs through the signrpc and walletrpc services of lnd.
+type LndSigner struct {
+	client     signrpc.SignerClient
+	wallet     walletrpc.WalletKitClient
+	hdCoinType uint32
+}
+
+func NewLndSigner(lndClient LndClient, hdCoinType uint32) *LndSigner {
+	return &LndSigner{lndClient.client, lndClient.wallet, hdCoinType}
+}
+
+// MuSig2CreateSession opens a MuSig2 session in lnd for the local key out of
+// allSignerKeys. lnd generates the nonces and keeps the secret nonce to
+// itself, the public nonce is returned so it can be exchanged with the other
+// signers.
+func (s *LndSigner) MuSig2CreateSession(ctx context.Context,
+	localKey keychain.KeyDescriptor, allSignerKeys [][]byte,
+	tweak *signrpc.TaprootTweakDesc) ([]byte, []byte, error) {
+
+	version := signrpc.MuSig2Version_MUSIG2_VERSION_V100RC2
+	sess, err := s.client.MuSig2CreateSession(
+		ctx, &signrpc.MuSig2SessionRequest{
+			KeyLoc: &signrpc.KeyLocator{
+				KeyFamily: int32(localKey.Family),
+				KeyIndex:  int32(localKey.Index),
+			},
+			AllSignerPubkeys: allSignerKeys,
+			TaprootTweak:     tweak,
+			Version:          version,
+		},
+	)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create MuSig2 Session %v", err)
+	}
+
+	return sess.SessionId, sess.LocalPublicNonces, nil
+}
+
+func (s *LndSigner) MuSig2RegisterNonces(ctx context.Context, sessID []byte, otherNonces [][]byte) error {
+	_, err := s.client.MuSig2RegisterNonces(
+		ctx, &signrpc.MuSig2RegisterNoncesRequest{
+			SessionId:               sessID,
+			OtherSignerPublicNonces: otherNonces,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("cannot register MuSig2 nonces %v", err)
+	}
+
+	return nil
+}
+
+func (s *LndSigner) MuSig2CombineSigs(ctx context.Context, sessID []byte, otherPartialSigs [][]byte) ([]byte, error) {
+	resp, err := s.client.MuSig2CombineSig(
+		ctx, &signrpc.MuSig2CombineSigRequest{
+			SessionId:              sessID,
+			OtherPartialSignatures: otherPartialSigs,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot combine signature %v", err)
+	}
+
+	if !resp.HaveAllSignatures {
+		return nil, fmt.Errorf("missing partial signatures for session %x", sessID)
+	}
+
+	return resp.FinalSignature, nil
+}
+
+// MuSig2Cleanup removes a session and its secret nonce from lnd.
+func (s *LndSigner) MuSig2Cleanup(ctx context.Context, sessID []byte) error {
+	_, err := s.client.MuSig2Cleanup(
+		ctx, &signrpc.MuSig2CleanupRequest{
+			SessionId: sessID,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("cannot cleanup MuSig2 session %v", err)
+	}
+
+	return nil
+}
+
+func (s *LndSigner) MuSig2Sign(ctx context.Context, sessID, sigHash []byte, cleanup bool) ([]byte, error) {
+	resp, err := s.client.MuSig2Sign(
+		ctx, &signrpc.MuSig2SignRequest{
+			SessionId:     sessID,
+			MessageDigest: sigHash,
+			Cleanup:       cleanup,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create MuSig2 partial sig %v", err)
+	}
+
+	return resp.LocalPartialSignature, nil
+}
+
+// SignBtcTapscript signs the inputs through their tapscript leaves with lnd's
+// SignPsbt.
+func (s *LndSigner) SignBtcTapscript(ctx context.Context, pkt *psbt.Packet, inputIndexes []int,
+	keys []keychain.KeyDescriptor, controlBlockBytesList [][]byte,
+	tapLeaves []txscript.TapLeaf) ([][]byte, error) {
+
+	if len(keys) != len(inputIndexes) || len(tapLeaves) != len(inputIndexes) ||
+		len(controlBlockBytesList) != len(inputIndexes) {
+
+		return nil, fmt.Errorf("need one key, leaf and control block per input, got %d inputs %d keys %d leaves %d control blocks",
+			len(inputIndexes), len(keys), len(tapLeaves), len(controlBlockBytesList))
+	}
+
+	// The lnd SignPsbt RPC doesn't really understand multi-sig yet, we
+	// cannot specify multiple keys that need to sign. So what we do here
+	// is just replace the derivation path info for the input we want to
+	// sign to the key we want to sign with. If we do this for every signing
+	// participant, we'll get the correct signatures for OP_CHECKSIGADD.
+	// The derivations go into a copy, the caller may move on to another
+	// signer before a timed out call returns.
+	signPkt, err := clonePacket(pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, inputIndex := range inputIndexes {
+		leafToSign := []*psbt.TaprootTapLeafScript{{
+			ControlBlock: controlBlockBytesList[i],
+			Script:       tapLeaves[i].Script,
+			LeafVersion:  tapLeaves[i].LeafVersion,
+		}}
+		signInput := &signPkt.Inputs[inputIndex]
+		derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
+			keys[i], s.hdCoinType,
+		)
+		trDerivation.LeafHashes = [][]byte{fn.ByteSlice(tapLeaves[i].TapHash())}
+		signInput.Bip32Derivation = []*psbt.Bip32Derivation{derivation}
+		signInput.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{
+			trDerivation,
+		}
+		signInput.TaprootLeafScript = leafToSign
+		signInput.SighashType = txscript.SigHashDefault
+	}
+
+	err = signPkt.SanityCheck()
+	if err != nil {
+		return nil, fmt.Errorf("error sanity checking packet: %v", err)
+	}
+
+	// Other inputs are either key spends or funded by a wallet, hide their
+	// derivation info so that lnd only signs the requested inputs.
+	for i := range signPkt.Inputs {
+		if !slices.Contains(inputIndexes, i) {
+			stripInputDerivations(&signPkt.Inputs[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	err = signPkt.Serialize(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing packet: %v", err)
+	}
+
+	resp, err := s.wallet.SignPsbt(
+		ctx, &walletrpc.SignPsbtRequest{
+			FundedPsbt: buf.Bytes(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error signing psbt: %v", err)
+	}
+	result, err := psbt.NewFromRawBytes(
+		bytes.NewReader(resp.SignedPsbt), false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signed psbt: %v", err)
+	}
+
+	// Make sure every input we wanted to sign for was actually signed
+	signatures := make([][]byte, len(inputIndexes))
+	for i, inputIndex := range inputIndexes {
+		if !slices.Contains(resp.SignedInputs, uint32(inputIndex)) {
+			return nil, fmt.Errorf("lnd did not sign input %d", inputIndex)
+		}
+		if inputIndex >= len(result.Inputs) || len(result.Inputs[inputIndex].TaprootScriptSpendSig) == 0 {
+			return nil, fmt.Errorf("lnd returned no tapscript signature for input %d", inputIndex)
+		}
+		signatures[i] = result.Inputs[inputIndex].TaprootScriptSpendSig[0].Signature
+	}
+
+	return signatures, nil
+}
@@ -0,0 +1,321 @@
+package taponark
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
+)
+
+// MemorySigner is a Signer holding its private keys in memory. It lets the
+// round and tree pipeline sign without lnd, e.g. in unit tests.
+type MemorySigner struct {
+	mu sync.Mutex
+
+	keys      map[[33]byte]*btcec.PrivateKey
+	locators  map[keychain.KeyLocator]*btcec.PrivateKey
+	nextIndex map[keychain.KeyFamily]uint32
+	sessions  map[[32]byte]*musig2.Session
+}
+
+func NewMemorySigner() *MemorySigner {
+	return &MemorySigner{
+		keys:      make(map[[33]byte]*btcec.PrivateKey),
+		locators:  make(map[keychain.KeyLocator]*btcec.PrivateKey),
+		nextIndex: make(map[keychain.KeyFamily]uint32),
+		sessions:  make(map[[32]byte]*musig2.Session),
+	}
+}
+
+// DeriveNextKey creates a fresh random key at the next index of the family.
+func (m *MemorySigner) DeriveNextKey(family keychain.KeyFamily) (keychain.KeyDescriptor, error) {
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("cannot generate private key %v", err)
+	}
+
+	m.mu.Lock()
+	keyLoc := keychain.KeyLocator{Family: family, Index: m.nextIndex[family]}
+	m.nextIndex[family]++
+	m.mu.Unlock()
+
+	return m.AddKey(privKey, keyLoc), nil
+}
+
+// DeriveKey returns the key at the locator, creating it on first use.
+func (m *MemorySigner) DeriveKey(keyLoc keychain.KeyLocator) (keychain.KeyDescriptor, error) {
+	m.mu.Lock()
+	privKey, ok := m.locators[keyLoc]
+	m.mu.Unlock()
+	if ok {
+		return keychain.KeyDescriptor{KeyLocator: keyLoc, PubKey: privKey.PubKey()}, nil
+	}
+
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("cannot generate private key %v", err)
+	}
+
+	return m.AddKey(privKey, keyLoc), nil
+}
+
+// ECDH returns the shared secret of the key at the locator and pub, hashed
+// like lnd's DeriveSharedKey.
+func (m *MemorySigner) ECDH(keyLoc keychain.KeyLocator, pub *btcec.PublicKey) ([32]byte, error) {
+	privKey, err := m.PrivKey(keychain.KeyDescriptor{KeyLocator: keyLoc})
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	ecdh := keychain.PrivKeyECDH{PrivKey: privKey}
+	return ecdh.ECDH(pub)
+}
+
+// SignMessageSchnorr signs the tagged hash of msg with the key at the locator,
+// like lnd's SignMessage with a tag.
+func (m *MemorySigner) SignMessageSchnorr(keyLoc keychain.KeyLocator, msg, tag []byte) (*schnorr.Signature, error) {
+	privKey, err := m.PrivKey(keychain.KeyDescriptor{KeyLocator: keyLoc})
+	if err != nil {
+		return nil, err
+	}
+
+	digest := chainhash.TaggedHash(tag, msg)
+	return schnorr.Sign(privKey, digest[:])
+}
+
+// AddKey imports a private key under the given locator.
+func (m *MemorySigner) AddKey(privKey *btcec.PrivateKey, keyLoc keychain.KeyLocator) keychain.KeyDescriptor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pubKey [33]byte
+	copy(pubKey[:], privKey.PubKey().SerializeCompressed())
+	m.keys[pubKey] = privKey
+	m.locators[keyLoc] = privKey
+
+	return keychain.KeyDescriptor{KeyLocator: keyLoc, PubKey: privKey.PubKey()}
+}
+
+// PrivKey looks a key up by its public key, or by its locator like lnd does
+// if the public key is not set. Fakes of lnd sign wallet inputs with it.
+func (m *MemorySigner) PrivKey(keyDesc keychain.KeyDescriptor) (*btcec.PrivateKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if keyDesc.PubKey != nil {
+		var pubKey [33]byte
+		copy(pubKey[:], keyDesc.PubKey.SerializeCompressed())
+		if privKey, ok := m.keys[pubKey]; ok {
+			return privKey, nil
+		}
+		return nil, fmt.Errorf("unknown key %x", pubKey)
+	}
+
+	if privKey, ok := m.locators[keyDesc.KeyLocator]; ok {
+		return privKey, nil
+	}
+
+	return nil, fmt.Errorf("unknown key locator %v", keyDesc.KeyLocator)
+}
+
+func (m *MemorySigner) SignBtcTapscript(_ context.Context, pkt *psbt.Packet, inputIndexes []int,
+	keys []keychain.KeyDescriptor, controlBlocks [][]byte,
+	tapLeaves []txscript.TapLeaf) ([][]byte, error) {
+
+	if len(keys) != len(inputIndexes) || len(tapLeaves) != len(inputIndexes) {
+		return nil, fmt.Errorf("need one key and leaf per input, got %d inputs %d keys %d leaves",
+			len(inputIndexes), len(keys), len(tapLeaves))
+	}
+
+	prevOutFetcher, err := PacketPrevOutFetcher(pkt)
+	if err != nil {
+		return nil, err
+	}
+	sigHashes := txscript.NewTxSigHashes(pkt.UnsignedTx, prevOutFetcher)
+
+	signatures := make([][]byte, len(inputIndexes))
+	for i, inputIndex := range inputIndexes {
+		privKey, err := m.PrivKey(keys[i])
+		if err != nil {
+			return nil, err
+		}
+
+		sigHash, err := txscript.CalcTapscriptSignaturehash(
+			sigHashes, txscript.SigHashDefault, pkt.UnsignedTx, inputIndex,
+			prevOutFetcher, tapLeaves[i],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compute tapscript sighash %v", err)
+		}
+
+		sig, err := schnorr.Sign(privKey, sigHash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot sign input %d %v", inputIndex, err)
+		}
+		signatures[i] = sig.Serialize()
+	}
+
+	return signatures, nil
+}
+
+func (m *MemorySigner) MuSig2CreateSession(_ context.Context, localKey keychain.KeyDescriptor,
+	allSignerKeys [][]byte, tweak *signrpc.TaprootTweakDesc) ([]byte, []byte, error) {
+
+	privKey, err := m.PrivKey(localKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signerKeys := make([]*btcec.PublicKey, len(allSignerKeys))
+	for i, signerKey := range allSignerKeys {
+		signerKeys[i], err = btcec.ParsePubKey(signerKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot parse signer key %d %v", i, err)
+		}
+	}
+
+	contextOpts := []musig2.ContextOption{musig2.WithKnownSigners(signerKeys)}
+	switch {
+	case tweak == nil:
+	case tweak.KeySpendOnly:
+		contextOpts = append(contextOpts, musig2.WithBip86TweakCtx())
+	default:
+		contextOpts = append(contextOpts, musig2.WithTaprootTweakCtx(tweak.ScriptRoot))
+	}
+
+	muSigContext, err := musig2.NewContext(privKey, true, contextOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create MuSig2 context %v", err)
+	}
+	session, err := muSigContext.NewSession()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create MuSig2 Session %v", err)
+	}
+
+	var sessID [32]byte
+	if _, err := rand.Read(sessID[:]); err != nil {
+		return nil, nil, fmt.Errorf("cannot generate session id %v", err)
+	}
+
+	m.mu.Lock()
+	m.sessions[sessID] = session
+	m.mu.Unlock()
+
+	publicNonce := session.PublicNonce()
+
+	return sessID[:], publicNonce[:], nil
+}
+
+func (m *MemorySigner) session(sessID []byte) (*musig2.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var id [32]byte
+	copy(id[:], sessID)
+	session, ok := m.sessions[id]
+	if !ok || len(sessID) != len(id) {
+		return nil, fmt.Errorf("unknown MuSig2 session %x", sessID)
+	}
+
+	return session, nil
+}
+
+func (m *MemorySigner) MuSig2RegisterNonces(_ context.Context, sessID []byte, otherNonces [][]byte) error {
+	session, err := m.session(sessID)
+	if err != nil {
+		return err
+	}
+
+	for _, otherNonce := range otherNonces {
+		if len(otherNonce) != musig2.PubNonceSize {
+			return fmt.Errorf("cannot register MuSig2 nonce of %d bytes", len(otherNonce))
+		}
+
+		var nonce [musig2.PubNonceSize]byte
+		copy(nonce[:], otherNonce)
+		if _, err := session.RegisterPubNonce(nonce); err != nil {
+			return fmt.Errorf("cannot register MuSig2 nonces %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemorySigner) MuSig2Sign(ctx context.Context, sessID, sigHash []byte, cleanup bool) ([]byte, error) {
+	session, err := m.session(sessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sigHash) != 32 {
+		return nil, fmt.Errorf("cannot sign digest of %d bytes", len(sigHash))
+	}
+
+	var msg [32]byte
+	copy(msg[:], sigHash)
+	partialSig, err := session.Sign(msg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create MuSig2 partial sig %v", err)
+	}
+
+	if cleanup {
+		m.MuSig2Cleanup(ctx, sessID)
+	}
+
+	var buf bytes.Buffer
+	if err := partialSig.Encode(&buf); err != nil {
+		return nil, fmt.Errorf("cannot encode MuSig2 partial sig %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (m *MemorySigner) MuSig2CombineSigs(_ context.Context, sessID []byte, otherPartialSigs [][]byte) ([]byte, error) {
+	session, err := m.session(sessID)
+	if err != nil {
+		return nil, err
+	}
+
+	haveAllSigs := false
+	for _, otherPartialSig := range otherPartialSigs {
+		if len(otherPartialSig) != 32 {
+			return nil, fmt.Errorf("cannot combine partial sig of %d bytes", len(otherPartialSig))
+		}
+
+		var partialSig musig2.PartialSignature
+		if err := partialSig.Decode(bytes.NewReader(otherPartialSig)); err != nil {
+			return nil, fmt.Errorf("cannot decode partial sig %v", err)
+		}
+
+		haveAllSigs, err = session.CombineSig(&partialSig)
+		if err != nil {
+			return nil, fmt.Errorf("cannot combine signature %v", err)
+		}
+	}
+
+	if !haveAllSigs {
+		return nil, fmt.Errorf("missing partial signatures for session %x", sessID)
+	}
+
+	return session.FinalSig().Serialize(), nil
+}
+
+func (m *MemorySigner) MuSig2Cleanup(_ context.Context, sessID []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var id [32]byte
+	copy(id[:], sessID)
+	delete(m.sessions, id)
+
+	return nil
+}
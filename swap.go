@@ -0,0 +1,87 @@
+package taponark
+
+import (
+	"fmt"
+)
+
+// SwapIntent trades the asset boarded in AssetTransfer for the BTC boarded
+// in BtcTransfer. The BTC side boards no asset.
+type SwapIntent struct {
+	AssetTransfer ArkBoardingTransfer
+	BtcTransfer   ArkBoardingTransfer
+}
+
+// ConstructAndBroadcastSwapRound settles the swap in a single round. Both
+// users co-sign the round output and its only leaf pays the asset VTXO to the
+// BTC side and the BTC VTXO, including the asset side's own boarded BTC, to
+// the asset side. Neither boarding input is signed unless the tree carries
+// both VTXOs.
+func ConstructAndBroadcastSwapRound(assetId []byte, swap SwapIntent, server *TapClient, bitcoinClient BitcoinClient) (Round, error) {
+	assetSeller := swap.AssetTransfer.user
+	btcSeller := swap.BtcTransfer.user
+	if assetSeller == nil || btcSeller == nil {
+		return Round{}, fmt.Errorf("swap intent is missing a user")
+	}
+	if assetSeller == btcSeller {
+		return Round{}, fmt.Errorf("swap intent has the same user on both sides")
+	}
+	if swap.AssetTransfer.AssetTransferDetails.assetBoardingAmount == 0 {
+		return Round{}, fmt.Errorf("swap intent carries no asset")
+	}
+	if swap.AssetTransfer.BtcAmount() == 0 {
+		return Round{}, fmt.Errorf("swap asset was boarded without btc")
+	}
+	if swap.BtcTransfer.btcTransferDetails.btcBoardingAmount == 0 {
+		return Round{}, fmt.Errorf("swap intent carries no btc")
+	}
+
+	buildTree := func(roundTransfer ColoredTransfer, roundSpendingDetails ArkSpendingDetails) (RoundTree, error) {
+		var rootNode *RoundTreeNode
+		err := constructLeaf(assetId, true, roundSpendingDetails, roundTransfer, btcSeller, assetSeller, server, &rootNode)
+		if err != nil {
+			return RoundTree{}, fmt.Errorf("failed to construct swap leaf: %w", err)
+		}
+
+		roundTree := RoundTree{rootNode}
+		err = verifySwapTree(roundTree, swap, leafBtcAmount(roundTransfer))
+		if err != nil {
+			return RoundTree{}, err
+		}
+
+		return roundTree, nil
+	}
+
+	btcTransfers := []BtcTransferDetails{
+		swap.AssetTransfer.btcTransferDetails,
+		swap.BtcTransfer.btcTransferDetails,
+	}
+	roundOwners := []*TapClient{btcSeller, assetSeller}
+
+	return constructAndBroadcastRound(assetId, swap.AssetTransfer, btcTransfers, 0, nil, roundOwners, buildTree, server, bitcoinClient)
+}
+
+// verifySwapTree checks that the tree pays each side of the swap its counter
+// asset.
+func verifySwapTree(roundTree RoundTree, swap SwapIntent, btcAmount int64) error {
+	leaf := roundTree.Root
+	if leaf == nil || leaf.NodeType != NodeTypeLeaf {
+		return fmt.Errorf("swap tree must be a single leaf")
+	}
+
+	assetVtxo, btcVtxo := leaf.LeftOutput, leaf.RightOutput
+	assetAmount := swap.AssetTransfer.AssetTransferDetails.assetBoardingAmount
+	if assetVtxo.OutputType != OutputTypeAsset || assetVtxo.Owner != swap.BtcTransfer.user || assetVtxo.AssetAmount != assetAmount {
+		return fmt.Errorf("swap tree does not pay %d asset to the btc side", assetAmount)
+	}
+
+	if btcVtxo.OutputType != OutputTypeBTC || btcVtxo.Owner != swap.AssetTransfer.user || btcVtxo.BTCAmount != btcAmount {
+		return fmt.Errorf("swap tree does not pay %d sats to the asset side", btcAmount)
+	}
+
+	txOuts := leaf.Transaction.TxOut
+	if len(txOuts) < 2 || txOuts[len(txOuts)-1].Value != btcAmount {
+		return fmt.Errorf("swap leaf transaction is missing the btc vtxo")
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package taponark_test
+
+import (
+	"taponark"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapAssetForBtc(t *testing.T) {
+	n := newTestNetwork(t)
+	assetTransfer := n.board(t, taponark.BtcSpendPathScript)
+
+	btcTransfer, err := taponark.OnboardBtcUser(testBtcAmount, taponark.BtcSpendPathScript, &n.exit, &n.server, &n.bitcoin)
+	require.NoError(t, err)
+
+	_, boardingBtc := n.balance(t, &n.boarding)
+
+	swap := taponark.SwapIntent{AssetTransfer: assetTransfer, BtcTransfer: btcTransfer}
+	round, err := taponark.ConstructAndBroadcastSwapRound(n.assetId, swap, &n.server, n.bitcoin)
+	require.NoError(t, err)
+	n.requireValidWitnesses(t, n.requireConfirmed(t, round.Txid()))
+
+	// A single leaf pays the asset to the BTC side and the BTC of both
+	// boardings to the asset side
+	leaf := round.RoundTree.Root
+	require.Equal(t, taponark.NodeTypeLeaf, leaf.NodeType)
+	require.Equal(t, taponark.OutputTypeAsset, leaf.LeftOutput.OutputType)
+	require.EqualValues(t, testAssetAmount, leaf.LeftOutput.AssetAmount)
+	require.Same(t, &n.exit, leaf.LeftOutput.Owner)
+	require.Equal(t, taponark.OutputTypeBTC, leaf.RightOutput.OutputType)
+	require.Same(t, &n.boarding, leaf.RightOutput.Owner)
+	require.Greater(t, leaf.RightOutput.BTCAmount, int64(testBtcAmount))
+
+	// Swapping the same boarding twice is refused
+	_, err = taponark.ConstructAndBroadcastSwapRound(n.assetId, taponark.SwapIntent{AssetTransfer: assetTransfer, BtcTransfer: assetTransfer}, &n.server, n.bitcoin)
+	require.Error(t, err)
+
+	proofFiles, err := taponark.ExitRoundAndAppendProof(round, &n.bitcoin)
+	require.NoError(t, err)
+	require.Len(t, proofFiles, 1)
+	n.requireValidWitnesses(t, n.requireConfirmed(t, leaf.Transaction.TxHash()))
+
+	require.NoError(t, taponark.SubmitProof(round.GenesisPoint, proofFiles[0], &n.exit))
+
+	assetBalance, _ := n.balance(t, &n.exit)
+	require.EqualValues(t, testAssetAmount, assetBalance)
+
+	_, btcBalance := n.balance(t, &n.boarding)
+	require.Equal(t, boardingBtc+leaf.RightOutput.BTCAmount, btcBalance)
+}
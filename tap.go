@@ -7,19 +7,16 @@ import (
 	"log"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
-	"github.com/lightninglabs/lndclient"
 	"github.com/lightninglabs/taproot-assets/address"
 	"github.com/lightninglabs/taproot-assets/asset"
 	"github.com/lightninglabs/taproot-assets/commitment"
-	"github.com/lightninglabs/taproot-assets/fn"
 	"github.com/lightninglabs/taproot-assets/proof"
 	"github.com/lightninglabs/taproot-assets/tappsbt"
 	"github.com/lightninglabs/taproot-assets/taprpc"
@@ -32,7 +29,6 @@ import (
 
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/lncfg"
-	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
 	"github.com/lightningnetwork/lnd/lntest/wait"
 	"github.com/lightningnetwork/lnd/macaroons"
@@ -47,22 +43,52 @@ type TapClient struct {
 	mintclient     mintrpc.MintClient
 	universeclient universerpc.UniverseClient
 	lndClient      LndClient
-	closeClient    func()
+	signer         Signer
+	closeClient    func() error
 	chainParams    chaincfg.Params
 	tapParams      address.ChainParams
 	timeout        time.Duration
 }
 
+// TapdServices are the tapd services a TapClient calls, e.g. served by one
+// in-process fake.
+type TapdServices interface {
+	taprpc.TaprootAssetsClient
+	assetwalletrpc.AssetWalletClient
+	tapdevrpc.TapDevClient
+	mintrpc.MintClient
+	universerpc.UniverseClient
+}
+
+// NewTapClient creates a client of already connected tapd services that
+// signs through lndClient.
+func NewTapClient(universeHost string, tapd TapdServices, lndClient LndClient, chainParams chaincfg.Params, tapParams address.ChainParams, timeout time.Duration) TapClient {
+	return TapClient{
+		universeHost:   universeHost,
+		client:         tapd,
+		wallet:         tapd,
+		devclient:      tapd,
+		mintclient:     tapd,
+		universeclient: tapd,
+		lndClient:      lndClient,
+		signer:         NewLndSigner(lndClient, chainParams.HDCoinType),
+		closeClient:    func() error { return nil },
+		chainParams:    chainParams,
+		tapParams:      tapParams,
+		timeout:        timeout,
+	}
+}
+
 func InitTapClient(universeHost string, tapConfig TapClientConfig, lndClient LndClient, tlsCert, adminMacaroon string, chainParams chaincfg.Params, tapParams address.ChainParams, timeout time.Duration) TapClient {
 	hostPort := tapConfig.Host + ":" + tapConfig.Port
-	clientConn, err := NewBasicConn(hostPort, tapConfig.Port, tlsCert, adminMacaroon)
+	clientConn, cleanUp, err := tapeConn(tapConfig.RecordFile, tapConfig.ReplayFile,
+		func(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+			return NewBasicConn(hostPort, tapConfig.Port, tlsCert, adminMacaroon, opts...)
+		},
+	)
 
 	if err != nil {
-		log.Fatalf("cannot initiate client")
-	}
-
-	cleanUp := func() {
-		clientConn.Close()
+		log.Fatalf("cannot initiate client %v", err)
 	}
 	devclient := tapdevrpc.NewTapDevClient(clientConn)
 
@@ -78,6 +104,7 @@ func InitTapClient(universeHost string, tapConfig TapClientConfig, lndClient Lnd
 		universeclient: universeclient,
 		closeClient:    cleanUp,
 		lndClient:      lndClient,
+		signer:         NewLndSigner(lndClient, chainParams.HDCoinType),
 		chainParams:    chainParams,
 		tapParams:      tapParams,
 		timeout:        timeout,
@@ -85,18 +112,33 @@ func InitTapClient(universeHost string, tapConfig TapClientConfig, lndClient Lnd
 
 }
 
-func (cl *TapClient) GetNewAddress(scriptBranch txscript.TapBranch, assetScriptKey asset.ScriptKey, assetId []byte, amnt uint64) (*taprpc.Addr, error) {
+// Close closes the connections to tapd and its lnd, replayed tapes must have
+// been served to their end.
+func (cl *TapClient) Close() error {
+	tapErr := cl.closeClient()
+	if err := cl.lndClient.Close(); err != nil {
+		return err
+	}
+	if tapErr != nil {
+		return fmt.Errorf("cannot close tap client %v", tapErr)
+	}
+
+	return nil
+}
 
-	btcInternalKey := asset.NUMSPubKey
+func (cl *TapClient) GetNewAddress(arkBtcScript ArkBtcScript, assetScriptKey asset.ScriptKey, assetId []byte, amnt uint64) (*taprpc.Addr, error) {
 
-	scriptBranchPreimage := commitment.NewPreimageFromBranch(scriptBranch)
-	encodedBranchPreimage, _, err := commitment.MaybeEncodeTapscriptPreimage(&scriptBranchPreimage)
+	btcInternalKey := arkBtcScript.internalKey
 
+	scriptBranchPreimage, err := arkBtcScript.tapscriptSibling()
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("%+v\n", encodedBranchPreimage)
+	encodedBranchPreimage, _, err := commitment.MaybeEncodeTapscriptPreimage(scriptBranchPreimage)
+	if err != nil {
+		return nil, err
+	}
 
 	addr, err := cl.client.NewAddr(context.TODO(), &taprpc.NewAddrRequest{
 		AssetId:   assetId,
@@ -115,6 +157,20 @@ func (cl *TapClient) GetNewAddress(scriptBranch txscript.TapBranch, assetScriptK
 	return addr, nil
 }
 
+// GetAssetAddress returns a plain Taproot Asset address of the client's
+// wallet, with keys derived by tapd.
+func (cl *TapClient) GetAssetAddress(assetId []byte, amnt uint64) (*taprpc.Addr, error) {
+	addr, err := cl.client.NewAddr(context.TODO(), &taprpc.NewAddrRequest{
+		AssetId: assetId,
+		Amt:     amnt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot Get new Asset Address %v", err)
+	}
+
+	return addr, nil
+}
+
 func (cl *TapClient) GetBtcAddress() (string, error) {
 	addr, err := cl.lndClient.wallet.NextAddr(context.TODO(), &walletrpc.AddrRequest{
 		Type:   walletrpc.AddressType_TAPROOT_PUBKEY,
@@ -128,6 +184,39 @@ func (cl *TapClient) GetBtcAddress() (string, error) {
 	return addr.Addr, nil
 }
 
+// GetBtcInternalKey returns the internal key of a fresh BIP-0086 address owned
+// by the lnd wallet. Outputs to it are tracked by lnd and can be spent or
+// fee bumped by the wallet, while the key itself is needed for the exclusion
+// proofs of outputs that share a transaction with assets.
+func (cl *TapClient) GetBtcInternalKey() (*btcec.PublicKey, error) {
+	addr, err := cl.GetBtcAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := cl.lndClient.wallet.ListAddresses(context.TODO(), &walletrpc.ListAddressesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list Btc Addresses %v", err)
+	}
+
+	for _, account := range addresses.AccountWithAddresses {
+		for _, property := range account.Addresses {
+			if property.Address != addr {
+				continue
+			}
+
+			internalKey, err := btcec.ParsePubKey(property.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse address internal key %v", err)
+			}
+
+			return internalKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot find internal key of address %s", addr)
+}
+
 func (cl *TapClient) SendAsset(addr *taprpc.Addr) (*taprpc.SendAssetResponse, error) {
 	return cl.client.SendAsset(
 		context.TODO(), &taprpc.SendAssetRequest{
@@ -231,6 +320,61 @@ func (cl *TapClient) GetBalance(assetId []byte) (uint64, int64, error) {
 	return assetBalance, btcBalance, nil
 }
 
+// AssetGroupKey returns the group key of an owned asset, nil for ungrouped
+// assets.
+func (cl *TapClient) AssetGroupKey(assetId []byte) (*btcec.PublicKey, error) {
+	assetsResp, err := cl.client.ListAssets(context.TODO(), &taprpc.ListAssetRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list assets %v", err)
+	}
+
+	for _, ownedAsset := range assetsResp.Assets {
+		if !bytes.Equal(ownedAsset.AssetGenesis.AssetId, assetId) {
+			continue
+		}
+		if ownedAsset.AssetGroup == nil {
+			return nil, nil
+		}
+
+		groupKey, err := btcec.ParsePubKey(ownedAsset.AssetGroup.TweakedGroupKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse group key %v", err)
+		}
+		return groupKey, nil
+	}
+
+	return nil, fmt.Errorf("asset %x not owned", assetId)
+}
+
+// GroupAssetId returns the owned asset of the group with the largest amount.
+func (cl *TapClient) GroupAssetId(groupKey *btcec.PublicKey) ([]byte, error) {
+	assetsResp, err := cl.client.ListAssets(context.TODO(), &taprpc.ListAssetRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list assets %v", err)
+	}
+
+	var assetId []byte
+	largestAmount := uint64(0)
+	for _, ownedAsset := range assetsResp.Assets {
+		if ownedAsset.AssetGroup == nil {
+			continue
+		}
+		if !bytes.Equal(ownedAsset.AssetGroup.TweakedGroupKey, groupKey.SerializeCompressed()) {
+			continue
+		}
+		if ownedAsset.Amount > largestAmount {
+			assetId = ownedAsset.AssetGenesis.AssetId
+			largestAmount = ownedAsset.Amount
+		}
+	}
+
+	if assetId == nil {
+		return nil, fmt.Errorf("no asset of group %x owned", groupKey.SerializeCompressed())
+	}
+
+	return assetId, nil
+}
+
 func (cl *TapClient) GetNextKeys() (asset.ScriptKey,
 	keychain.KeyDescriptor, error) {
 
@@ -268,6 +412,35 @@ func (cl *TapClient) GetNextKeys() (asset.ScriptKey,
 	return *scriptKey, internalKey, nil
 }
 
+// GetArkKey returns the identity key of the client's Ark role, the same key on
+// every call.
+func (cl *TapClient) GetArkKey() (keychain.KeyDescriptor, error) {
+	return cl.lndClient.DeriveKey(keychain.KeyLocator{Family: ARK_KEY_FAMILY, Index: 0})
+}
+
+// NewArkVtxoKey returns a fresh key to receive the VTXOs of an Ark address,
+// unlinked to the Ark key and to the keys of other addresses.
+func (cl *TapClient) NewArkVtxoKey() (keychain.KeyDescriptor, error) {
+	return cl.lndClient.DeriveNextKey(ARK_VTXO_KEY_FAMILY)
+}
+
+// ArkVtxoKey returns the key of one of the client's own Ark addresses,
+// derived from the locator stored with the address.
+func (cl *TapClient) ArkVtxoKey(addr ArkAddress) (keychain.KeyDescriptor, error) {
+	if addr.VtxoKeyLocator == nil {
+		return keychain.KeyDescriptor{}, fmt.Errorf("ark address has no key locator, it was not created by the client")
+	}
+	keyDesc, err := cl.lndClient.DeriveKey(*addr.VtxoKeyLocator)
+	if err != nil {
+		return keychain.KeyDescriptor{}, err
+	}
+	if !keyDesc.PubKey.IsEqual(addr.VtxoKey) {
+		return keychain.KeyDescriptor{}, fmt.Errorf("key %x is not an ark address key of the client", addr.VtxoKey.SerializeCompressed())
+	}
+
+	return keyDesc, nil
+}
+
 func (cl *TapClient) Sync() error {
 	_, err := cl.universeclient.SyncUniverse(context.TODO(), &universerpc.SyncRequest{
 		UniverseHost: cl.universeHost,
@@ -337,82 +510,22 @@ func (cl *TapClient) IncomingTransferEvent(addr *taprpc.Addr) error {
 	return err
 }
 
-func (cl *TapClient) createMuSig2Session(
-	localKey keychain.KeyDescriptor, otherKey []byte,
-	localNonces musig2.Nonces, otherNonces [][]byte) ([]byte, error) {
-
-	version := signrpc.MuSig2Version_MUSIG2_VERSION_V100RC2
-	sess, err := cl.lndClient.client.MuSig2CreateSession(
-		context.TODO(), &signrpc.MuSig2SessionRequest{
-			KeyLoc: &signrpc.KeyLocator{
-				KeyFamily: int32(localKey.Family),
-				KeyIndex:  int32(localKey.Index),
-			},
-			AllSignerPubkeys: [][]byte{
-				localKey.PubKey.SerializeCompressed(),
-				otherKey,
-			},
-			OtherSignerPublicNonces: otherNonces,
-			TaprootTweak: &signrpc.TaprootTweakDesc{
-				KeySpendOnly: true,
-			},
-			Version:                version,
-			PregeneratedLocalNonce: localNonces.SecNonce[:],
-		},
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("cannot create MuSig2 Session %v", err)
-	}
-
-	return sess.SessionId, nil
-}
-
-func (cl *TapClient) combineSigs(sessID,
-	otherPartialSig []byte, leafToSign txscript.TapLeaf,
-	tree *txscript.IndexedTapScriptTree,
-	controlBlock *txscript.ControlBlock) (wire.TxWitness, error) {
-
-	resp, err := cl.lndClient.client.MuSig2CombineSig(
-		context.TODO(), &signrpc.MuSig2CombineSigRequest{
-			SessionId:              sessID,
-			OtherPartialSignatures: [][]byte{otherPartialSig},
-		},
-	)
-
-	if err != nil {
-		return wire.TxWitness{}, fmt.Errorf("cannot combine signature %v", err)
-	}
-
-	for _, leaf := range tree.LeafMerkleProofs {
-		if leaf.TapHash() == leafToSign.TapHash() {
-			controlBlock.InclusionProof = leaf.InclusionProof
-		}
-	}
-
-	controlBlockBytes, err := controlBlock.ToBytes()
-	if err != nil {
-		return wire.TxWitness{}, fmt.Errorf("cannot Get control byte %v", err)
-	}
-
-	commitmentWitness := make(wire.TxWitness, 3)
-	commitmentWitness[0] = resp.FinalSignature
-	commitmentWitness[1] = leafToSign.Script
-	commitmentWitness[2] = controlBlockBytes
-
-	return commitmentWitness, nil
-}
-
 // Note: Commits Outputs pkscripts
 func (cl *TapClient) CommitVirtualPsbts(
 	fundedPacket *psbt.Packet, activePackets []*tappsbt.VPacket) error {
 
+	return CommitVirtualPsbts(fundedPacket, activePackets)
+}
+
+// CommitVirtualPsbts commits the virtual packets to their anchor outputs and
+// creates the proof suffix of every virtual output.
+func CommitVirtualPsbts(fundedPacket *psbt.Packet, activePackets []*tappsbt.VPacket) error {
 	outputCommitments := make(tappsbt.OutputCommitments)
 
 	// And now we commit each packet to the respective anchor output
 	// commitments.
 	for _, vPkt := range activePackets {
-		err := cl.commitPacket(vPkt, outputCommitments)
+		err := commitPacket(vPkt, outputCommitments)
 		if err != nil {
 			return fmt.Errorf("error committing packet: %v", err)
 		}
@@ -451,7 +564,7 @@ func (cl *TapClient) CommitVirtualPsbts(
 
 // commitPacket creates the output commitments for a virtual packet and merges
 // it with the existing commitments for the anchor outputs.
-func (cl *TapClient) commitPacket(vPkt *tappsbt.VPacket,
+func commitPacket(vPkt *tappsbt.VPacket,
 	outputCommitments tappsbt.OutputCommitments) error {
 
 	inputs := vPkt.Inputs
@@ -529,132 +642,7 @@ func (cl *TapClient) commitPacket(vPkt *tappsbt.VPacket,
 	return nil
 }
 
-func (cl *TapClient) partialSignBtcTransfer(pkt *psbt.Packet, length int,
-	keys []keychain.KeyDescriptor, controlBlockBytesList [][]byte,
-	tapLeaves []txscript.TapLeaf) ([][]byte, error) {
-
-	// The lnd SignPsbt RPC doesn't really understand multi-sig yet, we
-	// cannot specify multiple keys that need to sign. So what we do here
-	// is just replace the derivation path info for the input we want to
-	// sign to the key we want to sign with. If we do this for every signing
-	// participant, we'll get the correct signatures for OP_CHECKSIGADD.
-
-	for i := 0; i < length; i++ {
-		leafToSign := []*psbt.TaprootTapLeafScript{{
-			ControlBlock: controlBlockBytesList[i],
-			Script:       tapLeaves[i].Script,
-			LeafVersion:  tapLeaves[i].LeafVersion,
-		}}
-		signInput := &pkt.Inputs[i]
-		derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
-			keys[i], cl.chainParams.HDCoinType,
-		)
-		trDerivation.LeafHashes = [][]byte{fn.ByteSlice(tapLeaves[i].TapHash())}
-		signInput.Bip32Derivation = []*psbt.Bip32Derivation{derivation}
-		signInput.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{
-			trDerivation,
-		}
-		signInput.TaprootLeafScript = leafToSign
-		signInput.SighashType = txscript.SigHashDefault
-	}
-
-	err := pkt.SanityCheck()
-	if err != nil {
-		return nil, fmt.Errorf("error sanity checking packet: %v", err)
-	}
-
-	var buf bytes.Buffer
-	err = pkt.Serialize(&buf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing packet: %v", err)
-	}
-
-	resp, err := cl.lndClient.wallet.SignPsbt(
-		context.TODO(), &walletrpc.SignPsbtRequest{
-			FundedPsbt: buf.Bytes(),
-		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error signing psbt: %v", err)
-	}
-	result, err := psbt.NewFromRawBytes(
-		bytes.NewReader(resp.SignedPsbt), false,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing signed psbt: %v", err)
-	}
-	// Make sure the input we wanted to sign for was actually signed.
-	// require.Contains(t, resp.SignedInputs, inputIndex)
-
-	signatures := make([][]byte, length)
-	for i := 0; i < length; i++ {
-		signatures[i] = result.Inputs[i].TaprootScriptSpendSig[0].Signature
-	}
-
-	return signatures, nil
-}
-
-func (cl *TapClient) partialSignAssetTransfer(assetTransferPacket *tappsbt.VPacket, assetLeaf *txscript.TapLeaf, localScriptKeyDescriptor keychain.KeyDescriptor,
-	localNonces *musig2.Nonces, remoteScriptKey *secp256k1.PublicKey, remoteNonce [66]byte) ([]byte, []byte, error) {
-	sessID, err := cl.createMuSig2Session(localScriptKeyDescriptor, remoteScriptKey.SerializeCompressed(), *localNonces,
-		[][]byte{remoteNonce[:]},
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("cannot create MuSig2 Session %v", err)
-	}
-
-	partialSigner := &muSig2PartialSigner{
-		sessID:     sessID,
-		lnd:        &cl.lndClient,
-		leafToSign: *assetLeaf,
-	}
-
-	fmt.Printf("%+v\n", assetTransferPacket.Inputs)
-
-	vIn := assetTransferPacket.Inputs[0]
-	derivation, trDerivation := tappsbt.Bip32DerivationFromKeyDesc(
-		keychain.KeyDescriptor{
-			PubKey: localScriptKeyDescriptor.PubKey,
-		}, cl.chainParams.HDCoinType,
-	)
-	vIn.Bip32Derivation = []*psbt.Bip32Derivation{derivation}
-	vIn.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{
-		trDerivation,
-	}
-
-	// Note: This also adds Split Commitment Root to Split Asset
-	err = tapsend.SignVirtualTransaction(
-		assetTransferPacket, partialSigner, partialSigner,
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("cannot Sign Virtual Transaction %v", err)
-	}
-
-	isSplit, err := assetTransferPacket.HasSplitCommitment()
-	if err != nil {
-		return nil, nil, fmt.Errorf("cannot check for split commitment %v", err)
-	}
-
-	// Identify new output asset. For splits, the new asset that received
-	// the signature is the one with the split root set to true.
-	newAsset := assetTransferPacket.Outputs[0].Asset
-	if isSplit {
-		splitOut, err := assetTransferPacket.SplitRootOutput()
-		if err != nil {
-			return nil, nil, fmt.Errorf("cannot get split root output %v", err)
-		}
-
-		newAsset = splitOut.Asset
-	}
-
-	// The first part of the witness is just a fake R value, which we can
-	// ignore.
-	partialSig := newAsset.PrevWitnesses[0].TxWitness[0][32:]
-
-	return partialSig, sessID, nil
-}
-
-func NewBasicConn(tapdHost string, tapdPort string, tlsPath, macPath string) (*grpc.ClientConn, error) {
+func NewBasicConn(tapdHost string, tapdPort string, tlsPath, macPath string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
 
 	creds, mac, err := parseLndTLSAndMacaroon(
 		tlsPath, macPath,
@@ -684,6 +672,7 @@ func NewBasicConn(tapdHost string, tapdPort string, tlsPath, macPath string) (*g
 			lncfg.ClientAddressDialer(tapdPort),
 		),
 	)
+	opts = append(opts, extraOpts...)
 	conn, err := grpc.Dial(tapdHost, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to RPC server: %v",
@@ -693,60 +682,6 @@ func NewBasicConn(tapdHost string, tapdPort string, tlsPath, macPath string) (*g
 	return conn, nil
 }
 
-type muSig2PartialSigner struct {
-	sessID     []byte
-	lnd        *LndClient
-	leafToSign txscript.TapLeaf
-}
-
-func (m *muSig2PartialSigner) ValidateWitnesses(*asset.Asset,
-	[]*commitment.SplitAsset, commitment.InputSet) error {
-
-	return nil
-}
-
-func (m *muSig2PartialSigner) SignVirtualTx(_ *lndclient.SignDescriptor,
-	tx *wire.MsgTx, prevOut *wire.TxOut) (*schnorr.Signature, error) {
-
-	prevOutputFetcher := txscript.NewCannedPrevOutputFetcher(
-		prevOut.PkScript, prevOut.Value,
-	)
-	sighashes := txscript.NewTxSigHashes(tx, prevOutputFetcher)
-
-	sigHash, err := txscript.CalcTapscriptSignaturehash(
-		sighashes, txscript.SigHashDefault, tx, 0, prevOutputFetcher,
-		m.leafToSign,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	sign, err := m.lnd.client.MuSig2Sign(
-		context.TODO(), &signrpc.MuSig2SignRequest{
-			SessionId:     m.sessID,
-			MessageDigest: sigHash,
-			Cleanup:       false,
-		},
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	// We only get the 32-byte partial signature (just the s value).
-	// So we just use an all-zero value for R, since the parsing mechanism
-	// doesn't validate R to be a valid point on the curve.
-	var sig [schnorr.SignatureSize]byte
-	copy(sig[32:], sign.LocalPartialSignature)
-
-	return schnorr.ParseSignature(sig[:])
-}
-
-func (m *muSig2PartialSigner) Execute(*asset.Asset, []*commitment.SplitAsset,
-	commitment.InputSet) error {
-
-	return nil
-}
-
 // insertAssetInputInPacket creates a virtual packet input for the given asset input
 // and sets it on the given virtual packet.
 func insertAssetInputInPacket(vPkt *tappsbt.VPacket, idx int,
@@ -786,7 +721,7 @@ func insertAssetInputInPacket(vPkt *tappsbt.VPacket, idx int,
 		Anchor: tappsbt.Anchor{
 			Value:            btcutil.Amount(roundDetails.Anchor.Value),
 			PkScript:         outputScript,
-			InternalKey:      asset.NUMSPubKey,
+			InternalKey:      internalKey,
 			MerkleRoot:       roundDetails.Anchor.MerkleRoot,
 			TapscriptSibling: roundDetails.Anchor.TapscriptSibling,
 		},
@@ -823,7 +758,7 @@ func createAndSetInputIntermediate(vPkt *tappsbt.VPacket,
 		Anchor: tappsbt.Anchor{
 			Value:            btcutil.Amount(roundDetails.anchorValue),
 			PkScript:         outputScript,
-			InternalKey:      asset.NUMSPubKey,
+			InternalKey:      roundDetails.internalKey,
 			MerkleRoot:       roundDetails.merkleRoot,
 			TapscriptSibling: roundDetails.taprootSibling,
 		},
@@ -0,0 +1,200 @@
+package taponark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/lightningnetwork/lnd/brontide"
+	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// The Ark service runs over lnd's brontide, a Noise_XK handshake between the
+// static Ark keys of client and server followed by an encrypted stream. The
+// client pins the server key, and every RPC sees the Ark key of its caller.
+
+// arkKeyECDH is the static brontide key of a client or server. The Ark key
+// stays in lnd, which runs the ECDH.
+type arkKeyECDH struct {
+	keyDesc   keychain.KeyDescriptor
+	lndClient *LndClient
+}
+
+var _ keychain.SingleKeyECDH = (*arkKeyECDH)(nil)
+
+func (k *arkKeyECDH) PubKey() *btcec.PublicKey {
+	return k.keyDesc.PubKey
+}
+
+func (k *arkKeyECDH) ECDH(pub *btcec.PublicKey) ([32]byte, error) {
+	return k.lndClient.DeriveSharedKey(k.keyDesc.KeyLocator, pub)
+}
+
+func (cl *TapClient) arkKeyECDH() (*arkKeyECDH, error) {
+	keyDesc, err := cl.GetArkKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &arkKeyECDH{keyDesc, &cl.lndClient}, nil
+}
+
+// BrontideAuthInfo carries the static key of the remote end of a connection.
+type BrontideAuthInfo struct {
+	credentials.CommonAuthInfo
+	RemotePub *btcec.PublicKey
+}
+
+func (BrontideAuthInfo) AuthType() string {
+	return "brontide"
+}
+
+// brontideCredentials hands the key authenticated by the brontide handshake
+// to gRPC. The handshake itself already ran when the connection was dialed or
+// accepted.
+type brontideCredentials struct{}
+
+func (brontideCredentials) handshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, ok := rawConn.(*brontide.Conn)
+	if !ok {
+		return nil, nil, fmt.Errorf("connection is not brontide encrypted")
+	}
+
+	return conn, BrontideAuthInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity},
+		RemotePub:      conn.RemotePub(),
+	}, nil
+}
+
+func (c brontideCredentials) ClientHandshake(_ context.Context, _ string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.handshake(rawConn)
+}
+
+func (c brontideCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.handshake(rawConn)
+}
+
+func (brontideCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "brontide"}
+}
+
+func (c brontideCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (brontideCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// ListenArk accepts brontide connections to the server's Ark key, serve the
+// Ark service on it with ArkServerOptions.
+func ListenArk(server *TapClient, addr string) (net.Listener, error) {
+	localStatic, err := server.arkKeyECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := brontide.NewListener(localStatic, addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on %s %v", addr, err)
+	}
+
+	return &arkListener{listener, make(chan struct{})}, nil
+}
+
+// arkListener keeps accepting after a failed handshake, the brontide listener
+// returns those from Accept and gRPC stops serving on any Accept error. A
+// closed socket ends the loop, other errors are retried with a backoff so that
+// a failing socket is not spun on.
+type arkListener struct {
+	*brontide.Listener
+	quit chan struct{}
+}
+
+func (l *arkListener) Accept() (net.Conn, error) {
+	var backoff time.Duration
+	for {
+		conn, err := l.Listener.Accept()
+		if err == nil {
+			return conn, nil
+		}
+		if errors.Is(err, net.ErrClosed) {
+			return nil, err
+		}
+
+		backoff = min(max(2*backoff, ARK_ACCEPT_MIN_BACKOFF), ARK_ACCEPT_MAX_BACKOFF)
+		log.Printf("Rejected ark connection, retrying in %v: %v", backoff, err)
+
+		select {
+		case <-l.quit:
+			return nil, err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (l *arkListener) Close() error {
+	select {
+	case <-l.quit:
+	default:
+		close(l.quit)
+	}
+
+	return l.Listener.Close()
+}
+
+// ArkServerOptions makes a gRPC server surface the brontide keys of its callers.
+func ArkServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.Creds(brontideCredentials{})}
+}
+
+// DialArkServer connects the user to the server at addr, the handshake fails
+// unless the server holds serverKey.
+func DialArkServer(user *TapClient, addr string, serverKey *btcec.PublicKey) (*grpc.ClientConn, error) {
+	localStatic, err := user.arkKeyECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve ark server address %v", err)
+	}
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(brontideCredentials{}),
+		grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
+			return brontide.Dial(localStatic, &lnwire.NetAddress{IdentityKey: serverKey, Address: tcpAddr}, ARK_DIAL_TIMEOUT, net.DialTimeout)
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to ark server %v", err)
+	}
+
+	return conn, nil
+}
+
+// peerKey returns the Ark key the caller authenticated with.
+func peerKey(ctx context.Context) (*btcec.PublicKey, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unknown peer")
+	}
+
+	authInfo, ok := p.AuthInfo.(BrontideAuthInfo)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "peer is not brontide authenticated")
+	}
+
+	return authInfo.RemotePub, nil
+}
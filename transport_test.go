@@ -0,0 +1,48 @@
+package taponark_test
+
+import (
+	"net"
+	"taponark"
+	"taponark/internal/taponarktest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArkListenerSurvivesFailedHandshakes(t *testing.T) {
+	network := taponarktest.NewFakeNetwork(testTimeout)
+	t.Cleanup(network.Close)
+	server, err := network.NewNode(0)
+	require.NoError(t, err)
+
+	listener, err := taponark.ListenArk(&server, "127.0.0.1:0")
+	require.NoError(t, err)
+
+	accepted := make(chan error, 1)
+	go func() {
+		_, err := listener.Accept()
+		accepted <- err
+	}()
+
+	// A peer that fails the handshake is dropped, Accept keeps waiting
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write(make([]byte, 50))
+	require.NoError(t, err)
+	conn.Close()
+
+	select {
+	case err := <-accepted:
+		t.Fatalf("accept returned on a failed handshake: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, listener.Close())
+	select {
+	case err := <-accepted:
+		require.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("accept kept running after close")
+	}
+}
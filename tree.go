@@ -10,7 +10,6 @@ import (
 	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightninglabs/taproot-assets/asset"
-	"github.com/lightninglabs/taproot-assets/commitment"
 	"github.com/lightninglabs/taproot-assets/proof"
 	"github.com/lightninglabs/taproot-assets/tappsbt"
 	"github.com/lightninglabs/taproot-assets/tapsend"
@@ -60,6 +59,10 @@ type NodeOutput struct {
 	AssetProof  *proof.Proof
 	BTCAmount   int64
 	Node        *RoundTreeNode
+	// Owner receives the VTXO of a leaf output
+	Owner *TapClient
+	// Address receives the VTXO of a leaf output paid to an Ark address
+	Address *ArkAddress
 }
 
 // Access left child
@@ -124,29 +127,40 @@ func PrintTree(node *RoundTreeNode, prefix string, isTail bool) {
 	}
 }
 
-func ConstructRoundTree(roundTransfer ColoredTransfer, roundSpendingDetails ArkSpendingDetails, assetId []byte, user, server *TapClient, level uint64) (RoundTree, error) {
+// ConstructRoundTree builds a tree of the given level below the round output,
+// with one leaf per leaf owner.
+func ConstructRoundTree(roundTransfer ColoredTransfer, roundSpendingDetails ArkSpendingDetails, assetId []byte, leafOwners []*TapClient, server *TapClient, level uint64) (RoundTree, error) {
+	if uint64(len(leafOwners)) != 1<<(level-1) {
+		return RoundTree{}, fmt.Errorf("a %d level tree needs %d leaf owners, got %d", level, 1<<(level-1), len(leafOwners))
+	}
+
 	var rootNode *RoundTreeNode
 
-	err := constructBranch(assetId, true, roundSpendingDetails, roundTransfer, level-1, user, server, &rootNode)
+	err := constructBranch(assetId, true, roundSpendingDetails, roundTransfer, level-1, leafOwners, server, &rootNode)
 	if err != nil {
-		return RoundTree{}, fmt.Errorf("failed to construct branch: %v", err)
+		return RoundTree{}, fmt.Errorf("failed to construct branch: %w", err)
 	}
 
 	return RoundTree{rootNode}, nil
 
 }
 
-func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendingDetails, prevColoredTransfer ColoredTransfer, level uint64, user, server *TapClient, parentNode **RoundTreeNode) error {
+func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendingDetails, prevColoredTransfer ColoredTransfer, level uint64, leafOwners []*TapClient, server *TapClient, parentNode **RoundTreeNode) error {
 	if level == 0 {
-		return constructLeaf(assetId, isLeft, inputSpendingDetails, prevColoredTransfer, user, server, parentNode)
+		return constructLeaf(assetId, isLeft, inputSpendingDetails, prevColoredTransfer, leafOwners[0], leafOwners[0], server, parentNode)
 	}
 
-	leftOutputSpendingDetails, err := CreateRoundSpendingDetails(user, server)
+	// Each output is co-signed by the owners of the leaves below it
+	leftLeafOwners := leafOwners[:len(leafOwners)/2]
+	rightLeafOwners := leafOwners[len(leafOwners)/2:]
+
+	spendPath := inputSpendingDetails.arkBtcScript.spendPath
+	leftOutputSpendingDetails, err := CreateRoundSpendingDetails(leftLeafOwners, server, spendPath)
 	if err != nil {
 		return fmt.Errorf("failed to create Left output Spending Details %v", err)
 	}
 
-	rightOutputSpendingDetail, err := CreateRoundSpendingDetails(user, server)
+	rightOutputSpendingDetail, err := CreateRoundSpendingDetails(rightLeafOwners, server, spendPath)
 	if err != nil {
 		return fmt.Errorf("failed to create Right output Spending Details %v", err)
 	}
@@ -156,19 +170,25 @@ func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendi
 
 	fundedPkt := tappsbt.ForInteractiveSend(asset.ID(assetId), branchAssetAmount, leftOutputSpendingDetails.arkAssetScript.tapScriptKey, 0, 0, 0,
 		keychain.KeyDescriptor{
-			PubKey: asset.NUMSPubKey,
+			PubKey: leftOutputSpendingDetails.arkBtcScript.internalKey,
 		}, asset.V0, &server.tapParams)
 
 	fundedPkt.Outputs[0].Type = tappsbt.TypeSplitRoot
-	leftBranchScriptBranchPreimage := commitment.NewPreimageFromBranch(leftOutputSpendingDetails.arkBtcScript.Branch)
-	fundedPkt.Outputs[0].AnchorOutputTapscriptSibling = &leftBranchScriptBranchPreimage
+	leftBranchScriptBranchPreimage, err := leftOutputSpendingDetails.arkBtcScript.tapscriptSibling()
+	if err != nil {
+		return fmt.Errorf("cannot create left tapscript sibling %v", err)
+	}
+	fundedPkt.Outputs[0].AnchorOutputTapscriptSibling = leftBranchScriptBranchPreimage
 
 	tappsbt.AddOutput(fundedPkt, branchAssetAmount, rightOutputSpendingDetail.arkAssetScript.tapScriptKey, 1,
 		keychain.KeyDescriptor{
-			PubKey: asset.NUMSPubKey,
+			PubKey: rightOutputSpendingDetail.arkBtcScript.internalKey,
 		}, asset.V0)
-	rightBranchScriptBranchPreimage := commitment.NewPreimageFromBranch(rightOutputSpendingDetail.arkBtcScript.Branch)
-	fundedPkt.Outputs[1].AnchorOutputTapscriptSibling = &rightBranchScriptBranchPreimage
+	rightBranchScriptBranchPreimage, err := rightOutputSpendingDetail.arkBtcScript.tapscriptSibling()
+	if err != nil {
+		return fmt.Errorf("cannot create right tapscript sibling %v", err)
+	}
+	fundedPkt.Outputs[1].AnchorOutputTapscriptSibling = rightBranchScriptBranchPreimage
 
 	// Note: This add input details
 	createAndSetInputIntermediate(fundedPkt, prevColoredTransfer, assetId)
@@ -178,7 +198,10 @@ func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendi
 		return fmt.Errorf("cannot prepare Output %v", err)
 	}
 
-	InsertAssetTransferWitness(inputSpendingDetails, fundedPkt, user, server)
+	err = InsertAssetTransferWitness(inputSpendingDetails, fundedPkt)
+	if err != nil {
+		return fmt.Errorf("cannot sign branch asset transfer %w", err)
+	}
 
 	// Add Btc Output Amount
 	vPackets := []*tappsbt.VPacket{fundedPkt}
@@ -190,18 +213,21 @@ func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendi
 	transferBtcPkt.UnsignedTx.TxOut[1].Value = int64(branchBtcAmount)
 
 	//Adds Fees and commit
-	server.CommitVirtualPsbts(
+	err = server.CommitVirtualPsbts(
 		transferBtcPkt, vPackets,
 	)
+	if err != nil {
+		return fmt.Errorf("cannot commit branch asset transfer %v", err)
+	}
 
 	inputLength := 1
 	spendingDetailsLists := make([]ArkSpendingDetails, inputLength)
 	spendingDetailsLists[0] = inputSpendingDetails
 
 	// Sign BTC Input
-	btcTxWitness, err := CreateBtcWitness(spendingDetailsLists, transferBtcPkt, inputLength, user, server)
+	btcTxWitness, err := CreateBtcWitness(spendingDetailsLists, transferBtcPkt, inputLength)
 	if err != nil {
-		return fmt.Errorf("cannot Create BTC Witness %v", err)
+		return fmt.Errorf("cannot Create BTC Witness %w", err)
 	}
 
 	var buf bytes.Buffer
@@ -230,8 +256,14 @@ func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendi
 	}
 
 	// derive Left and Right Control Blocks
-	leftBtcControlBlock := extractControlBlock(leftOutputSpendingDetails.arkBtcScript, leftUnpublishedTransfer.taprootAssetRoot)
-	rightBtcControlBlock := extractControlBlock(rightOutputSpendingDetail.arkBtcScript, rightUnpublishedTransfer.taprootAssetRoot)
+	leftBtcControlBlock, err := extractControlBlock(leftOutputSpendingDetails.arkBtcScript, leftUnpublishedTransfer.taprootAssetRoot)
+	if err != nil {
+		return err
+	}
+	rightBtcControlBlock, err := extractControlBlock(rightOutputSpendingDetail.arkBtcScript, rightUnpublishedTransfer.taprootAssetRoot)
+	if err != nil {
+		return err
+	}
 
 	// derive and  Left and Right Proofs Details to the ProofList
 	leftOutput := NodeOutput{OutputType: OutputTypeColored, AssetProof: leftUnpublishedTransfer.transferProof, BTCAmount: branchBtcAmount, AssetAmount: branchAssetAmount}
@@ -244,49 +276,52 @@ func constructBranch(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendi
 		RightOutput: rightOutput,
 	}
 
-	if *parentNode == nil {
-		*parentNode = branchNode
-	} else if isLeft {
-		(*parentNode).LeftOutput.Node = branchNode
-	} else {
-		(*parentNode).RightOutput.Node = branchNode
-	}
+	attachNode(parentNode, isLeft, branchNode)
 
 	leftOutputSpendingDetails.arkBtcScript.controlBlock = leftBtcControlBlock
 	rightOutputSpendingDetail.arkBtcScript.controlBlock = rightBtcControlBlock
 
 	// Recursively create the next level of transfers
-	err = constructBranch(assetId, true, leftOutputSpendingDetails, leftUnpublishedTransfer, level-1, user, server, &branchNode)
+	err = constructBranch(assetId, true, leftOutputSpendingDetails, leftUnpublishedTransfer, level-1, leftLeafOwners, server, &branchNode)
 	if err != nil {
-		return fmt.Errorf("cannot construct Left Branch Transaction %v", err)
+		return fmt.Errorf("cannot construct Left Branch Transaction %w", err)
 	}
 
-	err = constructBranch(assetId, false, rightOutputSpendingDetail, rightUnpublishedTransfer, level-1, user, server, &branchNode)
+	err = constructBranch(assetId, false, rightOutputSpendingDetail, rightUnpublishedTransfer, level-1, rightLeafOwners, server, &branchNode)
 	if err != nil {
-		return fmt.Errorf("cannot construct Right Branch Transaction %v", err)
+		return fmt.Errorf("cannot construct Right Branch Transaction %w", err)
 	}
 
 	return nil
 }
 
-func constructLeaf(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendingDetails, prevColoredTransfer ColoredTransfer, user, server *TapClient, parentNode **RoundTreeNode) error {
+// constructLeaf pays the asset VTXO to assetOwner and the BTC VTXO to
+// btcOwner, a leaf owner usually receives both.
+func constructLeaf(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendingDetails, prevColoredTransfer ColoredTransfer, assetOwner, btcOwner, server *TapClient, parentNode **RoundTreeNode) error {
 	assetOutputIndex := 0
-	changeAssetInBtc := DUMMY_ASSET_BTC_AMOUNT
-	btcAmount := prevColoredTransfer.anchorValue - int64(changeAssetInBtc) - int64(FEE)
+	btcAmount := leafBtcAmount(prevColoredTransfer)
 
-	scriptKey, internalKey, err := user.GetNextKeys()
+	scriptKey, internalKey, err := assetOwner.GetNextKeys()
 	if err != nil {
 		return fmt.Errorf("can get next keys %v", err)
 	}
 
+	btcInternalKey := internalKey
+	if btcOwner != assetOwner {
+		_, btcInternalKey, err = btcOwner.GetNextKeys()
+		if err != nil {
+			return fmt.Errorf("can get next btc keys %v", err)
+		}
+	}
+
 	fundedPkt := tappsbt.ForInteractiveSend(asset.ID(assetId), prevColoredTransfer.assetAmount, scriptKey, 0, 0, 0,
 		internalKey, asset.V0, &server.tapParams)
 
 	fundedPkt.Outputs[0].Type = tappsbt.TypeSimple
 
 	// Import watch only wallet
-	_, err = user.lndClient.wallet.ImportPublicKey(context.TODO(), &walletrpc.ImportPublicKeyRequest{
-		PublicKey:   schnorr.SerializePubKey(internalKey.PubKey),
+	_, err = btcOwner.lndClient.wallet.ImportPublicKey(context.TODO(), &walletrpc.ImportPublicKeyRequest{
+		PublicKey:   schnorr.SerializePubKey(btcInternalKey.PubKey),
 		AddressType: walletrpc.AddressType_TAPROOT_PUBKEY,
 	})
 
@@ -303,26 +338,36 @@ func constructLeaf(assetId []byte, isLeft bool, inputSpendingDetails ArkSpending
 		log.Fatalf("cannot prepare Output %v", err)
 	}
 
-	InsertAssetTransferWitness(inputSpendingDetails, fundedPkt, user, server)
+	err = InsertAssetTransferWitness(inputSpendingDetails, fundedPkt)
+	if err != nil {
+		return fmt.Errorf("cannot sign leaf asset transfer %w", err)
+	}
+	fundedPkt.Outputs[CHANGE_OUTPUT_INDEX].AnchorOutputInternalKey = asset.NUMSPubKey
 
 	vPackets := []*tappsbt.VPacket{fundedPkt}
 	transferBtcPkt, err := tapsend.PrepareAnchoringTemplate(vPackets)
 	if err != nil {
 		return fmt.Errorf("cannot prepare TransferBtc Packet %v", err)
 	}
-	addBtcOutput(transferBtcPkt, uint64(btcAmount), internalKey.PubKey)
+	err = addBtcOutput(transferBtcPkt, uint64(btcAmount), btcInternalKey.PubKey)
+	if err != nil {
+		return fmt.Errorf("cannot add leaf btc output %v", err)
+	}
 
-	server.CommitVirtualPsbts(
+	err = server.CommitVirtualPsbts(
 		transferBtcPkt, vPackets,
 	)
+	if err != nil {
+		return fmt.Errorf("cannot commit leaf asset transfer %v", err)
+	}
 
 	inputLength := 1
 	spendingDetailsLists := make([]ArkSpendingDetails, inputLength)
 	spendingDetailsLists[0] = inputSpendingDetails
 
-	btcTxWitness, err := CreateBtcWitness(spendingDetailsLists, transferBtcPkt, inputLength, user, server)
+	btcTxWitness, err := CreateBtcWitness(spendingDetailsLists, transferBtcPkt, inputLength)
 	if err != nil {
-		return fmt.Errorf("cannot Create BTC Witness %v", err)
+		return fmt.Errorf("cannot Create BTC Witness %w", err)
 	}
 
 	var buf bytes.Buffer
@@ -344,8 +389,8 @@ func constructLeaf(assetId []byte, isLeft bool, inputSpendingDetails ArkSpending
 	if err != nil {
 		return fmt.Errorf("cannot Extract Colored Transfer %v", err)
 	}
-	assetVtxo := NodeOutput{OutputType: OutputTypeAsset, AssetProof: unpublishedTransfer.transferProof, AssetAmount: prevColoredTransfer.assetAmount}
-	btcVtxo := NodeOutput{OutputType: OutputTypeBTC, BTCAmount: btcAmount}
+	assetVtxo := NodeOutput{OutputType: OutputTypeAsset, AssetProof: unpublishedTransfer.transferProof, AssetAmount: prevColoredTransfer.assetAmount, Owner: assetOwner}
+	btcVtxo := NodeOutput{OutputType: OutputTypeBTC, BTCAmount: btcAmount, Owner: btcOwner}
 	leafNode := RoundTreeNode{
 		Transaction: unpublishedTransfer.finalTx,
 		NodeType:    NodeTypeLeaf,
@@ -353,11 +398,120 @@ func constructLeaf(assetId []byte, isLeft bool, inputSpendingDetails ArkSpending
 		RightOutput: btcVtxo,
 	}
 
-	if isLeft {
-		(*parentNode).LeftOutput.Node = &leafNode
-	} else {
-		(*parentNode).RightOutput.Node = &leafNode
+	attachNode(parentNode, isLeft, &leafNode)
+
+	return nil
+}
+
+// constructAddressLeaf pays the asset and the BTC of the colored output to the
+// policy of an Ark address, in a single anchor output like an out of round
+// payment. The leaf has no separate BTC VTXO, its right output is empty.
+func constructAddressLeaf(assetId []byte, isLeft bool, inputSpendingDetails ArkSpendingDetails, prevColoredTransfer ColoredTransfer, addr ArkAddress, server *TapClient, parentNode **RoundTreeNode) error {
+	arkAssetScript, arkBtcScript, err := addr.vtxoScripts()
+	if err != nil {
+		return err
+	}
+
+	fundedPkt := tappsbt.ForInteractiveSend(asset.ID(assetId), prevColoredTransfer.assetAmount, arkAssetScript.tapScriptKey, 0, 0, 0,
+		keychain.KeyDescriptor{
+			PubKey: arkBtcScript.internalKey,
+		}, asset.V0, &server.tapParams)
+	fundedPkt.Outputs[0].Type = tappsbt.TypeSimple
+
+	scriptBranchPreimage, err := arkBtcScript.tapscriptSibling()
+	if err != nil {
+		return fmt.Errorf("cannot create ark address tapscript sibling %v", err)
+	}
+	fundedPkt.Outputs[0].AnchorOutputTapscriptSibling = scriptBranchPreimage
+
+	createAndSetInputIntermediate(fundedPkt, prevColoredTransfer, assetId)
+	err = tapsend.PrepareOutputAssets(context.TODO(), fundedPkt)
+	if err != nil {
+		return fmt.Errorf("cannot prepare Output %v", err)
 	}
 
+	err = InsertAssetTransferWitness(inputSpendingDetails, fundedPkt)
+	if err != nil {
+		return fmt.Errorf("cannot sign address leaf asset transfer %w", err)
+	}
+
+	vPackets := []*tappsbt.VPacket{fundedPkt}
+	transferBtcPkt, err := tapsend.PrepareAnchoringTemplate(vPackets)
+	if err != nil {
+		return fmt.Errorf("cannot prepare TransferBtc Packet %v", err)
+	}
+	btcAmount := prevColoredTransfer.anchorValue - int64(FEE)
+	transferBtcPkt.UnsignedTx.TxOut[0].Value = btcAmount
+
+	err = server.CommitVirtualPsbts(
+		transferBtcPkt, vPackets,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot commit address leaf asset transfer %v", err)
+	}
+
+	btcTxWitness, err := CreateBtcWitness([]ArkSpendingDetails{inputSpendingDetails}, transferBtcPkt, 1)
+	if err != nil {
+		return fmt.Errorf("cannot Create BTC Witness %w", err)
+	}
+
+	transfer, err := finalizeTransfer(transferBtcPkt, btcTxWitness, fundedPkt.Outputs[0])
+	if err != nil {
+		return err
+	}
+
+	assetVtxo := NodeOutput{OutputType: OutputTypeAsset, AssetProof: transfer.transferProof, AssetAmount: prevColoredTransfer.assetAmount, BTCAmount: btcAmount, Address: &addr}
+	leafNode := RoundTreeNode{
+		Transaction: transfer.finalTx,
+		NodeType:    NodeTypeLeaf,
+		LeftOutput:  assetVtxo,
+		RightOutput: NodeOutput{OutputType: OutputTypeBTC},
+	}
+
+	attachNode(parentNode, isLeft, &leafNode)
+
 	return nil
 }
+
+// leafBtcAmount is the BTC VTXO of a leaf spending the colored output, which
+// keeps DUMMY_ASSET_BTC_AMOUNT next to the asset and pays FEE.
+func leafBtcAmount(prevColoredTransfer ColoredTransfer) int64 {
+	return prevColoredTransfer.anchorValue - int64(DUMMY_ASSET_BTC_AMOUNT) - int64(FEE)
+}
+
+// attachNode hangs node below the given side of the parent, or makes it the
+// root when there is no parent yet.
+func attachNode(parentNode **RoundTreeNode, isLeft bool, node *RoundTreeNode) {
+	if *parentNode == nil {
+		*parentNode = node
+	} else if isLeft {
+		(*parentNode).LeftOutput.Node = node
+	} else {
+		(*parentNode).RightOutput.Node = node
+	}
+}
+
+// forEachLeaf calls visit on every leaf below node.
+func forEachLeaf(node *RoundTreeNode, visit func(leaf *RoundTreeNode)) {
+	if node == nil {
+		return
+	}
+	if node.NodeType == NodeTypeLeaf {
+		visit(node)
+		return
+	}
+
+	forEachLeaf(node.LeftChild(), visit)
+	forEachLeaf(node.RightChild(), visit)
+}
+
+// leafOutpoint is the outpoint of a VTXO in its leaf transaction. The asset
+// VTXO is anchored in the first output, the BTC VTXO is the last one.
+func leafOutpoint(leaf *RoundTreeNode, output NodeOutput) wire.OutPoint {
+	index := uint32(0)
+	if output.OutputType == OutputTypeBTC {
+		index = uint32(len(leaf.Transaction.TxOut) - 1)
+	}
+
+	return wire.OutPoint{Hash: leaf.Transaction.TxHash(), Index: index}
+}
@@ -1,10 +1,12 @@
 package taponark
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -12,8 +14,6 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
-	"github.com/decred/dcrd/dcrec/secp256k1/v4"
-	"github.com/lightninglabs/taproot-assets/asset"
 	"github.com/lightninglabs/taproot-assets/commitment"
 	"github.com/lightninglabs/taproot-assets/tappsbt"
 	"github.com/lightninglabs/taproot-assets/taprpc"
@@ -28,8 +28,79 @@ const FEE uint64 = 10_000
 const DUMMY_ASSET_BTC_AMOUNT = 1_000
 const ROUND_ROOT_ANCHOR_OUTPUT_INDEX = 0
 const ROUND_ROOT_ASSET_OUTPUT_INDEX = 0
+const ROUND_TREE_LEVEL = 2
+
+// The round transaction carries a small server owned output so that a stuck
+// round can be CPFP fee bumped without changing the txid the tree commits to.
+const ROUND_CPFP_ANCHOR_AMOUNT = 1_000
+const ROUND_MAX_FEE_BUMPS = 3
+const CPFP_CONF_TARGET = 1
+const MIN_CPFP_SAT_PER_VBYTE = 5
+
+// Blocks a VHTLC output must be deep before its receiver or sender can spend
+// it without the server.
+const VHTLC_UNILATERAL_CLAIM_DELAY = 144
+const VHTLC_UNILATERAL_REFUND_DELAY = 288
+
+// A recorded gRPC call is one tape line, proof files make them large.
+const GRPC_TAPE_MAX_LINE = 64 * 1024 * 1024
+
+// Bounds of the delay between retries of a failing Ark listener, it doubles on
+// every failure in a row.
+const ARK_ACCEPT_MIN_BACKOFF = 5 * time.Millisecond
+const ARK_ACCEPT_MAX_BACKOFF = time.Second
+
+// A participant that does not answer a nonce or signature request in time is
+// dropped from the round.
+const SIGNER_TIMEOUT = 30 * time.Second
+
+// lnd key family of the server's Ark identity key, which users pin.
+const ARK_KEY_FAMILY = 314
+
+// lnd key family of the VTXO keys of Ark addresses, a fresh key per address.
+const ARK_VTXO_KEY_FAMILY = 315
+
+// Events queued for a daemon participant before its stream is considered
+// stuck.
+const PARTICIPANT_EVENT_BUFFER = 64
+
+// Time to connect and run the brontide handshake with the Ark server.
+const ARK_DIAL_TIMEOUT = 10 * time.Second
+
+// Payload version of encoded Ark addresses.
+const ARK_ADDRESS_VERSION = 0
+
+// Payload version of encoded payment requests, their signature commits to
+// the tag.
+const PAYMENT_REQUEST_VERSION = 0
+const PAYMENT_REQUEST_TAG = "taponark/payment-request"
+const MAX_PAYMENT_REQUEST_MEMO = 256
+
+// Number of blocks whose median timestamp is the mean time of a block when
+// verifying time locks of proofs.
+const MEDIAN_TIME_BLOCKS = 11
+
+// Blocks a VTXO paid to an Ark address must be deep before its receiver can
+// spend it without the server, unless the address names another delay.
+const DEFAULT_ARK_EXIT_DELAY = 144
 
 func ExtractColoredTransfer(btcPacket *psbt.Packet, transferOutput *tappsbt.VOutput) (ColoredTransfer, error) {
+	finalTx, err := psbt.Extract(btcPacket)
+	if err != nil {
+		return ColoredTransfer{}, fmt.Errorf("cannot extract final transaction %v", err)
+	}
+
+	return newColoredTransfer(btcPacket, transferOutput, finalTx)
+}
+
+// unsignedColoredTransfer returns the transfer of a packet whose BTC inputs
+// are not signed yet. Witnesses do not change the txid, so outputs of the
+// unsigned transaction can already be spent by presigned children.
+func unsignedColoredTransfer(btcPacket *psbt.Packet, transferOutput *tappsbt.VOutput) (ColoredTransfer, error) {
+	return newColoredTransfer(btcPacket, transferOutput, btcPacket.UnsignedTx.Copy())
+}
+
+func newColoredTransfer(btcPacket *psbt.Packet, transferOutput *tappsbt.VOutput, finalTx *wire.MsgTx) (ColoredTransfer, error) {
 	internalKey := transferOutput.AnchorOutputInternalKey
 	scriptKey := transferOutput.ScriptKey
 	merkleRoot := tappsbt.ExtractCustomField(
@@ -43,10 +114,6 @@ func ExtractColoredTransfer(btcPacket *psbt.Packet, transferOutput *tappsbt.VOut
 		return ColoredTransfer{}, fmt.Errorf("cannot encode tapscript preimage %v", err)
 	}
 
-	finalTx, err := psbt.Extract(btcPacket)
-	if err != nil {
-		return ColoredTransfer{}, fmt.Errorf("cannot extract final transaction %v", err)
-	}
 	txhash := transferOutput.ProofSuffix.AnchorTx.TxHash()
 
 	outpoint := wire.NewOutPoint(&txhash, transferOutput.AnchorOutputIndex)
@@ -58,25 +125,15 @@ func ExtractColoredTransfer(btcPacket *psbt.Packet, transferOutput *tappsbt.VOut
 
 }
 
-func extractControlBlock(arkBtcScript ArkBtcScript, taprootAssetRoot []byte) *txscript.ControlBlock {
-	btcInternalKey := asset.NUMSPubKey
-	btcControlBlock := &txscript.ControlBlock{
-		LeafVersion: txscript.BaseLeafVersion,
-		InternalKey: btcInternalKey,
-	}
-
-	rightNodeHash := arkBtcScript.unilateralSpend.TapHash()
-	inclusionproof := append(rightNodeHash[:], taprootAssetRoot[:]...)
-	btcControlBlock.InclusionProof = inclusionproof
-	rootHash := btcControlBlock.RootHash(arkBtcScript.cooperativeSpend.Script)
-	tapKey := txscript.ComputeTaprootOutputKey(btcInternalKey, rootHash)
-	if tapKey.SerializeCompressed()[0] ==
-		secp256k1.PubKeyFormatCompressedOdd {
-
-		btcControlBlock.OutputKeyYIsOdd = true
+// extractControlBlock proves the spend leaf of the anchor output once its
+// Taproot Asset root is known.
+func extractControlBlock(arkBtcScript ArkBtcScript, taprootAssetRoot []byte) (*txscript.ControlBlock, error) {
+	controlBlock, err := arkBtcScript.ControlBlock(arkBtcScript.spendLeaf, taprootAssetRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create control block %w", err)
 	}
 
-	return btcControlBlock
+	return controlBlock, nil
 }
 
 func addBtcInputToPSBT(transferPacket *psbt.Packet, btcTransferDetails BtcTransferDetails) {
@@ -88,14 +145,13 @@ func addBtcInputToPSBT(transferPacket *psbt.Packet, btcTransferDetails BtcTransf
 		},
 	)
 
-	controlBlock := signingDetails.arkSpendingDetails.arkBtcScript.controlBlock
-	rootHash := controlBlock.RootHash(signingDetails.arkSpendingDetails.arkBtcScript.cooperativeSpend.Script)
+	arkBtcScript := signingDetails.arkSpendingDetails.arkBtcScript
 	transferPacket.Inputs = append(transferPacket.Inputs, psbt.PInput{
 		WitnessUtxo: signingDetails.txout,
 		TaprootInternalKey: schnorr.SerializePubKey(
-			asset.NUMSPubKey,
+			arkBtcScript.internalKey,
 		),
-		TaprootMerkleRoot: rootHash,
+		TaprootMerkleRoot: arkBtcScript.merkleRoot(),
 	})
 
 }
@@ -127,6 +183,63 @@ func addBtcOutput(transferPacket *psbt.Packet, amount uint64, internalKey *btcec
 	return nil
 }
 
+// PacketPrevOutFetcher returns the witness utxos of all packet inputs, which
+// taproot sighashes commit to.
+func PacketPrevOutFetcher(pkt *psbt.Packet) (*txscript.MultiPrevOutFetcher, error) {
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for idx, txIn := range pkt.UnsignedTx.TxIn {
+		witnessUtxo := pkt.Inputs[idx].WitnessUtxo
+		if witnessUtxo == nil {
+			return nil, fmt.Errorf("input %d is missing its witness utxo", idx)
+		}
+		prevOutFetcher.AddPrevOut(txIn.PreviousOutPoint, witnessUtxo)
+	}
+
+	return prevOutFetcher, nil
+}
+
+// keySpendSigHash returns the BIP-341 key spend sighash of an input. Every
+// input of the packet must carry its WitnessUtxo.
+func keySpendSigHash(pkt *psbt.Packet, inputIndex int) ([]byte, error) {
+	prevOutFetcher, err := PacketPrevOutFetcher(pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	sigHashes := txscript.NewTxSigHashes(pkt.UnsignedTx, prevOutFetcher)
+	sigHash, err := txscript.CalcTaprootSignatureHash(
+		sigHashes, txscript.SigHashDefault, pkt.UnsignedTx, inputIndex,
+		prevOutFetcher,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute key spend sighash %v", err)
+	}
+
+	return sigHash, nil
+}
+
+// clonePacket returns a deep copy of the packet.
+func clonePacket(pkt *psbt.Packet) (*psbt.Packet, error) {
+	var buf bytes.Buffer
+	if err := pkt.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("cannot serialize packet %v", err)
+	}
+
+	clone, err := psbt.NewFromRawBytes(&buf, false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse packet %v", err)
+	}
+
+	return clone, nil
+}
+
+// stripInputDerivations removes the key derivation info lnd uses to decide
+// which inputs it should sign.
+func stripInputDerivations(pInput *psbt.PInput) {
+	pInput.Bip32Derivation = nil
+	pInput.TaprootBip32Derivation = nil
+}
+
 // waitForTransfers concurrently waits for both the BTC confirmation and the asset transfer event.
 func waitForTransfers(bitcoinClient *BitcoinClient, serverTapClient *TapClient, txHash chainhash.Hash, assetAddr *taprpc.Addr) error {
 	var wg sync.WaitGroup
@@ -0,0 +1,99 @@
+package taponark
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MAX_STANDARD_TX_VERSION is the highest transaction version relayed by
+// bitcoind's default policy.
+const MAX_STANDARD_TX_VERSION = 2
+
+// ValidateRoundTree checks that every exit transaction in the round tree is
+// standard and that its witnesses satisfy the parent outputs it spends. The
+// round transaction is the parent of the tree root.
+func ValidateRoundTree(roundTx *wire.MsgTx, roundTree RoundTree) error {
+	var validateRecursively func(node *RoundTreeNode, parentTx *wire.MsgTx) error
+
+	validateRecursively = func(node *RoundTreeNode, parentTx *wire.MsgTx) error {
+		if node == nil {
+			return nil
+		}
+
+		txid := node.Transaction.TxHash()
+		err := checkTransactionStandard(node.Transaction)
+		if err != nil {
+			return fmt.Errorf("exit transaction %s is not standard: %v", txid, err)
+		}
+
+		err = validateTransactionScripts(node.Transaction, parentTx)
+		if err != nil {
+			return fmt.Errorf("exit transaction %s is invalid: %v", txid, err)
+		}
+
+		for _, child := range []*RoundTreeNode{node.LeftChild(), node.RightChild()} {
+			if err := validateRecursively(child, node.Transaction); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return validateRecursively(roundTree.Root, roundTx)
+}
+
+// checkTransactionStandard applies bitcoind's relay policy checks that can
+// be evaluated without a UTXO set, such as version, size and dust limits.
+func checkTransactionStandard(tx *wire.MsgTx) error {
+	// Exit transactions are broadcast long after the round, so a zero
+	// height and the current time only reject genuinely unfinalized
+	// transactions.
+	return mempool.CheckTransactionStandard(
+		btcutil.NewTx(tx), 0, time.Now(), mempool.DefaultMinRelayTxFee,
+		MAX_STANDARD_TX_VERSION,
+	)
+}
+
+// validateTransactionScripts runs every input of tx through the script engine
+// against the matching output of parentTx.
+func validateTransactionScripts(tx, parentTx *wire.MsgTx) error {
+	parentTxid := parentTx.TxHash()
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+
+	for idx, txIn := range tx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		if prevOut.Hash != parentTxid {
+			return fmt.Errorf("input %d spends %s, expected an output of %s", idx, prevOut, parentTxid)
+		}
+		if prevOut.Index >= uint32(len(parentTx.TxOut)) {
+			return fmt.Errorf("input %d spends missing output %s", idx, prevOut)
+		}
+
+		prevOutFetcher.AddPrevOut(prevOut, parentTx.TxOut[prevOut.Index])
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+	for idx, txIn := range tx.TxIn {
+		prevTxOut := prevOutFetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+
+		engine, err := txscript.NewEngine(
+			prevTxOut.PkScript, tx, idx, txscript.StandardVerifyFlags,
+			nil, sigHashes, prevTxOut.Value, prevOutFetcher,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot create script engine for input %d: %v", idx, err)
+		}
+
+		if err := engine.Execute(); err != nil {
+			return fmt.Errorf("input %d failed script validation: %v", idx, err)
+		}
+	}
+
+	return nil
+}
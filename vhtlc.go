@@ -0,0 +1,161 @@
+package taponark
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/lightninglabs/taproot-assets/asset"
+)
+
+// VHTLC is an asset VTXO locked to a payment hash. The receiver claims it
+// with the server by revealing the preimage, the sender refunds it with the
+// server once RefundLocktime is reached. Without the server the receiver can
+// still claim, and the sender refund, after a relative delay.
+//
+// The same leaves are used for the asset script key and the BTC anchor, the
+// former over the script keys and the latter over the internal keys of the
+// parties.
+//
+// VHTLCs lock a boarded output out of round. They are not round tree leaves,
+// a VHTLC settled within a round is out of scope.
+type VHTLC struct {
+	sender   *TapClient
+	receiver *TapClient
+
+	senderKeys   Cosigner
+	receiverKeys Cosigner
+	serverKeys   Cosigner
+
+	PaymentHash    [32]byte
+	RefundLocktime uint32
+
+	policyOutput
+}
+
+// vhtlcPolicy builds the VHTLC leaves over one key of every party.
+func vhtlcPolicy(paymentHash [32]byte, refundLocktime uint32, sender, receiver, server *btcec.PublicKey) (ArkScriptPolicy, error) {
+	claimKey, err := muSig2LeafKey([]*btcec.PublicKey{receiver, server})
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+	claimLeaf, err := HashlockLeaf(ArkLeafClaim, paymentHash[:], claimKey)
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+
+	refundKey, err := muSig2LeafKey([]*btcec.PublicKey{sender, server})
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+	refundLeaf, err := AbsoluteTimelockLeaf(ArkLeafRefund, refundKey, refundLocktime)
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+
+	unilateralClaimCondition, err := txscript.NewScriptBuilder().
+		AddInt64(VHTLC_UNILATERAL_CLAIM_DELAY).
+		AddOp(txscript.OP_CHECKSEQUENCEVERIFY).
+		AddOp(txscript.OP_DROP).
+		AddOp(txscript.OP_SIZE).
+		AddInt64(32).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_SHA256).
+		AddData(paymentHash[:]).
+		AddOp(txscript.OP_EQUAL).
+		Script()
+	if err != nil {
+		return ArkScriptPolicy{}, fmt.Errorf("failed to create unilateral claim condition: %w", err)
+	}
+	unilateralClaimLeaf, err := ConditionLeaf(ArkLeafUnilateralClaim, unilateralClaimCondition, receiver)
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+
+	unilateralRefundLeaf, err := RelativeTimelockLeaf(ArkLeafUnilateralRefund, sender, VHTLC_UNILATERAL_REFUND_DELAY)
+	if err != nil {
+		return ArkScriptPolicy{}, err
+	}
+
+	return NewArkScriptPolicy(claimLeaf, refundLeaf, unilateralClaimLeaf, unilateralRefundLeaf)
+}
+
+// CreateVHTLC locks the boarded asset and BTC of the boarding user into a
+// VHTLC paying the receiver, and broadcasts it.
+func CreateVHTLC(assetId []byte, onboardTransfer ArkBoardingTransfer, receiver, server *TapClient, paymentHash [32]byte, refundLocktime uint32, bitcoinClient *BitcoinClient) (VHTLC, error) {
+	sender := onboardTransfer.user
+	cosigners, err := createCosigners([]*TapClient{sender, receiver}, server)
+	if err != nil {
+		return VHTLC{}, err
+	}
+	senderKeys, receiverKeys, serverKeys := cosigners[0], cosigners[1], cosigners[2]
+
+	assetPolicy, err := vhtlcPolicy(paymentHash, refundLocktime,
+		senderKeys.scriptKey.RawKey.PubKey, receiverKeys.scriptKey.RawKey.PubKey, serverKeys.scriptKey.RawKey.PubKey)
+	if err != nil {
+		return VHTLC{}, fmt.Errorf("cannot create vhtlc asset policy %v", err)
+	}
+	btcPolicy, err := vhtlcPolicy(paymentHash, refundLocktime,
+		senderKeys.internalKey.PubKey, receiverKeys.internalKey.PubKey, serverKeys.internalKey.PubKey)
+	if err != nil {
+		return VHTLC{}, fmt.Errorf("cannot create vhtlc btc policy %v", err)
+	}
+
+	arkAssetScript := NewArkAssetScript(assetPolicy)
+	arkBtcScript, err := NewArkBtcScript(btcPolicy, ArkLeafClaim, asset.NUMSPubKey, BtcSpendPathScript)
+	if err != nil {
+		return VHTLC{}, err
+	}
+
+	output, err := lockBoardingTransfer(assetId, onboardTransfer, arkAssetScript, arkBtcScript, server, bitcoinClient)
+	if err != nil {
+		return VHTLC{}, err
+	}
+
+	log.Printf("VHTLC TxId %s", output.transfer.finalTx.TxHash().String())
+
+	return VHTLC{
+		sender:         sender,
+		receiver:       receiver,
+		senderKeys:     senderKeys,
+		receiverKeys:   receiverKeys,
+		serverKeys:     serverKeys,
+		PaymentHash:    paymentHash,
+		RefundLocktime: refundLocktime,
+		policyOutput:   output,
+	}, nil
+}
+
+// Claim pays the VHTLC to a fresh key of the receiver, co-signed by the
+// server, and returns the receiver's proof file.
+func (h VHTLC) Claim(preimage [32]byte, bitcoinClient *BitcoinClient) ([]byte, error) {
+	if sha256.Sum256(preimage[:]) != h.PaymentHash {
+		return nil, fmt.Errorf("preimage does not match the vhtlc payment hash")
+	}
+
+	return h.spend(ArkLeafClaim, []Cosigner{h.receiverKeys, h.serverKeys}, h.receiver, 0, 0, bitcoinClient, preimage[:])
+}
+
+// Refund pays the VHTLC back to a fresh key of the sender, co-signed by the
+// server. The transaction is only final once RefundLocktime is reached.
+func (h VHTLC) Refund(bitcoinClient *BitcoinClient) ([]byte, error) {
+	return h.spend(ArkLeafRefund, []Cosigner{h.senderKeys, h.serverKeys}, h.sender, h.RefundLocktime, 0, bitcoinClient)
+}
+
+// UnilateralClaim pays the VHTLC to a fresh key of the receiver without the
+// server, once the output is VHTLC_UNILATERAL_CLAIM_DELAY blocks deep.
+func (h VHTLC) UnilateralClaim(preimage [32]byte, bitcoinClient *BitcoinClient) ([]byte, error) {
+	if sha256.Sum256(preimage[:]) != h.PaymentHash {
+		return nil, fmt.Errorf("preimage does not match the vhtlc payment hash")
+	}
+
+	return h.spend(ArkLeafUnilateralClaim, []Cosigner{h.receiverKeys}, h.receiver, 0, VHTLC_UNILATERAL_CLAIM_DELAY, bitcoinClient, preimage[:])
+}
+
+// UnilateralRefund pays the VHTLC back to a fresh key of the sender without
+// the server, once the output is VHTLC_UNILATERAL_REFUND_DELAY blocks deep.
+func (h VHTLC) UnilateralRefund(bitcoinClient *BitcoinClient) ([]byte, error) {
+	return h.spend(ArkLeafUnilateralRefund, []Cosigner{h.senderKeys}, h.sender, 0, VHTLC_UNILATERAL_REFUND_DELAY, bitcoinClient)
+}
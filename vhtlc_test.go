@@ -0,0 +1,137 @@
+package taponark_test
+
+import (
+	"crypto/sha256"
+	"taponark"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/taproot-assets/proof"
+	"github.com/stretchr/testify/require"
+)
+
+// mine mines blocks on top of the fake chain.
+func (n *testNetwork) mine(blocks int) {
+	for range blocks {
+		n.esplora.MineBlock()
+	}
+}
+
+// requireSpend checks that the proof file moves the VHTLC output to the
+// recipient with valid witnesses, and imports it.
+func (n *testNetwork) requireSpend(t *testing.T, htlc taponark.VHTLC, proofFile []byte, recipient *taponark.TapClient) *proof.Proof {
+	t.Helper()
+
+	file, err := proof.DecodeFile(proofFile)
+	require.NoError(t, err)
+	spendProof, err := file.LastProof()
+	require.NoError(t, err)
+	spendTx := n.requireConfirmed(t, spendProof.AnchorTx.TxHash())
+	n.requireValidWitnesses(t, spendTx)
+
+	require.NoError(t, taponark.SubmitProof(htlc.GenesisPoint, proofFile, recipient))
+
+	return spendProof
+}
+
+func TestVHTLC(t *testing.T) {
+	preimage := [32]byte{1, 2, 3}
+	paymentHash := sha256.Sum256(preimage[:])
+
+	// The recipient of every spend ends up with the boarded asset. The fake
+	// chain does not enforce timelocks, tooEarly moves the spend before its
+	// timelock for the script engine to refuse it
+	tests := []struct {
+		name      string
+		recipient func(n *testNetwork) *taponark.TapClient
+		spend     func(t *testing.T, n *testNetwork, htlc taponark.VHTLC) ([]byte, uint32)
+		tooEarly  func(tx *wire.MsgTx, htlc taponark.VHTLC)
+	}{{
+		name:      "claim",
+		recipient: func(n *testNetwork) *taponark.TapClient { return &n.exit },
+		spend: func(t *testing.T, n *testNetwork, htlc taponark.VHTLC) ([]byte, uint32) {
+			_, err := htlc.Claim([32]byte{}, &n.bitcoin)
+			require.Error(t, err)
+
+			proofFile, err := htlc.Claim(preimage, &n.bitcoin)
+			require.NoError(t, err)
+			return proofFile, 0
+		},
+	}, {
+		name:      "refund",
+		recipient: func(n *testNetwork) *taponark.TapClient { return &n.boarding },
+		spend: func(t *testing.T, n *testNetwork, htlc taponark.VHTLC) ([]byte, uint32) {
+			height, err := n.chain.GetBlockCount()
+			require.NoError(t, err)
+			n.mine(int(int64(htlc.RefundLocktime) - height))
+
+			proofFile, err := htlc.Refund(&n.bitcoin)
+			require.NoError(t, err)
+			return proofFile, 0
+		},
+		tooEarly: func(tx *wire.MsgTx, htlc taponark.VHTLC) {
+			tx.LockTime = htlc.RefundLocktime - 1
+		},
+	}, {
+		name:      "unilateral claim",
+		recipient: func(n *testNetwork) *taponark.TapClient { return &n.exit },
+		spend: func(t *testing.T, n *testNetwork, htlc taponark.VHTLC) ([]byte, uint32) {
+			n.mine(taponark.VHTLC_UNILATERAL_CLAIM_DELAY)
+
+			proofFile, err := htlc.UnilateralClaim(preimage, &n.bitcoin)
+			require.NoError(t, err)
+			return proofFile, taponark.VHTLC_UNILATERAL_CLAIM_DELAY
+		},
+		tooEarly: func(tx *wire.MsgTx, _ taponark.VHTLC) {
+			tx.TxIn[0].Sequence = taponark.VHTLC_UNILATERAL_CLAIM_DELAY - 1
+		},
+	}, {
+		name:      "unilateral refund",
+		recipient: func(n *testNetwork) *taponark.TapClient { return &n.boarding },
+		spend: func(t *testing.T, n *testNetwork, htlc taponark.VHTLC) ([]byte, uint32) {
+			n.mine(taponark.VHTLC_UNILATERAL_REFUND_DELAY)
+
+			proofFile, err := htlc.UnilateralRefund(&n.bitcoin)
+			require.NoError(t, err)
+			return proofFile, taponark.VHTLC_UNILATERAL_REFUND_DELAY
+		},
+		tooEarly: func(tx *wire.MsgTx, _ taponark.VHTLC) {
+			tx.TxIn[0].Sequence = taponark.VHTLC_UNILATERAL_REFUND_DELAY - 1
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n := newTestNetwork(t)
+			recipient := test.recipient(n)
+			assetBalance, _ := n.balance(t, recipient)
+
+			boarding := n.board(t, taponark.BtcSpendPathScript)
+			height, err := n.chain.GetBlockCount()
+			require.NoError(t, err)
+			htlc, err := taponark.CreateVHTLC(n.assetId, boarding, &n.exit, &n.server, paymentHash, uint32(height+10), &n.bitcoin)
+			require.NoError(t, err)
+
+			proofFile, relativeLock := test.spend(t, n, htlc)
+			spendProof := n.requireSpend(t, htlc, proofFile, recipient)
+			if relativeLock > 0 {
+				require.Equal(t, relativeLock, spendProof.AnchorTx.TxIn[0].Sequence)
+				require.EqualValues(t, relativeLock, spendProof.Asset.RelativeLockTime)
+			}
+			if test.tooEarly != nil {
+				early := spendProof.AnchorTx.Copy()
+				test.tooEarly(early, htlc)
+				err := n.scriptEngine(t, early, 0).Execute()
+				require.True(t, txscript.IsErrorCode(err, txscript.ErrUnsatisfiedLockTime), "spend before the timelock: %v", err)
+			}
+
+			assetBalanceAfter, _ := n.balance(t, recipient)
+			if recipient == &n.boarding {
+				require.Equal(t, assetBalance, assetBalanceAfter)
+			} else {
+				require.Equal(t, assetBalance+testAssetAmount, assetBalanceAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package taponark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// StoredVtxo is a VTXO of the client in a leaf of a finalized round.
+type StoredVtxo struct {
+	RoundTxid   string     `json:"round_txid"`
+	Outpoint    string     `json:"outpoint"`
+	Type        OutputType `json:"type"`
+	AssetAmount uint64     `json:"asset_amount,omitempty"`
+	BtcAmount   int64      `json:"btc_amount,omitempty"`
+	// Exited is set once the VTXO was exited on-chain
+	Exited bool `json:"exited,omitempty"`
+}
+
+// StoredRound keeps everything needed to exit the client's VTXOs of a round
+// without the server.
+type StoredRound struct {
+	Txid         string `json:"txid"`
+	GenesisPoint string `json:"genesis_point"`
+	// Tree is the serialized GetRoundTreeResponse of the round
+	Tree []byte `json:"tree"`
+	// ExitProofs are the proof files of the asset VTXOs once exited
+	ExitProofs [][]byte `json:"exit_proofs,omitempty"`
+}
+
+// VtxoStore persists the client's VTXOs and their exit data in a JSON file,
+// the file is rewritten on every change.
+type VtxoStore struct {
+	path string
+
+	mu     sync.Mutex
+	Rounds map[string]*StoredRound `json:"rounds"`
+	Vtxos  []StoredVtxo            `json:"vtxos"`
+}
+
+// OpenVtxoStore loads the store at path, a missing file opens an empty store.
+func OpenVtxoStore(path string) (*VtxoStore, error) {
+	store := &VtxoStore{path: path, Rounds: make(map[string]*StoredRound)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read vtxo store %v", err)
+	}
+
+	err = json.Unmarshal(data, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode vtxo store %v", err)
+	}
+	if store.Rounds == nil {
+		store.Rounds = make(map[string]*StoredRound)
+	}
+
+	return store, nil
+}
+
+// AddRound stores a round with the client's VTXOs in it, a known round is
+// kept as is.
+func (s *VtxoStore) AddRound(round StoredRound, vtxos []StoredVtxo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.Rounds[round.Txid]; ok {
+		return nil
+	}
+
+	s.Rounds[round.Txid] = &round
+	s.Vtxos = append(s.Vtxos, vtxos...)
+
+	return s.save()
+}
+
+func (s *VtxoStore) Round(txid string) (StoredRound, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	round, ok := s.Rounds[txid]
+	if !ok {
+		return StoredRound{}, false
+	}
+
+	return *round, true
+}
+
+// ListVtxos returns the VTXOs not exited yet.
+func (s *VtxoStore) ListVtxos() []StoredVtxo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vtxos := make([]StoredVtxo, 0, len(s.Vtxos))
+	for _, vtxo := range s.Vtxos {
+		if !vtxo.Exited {
+			vtxos = append(vtxos, vtxo)
+		}
+	}
+
+	return vtxos
+}
+
+// SetExited marks every VTXO of the round exited and keeps the proof files of
+// its asset VTXOs.
+func (s *VtxoStore) SetExited(txid string, exitProofs [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	round, ok := s.Rounds[txid]
+	if !ok {
+		return fmt.Errorf("unknown round %s", txid)
+	}
+
+	round.ExitProofs = exitProofs
+	for i := range s.Vtxos {
+		if s.Vtxos[i].RoundTxid == txid {
+			s.Vtxos[i].Exited = true
+		}
+	}
+
+	return s.save()
+}
+
+// save writes the store next to its file first so a crash never leaves a
+// partial store behind.
+func (s *VtxoStore) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode vtxo store %v", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot write vtxo store %v", err)
+	}
+
+	err = os.Rename(tmpPath, s.path)
+	if err != nil {
+		return fmt.Errorf("cannot replace vtxo store %v", err)
+	}
+
+	return nil
+}